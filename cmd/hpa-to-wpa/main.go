@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// hpa-to-wpa converts HorizontalPodAutoscaler manifests, read from files or from the cluster,
+// into WatermarkPodAutoscaler manifests written on the standard output.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/hpaconversion"
+
+	"github.com/spf13/pflag"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/yaml"
+)
+
+// wpaManifest is a WatermarkPodAutoscaler without status.
+type wpaManifest struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        manifestMetadata                             `json:"metadata"`
+	Spec            datadoghqv1alpha1.WatermarkPodAutoscalerSpec `json:"spec"`
+}
+
+type manifestMetadata struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+var (
+	filenames     []string
+	namespace     string
+	allNamespaces bool
+	band          float64
+)
+
+func main() {
+	pflag.StringSliceVarP(&filenames, "filename", "f", nil, "Files holding the HPA manifests, - for the standard input. The HPAs are read from the cluster if not set")
+	pflag.StringVarP(&namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of the HPAs read from the cluster")
+	pflag.BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Read the HPAs of all the namespaces of the cluster")
+	pflag.Float64Var(&band, "band", hpaconversion.DefaultBand, "Distance, in percent of the targets of the HPAs, between the targets and the watermarks")
+	pflag.Parse()
+
+	hpas, behaviors, err := readHPAs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	failed := false
+	for i, hpa := range hpas {
+		wpa, err := hpaconversion.Convert(hpa, band)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping the HPA %s/%s: %v\n", hpa.Namespace, hpa.Name, err)
+			failed = true
+			continue
+		}
+		hpaconversion.ApplyBehavior(&wpa.Spec, behaviors[i])
+		// the identity and the status of the HPA are not relevant to the new object
+		manifest, err := yaml.Marshal(wpaManifest{
+			TypeMeta: wpa.TypeMeta,
+			Metadata: manifestMetadata{Name: wpa.Name, Namespace: wpa.Namespace, Labels: wpa.Labels},
+			Spec:     wpa.Spec,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("---\n%s", manifest)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func readHPAs() ([]*autoscalingv2.HorizontalPodAutoscaler, []*hpaconversion.Behavior, error) {
+	if len(filenames) == 0 {
+		return readClusterHPAs()
+	}
+	var hpas []*autoscalingv2.HorizontalPodAutoscaler
+	var behaviors []*hpaconversion.Behavior
+	for _, filename := range filenames {
+		fileHPAs, fileBehaviors, err := readFileHPAs(filename)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read %s: %v", filename, err)
+		}
+		hpas = append(hpas, fileHPAs...)
+		behaviors = append(behaviors, fileBehaviors...)
+	}
+	return hpas, behaviors, nil
+}
+
+// readFileHPAs reads the YAML or JSON documents of the file.
+func readFileHPAs(filename string) ([]*autoscalingv2.HorizontalPodAutoscaler, []*hpaconversion.Behavior, error) {
+	var input io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(input))
+	var hpas []*autoscalingv2.HorizontalPodAutoscaler
+	var behaviors []*hpaconversion.Behavior
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return hpas, behaviors, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		documentHPAs, documentBehaviors, err := hpaconversion.Decode(document)
+		if err != nil {
+			return nil, nil, err
+		}
+		hpas = append(hpas, documentHPAs...)
+		behaviors = append(behaviors, documentBehaviors...)
+	}
+}
+
+// readClusterHPAs lists the HPAs with the autoscaling/v2beta1 API, which has no behavior.
+func readClusterHPAs() ([]*autoscalingv2.HorizontalPodAutoscaler, []*hpaconversion.Behavior, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	clientSet, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if allNamespaces {
+		namespace = metav1.NamespaceAll
+	}
+	list, err := clientSet.AutoscalingV2beta1().HorizontalPodAutoscalers(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to list the HPAs: %v", err)
+	}
+	hpas := make([]*autoscalingv2.HorizontalPodAutoscaler, 0, len(list.Items))
+	for i := range list.Items {
+		hpas = append(hpas, &list.Items[i])
+	}
+	return hpas, make([]*hpaconversion.Behavior, len(hpas)), nil
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/DataDog/watermarkpodautoscaler/pkg/apis"
 	"github.com/DataDog/watermarkpodautoscaler/pkg/controller"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
 	"github.com/DataDog/watermarkpodautoscaler/version"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
@@ -31,10 +32,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
 )
 
-// Change below variables to serve metrics on different host or port.
+// Change below variables to serve metrics and health probes on different host or port.
 var (
-	metricsHost       = "0.0.0.0"
-	metricsPort int32 = 8383
+	metricsHost           = "0.0.0.0"
+	metricsPort     int32 = 8383
+	healthProbePort int32 = 8081
 )
 var log = logf.Log.WithName("cmd")
 var printVersionArg bool
@@ -83,8 +85,18 @@ func main() {
 
 	ctx := context.TODO()
 
+	// Each shard processes its own namespaces, with its own leader
+	shard, err := util.SetupSharding(os.Getenv(k8sutil.PodNameEnvVar))
+	if err != nil {
+		log.Error(err, "Failed to set up the sharding")
+		os.Exit(1)
+	}
+	if shard.Count > 1 {
+		log.Info("Processing the namespaces of a shard", "shard", shard.Index, "shards", shard.Count)
+	}
+
 	// Become the leader before proceeding
-	err = leader.Become(ctx, "watermarkpodautoscaler-lock")
+	err = leader.Become(ctx, shard.LockName("watermarkpodautoscaler-lock"))
 	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
@@ -92,9 +104,10 @@ func main() {
 
 	// Create a new Cmd to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{
-		Namespace:          namespace,
-		MapperProvider:     restmapper.NewDynamicRESTMapper,
-		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		Namespace:              namespace,
+		MapperProvider:         restmapper.NewDynamicRESTMapper,
+		MetricsBindAddress:     fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		HealthProbeBindAddress: fmt.Sprintf("%s:%d", metricsHost, healthProbePort),
 	})
 	if err != nil {
 		log.Error(err, "")
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+)
+
+// CheckScalingPlanValidity use to check the validity of a ScalingPlan
+// return nil if valid, else an error
+func CheckScalingPlanValidity(plan *ScalingPlan) error {
+	if plan.Spec.WPA == "" {
+		return fmt.Errorf("the Spec.WPA should be set")
+	}
+	if len(plan.Spec.Steps) == 0 {
+		return fmt.Errorf("the Spec.Steps should have at least one step")
+	}
+	for i, step := range plan.Spec.Steps {
+		if i > 0 && !plan.Spec.Steps[i-1].At.Before(&step.At) {
+			msg := fmt.Sprintf("the Spec.Steps should be in chronological order, the step %d at %s is not after the step %d at %s", i, step.At.Format(time.RFC3339), i-1, plan.Spec.Steps[i-1].At.Format(time.RFC3339))
+			return fmt.Errorf(msg)
+		}
+		if step.Replicas != nil && *step.Replicas < 1 {
+			msg := fmt.Sprintf("the Replicas of the step %d should be at least 1, currently %d", i, *step.Replicas)
+			return fmt.Errorf(msg)
+		}
+		if step.MinReplicas != nil && *step.MinReplicas < 1 {
+			msg := fmt.Sprintf("the MinReplicas of the step %d should be at least 1, currently %d", i, *step.MinReplicas)
+			return fmt.Errorf(msg)
+		}
+	}
+	return nil
+}
+
+// ActiveStep returns the index of the last step of the plan whose time is not after t, -1 if there is none.
+func (p *ScalingPlan) ActiveStep(t time.Time) int {
+	active := -1
+	for i, step := range p.Spec.Steps {
+		if step.At.Time.After(t) {
+			break
+		}
+		active = i
+	}
+	return active
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package v1alpha1
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScalingPlan is the Schema for the scalingplans API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="wpa",type="string",JSONPath=".spec.wpa"
+// +kubebuilder:printcolumn:name="step",type="integer",JSONPath=".status.activeStep"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=scalingplans,shortName=splan
+type ScalingPlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ScalingPlanSpec   `json:"spec,omitempty"`
+	Status ScalingPlanStatus `json:"status,omitempty"`
+}
+
+// ScalingPlanSpec defines the desired state of ScalingPlan
+// +k8s:openapi-gen=true
+type ScalingPlanSpec struct {
+	// Name of the WPA, in the namespace of the plan, the steps apply to.
+	WPA string `json:"wpa"`
+	// Steps of the plan, in chronological order. Each step applies from its time until the time of the next one.
+	// +listType=set
+	Steps []ScalingPlanStep `json:"steps"`
+}
+
+// ScalingPlanStep overrides the replicas of a WPA from a given time. A step setting neither replicas nor minReplicas
+// hands the WPA back to its own spec.
+// +k8s:openapi-gen=true
+type ScalingPlanStep struct {
+	// Time from which the step applies.
+	At metav1.Time `json:"at"`
+	// Number of replicas the target is pinned to, like the replicas of the WPA.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Minimum number of replicas of the target, replacing the minReplicas of the WPA.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+}
+
+// ScalingPlanStatus defines the observed state of ScalingPlan
+// +k8s:openapi-gen=true
+type ScalingPlanStatus struct {
+	// Index of the step currently applied, -1 before the time of the first step.
+	ActiveStep int32 `json:"activeStep"`
+	// Time of the next step of the plan, if any.
+	// +optional
+	NextStepTime *metav1.Time `json:"nextStepTime,omitempty"`
+	// +listType=set
+	Conditions []autoscalingv2.HorizontalPodAutoscalerCondition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScalingPlanList contains a list of ScalingPlan
+// +k8s:openapi-gen=true
+type ScalingPlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []ScalingPlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScalingPlan{}, &ScalingPlanList{})
+}
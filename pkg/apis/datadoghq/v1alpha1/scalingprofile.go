@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const profilePeriodTimeLayout = "15:04"
+
+// IsActive returns whether the time t is part of the period.
+// The days of a period spanning midnight are the ones on which it starts.
+func (p *ProfilePeriod) IsActive(t time.Time) (bool, error) {
+	start, end, loc, err := p.parse()
+	if err != nil {
+		return false, err
+	}
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case start <= end:
+		return start <= minute && minute < end && p.includesDay(t.Weekday()), nil
+	case minute >= start:
+		return p.includesDay(t.Weekday()), nil
+	case minute < end:
+		return p.includesDay(t.AddDate(0, 0, -1).Weekday()), nil
+	}
+	return false, nil
+}
+
+// parse returns the start and the end of the period, in minutes since midnight, and its location.
+func (p *ProfilePeriod) parse() (start, end int, loc *time.Location, err error) {
+	if start, err = parseMinuteOfDay(p.Start); err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid start %q: %v", p.Start, err)
+	}
+	if end, err = parseMinuteOfDay(p.End); err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid end %q: %v", p.End, err)
+	}
+	if start == end {
+		return 0, 0, nil, fmt.Errorf("the start and the end of the period are both %s", p.Start)
+	}
+	if loc, err = time.LoadLocation(p.TimeZone); err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid time zone %q: %v", p.TimeZone, err)
+	}
+	for _, day := range p.Days {
+		if _, ok := parseWeekday(day); !ok {
+			return 0, 0, nil, fmt.Errorf("invalid day %q", day)
+		}
+	}
+	return start, end, loc, nil
+}
+
+func (p *ProfilePeriod) includesDay(weekday time.Weekday) bool {
+	if len(p.Days) == 0 {
+		return true
+	}
+	for _, day := range p.Days {
+		if d, ok := parseWeekday(day); ok && d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func parseMinuteOfDay(value string) (int, error) {
+	t, err := time.Parse(profilePeriodTimeLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(day, d.String()) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func checkProfiles(spec *WatermarkPodAutoscalerSpec) error {
+	names := map[string]bool{}
+	for _, profile := range spec.Profiles {
+		if profile.Name == "" {
+			return fmt.Errorf("a profile should have a name")
+		}
+		if names[profile.Name] {
+			return fmt.Errorf("several profiles are named %s", profile.Name)
+		}
+		names[profile.Name] = true
+		if len(profile.Periods) == 0 {
+			return fmt.Errorf("the profile %s should have at least one period", profile.Name)
+		}
+		for _, period := range profile.Periods {
+			if _, _, _, err := period.parse(); err != nil {
+				return fmt.Errorf("invalid period for the profile %s: %v", profile.Name, err)
+			}
+		}
+		for _, watermarks := range profile.Watermarks {
+			if !hasMetricNamed(spec.Metrics, watermarks.MetricName) {
+				return fmt.Errorf("the profile %s overrides the watermarks of the metric %s, which is not used by the WPA", profile.Name, watermarks.MetricName)
+			}
+			if watermarks.HighWatermark.MilliValue() < watermarks.LowWatermark.MilliValue() {
+				return fmt.Errorf("the low watermark of the metric %s in the profile %s has to be inferior to its high watermark", watermarks.MetricName, profile.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// hasMetricNamed returns whether one of the External metrics, or the resource of one of the Resource metrics, is named name.
+func hasMetricNamed(metrics []MetricSpec, name string) bool {
+	for _, metric := range metrics {
+		if metric.External != nil && metric.External.MetricName == name {
+			return true
+		}
+		if metric.Resource != nil && string(metric.Resource.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func checkCalendar(spec *WatermarkPodAutoscalerSpec) error {
+	calendar := spec.Calendar
+	if calendar == nil {
+		return nil
+	}
+	if (calendar.URL == "") == (calendar.ConfigMapKeyRef == nil) {
+		return fmt.Errorf("the calendar should have either a url or a configMapKeyRef")
+	}
+	if calendar.URL != "" {
+		if u, err := url.Parse(calendar.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("the url of the calendar should be an https URL, currently %q", calendar.URL)
+		}
+	}
+	if _, err := time.LoadLocation(calendar.TimeZone); err != nil {
+		return fmt.Errorf("invalid time zone %q: %v", calendar.TimeZone, err)
+	}
+	if calendar.Profile != "" && !hasProfileNamed(spec.Profiles, calendar.Profile) {
+		return fmt.Errorf("the profile %s is not defined", calendar.Profile)
+	}
+	if calendar.MinReplicas != nil && *calendar.MinReplicas < 1 {
+		return fmt.Errorf("the minReplicas should be at least 1, currently %d", *calendar.MinReplicas)
+	}
+	if calendar.MinReplicas != nil && calendar.MaxReplicas != nil && *calendar.MaxReplicas < *calendar.MinReplicas {
+		return fmt.Errorf("the minReplicas should be inferior to the maxReplicas, currently %d and %d", *calendar.MinReplicas, *calendar.MaxReplicas)
+	}
+	return nil
+}
+
+func hasProfileNamed(profiles []ScalingProfile, name string) bool {
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return true
+		}
+	}
+	return false
+}
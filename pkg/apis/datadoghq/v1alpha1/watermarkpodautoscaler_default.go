@@ -5,7 +5,12 @@
 
 package v1alpha1
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 const (
 	defaultTolerance                       = 0.1
@@ -44,91 +49,518 @@ func DefaultWatermarkPodAutoscaler(wpa *WatermarkPodAutoscaler) *WatermarkPodAut
 	if wpa.Spec.UpscaleForbiddenWindowSeconds == 0 {
 		defaultWPA.Spec.UpscaleForbiddenWindowSeconds = defaultUpscaleForbiddenWindowSeconds
 	}
+	// the deprecated scaleDownDisabled is converted to its scaling mode
+	if wpa.Spec.ScaleDownDisabled && wpa.Spec.ScalingMode == "" {
+		defaultWPA.Spec.ScalingMode = ScalingModeUpOnly
+	}
 	return defaultWPA
 }
 
 // IsDefaultWatermarkPodAutoscaler used to know if a WatermarkPodAutoscaler has default values
 func IsDefaultWatermarkPodAutoscaler(wpa *WatermarkPodAutoscaler) bool {
+	return len(MissingDefaults(wpa)) == 0
+}
 
+// MissingDefaults returns the names of the options of the WatermarkPodAutoscaler that are not set
+// and fall back to their default values
+func MissingDefaults(wpa *WatermarkPodAutoscaler) []string {
+	var missing []string
 	if wpa.Spec.MinReplicas == nil {
-		return false
+		missing = append(missing, "minReplicas")
 	}
 	if wpa.Spec.Algorithm == "" {
-		return false
+		missing = append(missing, "algorithm")
 	}
 	if wpa.Spec.Tolerance == 0 {
-		return false
+		missing = append(missing, "tolerance")
 	}
 	if wpa.Spec.ScaleUpLimitFactor == 0 {
-		return false
+		missing = append(missing, "scaleUpLimitFactor")
 	}
 	if wpa.Spec.ScaleDownLimitFactor == 0 {
-		return false
+		missing = append(missing, "scaleDownLimitFactor")
 	}
 	if wpa.Spec.DownscaleForbiddenWindowSeconds == 0 {
-		return false
+		missing = append(missing, "downscaleForbiddenWindowSeconds")
 	}
 	if wpa.Spec.UpscaleForbiddenWindowSeconds == 0 {
-		return false
+		missing = append(missing, "upscaleForbiddenWindowSeconds")
+	}
+	if wpa.Spec.ScaleDownDisabled && wpa.Spec.ScalingMode == "" {
+		missing = append(missing, "scalingMode")
 	}
-	return true
+	return missing
 }
 
 // CheckWPAValidity use to check the validty of a WatermarkPodAutoscaler
 // return nil if valid, else an error
 func CheckWPAValidity(wpa *WatermarkPodAutoscaler) error {
-	if wpa.Spec.ScaleTargetRef.Kind == "" || wpa.Spec.ScaleTargetRef.Name == "" {
+	if wpa.Spec.ScaleTargetRef.Kind == "" || !hasNameOrSelector(wpa.Spec.ScaleTargetRef) {
 		msg := fmt.Sprintf("the Spec.ScaleTargetRef should be populated, currently Kind:%s and/or Name:%s are not set properly", wpa.Spec.ScaleTargetRef.Kind, wpa.Spec.ScaleTargetRef.Name)
 		return fmt.Errorf(msg)
 	}
+	for _, ref := range wpa.Spec.ScaleTargetRefs {
+		if ref.Kind == "" || !hasNameOrSelector(ref.CrossVersionObjectReference) || ref.Weight < 1 {
+			msg := fmt.Sprintf("the Spec.ScaleTargetRefs should have a Kind, either a Name or a Selector and a positive Weight, currently Kind:%s, Name:%s and Weight:%d", ref.Kind, ref.Name, ref.Weight)
+			return fmt.Errorf(msg)
+		}
+	}
 	if wpa.Spec.MinReplicas == nil || wpa.Spec.MaxReplicas < *wpa.Spec.MinReplicas {
 		msg := fmt.Sprintf("watermark pod autoscaler requires the minimum number of replicas to be configured and inferior to the maximum")
 		return fmt.Errorf(msg)
 	}
-	return checkWPAMetricsValidity(wpa)
+	if err := checkScalingParameters(&wpa.Spec); err != nil {
+		return err
+	}
+	if wpa.Spec.Replicas != nil && *wpa.Spec.Replicas < 1 {
+		msg := fmt.Sprintf("the Spec.Replicas should be at least 1, currently %d", *wpa.Spec.Replicas)
+		return fmt.Errorf(msg)
+	}
+	if wpa.Spec.Budget != nil && (wpa.Spec.Budget.CostPerReplicaHour.MilliValue() <= 0 || wpa.Spec.Budget.MaxCostPerHour.MilliValue() <= 0) {
+		msg := fmt.Sprintf("the Spec.Budget costs should be strictly positive, currently CostPerReplicaHour:%s and MaxCostPerHour:%s", wpa.Spec.Budget.CostPerReplicaHour.String(), wpa.Spec.Budget.MaxCostPerHour.String())
+		return fmt.Errorf(msg)
+	}
+	if c := wpa.Spec.CapacityCeiling; c != nil {
+		if err := checkHTTPMetricSource(&c.HTTPMetricSource); err != nil {
+			return fmt.Errorf("invalid Spec.CapacityCeiling: %v", err)
+		}
+		if c.PollIntervalSeconds < 0 {
+			return fmt.Errorf("the Spec.CapacityCeiling.PollIntervalSeconds should be positive, currently %d", c.PollIntervalSeconds)
+		}
+	}
+	if c := wpa.Spec.SteppedConvergence; c != nil && (c.StepFraction <= 0 || c.StepFraction > 1 || (c.MinRatio != 0 && c.MinRatio < 1)) {
+		msg := fmt.Sprintf("the Spec.SteppedConvergence should have a StepFraction in ]0, 1] and a MinRatio of at least 1, currently StepFraction:%v and MinRatio:%v", c.StepFraction, c.MinRatio)
+		return fmt.Errorf(msg)
+	}
+	if b := wpa.Spec.Baseline; b != nil && (b.Replicas < 1 || b.IdleWindowSeconds < 1 || b.IdleThreshold.MilliValue() <= 0) {
+		msg := fmt.Sprintf("the Spec.Baseline should have at least 1 replica, an idle window of at least 1 second and a strictly positive idle threshold, currently Replicas:%d, IdleWindowSeconds:%d and IdleThreshold:%s", b.Replicas, b.IdleWindowSeconds, b.IdleThreshold.String())
+		return fmt.Errorf(msg)
+	}
+	if rc := wpa.Spec.RemoteCluster; rc != nil {
+		if rc.KubeconfigSecretRef.Name == "" || rc.KubeconfigSecretRef.Key == "" {
+			msg := fmt.Sprintf("the Spec.RemoteCluster should reference the Name and the Key of a Secret, currently Name:%s and Key:%s", rc.KubeconfigSecretRef.Name, rc.KubeconfigSecretRef.Key)
+			return fmt.Errorf(msg)
+		}
+		if wpa.Spec.ResourceQuotaAware || wpa.Spec.NodePressureAware || wpa.Spec.FreezeDuringRollout || wpa.Spec.StatefulSet != nil || hasSelector(wpa) {
+			msg := fmt.Sprintf("the Spec.RemoteCluster can't be set with the ResourceQuotaAware, NodePressureAware, FreezeDuringRollout, StatefulSet options or the selectors of the targets")
+			return fmt.Errorf(msg)
+		}
+	}
+	if shards := wpa.Spec.Shards; shards != nil {
+		if len(shards.Clusters) == 0 || shards.Weight < 0 {
+			msg := fmt.Sprintf("the Spec.Shards should have at least one cluster and a positive Weight, currently %d clusters and Weight:%d", len(shards.Clusters), shards.Weight)
+			return fmt.Errorf(msg)
+		}
+		for _, cluster := range shards.Clusters {
+			if cluster.KubeconfigSecretRef.Name == "" || cluster.KubeconfigSecretRef.Key == "" || cluster.Weight < 0 {
+				msg := fmt.Sprintf("the clusters of the Spec.Shards should reference the Name and the Key of a Secret and have a positive Weight, currently Name:%s, Key:%s and Weight:%d", cluster.KubeconfigSecretRef.Name, cluster.KubeconfigSecretRef.Key, cluster.Weight)
+				return fmt.Errorf(msg)
+			}
+		}
+		for _, metric := range wpa.Spec.Metrics {
+			if metric.Type != ExternalMetricSourceType {
+				msg := fmt.Sprintf("the Spec.Shards only supports External metrics, currently %s", metric.Type)
+				return fmt.Errorf(msg)
+			}
+		}
+		if wpa.Spec.RemoteCluster != nil || wpa.Spec.ResourceQuotaAware || wpa.Spec.NodePressureAware || wpa.Spec.StatefulSet != nil || len(wpa.Spec.ScaleTargetRefs) > 0 {
+			msg := fmt.Sprintf("the Spec.Shards can't be set with the RemoteCluster, ResourceQuotaAware, NodePressureAware, StatefulSet options or the scaleTargetRefs")
+			return fmt.Errorf(msg)
+		}
+	}
+	if bg := wpa.Spec.BlueGreen; bg != nil && (bg.ServiceName == "" || wpa.Spec.ScaleTargetRef.Selector == nil) {
+		msg := fmt.Sprintf("the Spec.BlueGreen should have a ServiceName and the Spec.ScaleTargetRef a Selector, currently ServiceName:%s", bg.ServiceName)
+		return fmt.Errorf(msg)
+	}
+	switch wpa.Spec.ScalingMode {
+	case "", ScalingModeBoth, ScalingModeUpOnly, ScalingModeDownOnly:
+	default:
+		msg := fmt.Sprintf("the Spec.ScalingMode should be Both, UpOnly or DownOnly, currently %s", wpa.Spec.ScalingMode)
+		return fmt.Errorf(msg)
+	}
+	switch wpa.Spec.DriftPolicy {
+	case "", DriftPolicyIgnore, DriftPolicyCorrect:
+	default:
+		msg := fmt.Sprintf("the Spec.DriftPolicy should be Ignore or Correct, currently %s", wpa.Spec.DriftPolicy)
+		return fmt.Errorf(msg)
+	}
+	if err := checkRounding(wpa.Spec.Rounding); err != nil {
+		return fmt.Errorf("invalid Spec.Rounding: %v", err)
+	}
+	if wpa.Spec.MaxChangePerReconcile != nil && *wpa.Spec.MaxChangePerReconcile < 1 {
+		msg := fmt.Sprintf("the Spec.MaxChangePerReconcile should be at least 1, currently %d", *wpa.Spec.MaxChangePerReconcile)
+		return fmt.Errorf(msg)
+	}
+	if wpa.Spec.MaxScaleEventsPerHour != nil && *wpa.Spec.MaxScaleEventsPerHour < 1 {
+		msg := fmt.Sprintf("the Spec.MaxScaleEventsPerHour should be at least 1, currently %d", *wpa.Spec.MaxScaleEventsPerHour)
+		return fmt.Errorf(msg)
+	}
+	if f := wpa.Spec.FlappingDetection; f != nil && (f.Reversals < 1 || f.WindowSeconds < 1 || (f.Factor != 0 && f.Factor < 1)) {
+		msg := fmt.Sprintf("the Spec.FlappingDetection should have at least 1 reversal, a window of at least 1 second and a factor of at least 1, currently Reversals:%d, WindowSeconds:%d and Factor:%v", f.Reversals, f.WindowSeconds, f.Factor)
+		return fmt.Errorf(msg)
+	}
+	if d := wpa.Spec.DryRunReport; d != nil && ((d.WindowSeconds != 0 && d.WindowSeconds < 60) || d.MaxReports < 0) {
+		msg := fmt.Sprintf("the Spec.DryRunReport should have a window of at least 60 seconds and a positive number of reports, currently WindowSeconds:%d and MaxReports:%d", d.WindowSeconds, d.MaxReports)
+		return fmt.Errorf(msg)
+	}
+	if d := wpa.Spec.DecisionHistory; d != nil && (d.MaxDecisions < 0 || (d.MaxSizeBytes != 0 && (d.MaxSizeBytes < 1024 || d.MaxSizeBytes > 1048576))) {
+		msg := fmt.Sprintf("the Spec.DecisionHistory should have a positive number of decisions and a size between 1024 and 1048576 bytes, currently MaxDecisions:%d and MaxSizeBytes:%d", d.MaxDecisions, d.MaxSizeBytes)
+		return fmt.Errorf(msg)
+	}
+	if f := wpa.Spec.FailedPods; f != nil && f.WarningThresholdPercent != nil && (*f.WarningThresholdPercent < 1 || *f.WarningThresholdPercent > 100) {
+		msg := fmt.Sprintf("the Spec.FailedPods.WarningThresholdPercent should be between 1 and 100, currently %d", *f.WarningThresholdPercent)
+		return fmt.Errorf(msg)
+	}
+	if h := wpa.Spec.Hysteresis; h != nil && (h.Percent < 1 || h.Percent > 99 || h.DurationSeconds < 1) {
+		msg := fmt.Sprintf("the Spec.Hysteresis should have a percent between 1 and 99 and a duration of at least 1 second, currently Percent:%d and DurationSeconds:%d", h.Percent, h.DurationSeconds)
+		return fmt.Errorf(msg)
+	}
+	if a := wpa.Spec.AdaptiveTolerance; a != nil && (a.Samples < 2 || a.Factor < 0 || a.MaxTolerance < 0 || a.MaxTolerance >= 1) {
+		msg := fmt.Sprintf("the Spec.AdaptiveTolerance should have at least 2 samples, a positive factor and a maximum tolerance between 0 and 1, currently Samples:%d, Factor:%v and MaxTolerance:%v", a.Samples, a.Factor, a.MaxTolerance)
+		return fmt.Errorf(msg)
+	}
+	if wpa.Spec.CanaryPercent < 0 || wpa.Spec.CanaryPercent > 99 || wpa.Spec.CanaryWindowSeconds < 0 {
+		msg := fmt.Sprintf("the Spec.CanaryPercent should be between 1 and 99 and the Spec.CanaryWindowSeconds positive, currently CanaryPercent:%d and CanaryWindowSeconds:%d", wpa.Spec.CanaryPercent, wpa.Spec.CanaryWindowSeconds)
+		return fmt.Errorf(msg)
+	}
+	if h := wpa.Spec.RecommendationHistory; h != nil && (h.Size < 1 || (h.Aggregation != "" && h.Aggregation != RecommendationAggregationMedian && h.Aggregation != RecommendationAggregationP90)) {
+		msg := fmt.Sprintf("the Spec.RecommendationHistory should have a size of at least 1 and a median or p90 aggregation, currently Size:%d and Aggregation:%s", h.Size, h.Aggregation)
+		return fmt.Errorf(msg)
+	}
+	if _, err := metav1.LabelSelectorAsSelector(wpa.Spec.ExcludedPodSelector); err != nil {
+		msg := fmt.Sprintf("the Spec.ExcludedPodSelector is invalid: %v", err)
+		return fmt.Errorf(msg)
+	}
+	if wpa.Spec.StatefulSet != nil && wpa.Spec.StatefulSet.OrderedDownscaleStep != nil && *wpa.Spec.StatefulSet.OrderedDownscaleStep < 1 {
+		msg := fmt.Sprintf("the Spec.StatefulSet.OrderedDownscaleStep should be at least 1, currently %d", *wpa.Spec.StatefulSet.OrderedDownscaleStep)
+		return fmt.Errorf(msg)
+	}
+	if err := checkProfiles(&wpa.Spec); err != nil {
+		return fmt.Errorf("invalid Spec.Profiles: %v", err)
+	}
+	if err := checkCalendar(&wpa.Spec); err != nil {
+		return fmt.Errorf("invalid Spec.Calendar: %v", err)
+	}
+	if err := checkWPAMetricsValidity(wpa); err != nil {
+		return err
+	}
+	return checkMetricFallbacks(wpa.Spec.Metrics)
 }
 
 func checkWPAMetricsValidity(wpa *WatermarkPodAutoscaler) (err error) {
 	// This function will not be needed for the vanilla k8s.
 	// For now we check only nil pointers here as they crash the default controller algorithm
 	// We also make sure that the Watermarks are properly set.
-	for _, metric := range wpa.Spec.Metrics {
+	for i, metric := range wpa.Spec.Metrics {
 		switch metric.Type {
 		case "External":
+			field := fmt.Sprintf("Spec.Metrics[%d].External", i)
 			if metric.External == nil {
-				return fmt.Errorf("metric.External is nil while metric.Type is '%s'", metric.Type)
+				return fmt.Errorf("the %s is nil while the metric type is '%s'", field, metric.Type)
 			}
 			if metric.External.LowWatermark == nil || metric.External.HighWatermark == nil {
-				msg := fmt.Sprintf("Watermarks are not set correctly, removing the WPA %s/%s from the Reconciler", wpa.Namespace, wpa.Name)
+				msg := fmt.Sprintf("the %s.LowWatermark and %s.HighWatermark of the metric %s should be set", field, field, metric.External.MetricName)
+				return fmt.Errorf(msg)
+			}
+			if metric.External.HTTP == nil && isEmptySelector(metric.External.MetricSelector) {
+				msg := fmt.Sprintf("the %s.MetricSelector of the metric %s should have labels or expressions", field, metric.External.MetricName)
 				return fmt.Errorf(msg)
 			}
-			if metric.External.MetricSelector == nil {
-				msg := fmt.Sprintf("Missing Labels for the External metric %s", metric.External.MetricName)
+			name := fmt.Sprintf("%s{%s}", metric.External.MetricName, MetricSelectorLabels(metric.External.MetricSelector))
+			if metric.External.HighWatermark.MilliValue() <= metric.External.LowWatermark.MilliValue() {
+				msg := fmt.Sprintf("the %s.LowWatermark of the metric %s has to be strictly inferior to the HighWatermark, currently %s and %s", field, name, metric.External.LowWatermark.String(), metric.External.HighWatermark.String())
 				return fmt.Errorf(msg)
 			}
-			if metric.External.HighWatermark.MilliValue() < metric.External.LowWatermark.MilliValue() {
-				msg := fmt.Sprintf("Low WaterMark of External metric %s{%s} has to be strictly inferior to the High Watermark", metric.External.MetricName, metric.External.MetricSelector.MatchLabels)
+			if e := metric.External.EmergencyHighWatermark; e != nil && e.MilliValue() <= metric.External.HighWatermark.MilliValue() {
+				msg := fmt.Sprintf("the %s.EmergencyHighWatermark of the metric %s has to be strictly superior to the HighWatermark, currently %s and %s", field, name, e.String(), metric.External.HighWatermark.String())
 				return fmt.Errorf(msg)
 			}
+			if err = checkWatermarkSteps(metric.External.WatermarkSteps); err != nil {
+				return fmt.Errorf("invalid %s.WatermarkSteps of the metric %s: %v", field, name, err)
+			}
+			if policy := metric.External.MissingDatapoints; policy != nil && policy.MaxGapSeconds < 1 {
+				return fmt.Errorf("the %s.MissingDatapoints.MaxGapSeconds of the metric %s should be at least 1, currently %d", field, name, policy.MaxGapSeconds)
+			}
+			if rate := metric.External.RateOfChange; rate != nil && (rate.HorizonSeconds < 1 || rate.Slope.Sign() < 0) {
+				return fmt.Errorf("the %s.RateOfChange of the metric %s should have a positive Slope and a HorizonSeconds of at least 1, currently %s and %d", field, name, rate.Slope.String(), rate.HorizonSeconds)
+			}
+			if err = checkScaleUpStrategy(metric.External.ScaleUpStrategy); err != nil {
+				return fmt.Errorf("invalid %s.ScaleUpStrategy of the metric %s: %v", field, name, err)
+			}
+			if err = checkHTTPMetricSource(metric.External.HTTP); err != nil {
+				return fmt.Errorf("invalid %s.HTTP of the metric %s: %v", field, name, err)
+			}
+			if err = checkHistogramMetric(metric.External); err != nil {
+				return fmt.Errorf("invalid %s.Histogram of the metric %s: %v", field, name, err)
+			}
 		case "Resource":
+			field := fmt.Sprintf("Spec.Metrics[%d].Resource", i)
 			if metric.Resource == nil {
-				return fmt.Errorf("metric.Resource is nil while metric.Type is '%s'", metric.Type)
+				return fmt.Errorf("the %s is nil while the metric type is '%s'", field, metric.Type)
+			}
+			if !HasResourceWatermarks(metric.Resource) {
+				msg := fmt.Sprintf("the %s.LowWatermark and %s.HighWatermark, or their utilizations, of the metric %s should be set", field, field, metric.Resource.Name)
+				return fmt.Errorf(msg)
+			}
+			if isEmptySelector(metric.Resource.MetricSelector) {
+				msg := fmt.Sprintf("the %s.MetricSelector of the metric %s should have labels or expressions", field, metric.Resource.Name)
+				return fmt.Errorf(msg)
+			}
+			name := fmt.Sprintf("%s{%s}", metric.Resource.Name, metric.Resource.MetricSelector.MatchLabels)
+			if (metric.Resource.LowWatermark != nil || metric.Resource.HighWatermark != nil) && (metric.Resource.LowWatermarkUtilization != nil || metric.Resource.HighWatermarkUtilization != nil) {
+				msg := fmt.Sprintf("the watermarks of the %s of the metric %s can be set either as quantities or as utilizations, not both", field, name)
+				return fmt.Errorf(msg)
+			}
+			if metric.Resource.HighWatermark != nil && metric.Resource.HighWatermark.MilliValue() <= metric.Resource.LowWatermark.MilliValue() {
+				msg := fmt.Sprintf("the %s.LowWatermark of the metric %s has to be strictly inferior to the HighWatermark, currently %s and %s", field, name, metric.Resource.LowWatermark.String(), metric.Resource.HighWatermark.String())
+				return fmt.Errorf(msg)
 			}
-			if metric.Resource.LowWatermark == nil || metric.Resource.HighWatermark == nil {
-				msg := fmt.Sprintf("Watermarks are not set correctly, removing the WPA %s/%s from the Reconciler", wpa.Namespace, wpa.Name)
+			if metric.Resource.HighWatermarkUtilization != nil && *metric.Resource.HighWatermarkUtilization <= *metric.Resource.LowWatermarkUtilization {
+				msg := fmt.Sprintf("the %s.LowWatermarkUtilization of the metric %s has to be strictly inferior to the HighWatermarkUtilization, currently %d and %d", field, name, *metric.Resource.LowWatermarkUtilization, *metric.Resource.HighWatermarkUtilization)
 				return fmt.Errorf(msg)
 			}
-			if metric.Resource.MetricSelector == nil {
-				msg := fmt.Sprintf("Missing Labels for the Resource metric %s", metric.Resource.Name)
+			if e := metric.Resource.EmergencyHighWatermark; e != nil && metric.Resource.HighWatermark != nil && e.MilliValue() <= metric.Resource.HighWatermark.MilliValue() {
+				msg := fmt.Sprintf("the %s.EmergencyHighWatermark of the metric %s has to be strictly superior to the HighWatermark, currently %s and %s", field, name, e.String(), metric.Resource.HighWatermark.String())
 				return fmt.Errorf(msg)
 			}
-			if metric.Resource.HighWatermark.MilliValue() < metric.Resource.LowWatermark.MilliValue() {
-				msg := fmt.Sprintf("Low WaterMark of Resource metric %s{%s} has to be strictly inferior to the High Watermark", metric.Resource.Name, metric.Resource.MetricSelector.MatchLabels)
+			if q := metric.Resource.PodQuantile; q != nil && (*q < 1 || *q > 100) {
+				msg := fmt.Sprintf("the %s.PodQuantile of the metric %s has to be between 1 and 100, currently %d", field, name, *q)
 				return fmt.Errorf(msg)
 			}
+			if err = checkContainerWeights(metric.Resource); err != nil {
+				return fmt.Errorf("invalid %s.ContainerWeights of the metric %s: %v", field, name, err)
+			}
+			if len(metric.Resource.WatermarkSteps) > 0 && metric.Resource.HighWatermarkUtilization != nil {
+				msg := fmt.Sprintf("the %s.WatermarkSteps of the metric %s can't be used with utilizations", field, name)
+				return fmt.Errorf(msg)
+			}
+			if err = checkWatermarkSteps(metric.Resource.WatermarkSteps); err != nil {
+				return fmt.Errorf("invalid %s.WatermarkSteps of the metric %s: %v", field, name, err)
+			}
 		default:
-			return fmt.Errorf("incorrect metric.Type: '%s'", metric.Type)
+			return fmt.Errorf("the Spec.Metrics[%d].Type should be External or Resource, currently '%s'", i, metric.Type)
+		}
+		if f := metric.ScaleUpLimitFactor; f != 0 && (f < 1 || f > 100) {
+			return fmt.Errorf("the Spec.Metrics[%d].ScaleUpLimitFactor should be between 1 and 100, currently %v", i, f)
+		}
+		if f := metric.ScaleDownLimitFactor; f != 0 && (f < 1 || f > 100) {
+			return fmt.Errorf("the Spec.Metrics[%d].ScaleDownLimitFactor should be between 1 and 100, currently %v", i, f)
 		}
 	}
 	return err
 }
+
+// isEmptySelector returns whether the selector is missing or matches everything.
+func isEmptySelector(selector *metav1.LabelSelector) bool {
+	return selector == nil || (len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0)
+}
+
+// MetricSelectorLabels returns the labels of a metric selector, which can be nil for the metrics read over HTTP.
+func MetricSelectorLabels(selector *metav1.LabelSelector) map[string]string {
+	if selector == nil {
+		return nil
+	}
+	return selector.MatchLabels
+}
+
+func checkContainerWeights(source *ResourceMetricSource) error {
+	if len(source.ContainerWeights) == 0 {
+		return nil
+	}
+	if source.Container != "" {
+		return fmt.Errorf("the container weights can't be used with the container %s", source.Container)
+	}
+	names := map[string]bool{}
+	weighted := false
+	for _, w := range source.ContainerWeights {
+		if w.Name == "" || names[w.Name] {
+			return fmt.Errorf("the containers should be named once, currently %q", w.Name)
+		}
+		names[w.Name] = true
+		if w.Weight < 0 || w.Weight > 100 {
+			return fmt.Errorf("the weight of the container %s should be between 0 and 100, currently %d", w.Name, w.Weight)
+		}
+		weighted = weighted || w.Weight > 0
+	}
+	if !weighted {
+		return fmt.Errorf("at least one container should have a positive weight")
+	}
+	return nil
+}
+
+func checkWatermarkSteps(steps []WatermarkStep) error {
+	minReplicas := map[int32]bool{}
+	for _, step := range steps {
+		if step.MinReplicas < 1 {
+			return fmt.Errorf("the minReplicas of a step should be at least 1, currently %d", step.MinReplicas)
+		}
+		if minReplicas[step.MinReplicas] {
+			return fmt.Errorf("several steps start at %d replicas", step.MinReplicas)
+		}
+		minReplicas[step.MinReplicas] = true
+		if step.HighWatermark.MilliValue() < step.LowWatermark.MilliValue() {
+			return fmt.Errorf("the low watermark of the step starting at %d replicas has to be inferior to its high watermark", step.MinReplicas)
+		}
+	}
+	return nil
+}
+
+func checkScalingParameters(spec *WatermarkPodAutoscalerSpec) error {
+	switch spec.Algorithm {
+	case "absolute", "average", "averageSpecReplicas":
+	default:
+		return fmt.Errorf("the Spec.Algorithm should be absolute, average or averageSpecReplicas, currently %s", spec.Algorithm)
+	}
+	if spec.Tolerance <= 0 || spec.Tolerance >= 1 {
+		return fmt.Errorf("the Spec.Tolerance should be in ]0, 1[, currently %v", spec.Tolerance)
+	}
+	if spec.ScaleUpLimitFactor < 1 || spec.ScaleUpLimitFactor > 100 {
+		return fmt.Errorf("the Spec.ScaleUpLimitFactor should be between 1 and 100, currently %v", spec.ScaleUpLimitFactor)
+	}
+	if spec.ScaleDownLimitFactor < 1 || spec.ScaleDownLimitFactor > 100 {
+		return fmt.Errorf("the Spec.ScaleDownLimitFactor should be between 1 and 100, currently %v", spec.ScaleDownLimitFactor)
+	}
+	if spec.DownscaleForbiddenWindowSeconds < 1 || spec.UpscaleForbiddenWindowSeconds < 1 {
+		return fmt.Errorf("the Spec.DownscaleForbiddenWindowSeconds and Spec.UpscaleForbiddenWindowSeconds should be at least 1, currently %d and %d", spec.DownscaleForbiddenWindowSeconds, spec.UpscaleForbiddenWindowSeconds)
+	}
+	if spec.DownscaleEvaluationWindowSeconds < 0 || spec.UpscaleEvaluationWindowSeconds < 0 {
+		return fmt.Errorf("the Spec.DownscaleEvaluationWindowSeconds and Spec.UpscaleEvaluationWindowSeconds should be positive, currently %d and %d", spec.DownscaleEvaluationWindowSeconds, spec.UpscaleEvaluationWindowSeconds)
+	}
+	return nil
+}
+
+func checkScaleUpStrategy(strategy *ScaleUpStrategySpec) error {
+	if strategy == nil {
+		return nil
+	}
+	switch strategy.Type {
+	case ScaleUpStrategyProportional:
+	case ScaleUpStrategyLinear:
+		if strategy.UsagePerReplica == nil || strategy.UsagePerReplica.Sign() <= 0 {
+			return fmt.Errorf("the linear strategy requires a positive usagePerReplica")
+		}
+	case ScaleUpStrategyMultiplier:
+		if strategy.Multiplier <= 1 {
+			return fmt.Errorf("the multiplier should be greater than 1, currently %v", strategy.Multiplier)
+		}
+	case ScaleUpStrategyBacklog:
+		if strategy.ProcessingRate == nil || strategy.ProcessingRate.Sign() <= 0 {
+			return fmt.Errorf("the backlog strategy requires a positive processingRate")
+		}
+		if strategy.DrainSeconds < 1 {
+			return fmt.Errorf("the drainSeconds of the backlog strategy should be at least 1, currently %d", strategy.DrainSeconds)
+		}
+	default:
+		return fmt.Errorf("unknown type %q", strategy.Type)
+	}
+	return nil
+}
+
+func checkRounding(rounding *RoundingSpec) error {
+	if rounding == nil {
+		return nil
+	}
+	for _, mode := range []RoundingMode{rounding.Upscale, rounding.Downscale} {
+		switch mode {
+		case "", RoundingModeCeil, RoundingModeFloor, RoundingModeRound:
+		default:
+			return fmt.Errorf("the modes should be ceil, floor or round, currently %s", mode)
+		}
+	}
+	if rounding.BiasReplicas < 0 {
+		return fmt.Errorf("the biasReplicas should be positive, currently %d", rounding.BiasReplicas)
+	}
+	return nil
+}
+
+func checkHTTPMetricSource(source *HTTPMetricSource) error {
+	if source == nil {
+		return nil
+	}
+	u, err := url.Parse(source.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("the url should be an https URL, currently %q", source.URL)
+	}
+	if source.JSONPath == "" {
+		return fmt.Errorf("the jsonPath should be set")
+	}
+	if ref := source.AuthorizationSecretRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+		return fmt.Errorf("the authorizationSecretRef should have a name and a key")
+	}
+	return nil
+}
+
+func checkHistogramMetric(source *ExternalMetricSource) error {
+	if source.Histogram == nil {
+		return nil
+	}
+	if source.HTTP != nil {
+		return fmt.Errorf("the buckets of a histogram can't be read over HTTP")
+	}
+	if q := source.Histogram.Quantile; q < 1 || q > 100 {
+		return fmt.Errorf("the quantile has to be between 1 and 100, currently %d", q)
+	}
+	return nil
+}
+
+func checkMetricFallbacks(metrics []MetricSpec) error {
+	names := map[string]bool{}
+	for _, metric := range metrics {
+		if metric.FallbackFor == "" {
+			names[MetricSourceName(metric)] = true
+		}
+	}
+	for _, metric := range metrics {
+		if metric.FallbackAfterFailures < 0 {
+			return fmt.Errorf("the fallbackAfterFailures of the metric %s should be positive, currently %d", MetricSourceName(metric), metric.FallbackAfterFailures)
+		}
+		if metric.FallbackFor == "" {
+			continue
+		}
+		if metric.FallbackFor == MetricSourceName(metric) {
+			return fmt.Errorf("the metric %s can't be its own fallback", metric.FallbackFor)
+		}
+		if !names[metric.FallbackFor] {
+			return fmt.Errorf("the metric %s is a fallback for %s, which is not a metric of the WPA without a fallbackFor", MetricSourceName(metric), metric.FallbackFor)
+		}
+	}
+	return nil
+}
+
+// MetricSourceName returns the name of the metric of the MetricSpec, the one referenced by the fallbackFor of the other metrics.
+func MetricSourceName(metric MetricSpec) string {
+	switch {
+	case metric.External != nil:
+		return metric.External.MetricName
+	case metric.Resource != nil:
+		return string(metric.Resource.Name)
+	}
+	return ""
+}
+
+// HasResourceWatermarks returns whether both watermarks of the ResourceMetricSource are set,
+// either as quantities or as utilizations of the requests.
+func HasResourceWatermarks(source *ResourceMetricSource) bool {
+	if source.LowWatermark != nil && source.HighWatermark != nil {
+		return true
+	}
+	return source.LowWatermarkUtilization != nil && source.HighWatermarkUtilization != nil
+}
+
+// hasNameOrSelector returns whether exactly one of the name and the selector of the reference is set
+func hasNameOrSelector(ref CrossVersionObjectReference) bool {
+	return (ref.Name == "") != (ref.Selector == nil)
+}
+
+// hasSelector returns whether a target of the WPA is matched by a selector.
+func hasSelector(wpa *WatermarkPodAutoscaler) bool {
+	if wpa.Spec.ScaleTargetRef.Selector != nil {
+		return true
+	}
+	for _, ref := range wpa.Spec.ScaleTargetRefs {
+		if ref.Selector != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -18,13 +18,18 @@ import (
 // WatermarkPodAutoscaler is the Schema for the watermarkpodautoscalers API
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
-// +kubebuilder:printcolumn:name="value",type="string",JSONPath=".status.currentMetrics[*].external.currentValue.."
-// +kubebuilder:printcolumn:name="high watermark",type="string",JSONPath=".spec.metrics[*].external.highWatermark.."
-// +kubebuilder:printcolumn:name="low watermark",type="string",JSONPath=".spec.metrics[*].external.lowWatermark.."
-// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.currentReplicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="value",type="string",JSONPath=".status.activeMetric.currentValue"
+// +kubebuilder:printcolumn:name="high watermark",type="string",JSONPath=".status.activeMetric.highWatermark"
+// +kubebuilder:printcolumn:name="low watermark",type="string",JSONPath=".status.activeMetric.lowWatermark"
+// +kubebuilder:printcolumn:name="metric",type="string",JSONPath=".status.activeMetric.name"
+// +kubebuilder:printcolumn:name="current replicas",type="integer",JSONPath=".status.currentReplicas"
+// +kubebuilder:printcolumn:name="desired replicas",type="integer",JSONPath=".status.desiredReplicas"
 // +kubebuilder:printcolumn:name="min replicas",type="integer",JSONPath=".spec.minReplicas"
 // +kubebuilder:printcolumn:name="max replicas",type="integer",JSONPath=".spec.maxReplicas"
-// +kubebuilder:printcolumn:name="dry-run",type="string",JSONPath=".spec.dryRun"
+// +kubebuilder:printcolumn:name="dry-run",type="boolean",JSONPath=".spec.dryRun"
+// +kubebuilder:printcolumn:name="last scale",type="date",JSONPath=".status.lastScaleTime"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:path=watermarkpodautoscalers,shortName=wpa
 type WatermarkPodAutoscaler struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -40,12 +45,25 @@ type CrossVersionObjectReference struct {
 	// Kind of the referent; More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds"
 	Kind string `json:"kind"`
 	// Name of the referent; More info: http://kubernetes.io/docs/user-guide/identifiers#names
-	Name string `json:"name"`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Label selector resolved to the referents at reconcile time, alternative to the name.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 	// API version of the referent
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
 }
 
+// WeightedCrossVersionObjectReference identifies an additional scale target and its weight.
+// +k8s:openapi-gen=true
+type WeightedCrossVersionObjectReference struct {
+	CrossVersionObjectReference `json:",inline"`
+	// Number of replicas of this target, in percent of the replicas of the scaleTargetRef.
+	// +kubebuilder:validation:Minimum=1
+	Weight int32 `json:"weight"`
+}
+
 // WatermarkPodAutoscalerSpec defines the desired state of WatermarkPodAutoscaler
 // +k8s:openapi-gen=true
 type WatermarkPodAutoscalerSpec struct {
@@ -57,6 +75,18 @@ type WatermarkPodAutoscalerSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	UpscaleForbiddenWindowSeconds int32 `json:"upscaleForbiddenWindowSeconds,omitempty"`
 
+	// Number of seconds the metrics have to stay below their low watermark before a downscale is proposed, so that
+	// brief lulls don't shrink the target. Unlike the downscaleForbiddenWindowSeconds, it doesn't depend on the last scale.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DownscaleEvaluationWindowSeconds int32 `json:"downscaleEvaluationWindowSeconds,omitempty"`
+
+	// Number of seconds the metrics have to stay above their high watermark before an upscale is proposed, for the
+	// targets absorbing short spikes whose pods are expensive to start.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	UpscaleEvaluationWindowSeconds int32 `json:"upscaleEvaluationWindowSeconds,omitempty"`
+
 	// Percentage of replicas that can be added in an upscale event. Max value will set the limit at the Maximum number of Replicas.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=100
@@ -67,6 +97,12 @@ type WatermarkPodAutoscalerSpec struct {
 	// +kubebuilder:validation:Maximum=100
 	ScaleDownLimitFactor float64 `json:"scaleDownLimitFactor,omitempty"`
 
+	// Maximum number of replicas added or removed in a single scale event, on top of the limit factors, which
+	// allow large changes once the target has hundreds of replicas.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxChangePerReconcile *int32 `json:"maxChangePerReconcile,omitempty"`
+
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:ExclusiveMinimum=true
 	// +kubebuilder:validation:Maximum=1
@@ -76,13 +112,29 @@ type WatermarkPodAutoscalerSpec struct {
 	// computed values take the # of replicas into account
 	Algorithm string `json:"algorithm,omitempty"`
 
+	// How the replicas computed from the metrics are rounded, and a number of replicas added to every upscale.
+	// +optional
+	Rounding *RoundingSpec `json:"rounding,omitempty"`
+
 	// Whether planned scale changes are actually applied
 	DryRun bool `json:"dryRun,omitempty"`
 
+	// Summary of what the WPA would have done, written to a ConfigMap per evaluation window while in dry-run mode.
+	// +optional
+	DryRunReport *DryRunReportSpec `json:"dryRunReport,omitempty"`
+
+	// Last scaling decisions of the WPA, persisted to a ConfigMap so that they survive the restarts of the controller.
+	// +optional
+	DecisionHistory *DecisionHistorySpec `json:"decisionHistory,omitempty"`
+
 	// part of HorizontalPodAutoscalerSpec, see comments in the k8s-1.10.8 repo: staging/src/k8s.io/api/autoscaling/v1/types.go
 	// reference to scaled resource; horizontal pod autoscaler will learn the current resource consumption
 	// and will set the desired number of pods by using its Scale subresource.
 	ScaleTargetRef CrossVersionObjectReference `json:"scaleTargetRef"`
+	// Additional targets scaled in lock-step with the scaleTargetRef, proportionally to their weight.
+	// +optional
+	// +listType=set
+	ScaleTargetRefs []WeightedCrossVersionObjectReference `json:"scaleTargetRefs,omitempty"`
 	// specifications that will be used to calculate the desired replica count
 	// +listType=set
 	Metrics []MetricSpec `json:"metrics,omitempty"`
@@ -90,10 +142,552 @@ type WatermarkPodAutoscalerSpec struct {
 	MinReplicas *int32 `json:"minReplicas,omitempty"`
 	// +kubebuilder:validation:Minimum=1
 	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// Number of replicas the target is pinned to, taking precedence over minReplicas and maxReplicas.
+	// It is the replica count of the scale subresource of the WPA, set by `kubectl scale`.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
 	// +kubebuilder:validation:Minimum=1
 	ReadinessDelaySeconds int32 `json:"readinessDelay,omitempty"`
+
+	// Whether the controller.kubernetes.io/pod-deletion-cost annotation of the pods is considered before downscaling.
+	// With `Wait`, downscale events are delayed until all the pods of the target carry the annotation.
+	// +kubebuilder:validation:Enum=Ignore;Wait
+	DeletionCostPolicy DeletionCostPolicy `json:"deletionCostPolicy,omitempty"`
+
+	// If set, the pods of the target are annotated with a controller.kubernetes.io/pod-deletion-cost based on their usage
+	// of this resource, so that the least loaded pods are removed first when downscaling. The resource has to be used in one
+	// of the Resource metrics.
+	// +optional
+	DeletionCostResource v1.ResourceName `json:"deletionCostResource,omitempty"`
+
+	// Whether the upscale should be capped to the number of replicas that the ResourceQuotas of the namespace can admit,
+	// based on the requests and limits of the pods of the target.
+	// +optional
+	ResourceQuotaAware bool `json:"resourceQuotaAware,omitempty"`
+
+	// Whether the upscale should be paused while some pods of the target can't be scheduled.
+	// +optional
+	PauseUpscaleOnUnschedulablePods bool `json:"pauseUpscaleOnUnschedulablePods,omitempty"`
+
+	// Directions in which the target is scaled, `Both` by default. The desired number of replicas in the
+	// other direction is reported in the status instead.
+	// +kubebuilder:validation:Enum=Both;UpOnly;DownOnly
+	// +optional
+	ScalingMode ScalingMode `json:"scalingMode,omitempty"`
+
+	// Whether the target should only be scaled up.
+	// Deprecated: use the `UpOnly` scaling mode instead, which this field is converted to when the scaling
+	// mode is not set, and ignored otherwise.
+	// +optional
+	ScaleDownDisabled bool `json:"scaleDownDisabled,omitempty"`
+
+	// Whether the pods are only counted as ready once they have been Ready for the minReadySeconds of the
+	// target Deployment, so that the pods not taking traffic yet don't dilute the average.
+	// +optional
+	HonorMinReadySeconds bool `json:"honorMinReadySeconds,omitempty"`
+
+	// Leaves the pods running on cordoned or draining nodes out of the ready pods and the metrics, their
+	// imminent disappearance being treated as capacity already lost.
+	// +optional
+	ExcludeCordonedNodes *CordonedNodesSpec `json:"excludeCordonedNodes,omitempty"`
+
+	// Whether the pods being deleted are left out of the ready pods and the metrics. They are still Ready
+	// until they are gone, and skew the average during the rollouts and the downscales.
+	// +optional
+	ExcludeTerminatingPods bool `json:"excludeTerminatingPods,omitempty"`
+
+	// Selector of the pods left out of the ready pods and the metrics, such as the canary or debug replicas.
+	// The pods annotated with wpa.datadoghq.com/exclude set to "true" are always left out.
+	// +optional
+	ExcludedPodSelector *metav1.LabelSelector `json:"excludedPodSelector,omitempty"`
+
+	// How the Failed pods of the target, such as the ones evicted by the kubelet, are reported and accounted for.
+	// +optional
+	FailedPods *FailedPodsSpec `json:"failedPods,omitempty"`
+
+	// Whether the pods not ready because of their node, NotReady or under memory, disk or PID pressure, are
+	// told apart from the application failures: their usage is ignored and the downscales are paused until
+	// they recover, so that an infrastructure failure isn't mistaken for a low utilization.
+	// +optional
+	NodePressureAware bool `json:"nodePressureAware,omitempty"`
+
+	// Whether scaling should be skipped while the target Deployment is rolling out.
+	// +optional
+	FreezeDuringRollout bool `json:"freezeDuringRollout,omitempty"`
+
+	// Cost model used as an additional ceiling on the number of replicas.
+	// +optional
+	Budget *BudgetSpec `json:"budget,omitempty"`
+
+	// Endpoint of a capacity-management service serving the maximum number of replicas currently allowed for the
+	// target, applied on top of the maxReplicas.
+	// +optional
+	CapacityCeiling *CapacityCeilingSpec `json:"capacityCeiling,omitempty"`
+
+	// Spreads the upscales far above the current number of replicas over several reconcile cycles.
+	// +optional
+	SteppedConvergence *SteppedConvergenceSpec `json:"steppedConvergence,omitempty"`
+
+	// Scales the target directly to a baseline once the metrics have been idle for a while, instead of
+	// walking down through the downscales limited by the scaleDownLimitFactor.
+	// +optional
+	Baseline *BaselineSpec `json:"baseline,omitempty"`
+
+	// Maximum number of scale events of the target over the last hour, on top of the forbidden windows.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxScaleEventsPerHour *int32 `json:"maxScaleEventsPerHour,omitempty"`
+
+	// Widens the forbidden windows and the tolerance while the WPA flaps between upscales and downscales.
+	// +optional
+	FlappingDetection *FlappingDetectionSpec `json:"flappingDetection,omitempty"`
+
+	// Widens the watermarks for a while after each scale event, so that the replicas just added or removed are
+	// not immediately judged as too many or too few and the scale reversed.
+	// +optional
+	Hysteresis *HysteresisSpec `json:"hysteresis,omitempty"`
+
+	// Widens the tolerance of noisy metrics according to the variation of their recent values.
+	// +optional
+	AdaptiveTolerance *AdaptiveToleranceSpec `json:"adaptiveTolerance,omitempty"`
+
+	// Applies an aggregation of the last proposals of replicas instead of the last one, filtering the spikes
+	// of a single reconcile cycle.
+	// +optional
+	RecommendationHistory *RecommendationHistorySpec `json:"recommendationHistory,omitempty"`
+
+	// Percentage of a recommended change of replicas applied first, as a canary. The rest of the change is applied
+	// if the recommendation is confirmed at the end of the canaryWindowSeconds, otherwise the target is rolled
+	// back to its replicas before the canary.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	CanaryPercent int32 `json:"canaryPercent,omitempty"`
+
+	// Time during which the canary is observed before the rest of the change is applied or rolled back, 300 by
+	// default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CanaryWindowSeconds int32 `json:"canaryWindowSeconds,omitempty"`
+
+	// Scaling constraints applied when the target is a StatefulSet.
+	// +optional
+	StatefulSet *StatefulSetScalingSpec `json:"statefulSet,omitempty"`
+
+	// Named sets of settings overriding the ones of the WPA during given periods of time.
+	// The first active profile of the list is applied.
+	// +optional
+	// +listType=set
+	Profiles []ScalingProfile `json:"profiles,omitempty"`
+
+	// Calendar of special days, such as holidays or sales, on which the scaling is adjusted.
+	// +optional
+	Calendar *SpecialDaysCalendar `json:"calendar,omitempty"`
+
+	// Cluster the targets run in, when it is not the cluster of the WPA. The targets are scaled, and their pods
+	// and metrics read, in the namespace of the same name in that cluster.
+	// +optional
+	RemoteCluster *RemoteClusterSpec `json:"remoteCluster,omitempty"`
+
+	// Other clusters the workload is sharded across, each running a target of the same kind and name in the
+	// namespace of the same name. The external metrics are aggregated across the clusters before the comparison
+	// to the watermarks, and the replicas, bounded by minReplicas and maxReplicas as a whole, are split across
+	// the targets proportionally to their weight.
+	// +optional
+	Shards *ShardsSpec `json:"shards,omitempty"`
+
+	// Pairs the blue and the green workloads matched by the selector of the scaleTargetRef. The recommendation
+	// is computed on the live color, routed to by a Service, and the other color is kept at the same number of
+	// replicas, so that it is ready to take the traffic after a switch.
+	// +optional
+	BlueGreen *BlueGreenSpec `json:"blueGreen,omitempty"`
+
+	// What the controller does when the target is scaled outside of the WPA. With `Correct`, the replicas the
+	// controller last applied are applied again, once the forbidden windows are over, unless the metrics
+	// recommend another change. `Ignore` by default.
+	// +kubebuilder:validation:Enum=Ignore;Correct
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
 }
 
+// RoundingSpec describes how the replicas computed from the metrics are rounded.
+// +k8s:openapi-gen=true
+type RoundingSpec struct {
+	// Rounding of the replicas computed for an upscale, `ceil` by default.
+	// +kubebuilder:validation:Enum=ceil;floor;round
+	// +optional
+	Upscale RoundingMode `json:"upscale,omitempty"`
+
+	// Rounding of the replicas computed for a downscale, `floor` by default.
+	// +kubebuilder:validation:Enum=ceil;floor;round
+	// +optional
+	Downscale RoundingMode `json:"downscale,omitempty"`
+
+	// Number of replicas added to the replicas computed for every upscale, as a safety margin.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BiasReplicas int32 `json:"biasReplicas,omitempty"`
+}
+
+// RoundingMode indicates how a fractional number of replicas is rounded.
+type RoundingMode string
+
+const (
+	// RoundingModeCeil rounds the replicas up.
+	RoundingModeCeil RoundingMode = "ceil"
+	// RoundingModeFloor rounds the replicas down.
+	RoundingModeFloor RoundingMode = "floor"
+	// RoundingModeRound rounds the replicas to the nearest integer, half away from zero.
+	RoundingModeRound RoundingMode = "round"
+)
+
+// DriftPolicy indicates what the controller does when the target is scaled outside of the WPA.
+type DriftPolicy string
+
+const (
+	// DriftPolicyIgnore only reports the drift.
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+	// DriftPolicyCorrect applies the replicas last applied by the controller again.
+	DriftPolicyCorrect DriftPolicy = "Correct"
+)
+
+// RemoteClusterSpec references the credentials of a remote cluster.
+// +k8s:openapi-gen=true
+type RemoteClusterSpec struct {
+	// Key of a Secret, in the namespace of the WPA, holding the kubeconfig of the cluster.
+	KubeconfigSecretRef v1.SecretKeySelector `json:"kubeconfigSecretRef"`
+}
+
+// BlueGreenSpec describes how the live color of a blue/green pair is determined.
+// +k8s:openapi-gen=true
+type BlueGreenSpec struct {
+	// Name of the Service, in the namespace of the WPA, routing the traffic to the live color.
+	ServiceName string `json:"serviceName"`
+	// Label of the workloads and of the selector of the Service holding the color, `color` by default.
+	// +optional
+	ColorLabel string `json:"colorLabel,omitempty"`
+}
+
+// ShardsSpec describes the other clusters a workload is sharded across.
+// +k8s:openapi-gen=true
+type ShardsSpec struct {
+	// Weight of the scaleTargetRef in the split of the replicas, 1 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+	// +listType=set
+	Clusters []ShardCluster `json:"clusters"`
+}
+
+// ShardCluster is a cluster a workload is sharded across.
+// +k8s:openapi-gen=true
+type ShardCluster struct {
+	RemoteClusterSpec `json:",inline"`
+	// Weight of the target of the cluster in the split of the replicas, 1 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+}
+
+// SpecialDaysCalendar reads the special days from an iCalendar feed or a ConfigMap, and sets the profile
+// and the replica bounds used on those days.
+// +k8s:openapi-gen=true
+type SpecialDaysCalendar struct {
+	// URL of an iCalendar (ICS) feed, every event of which marks the days it covers as special.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// Key of a ConfigMap, in the namespace of the WPA, holding either an iCalendar document
+	// or a list of special days formatted as YYYY-MM-DD, one per line.
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// IANA time zone in which the days are evaluated, UTC if not set.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Name of the profile applied on the special days, regardless of its periods.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+	// Minimum number of replicas on the special days.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// Maximum number of replicas on the special days.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+}
+
+// ScalingProfile overrides the watermarks, the cooldowns and the limit factors of the WPA while it is active.
+// +k8s:openapi-gen=true
+type ScalingProfile struct {
+	// Name of the profile, reported in the status of the WPA while it is active.
+	Name string `json:"name"`
+	// Periods during which the profile is active.
+	// +listType=set
+	Periods []ProfilePeriod `json:"periods"`
+
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DownscaleForbiddenWindowSeconds int32 `json:"downscaleForbiddenWindowSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	UpscaleForbiddenWindowSeconds int32 `json:"upscaleForbiddenWindowSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ScaleUpLimitFactor float64 `json:"scaleUpLimitFactor,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ScaleDownLimitFactor float64 `json:"scaleDownLimitFactor,omitempty"`
+	// Watermarks of the metrics overridden while the profile is active.
+	// +optional
+	// +listType=set
+	Watermarks []ProfileWatermarks `json:"watermarks,omitempty"`
+}
+
+// ProfilePeriod is a daily time range during which a profile is active.
+// +k8s:openapi-gen=true
+type ProfilePeriod struct {
+	// Days of the week of the period (e.g. Monday), every day if empty.
+	// +optional
+	// +listType=set
+	Days []string `json:"days,omitempty"`
+	// Start of the period, formatted as HH:MM.
+	Start string `json:"start"`
+	// End of the period, formatted as HH:MM. The period spans midnight if it is before the start.
+	End string `json:"end"`
+	// IANA time zone of the period, UTC if not set.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ProfileWatermarks overrides the watermarks of a metric.
+// +k8s:openapi-gen=true
+type ProfileWatermarks struct {
+	// Name of the External metric, or resource of the Resource metric.
+	MetricName    string            `json:"metricName"`
+	HighWatermark resource.Quantity `json:"highWatermark"`
+	LowWatermark  resource.Quantity `json:"lowWatermark"`
+}
+
+// StatefulSetScalingSpec describes how the ordering of the pods of a StatefulSet is taken into account when scaling it.
+// +k8s:openapi-gen=true
+type StatefulSetScalingSpec struct {
+	// Maximum number of replicas removed by a single downscale.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	OrderedDownscaleStep *int32 `json:"orderedDownscaleStep,omitempty"`
+
+	// Whether the upscale should wait for the pod with the highest ordinal to be Ready.
+	// +optional
+	WaitForReadyOrdinal bool `json:"waitForReadyOrdinal,omitempty"`
+
+	// Whether the constraints above only apply to the StatefulSets with the OrderedReady pod management policy.
+	// +optional
+	AlignWithPodManagementPolicy bool `json:"alignWithPodManagementPolicy,omitempty"`
+}
+
+// SteppedConvergenceSpec describes how a large upscale is split into steps, the metrics being evaluated again
+// before each of them.
+// +k8s:openapi-gen=true
+type SteppedConvergenceSpec struct {
+	// Ratio between the desired and the current number of replicas from which the upscale is stepped, 2 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinRatio float64 `json:"minRatio,omitempty"`
+	// Fraction of the gap between the current and the desired number of replicas added at each step.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Maximum=1
+	StepFraction float64 `json:"stepFraction"`
+}
+
+// BaselineSpec describes the number of replicas the target returns to when its metrics are idle.
+// +k8s:openapi-gen=true
+type BaselineSpec struct {
+	// Number of replicas of the target once idle, within the minReplicas and maxReplicas.
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+	// The metrics are idle while all their values are below this threshold.
+	IdleThreshold resource.Quantity `json:"idleThreshold"`
+	// Duration the metrics have to be idle for before the target returns to the baseline.
+	// +kubebuilder:validation:Minimum=1
+	IdleWindowSeconds int32 `json:"idleWindowSeconds"`
+}
+
+// AdaptiveToleranceSpec describes how the tolerance follows the variation of the metrics.
+// The effective tolerance of a metric is its coefficient of variation (standard deviation over mean)
+// over the recent values multiplied by the factor, within the tolerance of the spec and the maximum.
+// +k8s:openapi-gen=true
+type AdaptiveToleranceSpec struct {
+	// Number of recent values of each metric the variation is computed over.
+	// +kubebuilder:validation:Minimum=2
+	Samples int32 `json:"samples"`
+	// Factor applied to the coefficient of variation, 1 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +optional
+	Factor float64 `json:"factor,omitempty"`
+	// Maximum effective tolerance, 0.5 by default.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Maximum=1
+	// +kubebuilder:validation:ExclusiveMaximum=true
+	// +optional
+	MaxTolerance float64 `json:"maxTolerance,omitempty"`
+}
+
+// DryRunReportSpec describes the ConfigMap the reports of a WPA in dry-run mode are written to.
+// +k8s:openapi-gen=true
+type DryRunReportSpec struct {
+	// Name of the ConfigMap, in the namespace of the WPA, `<name of the WPA>-dry-run-report` by default.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Duration of the window summarized by a report, 3600 by default.
+	// +kubebuilder:validation:Minimum=60
+	// +optional
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
+	// Number of reports kept in the ConfigMap, the oldest ones being removed, 168 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReports int32 `json:"maxReports,omitempty"`
+}
+
+// DecisionHistorySpec describes the ConfigMap the last scaling decisions of a WPA are persisted to.
+// +k8s:openapi-gen=true
+type DecisionHistorySpec struct {
+	// Name of the ConfigMap, in the namespace of the WPA, `<name of the WPA>-decision-history` by default.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Number of decisions kept in the ConfigMap, the oldest ones being removed, 100 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxDecisions int32 `json:"maxDecisions,omitempty"`
+	// Size in bytes of the decisions kept in the ConfigMap, the oldest ones being removed beyond it, 262144 by default.
+	// +kubebuilder:validation:Minimum=1024
+	// +kubebuilder:validation:Maximum=1048576
+	// +optional
+	MaxSizeBytes int32 `json:"maxSizeBytes,omitempty"`
+}
+
+// RecommendationHistorySpec describes how the last proposals of replicas are aggregated.
+// +k8s:openapi-gen=true
+type RecommendationHistorySpec struct {
+	// Number of the last proposals aggregated.
+	// +kubebuilder:validation:Minimum=1
+	Size int32 `json:"size"`
+	// How the proposals are aggregated, `median` by default.
+	// +kubebuilder:validation:Enum=median;p90
+	// +optional
+	Aggregation RecommendationAggregation `json:"aggregation,omitempty"`
+}
+
+// RecommendationAggregation indicates how the last proposals of replicas are aggregated.
+type RecommendationAggregation string
+
+const (
+	// RecommendationAggregationMedian keeps the median of the proposals, the higher one if their number is even.
+	RecommendationAggregationMedian RecommendationAggregation = "median"
+	// RecommendationAggregationP90 keeps the 90th percentile of the proposals.
+	RecommendationAggregationP90 RecommendationAggregation = "p90"
+)
+
+// FlappingDetectionSpec describes when a WPA is considered flapping, and how its settings are adapted.
+// +k8s:openapi-gen=true
+type FlappingDetectionSpec struct {
+	// Number of reversals of the scaling direction within the window from which the WPA is flapping.
+	// +kubebuilder:validation:Minimum=1
+	Reversals int32 `json:"reversals"`
+	// Duration the reversals are counted over.
+	// +kubebuilder:validation:Minimum=1
+	WindowSeconds int32 `json:"windowSeconds"`
+	// Factor applied to the forbidden windows and the tolerance while the WPA is flapping, 2 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Factor float64 `json:"factor,omitempty"`
+}
+
+// FailedPodsSpec describes how the Failed pods of the target are reported and accounted for.
+// +k8s:openapi-gen=true
+type FailedPodsSpec struct {
+	// Percentage of the pods of the target in the Failed phase above which a FailedPods warning event is emitted.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	WarningThresholdPercent *int32 `json:"warningThresholdPercent,omitempty"`
+	// Whether the Succeeded pods and the pods evicted by the kubelet are left out of the pods of the target,
+	// instead of being counted among the pods that are not ready.
+	// +optional
+	ExcludeFinished bool `json:"excludeFinished,omitempty"`
+}
+
+// HysteresisSpec describes how far and for how long the watermarks are widened after a scale event.
+// +k8s:openapi-gen=true
+type HysteresisSpec struct {
+	// Percentage by which the low watermarks are lowered after an upscale, and the high watermarks raised after
+	// a downscale.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	Percent int32 `json:"percent"`
+	// Duration after the scale event during which the watermarks are widened.
+	// +kubebuilder:validation:Minimum=1
+	DurationSeconds int32 `json:"durationSeconds"`
+}
+
+// CordonedNodesSpec describes the nodes whose pods are considered lost.
+// +k8s:openapi-gen=true
+type CordonedNodesSpec struct {
+	// Keys of the taints marking a node as draining, such as the ones set ahead of a spot interruption.
+	// The cordoned nodes are always considered draining.
+	// +optional
+	TaintKeys []string `json:"taintKeys,omitempty"`
+}
+
+// BudgetSpec describes the cost of the replicas of the target and the maximum spend allowed.
+// +k8s:openapi-gen=true
+type BudgetSpec struct {
+	// Cost of a single replica for an hour.
+	CostPerReplicaHour resource.Quantity `json:"costPerReplicaHour"`
+	// Maximum cost of the target for an hour.
+	MaxCostPerHour resource.Quantity `json:"maxCostPerHour"`
+}
+
+// CapacityCeilingSpec describes a JSON document holding the maximum number of replicas currently allowed for the
+// target.
+// +k8s:openapi-gen=true
+type CapacityCeilingSpec struct {
+	// Document polled for the maximum number of replicas, the lowest of the values selected by the jsonPath is used.
+	HTTPMetricSource `json:",inline"`
+	// Number of seconds between two polls of the document, 60 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PollIntervalSeconds int32 `json:"pollIntervalSeconds,omitempty"`
+}
+
+// ScalingMode indicates the directions in which the target is scaled.
+type ScalingMode string
+
+const (
+	// ScalingModeBoth scales the target up and down.
+	ScalingModeBoth ScalingMode = "Both"
+	// ScalingModeUpOnly only scales the target up.
+	ScalingModeUpOnly ScalingMode = "UpOnly"
+	// ScalingModeDownOnly only scales the target down.
+	ScalingModeDownOnly ScalingMode = "DownOnly"
+)
+
+// DeletionCostPolicy indicates how the pod-deletion-cost annotation of the pods is considered when downscaling.
+type DeletionCostPolicy string
+
+const (
+	// DeletionCostPolicyIgnore downscales regardless of the pod-deletion-cost annotations.
+	DeletionCostPolicyIgnore DeletionCostPolicy = "Ignore"
+	// DeletionCostPolicyWait delays downscale events until all the pods have a pod-deletion-cost annotation,
+	// so that the ReplicaSet controller removes the cheapest pods.
+	DeletionCostPolicyWait DeletionCostPolicy = "Wait"
+)
+
 // ExternalMetricSource indicates how to scale on a metric not associated with
 // any Kubernetes object (for example length of queue in cloud
 // messaging service, or QPS from loadbalancer running outside of cluster).
@@ -109,8 +703,170 @@ type ExternalMetricSource struct {
 
 	HighWatermark *resource.Quantity `json:"highWatermark,omitempty"`
 	LowWatermark  *resource.Quantity `json:"lowWatermark,omitempty"`
+
+	// highWatermarkFrom reads the high watermark from a ConfigMap or a Secret. It can be used instead of highWatermark.
+	// +optional
+	HighWatermarkFrom *WatermarkSource `json:"highWatermarkFrom,omitempty"`
+	// lowWatermarkFrom reads the low watermark from a ConfigMap or a Secret. It can be used instead of lowWatermark.
+	// +optional
+	LowWatermarkFrom *WatermarkSource `json:"lowWatermarkFrom,omitempty"`
+
+	// watermarkSteps override the watermarks once the target has at least the given number of replicas.
+	// +optional
+	// +listType=set
+	WatermarkSteps []WatermarkStep `json:"watermarkSteps,omitempty"`
+
+	// emergencyHighWatermark is the usage above which the upscales ignore the upscaleForbiddenWindowSeconds and
+	// the scaleUpLimitFactor, the target being scaled straight to the computed number of replicas.
+	// +optional
+	EmergencyHighWatermark *resource.Quantity `json:"emergencyHighWatermark,omitempty"`
+
+	// How the values of the series returned for the metric are combined, `sum` by default.
+	// +kubebuilder:validation:Enum=sum;avg;max;min;p95
+	// +optional
+	SeriesAggregation SeriesAggregation `json:"seriesAggregation,omitempty"`
+
+	// Estimates the value of the metric from the previous ones when the provider does not return it,
+	// instead of failing the computation of the replicas.
+	// +optional
+	MissingDatapoints *MissingDatapointsPolicy `json:"missingDatapoints,omitempty"`
+
+	// Projects the value of the metric when it rises faster than a slope, so that the target is scaled up
+	// before the high watermark is crossed.
+	// +optional
+	RateOfChange *RateOfChangeSpec `json:"rateOfChange,omitempty"`
+
+	// How the replicas are computed when the metric is above its high watermark, proportionally to the ratio
+	// of the usage to the high watermark by default.
+	// +optional
+	ScaleUpStrategy *ScaleUpStrategySpec `json:"scaleUpStrategy,omitempty"`
+
+	// Fetches the values of the metric from a JSON document served over HTTPS instead of the External Metrics
+	// Provider. The metricSelector is then optional.
+	// +optional
+	HTTP *HTTPMetricSource `json:"http,omitempty"`
+
+	// Compares a quantile of the distribution whose buckets are the series of the metric to the watermarks,
+	// instead of combining the series with the seriesAggregation.
+	// +optional
+	Histogram *HistogramMetricSpec `json:"histogram,omitempty"`
+}
+
+// HistogramMetricSpec describes an external metric whose series are the cumulative buckets of a distribution.
+// +k8s:openapi-gen=true
+type HistogramMetricSpec struct {
+	// Percentile of the distribution compared to the watermarks, e.g. 95 for the p95.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Quantile int32 `json:"quantile"`
+	// Label of the series holding the upper bound of their bucket, `le` by default. Each bucket counts the
+	// observations lower than or equal to its bound, the `+Inf` one counting all of them.
+	// +optional
+	BucketLabel string `json:"bucketLabel,omitempty"`
+}
+
+// HTTPMetricSource describes a JSON document holding the values of a metric.
+// +k8s:openapi-gen=true
+type HTTPMetricSource struct {
+	// HTTPS URL of the JSON document.
+	URL string `json:"url"`
+	// JSONPath expression selecting the values in the document, such as `{.queues[*].depth}`. The values are
+	// combined with the seriesAggregation of the metric.
+	JSONPath string `json:"jsonPath"`
+	// Key of a Secret, in the namespace of the WPA, holding the value of the Authorization header of the requests.
+	// +optional
+	AuthorizationSecretRef *v1.SecretKeySelector `json:"authorizationSecretRef,omitempty"`
+}
+
+// ScaleUpStrategySpec describes how the replicas are computed from a metric above its high watermark.
+// +k8s:openapi-gen=true
+type ScaleUpStrategySpec struct {
+	// With `proportional` the replicas are multiplied by the ratio of the usage to the high watermark, with `linear`
+	// a replica is added per usagePerReplica above the high watermark, with `multiplier` the replicas are multiplied
+	// by the multiplier, and with `backlog` the replicas drain the backlog measured by the metric within drainSeconds.
+	// +kubebuilder:validation:Enum=proportional;linear;multiplier;backlog
+	Type ScaleUpStrategyType `json:"type"`
+
+	// Usage above the high watermark absorbed by each added replica, for the `linear` strategy.
+	// +optional
+	UsagePerReplica *resource.Quantity `json:"usagePerReplica,omitempty"`
+
+	// Factor applied to the current replicas, for the `multiplier` strategy. It has to be greater than 1.
+	// +optional
+	Multiplier float64 `json:"multiplier,omitempty"`
+
+	// Part of the backlog processed per second by a replica, for the `backlog` strategy.
+	// +optional
+	ProcessingRate *resource.Quantity `json:"processingRate,omitempty"`
+
+	// Duration within which the backlog should be drained, for the `backlog` strategy.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DrainSeconds int32 `json:"drainSeconds,omitempty"`
+}
+
+// ScaleUpStrategyType indicates how the replicas are computed from a metric above its high watermark.
+type ScaleUpStrategyType string
+
+const (
+	// ScaleUpStrategyProportional scales the replicas proportionally to the ratio of the usage to the high watermark.
+	ScaleUpStrategyProportional ScaleUpStrategyType = "proportional"
+	// ScaleUpStrategyLinear adds a replica per usagePerReplica above the high watermark.
+	ScaleUpStrategyLinear ScaleUpStrategyType = "linear"
+	// ScaleUpStrategyMultiplier multiplies the replicas by a fixed factor.
+	ScaleUpStrategyMultiplier ScaleUpStrategyType = "multiplier"
+	// ScaleUpStrategyBacklog computes the replicas draining the backlog measured by the metric within a duration.
+	ScaleUpStrategyBacklog ScaleUpStrategyType = "backlog"
+)
+
+// RateOfChangeSpec describes when and how far ahead the value of an external metric is projected.
+// +k8s:openapi-gen=true
+type RateOfChangeSpec struct {
+	// Increase of the metric per second above which its value is projected.
+	Slope resource.Quantity `json:"slope"`
+	// Duration the increase is projected over: the value compared to the watermarks is the current one
+	// plus the increase per second multiplied by this duration.
+	// +kubebuilder:validation:Minimum=1
+	HorizonSeconds int32 `json:"horizonSeconds"`
 }
 
+// MissingDatapointsPolicy indicates how a missing value of an external metric is estimated.
+// +k8s:openapi-gen=true
+type MissingDatapointsPolicy struct {
+	// With `carryForward` the last value is reused, with `linear` the trend of the last two values is extended.
+	// +kubebuilder:validation:Enum=carryForward;linear
+	Strategy MissingDatapointsStrategy `json:"strategy"`
+	// Maximum age of the last value for an estimation to be made.
+	// +kubebuilder:validation:Minimum=1
+	MaxGapSeconds int32 `json:"maxGapSeconds"`
+}
+
+// MissingDatapointsStrategy indicates how a missing value is estimated from the previous ones.
+type MissingDatapointsStrategy string
+
+const (
+	// MissingDatapointsCarryForward reuses the last value.
+	MissingDatapointsCarryForward MissingDatapointsStrategy = "carryForward"
+	// MissingDatapointsLinear extends the line going through the last two values.
+	MissingDatapointsLinear MissingDatapointsStrategy = "linear"
+)
+
+// SeriesAggregation indicates how the values of several series of an external metric are combined.
+type SeriesAggregation string
+
+const (
+	// SeriesAggregationSum adds the values of the series.
+	SeriesAggregationSum SeriesAggregation = "sum"
+	// SeriesAggregationAvg averages the values of the series.
+	SeriesAggregationAvg SeriesAggregation = "avg"
+	// SeriesAggregationMax keeps the highest value of the series.
+	SeriesAggregationMax SeriesAggregation = "max"
+	// SeriesAggregationMin keeps the lowest value of the series.
+	SeriesAggregationMin SeriesAggregation = "min"
+	// SeriesAggregationP95 keeps the 95th percentile of the values of the series.
+	SeriesAggregationP95 SeriesAggregation = "p95"
+)
+
 // ResourceMetricSource indicates how to scale on a resource metric known to
 // Kubernetes, as specified in requests and limits, describing each pod in the
 // current scale target (e.g. CPU or memory).  The values will be averaged
@@ -122,6 +878,15 @@ type ExternalMetricSource struct {
 type ResourceMetricSource struct {
 	// name is the name of the resource in question.
 	Name v1.ResourceName `json:"name"`
+	// container is the name of the container whose usage is considered.
+	// If not set, the usage of all the containers of the pods is summed.
+	// +optional
+	Container string `json:"container,omitempty"`
+	// containerWeights weights the usage (and the requests) of the listed containers before they are summed per pod,
+	// the containers not listed being left out. It can't be used with container.
+	// +optional
+	// +listType=set
+	ContainerWeights []ContainerWeight `json:"containerWeights,omitempty"`
 	// metricSelector is used to identify a specific time series
 	// within a given metric.
 	// +optional
@@ -129,6 +894,73 @@ type ResourceMetricSource struct {
 
 	HighWatermark *resource.Quantity `json:"highWatermark,omitempty"`
 	LowWatermark  *resource.Quantity `json:"lowWatermark,omitempty"`
+
+	// highWatermarkFrom reads the high watermark from a ConfigMap or a Secret. It can be used instead of highWatermark.
+	// +optional
+	HighWatermarkFrom *WatermarkSource `json:"highWatermarkFrom,omitempty"`
+	// lowWatermarkFrom reads the low watermark from a ConfigMap or a Secret. It can be used instead of lowWatermark.
+	// +optional
+	LowWatermarkFrom *WatermarkSource `json:"lowWatermarkFrom,omitempty"`
+
+	// watermarkSteps override the watermarks once the target has at least the given number of replicas.
+	// +optional
+	// +listType=set
+	WatermarkSteps []WatermarkStep `json:"watermarkSteps,omitempty"`
+
+	// emergencyHighWatermark is the usage above which the upscales ignore the upscaleForbiddenWindowSeconds and
+	// the scaleUpLimitFactor, the target being scaled straight to the computed number of replicas.
+	// +optional
+	EmergencyHighWatermark *resource.Quantity `json:"emergencyHighWatermark,omitempty"`
+
+	// highWatermarkUtilization is the high watermark expressed as a percentage of the resource requests
+	// of the pods. It can be used instead of highWatermark.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	HighWatermarkUtilization *int32 `json:"highWatermarkUtilization,omitempty"`
+	// lowWatermarkUtilization is the low watermark expressed as a percentage of the resource requests
+	// of the pods. It can be used instead of lowWatermark.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	LowWatermarkUtilization *int32 `json:"lowWatermarkUtilization,omitempty"`
+
+	// podQuantile compares the given percentile of the usage of the ready pods to the watermarks, instead of
+	// their sum or average, so that a few saturated pods are not hidden by idle ones. The watermarks are per pod.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	PodQuantile *int32 `json:"podQuantile,omitempty"`
+}
+
+// ContainerWeight is the share of the usage of a container accounted for in the usage of its pod.
+// +k8s:openapi-gen=true
+type ContainerWeight struct {
+	// Name of the container.
+	Name string `json:"name"`
+	// Percentage of the usage, and of the requests, of the container accounted for.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+}
+
+// WatermarkStep defines the watermarks used from a number of replicas of the target.
+// +k8s:openapi-gen=true
+type WatermarkStep struct {
+	// Number of replicas from which the watermarks of the step are used.
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas   int32             `json:"minReplicas"`
+	HighWatermark resource.Quantity `json:"highWatermark"`
+	LowWatermark  resource.Quantity `json:"lowWatermark"`
+}
+
+// WatermarkSource selects a key of a ConfigMap or a Secret, in the namespace of the WPA, holding the value of a watermark.
+// The controller watches the ConfigMaps and Secrets, so that updating the value is taken into account by all the WPAs referencing it.
+// Exactly one of the references should be set.
+// +k8s:openapi-gen=true
+type WatermarkSource struct {
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // MetricSourceType indicates the type of metric.
@@ -171,6 +1003,27 @@ type MetricSpec struct {
 	// to normal per-pod metrics using the "pods" source.
 	// +optional
 	Resource *ResourceMetricSource `json:"resource,omitempty"`
+	// fallbackFor is the name of another metric of the WPA this one stands in for. It is only evaluated once
+	// the other metric failed for more than fallbackAfterFailures consecutive reconciles.
+	// +optional
+	FallbackFor string `json:"fallbackFor,omitempty"`
+	// Number of consecutive failures of the metric named in fallbackFor after which this metric is evaluated
+	// instead, 3 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FallbackAfterFailures int32 `json:"fallbackAfterFailures,omitempty"`
+	// Percentage of replicas that can be added in an upscale recommended by this metric, overriding the
+	// scaleUpLimitFactor of the WPA. The strictest factor of the metrics recommending an upscale is applied.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ScaleUpLimitFactor float64 `json:"scaleUpLimitFactor,omitempty"`
+	// Percentage of replicas that can be removed in a downscale recommended by this metric, overriding the
+	// scaleDownLimitFactor of the WPA. The strictest factor of the metrics recommending a downscale is applied.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ScaleDownLimitFactor float64 `json:"scaleDownLimitFactor,omitempty"`
 }
 
 // WatermarkPodAutoscalerStatus defines the observed state of WatermarkPodAutoscaler
@@ -184,6 +1037,155 @@ type WatermarkPodAutoscalerStatus struct {
 	CurrentMetrics []autoscalingv2.MetricStatus `json:"currentMetrics"`
 	// +listType=set
 	Conditions []autoscalingv2.HorizontalPodAutoscalerCondition `json:"conditions"`
+	// Name of the profile currently applied.
+	// +optional
+	ActiveProfile string `json:"activeProfile,omitempty"`
+	// Whether the current day is marked as special by the calendar.
+	// +optional
+	SpecialDay bool `json:"specialDay,omitempty"`
+	// Label selector of the pods of the target, exposed by the scale subresource of the WPA.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+	// Metric driving the replica count proposed by the last computation.
+	// +optional
+	ActiveMetric *ActiveMetricStatus `json:"activeMetric,omitempty"`
+	// How each metric was evaluated by the last computation of the replicas.
+	// +optional
+	// +listType=set
+	MetricDetails []MetricDetailStatus `json:"metricDetails,omitempty"`
+	// Time since when all the metrics are below the idle threshold of the baseline.
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
+	// Direction of the last scaling of the target, `up` or `down`, tracked for the flapping detection and the
+	// hysteresis.
+	// +optional
+	LastScaleDirection string `json:"lastScaleDirection,omitempty"`
+	// Times of the recent reversals of the scaling direction, within the window of the flapping detection.
+	// +optional
+	// +listType=set
+	ScaleReversals []metav1.Time `json:"scaleReversals,omitempty"`
+	// Desired number of replicas in the direction suppressed by the scaling mode, when there is one.
+	// +optional
+	SuppressedReplicas int32 `json:"suppressedReplicas,omitempty"`
+	// Times of the scale events of the last hour, when the maxScaleEventsPerHour is set.
+	// +optional
+	// +listType=set
+	RecentScaleEvents []metav1.Time `json:"recentScaleEvents,omitempty"`
+	// Time of the last reconcile that went through the whole evaluation of the WPA.
+	// +optional
+	LastSuccessfulReconcile *metav1.Time `json:"lastSuccessfulReconcile,omitempty"`
+	// Canary being observed, when the canaryPercent is set.
+	// +optional
+	Canary *CanaryStatus `json:"canary,omitempty"`
+	// Consecutive failures of the metrics having a fallback, reset once the metric is computed again.
+	// +optional
+	// +listType=set
+	MetricFailures []MetricFailureStatus `json:"metricFailures,omitempty"`
+	// Number of replicas the controller last applied to the target, compared to the replicas of the target to
+	// detect the scaling done outside of the WPA.
+	// +optional
+	LastAppliedReplicas int32 `json:"lastAppliedReplicas,omitempty"`
+
+	// Options left unset in the spec, for which the controller uses the default values. The defaults are
+	// applied in memory and never written to the spec.
+	// +optional
+	AppliedDefaults []string `json:"appliedDefaults,omitempty"`
+	// Maximum number of replicas last served by the capacityCeiling endpoint.
+	// +optional
+	CapacityCeiling *CapacityCeilingStatus `json:"capacityCeiling,omitempty"`
+	// Replica-hours used by the target, compared to the ones it would have used at maxReplicas.
+	// +optional
+	Efficiency *EfficiencyStatus `json:"efficiency,omitempty"`
+	// How the pods of the target were accounted for in the computation of the last metric evaluated.
+	// +optional
+	Pods *PodsStatus `json:"pods,omitempty"`
+}
+
+// PodsStatus counts the pods of the target by how they were accounted for in the computation of a metric.
+// +k8s:openapi-gen=true
+type PodsStatus struct {
+	// Pods matching the selector of the target.
+	Total int32 `json:"total"`
+	// Pods counted as ready replicas, whose metrics are used.
+	Ready int32 `json:"ready"`
+	// Pods left out of the ready replicas: excluded, Failed, Pending, not ready yet or warming up.
+	Ignored int32 `json:"ignored"`
+	// Pods without a value returned by the resource metrics API, only counted for the Resource metrics.
+	MissingMetrics int32 `json:"missingMetrics"`
+}
+
+// EfficiencyStatus accumulates the replica-hours of the target and the ones it would have used at maxReplicas.
+// +k8s:openapi-gen=true
+type EfficiencyStatus struct {
+	// Time the accounting started.
+	Since metav1.Time `json:"since"`
+	// Time the replica-hours were last accounted.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// Replica-hours used by the target since the start of the accounting.
+	ReplicaHours float64 `json:"replicaHours"`
+	// Replica-hours the target would have used at maxReplicas since the start of the accounting.
+	MaxReplicaHours float64 `json:"maxReplicaHours"`
+}
+
+// CapacityCeilingStatus describes the last maximum number of replicas polled from the capacityCeiling endpoint.
+// +k8s:openapi-gen=true
+type CapacityCeilingStatus struct {
+	// Maximum number of replicas allowed by the endpoint.
+	MaxReplicas int32 `json:"maxReplicas"`
+	// Time the endpoint was last polled successfully.
+	LastPollTime metav1.Time `json:"lastPollTime"`
+}
+
+// MetricFailureStatus counts the consecutive failures of a metric having a fallback.
+// +k8s:openapi-gen=true
+type MetricFailureStatus struct {
+	// Name of the metric.
+	MetricName string `json:"metricName"`
+	// Number of consecutive reconciles the metric failed in.
+	ConsecutiveFailures int32 `json:"consecutiveFailures"`
+}
+
+// CanaryStatus describes the partial application of a recommended change of replicas.
+// +k8s:openapi-gen=true
+type CanaryStatus struct {
+	// Number of replicas of the target before the canary, restored when it is rolled back.
+	FromReplicas int32 `json:"fromReplicas"`
+	// Number of replicas recommended when the canary was applied.
+	ToReplicas int32 `json:"toReplicas"`
+	// Time the canary was applied.
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// ActiveMetricStatus describes the metric driving the replica count of the target
+// +k8s:openapi-gen=true
+type ActiveMetricStatus struct {
+	// Name of the metric.
+	Name string `json:"name"`
+	// Current value of the metric, averaged over the replicas with the average algorithm.
+	CurrentValue resource.Quantity `json:"currentValue"`
+	// High watermark in effect for the metric, once resolved from the steps or the utilization.
+	HighWatermark resource.Quantity `json:"highWatermark"`
+	// Low watermark in effect for the metric, once resolved from the steps or the utilization.
+	LowWatermark resource.Quantity `json:"lowWatermark"`
+}
+
+// MetricDetailStatus describes how a metric was compared to its watermarks, and the replicas it proposed.
+// +k8s:openapi-gen=true
+type MetricDetailStatus struct {
+	// Name of the metric.
+	Name string `json:"name"`
+	// Value of the metric, once its series aggregated or the values of its pods summed.
+	RawValue resource.Quantity `json:"rawValue"`
+	// Value compared to the watermarks, averaged over the replicas with the average algorithms.
+	AdjustedValue resource.Quantity `json:"adjustedValue"`
+	// Low watermark in effect for the metric, lowered by the tolerance.
+	EffectiveLowWatermark resource.Quantity `json:"effectiveLowWatermark"`
+	// High watermark in effect for the metric, raised by the tolerance.
+	EffectiveHighWatermark resource.Quantity `json:"effectiveHighWatermark"`
+	// Whether the adjusted value is between the effective watermarks.
+	WithinBounds bool `json:"withinBounds"`
+	// Number of replicas proposed by the metric.
+	ProposedReplicas int32 `json:"proposedReplicas"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
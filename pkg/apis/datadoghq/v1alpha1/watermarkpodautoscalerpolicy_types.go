@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WatermarkPodAutoscalerPolicy is the Schema for the watermarkpodautoscalerpolicies API
+// +k8s:openapi-gen=true
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=watermarkpodautoscalerpolicies,scope=Cluster,shortName=wpapolicy
+type WatermarkPodAutoscalerPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WatermarkPodAutoscalerPolicySpec `json:"spec,omitempty"`
+}
+
+// WatermarkPodAutoscalerPolicySpec defines the defaults and the limits applied to all the WPAs of the cluster.
+// When several policies exist, the defaults of the first one in the alphabetical order of their names are used,
+// and the most restrictive limits are enforced.
+// +k8s:openapi-gen=true
+type WatermarkPodAutoscalerPolicySpec struct {
+	// Values set on the WPAs that don't set them when they are created.
+	// +optional
+	Defaults *WatermarkPodAutoscalerPolicyDefaults `json:"defaults,omitempty"`
+	// Limits enforced on all the WPAs.
+	// +optional
+	Limits *WatermarkPodAutoscalerPolicyLimits `json:"limits,omitempty"`
+}
+
+// WatermarkPodAutoscalerPolicyDefaults replaces the built-in defaults of the WPAs.
+// +k8s:openapi-gen=true
+type WatermarkPodAutoscalerPolicyDefaults struct {
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:ExclusiveMinimum=true
+	// +kubebuilder:validation:Maximum=1
+	// +optional
+	Tolerance float64 `json:"tolerance,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DownscaleForbiddenWindowSeconds int32 `json:"downscaleForbiddenWindowSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	UpscaleForbiddenWindowSeconds int32 `json:"upscaleForbiddenWindowSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ScaleUpLimitFactor float64 `json:"scaleUpLimitFactor,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ScaleDownLimitFactor float64 `json:"scaleDownLimitFactor,omitempty"`
+}
+
+// WatermarkPodAutoscalerPolicyLimits bounds the settings of the WPAs. The settings beyond the limits are brought
+// back to them, and the WPAs using a forbidden algorithm don't scale.
+// +k8s:openapi-gen=true
+type WatermarkPodAutoscalerPolicyLimits struct {
+	// Highest maxReplicas allowed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// Shortest downscaleForbiddenWindowSeconds allowed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinDownscaleForbiddenWindowSeconds int32 `json:"minDownscaleForbiddenWindowSeconds,omitempty"`
+	// Shortest upscaleForbiddenWindowSeconds allowed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinUpscaleForbiddenWindowSeconds int32 `json:"minUpscaleForbiddenWindowSeconds,omitempty"`
+	// Algorithms the WPAs can't use.
+	// +optional
+	// +listType=set
+	ForbiddenAlgorithms []string `json:"forbiddenAlgorithms,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WatermarkPodAutoscalerPolicyList contains a list of WatermarkPodAutoscalerPolicy
+// +k8s:openapi-gen=true
+type WatermarkPodAutoscalerPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []WatermarkPodAutoscalerPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WatermarkPodAutoscalerPolicy{}, &WatermarkPodAutoscalerPolicyList{})
+}
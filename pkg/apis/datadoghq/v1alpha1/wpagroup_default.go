@@ -0,0 +1,32 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package v1alpha1
+
+import "fmt"
+
+// CheckWPAGroupValidity use to check the validity of a WPAGroup
+// return nil if valid, else an error
+func CheckWPAGroupValidity(group *WPAGroup) error {
+	for _, constraint := range group.Spec.Constraints {
+		if constraint.WPA == "" || constraint.Reference == "" {
+			msg := fmt.Sprintf("the constraints should have a WPA and a Reference, currently WPA:%s and Reference:%s", constraint.WPA, constraint.Reference)
+			return fmt.Errorf(msg)
+		}
+		if constraint.WPA == constraint.Reference {
+			msg := fmt.Sprintf("the WPA %s can't be constrained by itself", constraint.WPA)
+			return fmt.Errorf(msg)
+		}
+		if constraint.MinRatio == nil && constraint.MaxRatio == nil {
+			msg := fmt.Sprintf("the constraint of the WPA %s should have a MinRatio and/or a MaxRatio", constraint.WPA)
+			return fmt.Errorf(msg)
+		}
+		if constraint.MinRatio != nil && constraint.MaxRatio != nil && *constraint.MinRatio > *constraint.MaxRatio {
+			msg := fmt.Sprintf("the MinRatio of the constraint of the WPA %s has to be inferior to its MaxRatio, currently MinRatio:%d and MaxRatio:%d", constraint.WPA, *constraint.MinRatio, *constraint.MaxRatio)
+			return fmt.Errorf(msg)
+		}
+	}
+	return nil
+}
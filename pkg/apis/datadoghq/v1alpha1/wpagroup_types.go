@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package v1alpha1
+
+import (
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WPAGroup is the Schema for the wpagroups API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:path=wpagroups,shortName=wpag
+type WPAGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WPAGroupSpec   `json:"spec,omitempty"`
+	Status WPAGroupStatus `json:"status,omitempty"`
+}
+
+// WPAGroupSpec defines the desired state of WPAGroup
+// +k8s:openapi-gen=true
+type WPAGroupSpec struct {
+	// Ratios enforced between the replicas of the WPAs of the group.
+	// +listType=set
+	Constraints []WPAGroupConstraint `json:"constraints"`
+}
+
+// WPAGroupConstraint bounds the replicas of the target of a WPA with the current replicas of the target of another WPA
+// of the same namespace, e.g. workers ≤ 2×dispatchers is expressed with `wpa: workers`, `reference: dispatchers` and `maxRatio: 200`.
+// As the bounds follow the current replicas of the reference, the reference is scaled first.
+// +k8s:openapi-gen=true
+type WPAGroupConstraint struct {
+	// Name of the constrained WPA.
+	WPA string `json:"wpa"`
+	// Name of the WPA the constrained WPA follows.
+	Reference string `json:"reference"`
+	// Minimum number of replicas of the WPA, in percent of the replicas of the reference.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinRatio *int32 `json:"minRatio,omitempty"`
+	// Maximum number of replicas of the WPA, in percent of the replicas of the reference.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRatio *int32 `json:"maxRatio,omitempty"`
+}
+
+// WPAGroupStatus defines the observed state of WPAGroup
+// +k8s:openapi-gen=true
+type WPAGroupStatus struct {
+	// Replicas allowed for the constrained WPAs, given the current replicas of their references.
+	// +listType=set
+	Bounds []WPAGroupBound `json:"bounds,omitempty"`
+	// +listType=set
+	Conditions []autoscalingv2.HorizontalPodAutoscalerCondition `json:"conditions,omitempty"`
+}
+
+// WPAGroupBound is the range of replicas allowed for a WPA of the group.
+// +k8s:openapi-gen=true
+type WPAGroupBound struct {
+	// Name of the WPA.
+	WPA         string `json:"wpa"`
+	MinReplicas int32  `json:"minReplicas"`
+	MaxReplicas int32  `json:"maxReplicas"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WPAGroupList contains a list of WPAGroup
+// +k8s:openapi-gen=true
+type WPAGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// +listType=set
+	Items []WPAGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WPAGroup{}, &WPAGroupList{})
+}
@@ -11,13 +11,193 @@ package v1alpha1
 
 import (
 	v2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveMetricStatus) DeepCopyInto(out *ActiveMetricStatus) {
+	*out = *in
+	out.CurrentValue = in.CurrentValue.DeepCopy()
+	out.HighWatermark = in.HighWatermark.DeepCopy()
+	out.LowWatermark = in.LowWatermark.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveMetricStatus.
+func (in *ActiveMetricStatus) DeepCopy() *ActiveMetricStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveMetricStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveToleranceSpec) DeepCopyInto(out *AdaptiveToleranceSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveToleranceSpec.
+func (in *AdaptiveToleranceSpec) DeepCopy() *AdaptiveToleranceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveToleranceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BaselineSpec) DeepCopyInto(out *BaselineSpec) {
+	*out = *in
+	out.IdleThreshold = in.IdleThreshold.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BaselineSpec.
+func (in *BaselineSpec) DeepCopy() *BaselineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BaselineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenSpec) DeepCopyInto(out *BlueGreenSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenSpec.
+func (in *BlueGreenSpec) DeepCopy() *BlueGreenSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BudgetSpec) DeepCopyInto(out *BudgetSpec) {
+	*out = *in
+	out.CostPerReplicaHour = in.CostPerReplicaHour.DeepCopy()
+	out.MaxCostPerHour = in.MaxCostPerHour.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BudgetSpec.
+func (in *BudgetSpec) DeepCopy() *BudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityCeilingSpec) DeepCopyInto(out *CapacityCeilingSpec) {
+	*out = *in
+	in.HTTPMetricSource.DeepCopyInto(&out.HTTPMetricSource)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityCeilingSpec.
+func (in *CapacityCeilingSpec) DeepCopy() *CapacityCeilingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityCeilingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityCeilingStatus) DeepCopyInto(out *CapacityCeilingStatus) {
+	*out = *in
+	in.LastPollTime.DeepCopyInto(&out.LastPollTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityCeilingStatus.
+func (in *CapacityCeilingStatus) DeepCopy() *CapacityCeilingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityCeilingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerWeight) DeepCopyInto(out *ContainerWeight) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerWeight.
+func (in *ContainerWeight) DeepCopy() *ContainerWeight {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerWeight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CordonedNodesSpec) DeepCopyInto(out *CordonedNodesSpec) {
+	*out = *in
+	if in.TaintKeys != nil {
+		in, out := &in.TaintKeys, &out.TaintKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CordonedNodesSpec.
+func (in *CordonedNodesSpec) DeepCopy() *CordonedNodesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CordonedNodesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossVersionObjectReference) DeepCopyInto(out *CrossVersionObjectReference) {
 	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -31,6 +211,56 @@ func (in *CrossVersionObjectReference) DeepCopy() *CrossVersionObjectReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecisionHistorySpec) DeepCopyInto(out *DecisionHistorySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecisionHistorySpec.
+func (in *DecisionHistorySpec) DeepCopy() *DecisionHistorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DecisionHistorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunReportSpec) DeepCopyInto(out *DryRunReportSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunReportSpec.
+func (in *DryRunReportSpec) DeepCopy() *DryRunReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EfficiencyStatus) DeepCopyInto(out *EfficiencyStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EfficiencyStatus.
+func (in *EfficiencyStatus) DeepCopy() *EfficiencyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EfficiencyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalMetricSource) DeepCopyInto(out *ExternalMetricSource) {
 	*out = *in
@@ -49,6 +279,53 @@ func (in *ExternalMetricSource) DeepCopyInto(out *ExternalMetricSource) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.HighWatermarkFrom != nil {
+		in, out := &in.HighWatermarkFrom, &out.HighWatermarkFrom
+		*out = new(WatermarkSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LowWatermarkFrom != nil {
+		in, out := &in.LowWatermarkFrom, &out.LowWatermarkFrom
+		*out = new(WatermarkSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WatermarkSteps != nil {
+		in, out := &in.WatermarkSteps, &out.WatermarkSteps
+		*out = make([]WatermarkStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EmergencyHighWatermark != nil {
+		in, out := &in.EmergencyHighWatermark, &out.EmergencyHighWatermark
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MissingDatapoints != nil {
+		in, out := &in.MissingDatapoints, &out.MissingDatapoints
+		*out = new(MissingDatapointsPolicy)
+		**out = **in
+	}
+	if in.RateOfChange != nil {
+		in, out := &in.RateOfChange, &out.RateOfChange
+		*out = new(RateOfChangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleUpStrategy != nil {
+		in, out := &in.ScaleUpStrategy, &out.ScaleUpStrategy
+		*out = new(ScaleUpStrategySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPMetricSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Histogram != nil {
+		in, out := &in.Histogram, &out.Histogram
+		*out = new(HistogramMetricSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -63,58 +340,819 @@ func (in *ExternalMetricSource) DeepCopy() *ExternalMetricSource {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
+func (in *FailedPodsSpec) DeepCopyInto(out *FailedPodsSpec) {
+	*out = *in
+	if in.WarningThresholdPercent != nil {
+		in, out := &in.WarningThresholdPercent, &out.WarningThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedPodsSpec.
+func (in *FailedPodsSpec) DeepCopy() *FailedPodsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedPodsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlappingDetectionSpec) DeepCopyInto(out *FlappingDetectionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlappingDetectionSpec.
+func (in *FlappingDetectionSpec) DeepCopy() *FlappingDetectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FlappingDetectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPMetricSource) DeepCopyInto(out *HTTPMetricSource) {
+	*out = *in
+	if in.AuthorizationSecretRef != nil {
+		in, out := &in.AuthorizationSecretRef, &out.AuthorizationSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPMetricSource.
+func (in *HTTPMetricSource) DeepCopy() *HTTPMetricSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPMetricSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistogramMetricSpec) DeepCopyInto(out *HistogramMetricSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistogramMetricSpec.
+func (in *HistogramMetricSpec) DeepCopy() *HistogramMetricSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HistogramMetricSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HysteresisSpec) DeepCopyInto(out *HysteresisSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HysteresisSpec.
+func (in *HysteresisSpec) DeepCopy() *HysteresisSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HysteresisSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricDetailStatus) DeepCopyInto(out *MetricDetailStatus) {
+	*out = *in
+	out.RawValue = in.RawValue.DeepCopy()
+	out.AdjustedValue = in.AdjustedValue.DeepCopy()
+	out.EffectiveLowWatermark = in.EffectiveLowWatermark.DeepCopy()
+	out.EffectiveHighWatermark = in.EffectiveHighWatermark.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricDetailStatus.
+func (in *MetricDetailStatus) DeepCopy() *MetricDetailStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricDetailStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricFailureStatus) DeepCopyInto(out *MetricFailureStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricFailureStatus.
+func (in *MetricFailureStatus) DeepCopy() *MetricFailureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricFailureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricSpec) DeepCopyInto(out *MetricSpec) {
+	*out = *in
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalMetricSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resource != nil {
+		in, out := &in.Resource, &out.Resource
+		*out = new(ResourceMetricSource)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSpec.
+func (in *MetricSpec) DeepCopy() *MetricSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MissingDatapointsPolicy) DeepCopyInto(out *MissingDatapointsPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MissingDatapointsPolicy.
+func (in *MissingDatapointsPolicy) DeepCopy() *MissingDatapointsPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MissingDatapointsPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodsStatus) DeepCopyInto(out *PodsStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodsStatus.
+func (in *PodsStatus) DeepCopy() *PodsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PodsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfilePeriod) DeepCopyInto(out *ProfilePeriod) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfilePeriod.
+func (in *ProfilePeriod) DeepCopy() *ProfilePeriod {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfilePeriod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileWatermarks) DeepCopyInto(out *ProfileWatermarks) {
+	*out = *in
+	out.HighWatermark = in.HighWatermark.DeepCopy()
+	out.LowWatermark = in.LowWatermark.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileWatermarks.
+func (in *ProfileWatermarks) DeepCopy() *ProfileWatermarks {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileWatermarks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateOfChangeSpec) DeepCopyInto(out *RateOfChangeSpec) {
+	*out = *in
+	out.Slope = in.Slope.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateOfChangeSpec.
+func (in *RateOfChangeSpec) DeepCopy() *RateOfChangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RateOfChangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendationHistorySpec) DeepCopyInto(out *RecommendationHistorySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendationHistorySpec.
+func (in *RecommendationHistorySpec) DeepCopy() *RecommendationHistorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendationHistorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterSpec) DeepCopyInto(out *RemoteClusterSpec) {
+	*out = *in
+	in.KubeconfigSecretRef.DeepCopyInto(&out.KubeconfigSecretRef)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterSpec.
+func (in *RemoteClusterSpec) DeepCopy() *RemoteClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceMetricSource) DeepCopyInto(out *ResourceMetricSource) {
+	*out = *in
+	if in.ContainerWeights != nil {
+		in, out := &in.ContainerWeights, &out.ContainerWeights
+		*out = make([]ContainerWeight, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetricSelector != nil {
+		in, out := &in.MetricSelector, &out.MetricSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighWatermark != nil {
+		in, out := &in.HighWatermark, &out.HighWatermark
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LowWatermark != nil {
+		in, out := &in.LowWatermark, &out.LowWatermark
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.HighWatermarkFrom != nil {
+		in, out := &in.HighWatermarkFrom, &out.HighWatermarkFrom
+		*out = new(WatermarkSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LowWatermarkFrom != nil {
+		in, out := &in.LowWatermarkFrom, &out.LowWatermarkFrom
+		*out = new(WatermarkSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WatermarkSteps != nil {
+		in, out := &in.WatermarkSteps, &out.WatermarkSteps
+		*out = make([]WatermarkStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EmergencyHighWatermark != nil {
+		in, out := &in.EmergencyHighWatermark, &out.EmergencyHighWatermark
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.HighWatermarkUtilization != nil {
+		in, out := &in.HighWatermarkUtilization, &out.HighWatermarkUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LowWatermarkUtilization != nil {
+		in, out := &in.LowWatermarkUtilization, &out.LowWatermarkUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodQuantile != nil {
+		in, out := &in.PodQuantile, &out.PodQuantile
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceMetricSource.
+func (in *ResourceMetricSource) DeepCopy() *ResourceMetricSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceMetricSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoundingSpec) DeepCopyInto(out *RoundingSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoundingSpec.
+func (in *RoundingSpec) DeepCopy() *RoundingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RoundingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleUpStrategySpec) DeepCopyInto(out *ScaleUpStrategySpec) {
+	*out = *in
+	if in.UsagePerReplica != nil {
+		in, out := &in.UsagePerReplica, &out.UsagePerReplica
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ProcessingRate != nil {
+		in, out := &in.ProcessingRate, &out.ProcessingRate
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleUpStrategySpec.
+func (in *ScaleUpStrategySpec) DeepCopy() *ScaleUpStrategySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleUpStrategySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPlan) DeepCopyInto(out *ScalingPlan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPlan.
+func (in *ScalingPlan) DeepCopy() *ScalingPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScalingPlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPlanList) DeepCopyInto(out *ScalingPlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScalingPlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPlanList.
+func (in *ScalingPlanList) DeepCopy() *ScalingPlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScalingPlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPlanSpec) DeepCopyInto(out *ScalingPlanSpec) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ScalingPlanStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPlanSpec.
+func (in *ScalingPlanSpec) DeepCopy() *ScalingPlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPlanStatus) DeepCopyInto(out *ScalingPlanStatus) {
+	*out = *in
+	if in.NextStepTime != nil {
+		in, out := &in.NextStepTime, &out.NextStepTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v2beta1.HorizontalPodAutoscalerCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPlanStatus.
+func (in *ScalingPlanStatus) DeepCopy() *ScalingPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPlanStep) DeepCopyInto(out *ScalingPlanStep) {
+	*out = *in
+	in.At.DeepCopyInto(&out.At)
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPlanStep.
+func (in *ScalingPlanStep) DeepCopy() *ScalingPlanStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPlanStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingProfile) DeepCopyInto(out *ScalingProfile) {
+	*out = *in
+	if in.Periods != nil {
+		in, out := &in.Periods, &out.Periods
+		*out = make([]ProfilePeriod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Watermarks != nil {
+		in, out := &in.Watermarks, &out.Watermarks
+		*out = make([]ProfileWatermarks, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingProfile.
+func (in *ScalingProfile) DeepCopy() *ScalingProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShardCluster) DeepCopyInto(out *ShardCluster) {
+	*out = *in
+	in.RemoteClusterSpec.DeepCopyInto(&out.RemoteClusterSpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShardCluster.
+func (in *ShardCluster) DeepCopy() *ShardCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ShardCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShardsSpec) DeepCopyInto(out *ShardsSpec) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ShardCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShardsSpec.
+func (in *ShardsSpec) DeepCopy() *ShardsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ShardsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpecialDaysCalendar) DeepCopyInto(out *SpecialDaysCalendar) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpecialDaysCalendar.
+func (in *SpecialDaysCalendar) DeepCopy() *SpecialDaysCalendar {
+	if in == nil {
+		return nil
+	}
+	out := new(SpecialDaysCalendar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetScalingSpec) DeepCopyInto(out *StatefulSetScalingSpec) {
+	*out = *in
+	if in.OrderedDownscaleStep != nil {
+		in, out := &in.OrderedDownscaleStep, &out.OrderedDownscaleStep
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetScalingSpec.
+func (in *StatefulSetScalingSpec) DeepCopy() *StatefulSetScalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetScalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SteppedConvergenceSpec) DeepCopyInto(out *SteppedConvergenceSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SteppedConvergenceSpec.
+func (in *SteppedConvergenceSpec) DeepCopy() *SteppedConvergenceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SteppedConvergenceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WPAGroup) DeepCopyInto(out *WPAGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WPAGroup.
+func (in *WPAGroup) DeepCopy() *WPAGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(WPAGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WPAGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WPAGroupBound) DeepCopyInto(out *WPAGroupBound) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WPAGroupBound.
+func (in *WPAGroupBound) DeepCopy() *WPAGroupBound {
+	if in == nil {
+		return nil
+	}
+	out := new(WPAGroupBound)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WPAGroupConstraint) DeepCopyInto(out *WPAGroupConstraint) {
+	*out = *in
+	if in.MinRatio != nil {
+		in, out := &in.MinRatio, &out.MinRatio
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRatio != nil {
+		in, out := &in.MaxRatio, &out.MaxRatio
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WPAGroupConstraint.
+func (in *WPAGroupConstraint) DeepCopy() *WPAGroupConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(WPAGroupConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WPAGroupList) DeepCopyInto(out *WPAGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WPAGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WPAGroupList.
+func (in *WPAGroupList) DeepCopy() *WPAGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(WPAGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WPAGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WPAGroupSpec) DeepCopyInto(out *WPAGroupSpec) {
 	*out = *in
-	if in.External != nil {
-		in, out := &in.External, &out.External
-		*out = new(ExternalMetricSource)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Resource != nil {
-		in, out := &in.Resource, &out.Resource
-		*out = new(ResourceMetricSource)
-		(*in).DeepCopyInto(*out)
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = make([]WPAGroupConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricSpec.
-func (in *MetricSpec) DeepCopy() *MetricSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WPAGroupSpec.
+func (in *WPAGroupSpec) DeepCopy() *WPAGroupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MetricSpec)
+	out := new(WPAGroupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceMetricSource) DeepCopyInto(out *ResourceMetricSource) {
+func (in *WPAGroupStatus) DeepCopyInto(out *WPAGroupStatus) {
 	*out = *in
-	if in.MetricSelector != nil {
-		in, out := &in.MetricSelector, &out.MetricSelector
-		*out = new(v1.LabelSelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.HighWatermark != nil {
-		in, out := &in.HighWatermark, &out.HighWatermark
-		x := (*in).DeepCopy()
-		*out = &x
+	if in.Bounds != nil {
+		in, out := &in.Bounds, &out.Bounds
+		*out = make([]WPAGroupBound, len(*in))
+		copy(*out, *in)
 	}
-	if in.LowWatermark != nil {
-		in, out := &in.LowWatermark, &out.LowWatermark
-		x := (*in).DeepCopy()
-		*out = &x
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v2beta1.HorizontalPodAutoscalerCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceMetricSource.
-func (in *ResourceMetricSource) DeepCopy() *ResourceMetricSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WPAGroupStatus.
+func (in *WPAGroupStatus) DeepCopy() *WPAGroupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceMetricSource)
+	out := new(WPAGroupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -180,10 +1218,165 @@ func (in *WatermarkPodAutoscalerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatermarkPodAutoscalerPolicy) DeepCopyInto(out *WatermarkPodAutoscalerPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatermarkPodAutoscalerPolicy.
+func (in *WatermarkPodAutoscalerPolicy) DeepCopy() *WatermarkPodAutoscalerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WatermarkPodAutoscalerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WatermarkPodAutoscalerPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatermarkPodAutoscalerPolicyDefaults) DeepCopyInto(out *WatermarkPodAutoscalerPolicyDefaults) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatermarkPodAutoscalerPolicyDefaults.
+func (in *WatermarkPodAutoscalerPolicyDefaults) DeepCopy() *WatermarkPodAutoscalerPolicyDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(WatermarkPodAutoscalerPolicyDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatermarkPodAutoscalerPolicyLimits) DeepCopyInto(out *WatermarkPodAutoscalerPolicyLimits) {
+	*out = *in
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ForbiddenAlgorithms != nil {
+		in, out := &in.ForbiddenAlgorithms, &out.ForbiddenAlgorithms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatermarkPodAutoscalerPolicyLimits.
+func (in *WatermarkPodAutoscalerPolicyLimits) DeepCopy() *WatermarkPodAutoscalerPolicyLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(WatermarkPodAutoscalerPolicyLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatermarkPodAutoscalerPolicyList) DeepCopyInto(out *WatermarkPodAutoscalerPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WatermarkPodAutoscalerPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatermarkPodAutoscalerPolicyList.
+func (in *WatermarkPodAutoscalerPolicyList) DeepCopy() *WatermarkPodAutoscalerPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(WatermarkPodAutoscalerPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WatermarkPodAutoscalerPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatermarkPodAutoscalerPolicySpec) DeepCopyInto(out *WatermarkPodAutoscalerPolicySpec) {
+	*out = *in
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(WatermarkPodAutoscalerPolicyDefaults)
+		**out = **in
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(WatermarkPodAutoscalerPolicyLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatermarkPodAutoscalerPolicySpec.
+func (in *WatermarkPodAutoscalerPolicySpec) DeepCopy() *WatermarkPodAutoscalerPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WatermarkPodAutoscalerPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WatermarkPodAutoscalerSpec) DeepCopyInto(out *WatermarkPodAutoscalerSpec) {
 	*out = *in
-	out.ScaleTargetRef = in.ScaleTargetRef
+	if in.MaxChangePerReconcile != nil {
+		in, out := &in.MaxChangePerReconcile, &out.MaxChangePerReconcile
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Rounding != nil {
+		in, out := &in.Rounding, &out.Rounding
+		*out = new(RoundingSpec)
+		**out = **in
+	}
+	if in.DryRunReport != nil {
+		in, out := &in.DryRunReport, &out.DryRunReport
+		*out = new(DryRunReportSpec)
+		**out = **in
+	}
+	if in.DecisionHistory != nil {
+		in, out := &in.DecisionHistory, &out.DecisionHistory
+		*out = new(DecisionHistorySpec)
+		**out = **in
+	}
+	in.ScaleTargetRef.DeepCopyInto(&out.ScaleTargetRef)
+	if in.ScaleTargetRefs != nil {
+		in, out := &in.ScaleTargetRefs, &out.ScaleTargetRefs
+		*out = make([]WeightedCrossVersionObjectReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Metrics != nil {
 		in, out := &in.Metrics, &out.Metrics
 		*out = make([]MetricSpec, len(*in))
@@ -196,6 +1389,103 @@ func (in *WatermarkPodAutoscalerSpec) DeepCopyInto(out *WatermarkPodAutoscalerSp
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExcludeCordonedNodes != nil {
+		in, out := &in.ExcludeCordonedNodes, &out.ExcludeCordonedNodes
+		*out = new(CordonedNodesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludedPodSelector != nil {
+		in, out := &in.ExcludedPodSelector, &out.ExcludedPodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailedPods != nil {
+		in, out := &in.FailedPods, &out.FailedPods
+		*out = new(FailedPodsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Budget != nil {
+		in, out := &in.Budget, &out.Budget
+		*out = new(BudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapacityCeiling != nil {
+		in, out := &in.CapacityCeiling, &out.CapacityCeiling
+		*out = new(CapacityCeilingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SteppedConvergence != nil {
+		in, out := &in.SteppedConvergence, &out.SteppedConvergence
+		*out = new(SteppedConvergenceSpec)
+		**out = **in
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		*out = new(BaselineSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxScaleEventsPerHour != nil {
+		in, out := &in.MaxScaleEventsPerHour, &out.MaxScaleEventsPerHour
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FlappingDetection != nil {
+		in, out := &in.FlappingDetection, &out.FlappingDetection
+		*out = new(FlappingDetectionSpec)
+		**out = **in
+	}
+	if in.Hysteresis != nil {
+		in, out := &in.Hysteresis, &out.Hysteresis
+		*out = new(HysteresisSpec)
+		**out = **in
+	}
+	if in.AdaptiveTolerance != nil {
+		in, out := &in.AdaptiveTolerance, &out.AdaptiveTolerance
+		*out = new(AdaptiveToleranceSpec)
+		**out = **in
+	}
+	if in.RecommendationHistory != nil {
+		in, out := &in.RecommendationHistory, &out.RecommendationHistory
+		*out = new(RecommendationHistorySpec)
+		**out = **in
+	}
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(StatefulSetScalingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]ScalingProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Calendar != nil {
+		in, out := &in.Calendar, &out.Calendar
+		*out = new(SpecialDaysCalendar)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteCluster != nil {
+		in, out := &in.RemoteCluster, &out.RemoteCluster
+		*out = new(RemoteClusterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = new(ShardsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(BlueGreenSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -235,6 +1525,70 @@ func (in *WatermarkPodAutoscalerStatus) DeepCopyInto(out *WatermarkPodAutoscaler
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ActiveMetric != nil {
+		in, out := &in.ActiveMetric, &out.ActiveMetric
+		*out = new(ActiveMetricStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricDetails != nil {
+		in, out := &in.MetricDetails, &out.MetricDetails
+		*out = make([]MetricDetailStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
+	if in.ScaleReversals != nil {
+		in, out := &in.ScaleReversals, &out.ScaleReversals
+		*out = make([]v1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RecentScaleEvents != nil {
+		in, out := &in.RecentScaleEvents, &out.RecentScaleEvents
+		*out = make([]v1.Time, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSuccessfulReconcile != nil {
+		in, out := &in.LastSuccessfulReconcile, &out.LastSuccessfulReconcile
+		*out = (*in).DeepCopy()
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricFailures != nil {
+		in, out := &in.MetricFailures, &out.MetricFailures
+		*out = make([]MetricFailureStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.AppliedDefaults != nil {
+		in, out := &in.AppliedDefaults, &out.AppliedDefaults
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CapacityCeiling != nil {
+		in, out := &in.CapacityCeiling, &out.CapacityCeiling
+		*out = new(CapacityCeilingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Efficiency != nil {
+		in, out := &in.Efficiency, &out.Efficiency
+		*out = new(EfficiencyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = new(PodsStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -247,3 +1601,64 @@ func (in *WatermarkPodAutoscalerStatus) DeepCopy() *WatermarkPodAutoscalerStatus
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatermarkSource) DeepCopyInto(out *WatermarkSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatermarkSource.
+func (in *WatermarkSource) DeepCopy() *WatermarkSource {
+	if in == nil {
+		return nil
+	}
+	out := new(WatermarkSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WatermarkStep) DeepCopyInto(out *WatermarkStep) {
+	*out = *in
+	out.HighWatermark = in.HighWatermark.DeepCopy()
+	out.LowWatermark = in.LowWatermark.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WatermarkStep.
+func (in *WatermarkStep) DeepCopy() *WatermarkStep {
+	if in == nil {
+		return nil
+	}
+	out := new(WatermarkStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedCrossVersionObjectReference) DeepCopyInto(out *WeightedCrossVersionObjectReference) {
+	*out = *in
+	in.CrossVersionObjectReference.DeepCopyInto(&out.CrossVersionObjectReference)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedCrossVersionObjectReference.
+func (in *WeightedCrossVersionObjectReference) DeepCopy() *WeightedCrossVersionObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedCrossVersionObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
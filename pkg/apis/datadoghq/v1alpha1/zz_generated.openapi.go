@@ -18,376 +18,2864 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CrossVersionObjectReference":  schema_pkg_apis_datadoghq_v1alpha1_CrossVersionObjectReference(ref),
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ExternalMetricSource":         schema_pkg_apis_datadoghq_v1alpha1_ExternalMetricSource(ref),
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricSpec":                   schema_pkg_apis_datadoghq_v1alpha1_MetricSpec(ref),
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ResourceMetricSource":         schema_pkg_apis_datadoghq_v1alpha1_ResourceMetricSource(ref),
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscaler":       schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscaler(ref),
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerList":   schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerList(ref),
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerSpec":   schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerSpec(ref),
-		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerStatus": schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ActiveMetricStatus":                   schema_pkg_apis_datadoghq_v1alpha1_ActiveMetricStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.AdaptiveToleranceSpec":                schema_pkg_apis_datadoghq_v1alpha1_AdaptiveToleranceSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BaselineSpec":                         schema_pkg_apis_datadoghq_v1alpha1_BaselineSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BlueGreenSpec":                        schema_pkg_apis_datadoghq_v1alpha1_BlueGreenSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BudgetSpec":                           schema_pkg_apis_datadoghq_v1alpha1_BudgetSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CanaryStatus":                         schema_pkg_apis_datadoghq_v1alpha1_CanaryStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CapacityCeilingSpec":                  schema_pkg_apis_datadoghq_v1alpha1_CapacityCeilingSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CapacityCeilingStatus":                schema_pkg_apis_datadoghq_v1alpha1_CapacityCeilingStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ContainerWeight":                      schema_pkg_apis_datadoghq_v1alpha1_ContainerWeight(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CordonedNodesSpec":                    schema_pkg_apis_datadoghq_v1alpha1_CordonedNodesSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CrossVersionObjectReference":          schema_pkg_apis_datadoghq_v1alpha1_CrossVersionObjectReference(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.DecisionHistorySpec":                  schema_pkg_apis_datadoghq_v1alpha1_DecisionHistorySpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.DryRunReportSpec":                     schema_pkg_apis_datadoghq_v1alpha1_DryRunReportSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.EfficiencyStatus":                     schema_pkg_apis_datadoghq_v1alpha1_EfficiencyStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ExternalMetricSource":                 schema_pkg_apis_datadoghq_v1alpha1_ExternalMetricSource(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.FailedPodsSpec":                       schema_pkg_apis_datadoghq_v1alpha1_FailedPodsSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.FlappingDetectionSpec":                schema_pkg_apis_datadoghq_v1alpha1_FlappingDetectionSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HTTPMetricSource":                     schema_pkg_apis_datadoghq_v1alpha1_HTTPMetricSource(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HistogramMetricSpec":                  schema_pkg_apis_datadoghq_v1alpha1_HistogramMetricSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HysteresisSpec":                       schema_pkg_apis_datadoghq_v1alpha1_HysteresisSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricDetailStatus":                   schema_pkg_apis_datadoghq_v1alpha1_MetricDetailStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricFailureStatus":                  schema_pkg_apis_datadoghq_v1alpha1_MetricFailureStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricSpec":                           schema_pkg_apis_datadoghq_v1alpha1_MetricSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MissingDatapointsPolicy":              schema_pkg_apis_datadoghq_v1alpha1_MissingDatapointsPolicy(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.PodsStatus":                           schema_pkg_apis_datadoghq_v1alpha1_PodsStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ProfilePeriod":                        schema_pkg_apis_datadoghq_v1alpha1_ProfilePeriod(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ProfileWatermarks":                    schema_pkg_apis_datadoghq_v1alpha1_ProfileWatermarks(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RateOfChangeSpec":                     schema_pkg_apis_datadoghq_v1alpha1_RateOfChangeSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RecommendationHistorySpec":            schema_pkg_apis_datadoghq_v1alpha1_RecommendationHistorySpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RemoteClusterSpec":                    schema_pkg_apis_datadoghq_v1alpha1_RemoteClusterSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ResourceMetricSource":                 schema_pkg_apis_datadoghq_v1alpha1_ResourceMetricSource(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RoundingSpec":                         schema_pkg_apis_datadoghq_v1alpha1_RoundingSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScaleUpStrategySpec":                  schema_pkg_apis_datadoghq_v1alpha1_ScaleUpStrategySpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlan":                          schema_pkg_apis_datadoghq_v1alpha1_ScalingPlan(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanList":                      schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanList(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanSpec":                      schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanStatus":                    schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanStep":                      schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanStep(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingProfile":                       schema_pkg_apis_datadoghq_v1alpha1_ScalingProfile(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ShardCluster":                         schema_pkg_apis_datadoghq_v1alpha1_ShardCluster(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ShardsSpec":                           schema_pkg_apis_datadoghq_v1alpha1_ShardsSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.SpecialDaysCalendar":                  schema_pkg_apis_datadoghq_v1alpha1_SpecialDaysCalendar(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.StatefulSetScalingSpec":               schema_pkg_apis_datadoghq_v1alpha1_StatefulSetScalingSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.SteppedConvergenceSpec":               schema_pkg_apis_datadoghq_v1alpha1_SteppedConvergenceSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroup":                             schema_pkg_apis_datadoghq_v1alpha1_WPAGroup(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupBound":                        schema_pkg_apis_datadoghq_v1alpha1_WPAGroupBound(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupConstraint":                   schema_pkg_apis_datadoghq_v1alpha1_WPAGroupConstraint(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupList":                         schema_pkg_apis_datadoghq_v1alpha1_WPAGroupList(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupSpec":                         schema_pkg_apis_datadoghq_v1alpha1_WPAGroupSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupStatus":                       schema_pkg_apis_datadoghq_v1alpha1_WPAGroupStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscaler":               schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscaler(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerList":           schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerList(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicy":         schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicy(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicyDefaults": schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicyDefaults(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicyLimits":   schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicyLimits(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicyList":     schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicyList(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicySpec":     schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicySpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerSpec":           schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerSpec(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerStatus":         schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerStatus(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkSource":                      schema_pkg_apis_datadoghq_v1alpha1_WatermarkSource(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkStep":                        schema_pkg_apis_datadoghq_v1alpha1_WatermarkStep(ref),
+		"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WeightedCrossVersionObjectReference":  schema_pkg_apis_datadoghq_v1alpha1_WeightedCrossVersionObjectReference(ref),
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_CrossVersionObjectReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_ActiveMetricStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "CrossVersionObjectReference contains enough information to let you identify the referred resource.",
+				Description: "ActiveMetricStatus describes the metric driving the replica count of the target",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind of the referent; More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds\"",
+							Description: "Name of the metric.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"name": {
+					"currentValue": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the referent; More info: http://kubernetes.io/docs/user-guide/identifiers#names",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Current value of the metric, averaged over the replicas with the average algorithm.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
-					"apiVersion": {
+					"highWatermark": {
 						SchemaProps: spec.SchemaProps{
-							Description: "API version of the referent",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "High watermark in effect for the metric, once resolved from the steps or the utilization.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"lowWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Low watermark in effect for the metric, once resolved from the steps or the utilization.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
 				},
-				Required: []string{"kind", "name"},
+				Required: []string{"name", "currentValue", "highWatermark", "lowWatermark"},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_ExternalMetricSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_AdaptiveToleranceSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ExternalMetricSource indicates how to scale on a metric not associated with any Kubernetes object (for example length of queue in cloud messaging service, or QPS from loadbalancer running outside of cluster). Exactly one \"target\" type should be set.",
+				Description: "AdaptiveToleranceSpec describes how the tolerance follows the variation of the metrics. The effective tolerance of a metric is its coefficient of variation (standard deviation over mean) over the recent values multiplied by the factor, within the tolerance of the spec and the maximum.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"metricName": {
-						SchemaProps: spec.SchemaProps{
-							Description: "metricName is the name of the metric in question.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"metricSelector": {
+					"samples": {
 						SchemaProps: spec.SchemaProps{
-							Description: "metricSelector is used to identify a specific time series within a given metric.",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+							Description: "Number of recent values of each metric the variation is computed over.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"highWatermark": {
+					"factor": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+							Description: "Factor applied to the coefficient of variation, 1 by default.",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
-					"lowWatermark": {
+					"maxTolerance": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+							Description: "Maximum effective tolerance, 0.5 by default.",
+							Type:        []string{"number"},
+							Format:      "double",
 						},
 					},
 				},
-				Required: []string{"metricName"},
+				Required: []string{"samples"},
 			},
 		},
-		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/api/resource.Quantity", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_MetricSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_BaselineSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "MetricSpec specifies how to scale based on a single metric (only `type` and one other matching field should be set at once).",
+				Description: "BaselineSpec describes the number of replicas the target returns to when its metrics are idle.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"type": {
+					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "type is the type of metric source.  It should be one of \"Object\", \"Pods\" or \"Resource\", each mapping to a matching field in the object.",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "Number of replicas of the target once idle, within the minReplicas and maxReplicas.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"external": {
+					"idleThreshold": {
 						SchemaProps: spec.SchemaProps{
-							Description: "external refers to a global metric that is not associated with any Kubernetes object. It allows autoscaling based on information coming from components running outside of cluster (for example length of queue in cloud messaging service, or QPS from loadbalancer running outside of cluster).",
-							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ExternalMetricSource"),
+							Description: "The metrics are idle while all their values are below this threshold.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
-					"resource": {
+					"idleWindowSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Description: "resource refers to a resource metric (such as those specified in requests and limits) known to Kubernetes describing each pod in the current scale target (e.g. CPU or memory). Such metrics are built in to Kubernetes, and have special scaling options on top of those available to normal per-pod metrics using the \"pods\" source.",
-							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ResourceMetricSource"),
+							Description: "Duration the metrics have to be idle for before the target returns to the baseline.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
-				Required: []string{"type"},
+				Required: []string{"replicas", "idleThreshold", "idleWindowSeconds"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ExternalMetricSource", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ResourceMetricSource"},
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_ResourceMetricSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_BlueGreenSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ResourceMetricSource indicates how to scale on a resource metric known to Kubernetes, as specified in requests and limits, describing each pod in the current scale target (e.g. CPU or memory).  The values will be averaged together before being compared to the target.  Such metrics are built in to Kubernetes, and have special scaling options on top of those available to normal per-pod metrics using the \"pods\" source.  Only one \"target\" type should be set.",
+				Description: "BlueGreenSpec describes how the live color of a blue/green pair is determined.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
+					"serviceName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "name is the name of the resource in question.",
+							Description: "Name of the Service, in the namespace of the WPA, routing the traffic to the live color.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metricSelector": {
+					"colorLabel": {
 						SchemaProps: spec.SchemaProps{
-							Description: "metricSelector is used to identify a specific time series within a given metric.",
-							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+							Description: "Label of the workloads and of the selector of the Service holding the color, `color` by default.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"highWatermark": {
+				},
+				Required: []string{"serviceName"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_BudgetSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BudgetSpec describes the cost of the replicas of the target and the maximum spend allowed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"costPerReplicaHour": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+							Description: "Cost of a single replica for an hour.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
-					"lowWatermark": {
+					"maxCostPerHour": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+							Description: "Maximum cost of the target for an hour.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
 						},
 					},
 				},
-				Required: []string{"name"},
+				Required: []string{"costPerReplicaHour", "maxCostPerHour"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/api/resource.Quantity", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscaler(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_CanaryStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "WatermarkPodAutoscaler is the Schema for the watermarkpodautoscalers API",
+				Description: "CanaryStatus describes the partial application of a recommended change of replicas.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"apiVersion": {
+					"fromReplicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"metadata": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+							Description: "Number of replicas of the target before the canary, restored when it is rolled back.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"spec": {
+					"toReplicas": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerSpec"),
+							Description: "Number of replicas recommended when the canary was applied.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"status": {
+					"startTime": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerStatus"),
+							Description: "Time the canary was applied.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
 				},
+				Required: []string{"fromReplicas", "toReplicas", "startTime"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_CapacityCeilingSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "WatermarkPodAutoscalerList contains a list of WatermarkPodAutoscaler",
+				Description: "CapacityCeilingSpec describes a JSON document holding the maximum number of replicas currently allowed for the target.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"url": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "HTTPS URL of the JSON document.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"jsonPath": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "JSONPath expression selecting the values in the document, such as `{.queues[*].depth}`. The values are combined with the seriesAggregation of the metric.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
+					"authorizationSecretRef": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+							Description: "Key of a Secret, in the namespace of the WPA, holding the value of the Authorization header of the requests.",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
 						},
 					},
-					"items": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "set",
-							},
-						},
+					"pollIntervalSeconds": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscaler"),
-									},
-								},
-							},
+							Description: "Number of seconds between two polls of the document, 60 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
-				Required: []string{"items"},
+				Required: []string{"url", "jsonPath"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscaler", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+			"k8s.io/api/core/v1.SecretKeySelector"},
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_CapacityCeilingStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "WatermarkPodAutoscalerSpec defines the desired state of WatermarkPodAutoscaler",
+				Description: "CapacityCeilingStatus describes the last maximum number of replicas polled from the capacityCeiling endpoint.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"downscaleForbiddenWindowSeconds": {
+					"maxReplicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "part of HorizontalController, see comments in the k8s repo: pkg/controller/podautoscaler/horizontal.go",
+							Description: "Maximum number of replicas allowed by the endpoint.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"upscaleForbiddenWindowSeconds": {
-						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
-						},
-					},
-					"scaleUpLimitFactor": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Percentage of replicas that can be added in an upscale event. Max value will set the limit at the Maximum number of Replicas.",
-							Type:        []string{"number"},
-							Format:      "double",
-						},
-					},
-					"scaleDownLimitFactor": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Percentage of replicas that can be added in an upscale event. Max value will set the limit at the Maximum number of Replicas.",
-							Type:        []string{"number"},
-							Format:      "double",
-						},
-					},
-					"tolerance": {
+					"lastPollTime": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"number"},
-							Format: "double",
+							Description: "Time the endpoint was last polled successfully.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"algorithm": {
+				},
+				Required: []string{"maxReplicas", "lastPollTime"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ContainerWeight(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ContainerWeight is the share of the usage of a container accounted for in the usage of its pod.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "computed values take the # of replicas into account",
+							Description: "Name of the container.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"dryRun": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Whether planned scale changes are actually applied",
-							Type:        []string{"boolean"},
-							Format:      "",
-						},
-					},
-					"scaleTargetRef": {
+					"weight": {
 						SchemaProps: spec.SchemaProps{
-							Description: "part of HorizontalPodAutoscalerSpec, see comments in the k8s-1.10.8 repo: staging/src/k8s.io/api/autoscaling/v1/types.go reference to scaled resource; horizontal pod autoscaler will learn the current resource consumption and will set the desired number of pods by using its Scale subresource.",
-							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CrossVersionObjectReference"),
+							Description: "Percentage of the usage, and of the requests, of the container accounted for.",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
-					"metrics": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "set",
-							},
-						},
+				},
+				Required: []string{"name", "weight"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_CordonedNodesSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CordonedNodesSpec describes the nodes whose pods are considered lost.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"taintKeys": {
 						SchemaProps: spec.SchemaProps{
-							Description: "specifications that will be used to calculate the desired replica count",
+							Description: "Keys of the taints marking a node as draining, such as the ones set ahead of a spot interruption. The cordoned nodes are always considered draining.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricSpec"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"minReplicas": {
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_CrossVersionObjectReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CrossVersionObjectReference contains enough information to let you identify the referred resource.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Kind of the referent; More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds\"",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"maxReplicas": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Name of the referent; More info: http://kubernetes.io/docs/user-guide/identifiers#names",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"readinessDelay": {
+					"selector": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
-							Format: "int32",
+							Description: "Label selector resolved to the referents at reconcile time, alternative to the name.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "API version of the referent",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"scaleTargetRef"},
+				Required: []string{"kind"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CrossVersionObjectReference", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricSpec"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
 
-func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_datadoghq_v1alpha1_DecisionHistorySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "WatermarkPodAutoscalerStatus defines the observed state of WatermarkPodAutoscaler",
+				Description: "DecisionHistorySpec describes the ConfigMap the last scaling decisions of a WPA are persisted to.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"observedGeneration": {
+					"configMapName": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"integer"},
+							Description: "Name of the ConfigMap, in the namespace of the WPA, `<name of the WPA>-decision-history` by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"maxDecisions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of decisions kept in the ConfigMap, the oldest ones being removed, 100 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxSizeBytes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Size in bytes of the decisions kept in the ConfigMap, the oldest ones being removed beyond it, 262144 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_DryRunReportSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DryRunReportSpec describes the ConfigMap the reports of a WPA in dry-run mode are written to.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"configMapName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the ConfigMap, in the namespace of the WPA, `<name of the WPA>-dry-run-report` by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"windowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration of the window summarized by a report, 3600 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxReports": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of reports kept in the ConfigMap, the oldest ones being removed, 168 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_EfficiencyStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "EfficiencyStatus accumulates the replica-hours of the target and the ones it would have used at maxReplicas.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"since": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time the accounting started.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastUpdateTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time the replica-hours were last accounted.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"replicaHours": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Replica-hours used by the target since the start of the accounting.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"maxReplicaHours": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Replica-hours the target would have used at maxReplicas since the start of the accounting.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+				},
+				Required: []string{"since", "lastUpdateTime", "replicaHours", "maxReplicaHours"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ExternalMetricSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExternalMetricSource indicates how to scale on a metric not associated with any Kubernetes object (for example length of queue in cloud messaging service, or QPS from loadbalancer running outside of cluster). Exactly one \"target\" type should be set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"metricName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "metricName is the name of the metric in question.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metricSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "metricSelector is used to identify a specific time series within a given metric.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"highWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"lowWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"highWatermarkFrom": {
+						SchemaProps: spec.SchemaProps{
+							Description: "highWatermarkFrom reads the high watermark from a ConfigMap or a Secret. It can be used instead of highWatermark.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkSource"),
+						},
+					},
+					"lowWatermarkFrom": {
+						SchemaProps: spec.SchemaProps{
+							Description: "lowWatermarkFrom reads the low watermark from a ConfigMap or a Secret. It can be used instead of lowWatermark.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkSource"),
+						},
+					},
+					"watermarkSteps": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "watermarkSteps override the watermarks once the target has at least the given number of replicas.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkStep"),
+									},
+								},
+							},
+						},
+					},
+					"emergencyHighWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Description: "emergencyHighWatermark is the usage above which the upscales ignore the upscaleForbiddenWindowSeconds and the scaleUpLimitFactor, the target being scaled straight to the computed number of replicas.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"seriesAggregation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How the values of the series returned for the metric are combined, `sum` by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"missingDatapoints": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Estimates the value of the metric from the previous ones when the provider does not return it, instead of failing the computation of the replicas.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MissingDatapointsPolicy"),
+						},
+					},
+					"rateOfChange": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Projects the value of the metric when it rises faster than a slope, so that the target is scaled up before the high watermark is crossed.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RateOfChangeSpec"),
+						},
+					},
+					"scaleUpStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How the replicas are computed when the metric is above its high watermark, proportionally to the ratio of the usage to the high watermark by default.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScaleUpStrategySpec"),
+						},
+					},
+					"http": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Fetches the values of the metric from a JSON document served over HTTPS instead of the External Metrics Provider. The metricSelector is then optional.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HTTPMetricSource"),
+						},
+					},
+					"histogram": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Compares a quantile of the distribution whose buckets are the series of the metric to the watermarks, instead of combining the series with the seriesAggregation.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HistogramMetricSpec"),
+						},
+					},
+				},
+				Required: []string{"metricName"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HTTPMetricSource", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HistogramMetricSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MissingDatapointsPolicy", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RateOfChangeSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScaleUpStrategySpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkSource", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkStep", "k8s.io/apimachinery/pkg/api/resource.Quantity", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_FailedPodsSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FailedPodsSpec describes how the Failed pods of the target are reported and accounted for.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"warningThresholdPercent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentage of the pods of the target in the Failed phase above which a FailedPods warning event is emitted.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"excludeFinished": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the Succeeded pods and the pods evicted by the kubelet are left out of the pods of the target, instead of being counted among the pods that are not ready.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_FlappingDetectionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FlappingDetectionSpec describes when a WPA is considered flapping, and how its settings are adapted.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"reversals": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of reversals of the scaling direction within the window from which the WPA is flapping.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"windowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration the reversals are counted over.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"factor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Factor applied to the forbidden windows and the tolerance while the WPA is flapping, 2 by default.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+				},
+				Required: []string{"reversals", "windowSeconds"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_HTTPMetricSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HTTPMetricSource describes a JSON document holding the values of a metric.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HTTPS URL of the JSON document.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"jsonPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "JSONPath expression selecting the values in the document, such as `{.queues[*].depth}`. The values are combined with the seriesAggregation of the metric.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"authorizationSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key of a Secret, in the namespace of the WPA, holding the value of the Authorization header of the requests.",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+				Required: []string{"url", "jsonPath"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_HistogramMetricSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HistogramMetricSpec describes an external metric whose series are the cumulative buckets of a distribution.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"quantile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentile of the distribution compared to the watermarks, e.g. 95 for the p95.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"bucketLabel": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Label of the series holding the upper bound of their bucket, `le` by default. Each bucket counts the observations lower than or equal to its bound, the `+Inf` one counting all of them.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"quantile"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_HysteresisSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HysteresisSpec describes how far and for how long the watermarks are widened after a scale event.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"percent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentage by which the low watermarks are lowered after an upscale, and the high watermarks raised after a downscale.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"durationSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration after the scale event during which the watermarks are widened.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"percent", "durationSeconds"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_MetricDetailStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MetricDetailStatus describes how a metric was compared to its watermarks, and the replicas it proposed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the metric.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rawValue": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Value of the metric, once its series aggregated or the values of its pods summed.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"adjustedValue": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Value compared to the watermarks, averaged over the replicas with the average algorithms.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"effectiveLowWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Low watermark in effect for the metric, lowered by the tolerance.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"effectiveHighWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Description: "High watermark in effect for the metric, raised by the tolerance.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"withinBounds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the adjusted value is between the effective watermarks.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"proposedReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of replicas proposed by the metric.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"name", "rawValue", "adjustedValue", "effectiveLowWatermark", "effectiveHighWatermark", "withinBounds", "proposedReplicas"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_MetricFailureStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MetricFailureStatus counts the consecutive failures of a metric having a fallback.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"metricName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the metric.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"consecutiveFailures": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of consecutive reconciles the metric failed in.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"metricName", "consecutiveFailures"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_MetricSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MetricSpec specifies how to scale based on a single metric (only `type` and one other matching field should be set at once).",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "type is the type of metric source.  It should be one of \"Object\", \"Pods\" or \"Resource\", each mapping to a matching field in the object.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"external": {
+						SchemaProps: spec.SchemaProps{
+							Description: "external refers to a global metric that is not associated with any Kubernetes object. It allows autoscaling based on information coming from components running outside of cluster (for example length of queue in cloud messaging service, or QPS from loadbalancer running outside of cluster).",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ExternalMetricSource"),
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "resource refers to a resource metric (such as those specified in requests and limits) known to Kubernetes describing each pod in the current scale target (e.g. CPU or memory). Such metrics are built in to Kubernetes, and have special scaling options on top of those available to normal per-pod metrics using the \"pods\" source.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ResourceMetricSource"),
+						},
+					},
+					"fallbackFor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "fallbackFor is the name of another metric of the WPA this one stands in for. It is only evaluated once the other metric failed for more than fallbackAfterFailures consecutive reconciles.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"fallbackAfterFailures": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of consecutive failures of the metric named in fallbackFor after which this metric is evaluated instead, 3 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"scaleUpLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentage of replicas that can be added in an upscale recommended by this metric, overriding the scaleUpLimitFactor of the WPA. The strictest factor of the metrics recommending an upscale is applied.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"scaleDownLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentage of replicas that can be removed in a downscale recommended by this metric, overriding the scaleDownLimitFactor of the WPA. The strictest factor of the metrics recommending a downscale is applied.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+				},
+				Required: []string{"type"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ExternalMetricSource", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ResourceMetricSource"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_MissingDatapointsPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MissingDatapointsPolicy indicates how a missing value of an external metric is estimated.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"strategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "With `carryForward` the last value is reused, with `linear` the trend of the last two values is extended.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"maxGapSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum age of the last value for an estimation to be made.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"strategy", "maxGapSeconds"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_PodsStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PodsStatus counts the pods of the target by how they were accounted for in the computation of a metric.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"total": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pods matching the selector of the target.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"ready": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pods counted as ready replicas, whose metrics are used.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"ignored": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pods left out of the ready replicas: excluded, Failed, Pending, not ready yet or warming up.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"missingMetrics": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pods without a value returned by the resource metrics API, only counted for the Resource metrics.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"total", "ready", "ignored", "missingMetrics"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ProfilePeriod(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProfilePeriod is a daily time range during which a profile is active.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"days": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Days of the week of the period (e.g. Monday), every day if empty.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start of the period, formatted as HH:MM.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"end": {
+						SchemaProps: spec.SchemaProps{
+							Description: "End of the period, formatted as HH:MM. The period spans midnight if it is before the start.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IANA time zone of the period, UTC if not set.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"start", "end"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ProfileWatermarks(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProfileWatermarks overrides the watermarks of a metric.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"metricName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the External metric, or resource of the Resource metric.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"highWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"lowWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+				},
+				Required: []string{"metricName", "highWatermark", "lowWatermark"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_RateOfChangeSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RateOfChangeSpec describes when and how far ahead the value of an external metric is projected.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"slope": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Increase of the metric per second above which its value is projected.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"horizonSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration the increase is projected over: the value compared to the watermarks is the current one plus the increase per second multiplied by this duration.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"slope", "horizonSeconds"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_RecommendationHistorySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RecommendationHistorySpec describes how the last proposals of replicas are aggregated.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of the last proposals aggregated.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"aggregation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How the proposals are aggregated, `median` by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"size"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_RemoteClusterSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RemoteClusterSpec references the credentials of a remote cluster.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kubeconfigSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key of a Secret, in the namespace of the WPA, holding the kubeconfig of the cluster.",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+				Required: []string{"kubeconfigSecretRef"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ResourceMetricSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ResourceMetricSource indicates how to scale on a resource metric known to Kubernetes, as specified in requests and limits, describing each pod in the current scale target (e.g. CPU or memory).  The values will be averaged together before being compared to the target.  Such metrics are built in to Kubernetes, and have special scaling options on top of those available to normal per-pod metrics using the \"pods\" source.  Only one \"target\" type should be set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "name is the name of the resource in question.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"container": {
+						SchemaProps: spec.SchemaProps{
+							Description: "container is the name of the container whose usage is considered. If not set, the usage of all the containers of the pods is summed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"containerWeights": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "containerWeights weights the usage (and the requests) of the listed containers before they are summed per pod, the containers not listed being left out. It can't be used with container.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ContainerWeight"),
+									},
+								},
+							},
+						},
+					},
+					"metricSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "metricSelector is used to identify a specific time series within a given metric.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"highWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"lowWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"highWatermarkFrom": {
+						SchemaProps: spec.SchemaProps{
+							Description: "highWatermarkFrom reads the high watermark from a ConfigMap or a Secret. It can be used instead of highWatermark.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkSource"),
+						},
+					},
+					"lowWatermarkFrom": {
+						SchemaProps: spec.SchemaProps{
+							Description: "lowWatermarkFrom reads the low watermark from a ConfigMap or a Secret. It can be used instead of lowWatermark.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkSource"),
+						},
+					},
+					"watermarkSteps": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "watermarkSteps override the watermarks once the target has at least the given number of replicas.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkStep"),
+									},
+								},
+							},
+						},
+					},
+					"emergencyHighWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Description: "emergencyHighWatermark is the usage above which the upscales ignore the upscaleForbiddenWindowSeconds and the scaleUpLimitFactor, the target being scaled straight to the computed number of replicas.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"highWatermarkUtilization": {
+						SchemaProps: spec.SchemaProps{
+							Description: "highWatermarkUtilization is the high watermark expressed as a percentage of the resource requests of the pods. It can be used instead of highWatermark.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"lowWatermarkUtilization": {
+						SchemaProps: spec.SchemaProps{
+							Description: "lowWatermarkUtilization is the low watermark expressed as a percentage of the resource requests of the pods. It can be used instead of lowWatermark.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"podQuantile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "podQuantile compares the given percentile of the usage of the ready pods to the watermarks, instead of their sum or average, so that a few saturated pods are not hidden by idle ones. The watermarks are per pod.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ContainerWeight", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkSource", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkStep", "k8s.io/apimachinery/pkg/api/resource.Quantity", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_RoundingSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RoundingSpec describes how the replicas computed from the metrics are rounded.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"upscale": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rounding of the replicas computed for an upscale, `ceil` by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"downscale": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rounding of the replicas computed for a downscale, `floor` by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"biasReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of replicas added to the replicas computed for every upscale, as a safety margin.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ScaleUpStrategySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScaleUpStrategySpec describes how the replicas are computed from a metric above its high watermark.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "With `proportional` the replicas are multiplied by the ratio of the usage to the high watermark, with `linear` a replica is added per usagePerReplica above the high watermark, with `multiplier` the replicas are multiplied by the multiplier, and with `backlog` the replicas drain the backlog measured by the metric within drainSeconds.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"usagePerReplica": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Usage above the high watermark absorbed by each added replica, for the `linear` strategy.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"multiplier": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Factor applied to the current replicas, for the `multiplier` strategy. It has to be greater than 1.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"processingRate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Part of the backlog processed per second by a replica, for the `backlog` strategy.",
+							Ref:         ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"drainSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration within which the backlog should be drained, for the `backlog` strategy.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"type"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ScalingPlan(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScalingPlan is the Schema for the scalingplans API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScalingPlanList contains a list of ScalingPlan",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlan"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlan", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScalingPlanSpec defines the desired state of ScalingPlan",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"wpa": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the WPA, in the namespace of the plan, the steps apply to.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"steps": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Steps of the plan, in chronological order. Each step applies from its time until the time of the next one.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanStep"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"wpa", "steps"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingPlanStep"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScalingPlanStatus defines the observed state of ScalingPlan",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"activeStep": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Index of the step currently applied, -1 before the time of the first step.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"nextStepTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time of the next step of the plan, if any.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/autoscaling/v2beta1.HorizontalPodAutoscalerCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"activeStep"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/autoscaling/v2beta1.HorizontalPodAutoscalerCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ScalingPlanStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScalingPlanStep overrides the replicas of a WPA from a given time. A step setting neither replicas nor minReplicas hands the WPA back to its own spec.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"at": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time from which the step applies.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of replicas the target is pinned to, like the replicas of the WPA.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"minReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Minimum number of replicas of the target, replacing the minReplicas of the WPA.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"at"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ScalingProfile(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScalingProfile overrides the watermarks, the cooldowns and the limit factors of the WPA while it is active.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the profile, reported in the status of the WPA while it is active.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"periods": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Periods during which the profile is active.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ProfilePeriod"),
+									},
+								},
+							},
+						},
+					},
+					"downscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"upscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"scaleUpLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"scaleDownLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"watermarks": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Watermarks of the metrics overridden while the profile is active.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ProfileWatermarks"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"name", "periods"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ProfilePeriod", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ProfileWatermarks"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ShardCluster(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ShardCluster is a cluster a workload is sharded across.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kubeconfigSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key of a Secret, in the namespace of the WPA, holding the kubeconfig of the cluster.",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+					"weight": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Weight of the target of the cluster in the split of the replicas, 1 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"kubeconfigSecretRef"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_ShardsSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ShardsSpec describes the other clusters a workload is sharded across.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"weight": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Weight of the scaleTargetRef in the split of the replicas, 1 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"clusters": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ShardCluster"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"clusters"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ShardCluster"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_SpecialDaysCalendar(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SpecialDaysCalendar reads the special days from an iCalendar feed or a ConfigMap, and sets the profile and the replica bounds used on those days.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "URL of an iCalendar (ICS) feed, every event of which marks the days it covers as special.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"configMapKeyRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key of a ConfigMap, in the namespace of the WPA, holding either an iCalendar document or a list of special days formatted as YYYY-MM-DD, one per line.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
+					"timeZone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IANA time zone in which the days are evaluated, UTC if not set.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"profile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the profile applied on the special days, regardless of its periods.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"minReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Minimum number of replicas on the special days.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum number of replicas on the special days.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ConfigMapKeySelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_StatefulSetScalingSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StatefulSetScalingSpec describes how the ordering of the pods of a StatefulSet is taken into account when scaling it.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"orderedDownscaleStep": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum number of replicas removed by a single downscale.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"waitForReadyOrdinal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the upscale should wait for the pod with the highest ordinal to be Ready.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"alignWithPodManagementPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the constraints above only apply to the StatefulSets with the OrderedReady pod management policy.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_SteppedConvergenceSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SteppedConvergenceSpec describes how a large upscale is split into steps, the metrics being evaluated again before each of them.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"minRatio": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ratio between the desired and the current number of replicas from which the upscale is stepped, 2 by default.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"stepFraction": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Fraction of the gap between the current and the desired number of replicas added at each step.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+				},
+				Required: []string{"stepFraction"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WPAGroup(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WPAGroup is the Schema for the wpagroups API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WPAGroupBound(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WPAGroupBound is the range of replicas allowed for a WPA of the group.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"wpa": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the WPA.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"minReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"maxReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+				},
+				Required: []string{"wpa", "minReplicas", "maxReplicas"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WPAGroupConstraint(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WPAGroupConstraint bounds the replicas of the target of a WPA with the current replicas of the target of another WPA of the same namespace, e.g. workers ≤ 2×dispatchers is expressed with `wpa: workers`, `reference: dispatchers` and `maxRatio: 200`. As the bounds follow the current replicas of the reference, the reference is scaled first.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"wpa": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the constrained WPA.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reference": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the WPA the constrained WPA follows.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"minRatio": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Minimum number of replicas of the WPA, in percent of the replicas of the reference.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxRatio": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum number of replicas of the WPA, in percent of the replicas of the reference.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"wpa", "reference"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WPAGroupList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WPAGroupList contains a list of WPAGroup",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroup"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroup", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WPAGroupSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WPAGroupSpec defines the desired state of WPAGroup",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"constraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Ratios enforced between the replicas of the WPAs of the group.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupConstraint"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"constraints"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupConstraint"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WPAGroupStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WPAGroupStatus defines the observed state of WPAGroup",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"bounds": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Replicas allowed for the constrained WPAs, given the current replicas of their references.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupBound"),
+									},
+								},
+							},
+						},
+					},
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/autoscaling/v2beta1.HorizontalPodAutoscalerCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WPAGroupBound", "k8s.io/api/autoscaling/v2beta1.HorizontalPodAutoscalerCondition"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscaler(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscaler is the Schema for the watermarkpodautoscalers API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerList contains a list of WatermarkPodAutoscaler",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscaler"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscaler", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerPolicy is the Schema for the watermarkpodautoscalerpolicies API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicySpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicySpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicyDefaults(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerPolicyDefaults replaces the built-in defaults of the WPAs.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"algorithm": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"tolerance": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"downscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"upscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"scaleUpLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"scaleDownLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicyLimits(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerPolicyLimits bounds the settings of the WPAs. The settings beyond the limits are brought back to them, and the WPAs using a forbidden algorithm don't scale.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"maxReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Highest maxReplicas allowed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"minDownscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Shortest downscaleForbiddenWindowSeconds allowed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"minUpscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Shortest upscaleForbiddenWindowSeconds allowed.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"forbiddenAlgorithms": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Algorithms the WPAs can't use.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicyList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerPolicyList contains a list of WatermarkPodAutoscalerPolicy",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"),
+						},
+					},
+					"items": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicy"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicy", "k8s.io/apimachinery/pkg/apis/meta/v1.ListMeta"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerPolicySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerPolicySpec defines the defaults and the limits applied to all the WPAs of the cluster. When several policies exist, the defaults of the first one in the alphabetical order of their names are used, and the most restrictive limits are enforced.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"defaults": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Values set on the WPAs that don't set them when they are created.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicyDefaults"),
+						},
+					},
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits enforced on all the WPAs.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicyLimits"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicyDefaults", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WatermarkPodAutoscalerPolicyLimits"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerSpec defines the desired state of WatermarkPodAutoscaler",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"downscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "part of HorizontalController, see comments in the k8s repo: pkg/controller/podautoscaler/horizontal.go",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"upscaleForbiddenWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"downscaleEvaluationWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of seconds the metrics have to stay below their low watermark before a downscale is proposed, so that brief lulls don't shrink the target. Unlike the downscaleForbiddenWindowSeconds, it doesn't depend on the last scale.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"upscaleEvaluationWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of seconds the metrics have to stay above their high watermark before an upscale is proposed, for the targets absorbing short spikes whose pods are expensive to start.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"scaleUpLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentage of replicas that can be added in an upscale event. Max value will set the limit at the Maximum number of Replicas.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"scaleDownLimitFactor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentage of replicas that can be added in an upscale event. Max value will set the limit at the Maximum number of Replicas.",
+							Type:        []string{"number"},
+							Format:      "double",
+						},
+					},
+					"maxChangePerReconcile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum number of replicas added or removed in a single scale event, on top of the limit factors, which allow large changes once the target has hundreds of replicas.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"tolerance": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"number"},
+							Format: "double",
+						},
+					},
+					"algorithm": {
+						SchemaProps: spec.SchemaProps{
+							Description: "computed values take the # of replicas into account",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rounding": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How the replicas computed from the metrics are rounded, and a number of replicas added to every upscale.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RoundingSpec"),
+						},
+					},
+					"dryRun": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether planned scale changes are actually applied",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"dryRunReport": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Summary of what the WPA would have done, written to a ConfigMap per evaluation window while in dry-run mode.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.DryRunReportSpec"),
+						},
+					},
+					"decisionHistory": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Last scaling decisions of the WPA, persisted to a ConfigMap so that they survive the restarts of the controller.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.DecisionHistorySpec"),
+						},
+					},
+					"scaleTargetRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "part of HorizontalPodAutoscalerSpec, see comments in the k8s-1.10.8 repo: staging/src/k8s.io/api/autoscaling/v1/types.go reference to scaled resource; horizontal pod autoscaler will learn the current resource consumption and will set the desired number of pods by using its Scale subresource.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CrossVersionObjectReference"),
+						},
+					},
+					"scaleTargetRefs": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Additional targets scaled in lock-step with the scaleTargetRef, proportionally to their weight.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WeightedCrossVersionObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"metrics": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "specifications that will be used to calculate the desired replica count",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricSpec"),
+									},
+								},
+							},
+						},
+					},
+					"minReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"maxReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"replicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of replicas the target is pinned to, taking precedence over minReplicas and maxReplicas. It is the replica count of the scale subresource of the WPA, set by `kubectl scale`.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"readinessDelay": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"deletionCostPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the controller.kubernetes.io/pod-deletion-cost annotation of the pods is considered before downscaling. With `Wait`, downscale events are delayed until all the pods of the target carry the annotation.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"deletionCostResource": {
+						SchemaProps: spec.SchemaProps{
+							Description: "If set, the pods of the target are annotated with a controller.kubernetes.io/pod-deletion-cost based on their usage of this resource, so that the least loaded pods are removed first when downscaling. The resource has to be used in one of the Resource metrics.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resourceQuotaAware": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the upscale should be capped to the number of replicas that the ResourceQuotas of the namespace can admit, based on the requests and limits of the pods of the target.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"pauseUpscaleOnUnschedulablePods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the upscale should be paused while some pods of the target can't be scheduled.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"scalingMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Directions in which the target is scaled, `Both` by default. The desired number of replicas in the other direction is reported in the status instead.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"scaleDownDisabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the target should only be scaled up. Deprecated: use the `UpOnly` scaling mode instead, which this field is converted to when the scaling mode is not set, and ignored otherwise.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"honorMinReadySeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the pods are only counted as ready once they have been Ready for the minReadySeconds of the target Deployment, so that the pods not taking traffic yet don't dilute the average.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"excludeCordonedNodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Leaves the pods running on cordoned or draining nodes out of the ready pods and the metrics, their imminent disappearance being treated as capacity already lost.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CordonedNodesSpec"),
+						},
+					},
+					"excludeTerminatingPods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the pods being deleted are left out of the ready pods and the metrics. They are still Ready until they are gone, and skew the average during the rollouts and the downscales.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"excludedPodSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector of the pods left out of the ready pods and the metrics, such as the canary or debug replicas. The pods annotated with wpa.datadoghq.com/exclude set to \"true\" are always left out.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"failedPods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How the Failed pods of the target, such as the ones evicted by the kubelet, are reported and accounted for.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.FailedPodsSpec"),
+						},
+					},
+					"nodePressureAware": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the pods not ready because of their node, NotReady or under memory, disk or PID pressure, are told apart from the application failures: their usage is ignored and the downscales are paused until they recover, so that an infrastructure failure isn't mistaken for a low utilization.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"freezeDuringRollout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether scaling should be skipped while the target Deployment is rolling out.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"budget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cost model used as an additional ceiling on the number of replicas.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BudgetSpec"),
+						},
+					},
+					"capacityCeiling": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoint of a capacity-management service serving the maximum number of replicas currently allowed for the target, applied on top of the maxReplicas.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CapacityCeilingSpec"),
+						},
+					},
+					"steppedConvergence": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spreads the upscales far above the current number of replicas over several reconcile cycles.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.SteppedConvergenceSpec"),
+						},
+					},
+					"baseline": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Scales the target directly to a baseline once the metrics have been idle for a while, instead of walking down through the downscales limited by the scaleDownLimitFactor.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BaselineSpec"),
+						},
+					},
+					"maxScaleEventsPerHour": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum number of scale events of the target over the last hour, on top of the forbidden windows.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"flappingDetection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Widens the forbidden windows and the tolerance while the WPA flaps between upscales and downscales.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.FlappingDetectionSpec"),
+						},
+					},
+					"hysteresis": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Widens the watermarks for a while after each scale event, so that the replicas just added or removed are not immediately judged as too many or too few and the scale reversed.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HysteresisSpec"),
+						},
+					},
+					"adaptiveTolerance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Widens the tolerance of noisy metrics according to the variation of their recent values.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.AdaptiveToleranceSpec"),
+						},
+					},
+					"recommendationHistory": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Applies an aggregation of the last proposals of replicas instead of the last one, filtering the spikes of a single reconcile cycle.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RecommendationHistorySpec"),
+						},
+					},
+					"canaryPercent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Percentage of a recommended change of replicas applied first, as a canary. The rest of the change is applied if the recommendation is confirmed at the end of the canaryWindowSeconds, otherwise the target is rolled back to its replicas before the canary.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"canaryWindowSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time during which the canary is observed before the rest of the change is applied or rolled back, 300 by default.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"statefulSet": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Scaling constraints applied when the target is a StatefulSet.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.StatefulSetScalingSpec"),
+						},
+					},
+					"profiles": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Named sets of settings overriding the ones of the WPA during given periods of time. The first active profile of the list is applied.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingProfile"),
+									},
+								},
+							},
+						},
+					},
+					"calendar": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Calendar of special days, such as holidays or sales, on which the scaling is adjusted.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.SpecialDaysCalendar"),
+						},
+					},
+					"remoteCluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster the targets run in, when it is not the cluster of the WPA. The targets are scaled, and their pods and metrics read, in the namespace of the same name in that cluster.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RemoteClusterSpec"),
+						},
+					},
+					"shards": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Other clusters the workload is sharded across, each running a target of the same kind and name in the namespace of the same name. The external metrics are aggregated across the clusters before the comparison to the watermarks, and the replicas, bounded by minReplicas and maxReplicas as a whole, are split across the targets proportionally to their weight.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ShardsSpec"),
+						},
+					},
+					"blueGreen": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pairs the blue and the green workloads matched by the selector of the scaleTargetRef. The recommendation is computed on the live color, routed to by a Service, and the other color is kept at the same number of replicas, so that it is ready to take the traffic after a switch.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BlueGreenSpec"),
+						},
+					},
+					"driftPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "What the controller does when the target is scaled outside of the WPA. With `Correct`, the replicas the controller last applied are applied again, once the forbidden windows are over, unless the metrics recommend another change. `Ignore` by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"scaleTargetRef"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.AdaptiveToleranceSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BaselineSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BlueGreenSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.BudgetSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CapacityCeilingSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CordonedNodesSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CrossVersionObjectReference", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.DecisionHistorySpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.DryRunReportSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.FailedPodsSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.FlappingDetectionSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.HysteresisSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RecommendationHistorySpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RemoteClusterSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.RoundingSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ScalingProfile", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ShardsSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.SpecialDaysCalendar", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.StatefulSetScalingSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.SteppedConvergenceSpec", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.WeightedCrossVersionObjectReference", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkPodAutoscalerStatus defines the observed state of WatermarkPodAutoscaler",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
 							Format: "int64",
 						},
 					},
@@ -442,11 +2930,289 @@ func schema_pkg_apis_datadoghq_v1alpha1_WatermarkPodAutoscalerStatus(ref common.
 							},
 						},
 					},
+					"activeProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the profile currently applied.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"specialDay": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the current day is marked as special by the calendar.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"selector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Label selector of the pods of the target, exposed by the scale subresource of the WPA.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"activeMetric": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Metric driving the replica count proposed by the last computation.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ActiveMetricStatus"),
+						},
+					},
+					"metricDetails": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "How each metric was evaluated by the last computation of the replicas.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricDetailStatus"),
+									},
+								},
+							},
+						},
+					},
+					"idleSince": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time since when all the metrics are below the idle threshold of the baseline.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"lastScaleDirection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Direction of the last scaling of the target, `up` or `down`, tracked for the flapping detection and the hysteresis.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"scaleReversals": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Times of the recent reversals of the scaling direction, within the window of the flapping detection.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+									},
+								},
+							},
+						},
+					},
+					"suppressedReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Desired number of replicas in the direction suppressed by the scaling mode, when there is one.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"recentScaleEvents": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Times of the scale events of the last hour, when the maxScaleEventsPerHour is set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+									},
+								},
+							},
+						},
+					},
+					"lastSuccessfulReconcile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Time of the last reconcile that went through the whole evaluation of the WPA.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"canary": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Canary being observed, when the canaryPercent is set.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CanaryStatus"),
+						},
+					},
+					"metricFailures": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Consecutive failures of the metrics having a fallback, reset once the metric is computed again.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricFailureStatus"),
+									},
+								},
+							},
+						},
+					},
+					"lastAppliedReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of replicas the controller last applied to the target, compared to the replicas of the target to detect the scaling done outside of the WPA.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"appliedDefaults": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Options left unset in the spec, for which the controller uses the default values. The defaults are applied in memory and never written to the spec.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"capacityCeiling": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum number of replicas last served by the capacityCeiling endpoint.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CapacityCeilingStatus"),
+						},
+					},
+					"efficiency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Replica-hours used by the target, compared to the ones it would have used at maxReplicas.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.EfficiencyStatus"),
+						},
+					},
+					"pods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "How the pods of the target were accounted for in the computation of the last metric evaluated.",
+							Ref:         ref("github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.PodsStatus"),
+						},
+					},
 				},
 				Required: []string{"currentReplicas", "desiredReplicas", "currentMetrics", "conditions"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/autoscaling/v2beta1.HorizontalPodAutoscalerCondition", "k8s.io/api/autoscaling/v2beta1.MetricStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.ActiveMetricStatus", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CanaryStatus", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.CapacityCeilingStatus", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.EfficiencyStatus", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricDetailStatus", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.MetricFailureStatus", "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1.PodsStatus", "k8s.io/api/autoscaling/v2beta1.HorizontalPodAutoscalerCondition", "k8s.io/api/autoscaling/v2beta1.MetricStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkSource selects a key of a ConfigMap or a Secret, in the namespace of the WPA, holding the value of a watermark. The controller watches the ConfigMaps and Secrets, so that updating the value is taken into account by all the WPAs referencing it. Exactly one of the references should be set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"configMapKeyRef": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
+					"secretKeyRef": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WatermarkStep(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WatermarkStep defines the watermarks used from a number of replicas of the target.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"minReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of replicas from which the watermarks of the step are used.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"highWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"lowWatermark": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+				},
+				Required: []string{"minReplicas", "highWatermark", "lowWatermark"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
+	}
+}
+
+func schema_pkg_apis_datadoghq_v1alpha1_WeightedCrossVersionObjectReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WeightedCrossVersionObjectReference identifies an additional scale target and its weight.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind of the referent; More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the referent; More info: http://kubernetes.io/docs/user-guide/identifiers#names",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"selector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Label selector resolved to the referents at reconcile time, alternative to the name.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "API version of the referent",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"weight": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of replicas of this target, in percent of the replicas of the scaleTargetRef.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"kind", "weight"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
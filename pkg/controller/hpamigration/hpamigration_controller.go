@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package hpamigration
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/hpaconversion"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// migrateAnnotation opts an HPA in the migration, with either the dryRunMode or the deleteMode value.
+	migrateAnnotation = "watermarkpodautoscaler.datadoghq.com/migrate"
+	// bandAnnotation overrides the band of the watermarks of the generated WPA, in percent of the targets of the HPA.
+	bandAnnotation = "watermarkpodautoscaler.datadoghq.com/watermark-band"
+	// statusAnnotation reports the state of the migration on the HPA.
+	statusAnnotation = "watermarkpodautoscaler.datadoghq.com/migration-status"
+	// migratedFromAnnotation is set on the generated WPAs with the name of the HPA they replace.
+	migratedFromAnnotation = "watermarkpodautoscaler.datadoghq.com/migrated-from"
+
+	// dryRunMode generates the WPA in dry-run mode and keeps the HPA in charge of the scaling.
+	dryRunMode = "dry-run"
+	// deleteMode hands the scaling over to the WPA and deletes the HPA.
+	deleteMode = "delete"
+
+	migratedStatus = "Migrated"
+	failedStatus   = "Failed"
+)
+
+var (
+	log = logf.Log.WithName("hpamigration_controller")
+
+	enabled     bool
+	defaultBand float64
+)
+
+func init() {
+	flag.BoolVar(&enabled, "hpa-migration", false, "Migrate the HPAs annotated with "+migrateAnnotation+" to WPAs")
+	flag.Float64Var(&defaultBand, "hpa-migration-band", hpaconversion.DefaultBand, "Distance, in percent of the targets of the HPAs, between the targets and the watermarks of the generated WPAs")
+}
+
+// Add creates a new HPA migration Controller and adds it to the Manager, if the migration is enabled.
+func Add(mgr manager.Manager) error {
+	if !enabled {
+		return nil
+	}
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileHPAMigration{
+		client:        mgr.GetClient(),
+		eventRecorder: mgr.GetEventRecorderFor("hpamigration_controller"),
+		band:          defaultBand,
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("hpamigration-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the HPAs, the ones without the migrate annotation are skipped by Reconcile
+	return c.Watch(&source.Kind{Type: &autoscalingv2.HorizontalPodAutoscaler{}}, &handler.EnqueueRequestForObject{})
+}
+
+// blank assignment to verify that ReconcileHPAMigration implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileHPAMigration{}
+
+// ReconcileHPAMigration migrates the annotated HPAs to WPAs
+type ReconcileHPAMigration struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client        client.Client
+	eventRecorder record.EventRecorder
+	band          float64
+}
+
+// Reconcile generates the WPA equivalent to an annotated HPA, then deletes the HPA in the delete mode.
+// The state of the migration is reported in the migration-status annotation of the HPA.
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups=datadoghq.com,resources=watermarkpodautoscalers,verbs=get;list;watch;create;update
+func (r *ReconcileHPAMigration) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	if !util.OwnsNamespace(request.Namespace) {
+		// the namespace belongs to the shard of another replica of the controller
+		return reconcile.Result{}, nil
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, hpa)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	mode, found := hpa.Annotations[migrateAnnotation]
+	if !found {
+		return reconcile.Result{}, nil
+	}
+	logger.Info("Migrating HorizontalPodAutoscaler", "mode", mode)
+
+	wpa, err := r.migrate(hpa, mode)
+	if err != nil {
+		if errors.IsConflict(err) {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		logger.Info("Failed to migrate the HPA", "error", err)
+		r.eventRecorder.Event(hpa, corev1.EventTypeWarning, "FailedMigration", err.Error())
+		// we don't requeue here, updating the annotations of the HPA will requeue it.
+		return reconcile.Result{}, r.setMigrationStatus(hpa, fmt.Sprintf("%s: %v", failedStatus, err))
+	}
+
+	if mode == deleteMode {
+		if err = r.client.Delete(context.TODO(), hpa); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "SuccessfulMigration", "Took over the scaling from the HPA %s, which was deleted", hpa.Name)
+		return reconcile.Result{}, nil
+	}
+	if hpa.Annotations[statusAnnotation] != migratedStatus {
+		r.eventRecorder.Eventf(hpa, corev1.EventTypeNormal, "SuccessfulMigration", "Created the WPA %s in dry-run mode", wpa.Name)
+	}
+	return reconcile.Result{}, r.setMigrationStatus(hpa, migratedStatus)
+}
+
+// migrate creates the WPA equivalent to the HPA, or takes it out of dry-run mode in the delete mode.
+func (r *ReconcileHPAMigration) migrate(hpa *autoscalingv2.HorizontalPodAutoscaler, mode string) (*datadoghqv1alpha1.WatermarkPodAutoscaler, error) {
+	if mode != dryRunMode && mode != deleteMode {
+		return nil, fmt.Errorf("invalid value %q of the %s annotation, should be %s or %s", mode, migrateAnnotation, dryRunMode, deleteMode)
+	}
+
+	wpa := &datadoghqv1alpha1.WatermarkPodAutoscaler{}
+	err := r.client.Get(context.TODO(), client.ObjectKey{Namespace: hpa.Namespace, Name: hpa.Name}, wpa)
+	switch {
+	case errors.IsNotFound(err):
+		band, err := r.bandOf(hpa)
+		if err != nil {
+			return nil, err
+		}
+		wpa, err = hpaconversion.Convert(hpa, band)
+		if err != nil {
+			return nil, err
+		}
+		wpa.Annotations = map[string]string{migratedFromAnnotation: hpa.Name}
+		wpa.Spec.DryRun = mode == dryRunMode
+		if err = r.client.Create(context.TODO(), wpa); err != nil {
+			return nil, err
+		}
+		return wpa, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if wpa.Annotations[migratedFromAnnotation] != hpa.Name {
+		return nil, fmt.Errorf("the WPA %s already exists and was not generated from the HPA", wpa.Name)
+	}
+	if mode == deleteMode && wpa.Spec.DryRun {
+		wpa.Spec.DryRun = false
+		if err = r.client.Update(context.TODO(), wpa); err != nil {
+			return nil, err
+		}
+	}
+	return wpa, nil
+}
+
+func (r *ReconcileHPAMigration) bandOf(hpa *autoscalingv2.HorizontalPodAutoscaler) (float64, error) {
+	value, found := hpa.Annotations[bandAnnotation]
+	if !found {
+		return r.band, nil
+	}
+	band, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q of the %s annotation: %v", value, bandAnnotation, err)
+	}
+	return band, nil
+}
+
+func (r *ReconcileHPAMigration) setMigrationStatus(hpa *autoscalingv2.HorizontalPodAutoscaler, status string) error {
+	if hpa.Annotations[statusAnnotation] == status {
+		return nil
+	}
+	hpa.Annotations[statusAnnotation] = status
+	return r.client.Update(context.TODO(), hpa)
+}
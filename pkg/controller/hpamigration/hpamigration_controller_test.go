@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package hpamigration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestHPA(annotations map[string]string) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "foo", Annotations: annotations},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "app", APIVersion: "apps/v1"},
+			MinReplicas:    v1alpha1.NewInt32(2),
+			MaxReplicas:    10,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type:     autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceCPU, TargetAverageUtilization: v1alpha1.NewInt32(50)},
+			}},
+		},
+	}
+}
+
+func newTestReconciler(t *testing.T, objs ...runtime.Object) *ReconcileHPAMigration {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	return &ReconcileHPAMigration{
+		client:        fake.NewFakeClientWithScheme(s, objs...),
+		eventRecorder: record.NewFakeRecorder(10),
+		band:          20,
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "bar", Name: "foo"}}
+	generatedWPA := &v1alpha1.WatermarkPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "foo", Annotations: map[string]string{migratedFromAnnotation: "foo"}},
+		Spec:       v1alpha1.WatermarkPodAutoscalerSpec{DryRun: true},
+	}
+	tests := []struct {
+		name        string
+		objs        []runtime.Object
+		wantStatus  string
+		wantDeleted bool
+		wantWPA     bool
+		wantDryRun  bool
+		wantHigh    int32
+	}{
+		{
+			name: "not annotated",
+			objs: []runtime.Object{newTestHPA(nil)},
+		},
+		{
+			name:       "dry-run",
+			objs:       []runtime.Object{newTestHPA(map[string]string{migrateAnnotation: dryRunMode})},
+			wantStatus: migratedStatus,
+			wantWPA:    true,
+			wantDryRun: true,
+			wantHigh:   60,
+		},
+		{
+			name:       "band annotation",
+			objs:       []runtime.Object{newTestHPA(map[string]string{migrateAnnotation: dryRunMode, bandAnnotation: "10"})},
+			wantStatus: migratedStatus,
+			wantWPA:    true,
+			wantDryRun: true,
+			wantHigh:   55,
+		},
+		{
+			name:        "delete",
+			objs:        []runtime.Object{newTestHPA(map[string]string{migrateAnnotation: deleteMode})},
+			wantDeleted: true,
+			wantWPA:     true,
+			wantHigh:    60,
+		},
+		{
+			name:        "delete after dry-run",
+			objs:        []runtime.Object{newTestHPA(map[string]string{migrateAnnotation: deleteMode}), generatedWPA.DeepCopy()},
+			wantDeleted: true,
+			wantWPA:     true,
+		},
+		{
+			name:       "invalid mode",
+			objs:       []runtime.Object{newTestHPA(map[string]string{migrateAnnotation: "yes"})},
+			wantStatus: `Failed: invalid value "yes" of the watermarkpodautoscaler.datadoghq.com/migrate annotation, should be dry-run or delete`,
+		},
+		{
+			name: "existing WPA",
+			objs: []runtime.Object{
+				newTestHPA(map[string]string{migrateAnnotation: deleteMode}),
+				&v1alpha1.WatermarkPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "foo"}},
+			},
+			wantStatus: "Failed: the WPA foo already exists and was not generated from the HPA",
+			wantWPA:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t, tt.objs...)
+			_, err := r.Reconcile(request)
+			require.NoError(t, err)
+
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			err = r.client.Get(context.TODO(), request.NamespacedName, hpa)
+			if tt.wantDeleted {
+				require.True(t, errors.IsNotFound(err))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantStatus, hpa.Annotations[statusAnnotation])
+			}
+
+			wpa := &v1alpha1.WatermarkPodAutoscaler{}
+			err = r.client.Get(context.TODO(), client.ObjectKey{Namespace: "bar", Name: "foo"}, wpa)
+			if !tt.wantWPA {
+				require.True(t, errors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantDryRun, wpa.Spec.DryRun)
+			if tt.wantHigh != 0 {
+				require.Equal(t, "foo", wpa.Annotations[migratedFromAnnotation])
+				require.Equal(t, tt.wantHigh, *wpa.Spec.Metrics[0].Resource.HighWatermarkUtilization)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package scalingplan
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var (
+	log                                                               = logf.Log.WithName("scalingplan_controller")
+	validCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "Valid"
+)
+
+// Add creates a new ScalingPlan Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileScalingPlan{
+		client:        mgr.GetClient(),
+		eventRecorder: mgr.GetEventRecorderFor("scalingplan_controller"),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("scalingplan-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource ScalingPlan
+	if err = c.Watch(&source.Kind{Type: &datadoghqv1alpha1.ScalingPlan{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// The plans report whether their WPA exists, so the plans of its namespace are requeued when it is created or deleted
+	mapFn := handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+		return plansForWPA(mgr.GetClient(), a.Meta.GetNamespace(), a.Meta.GetName())
+	})
+	return c.Watch(&source.Kind{Type: &datadoghqv1alpha1.WatermarkPodAutoscaler{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn})
+}
+
+func plansForWPA(c client.Client, namespace, name string) []reconcile.Request {
+	plans := &datadoghqv1alpha1.ScalingPlanList{}
+	if err := c.List(context.TODO(), plans, client.InNamespace(namespace)); err != nil {
+		log.Error(err, "Could not list the ScalingPlans", "namespace", namespace)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, plan := range plans.Items {
+		if plan.Spec.WPA == name {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: plan.Namespace, Name: plan.Name}})
+		}
+	}
+	return requests
+}
+
+// blank assignment to verify that ReconcileScalingPlan implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileScalingPlan{}
+
+// ReconcileScalingPlan reconciles a ScalingPlan object
+type ReconcileScalingPlan struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client        client.Client
+	eventRecorder record.EventRecorder
+}
+
+// Reconcile reports the step of a ScalingPlan applying at the current time, and requeues the plan at the time of its
+// next step. The WatermarkPodAutoscaler controller applies the active step to the WPA of the plan.
+// +kubebuilder:rbac:groups=datadoghq.com,resources=scalingplans;scalingplans/status,verbs=get;list;watch;update;patch
+func (r *ReconcileScalingPlan) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	if !util.OwnsNamespace(request.Namespace) {
+		// the namespace belongs to the shard of another replica of the controller
+		return reconcile.Result{}, nil
+	}
+	logger.Info("Reconciling ScalingPlan")
+
+	plan := &datadoghqv1alpha1.ScalingPlan{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, plan)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	statusOriginal := plan.Status.DeepCopy()
+
+	if err = datadoghqv1alpha1.CheckScalingPlanValidity(plan); err != nil {
+		logger.Info("Got an invalid ScalingPlan spec", "error", err)
+		r.eventRecorder.Event(plan, corev1.EventTypeWarning, "FailedSpecCheck", err.Error())
+		plan.Status.ActiveStep = -1
+		plan.Status.NextStepTime = nil
+		setCondition(plan, corev1.ConditionFalse, "FailedSpecCheck", "Invalid ScalingPlan specification: %s", err)
+		// we don't requeue here, the update of the spec will requeue the resource.
+		return reconcile.Result{}, r.updateStatusIfNeeded(statusOriginal, plan)
+	}
+
+	wpa := &datadoghqv1alpha1.WatermarkPodAutoscaler{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Namespace: plan.Namespace, Name: plan.Spec.WPA}, wpa)
+	switch {
+	case errors.IsNotFound(err):
+		setCondition(plan, corev1.ConditionFalse, "MissingWPA", "the WPA %s of the plan is missing", plan.Spec.WPA)
+	case err != nil:
+		return reconcile.Result{}, err
+	default:
+		setCondition(plan, corev1.ConditionTrue, "StepsScheduled", "the steps of the plan apply to the WPA %s", plan.Spec.WPA)
+	}
+
+	now := time.Now()
+	active := int32(plan.ActiveStep(now))
+	// the status of a new plan has no condition, its activeStep is not set yet
+	started := active != statusOriginal.ActiveStep || len(statusOriginal.Conditions) == 0
+	if started && active >= 0 {
+		r.eventRecorder.Eventf(plan, corev1.EventTypeNormal, "StepStarted", "Step %d of the plan applies to the WPA %s: %s", active, plan.Spec.WPA, describeStep(plan.Spec.Steps[active]))
+	}
+	plan.Status.ActiveStep = active
+	plan.Status.NextStepTime = nil
+	result := reconcile.Result{}
+	if next := int(active) + 1; next < len(plan.Spec.Steps) {
+		plan.Status.NextStepTime = plan.Spec.Steps[next].At.DeepCopy()
+		result.RequeueAfter = plan.Spec.Steps[next].At.Sub(now)
+	}
+	if err = r.updateStatusIfNeeded(statusOriginal, plan); err != nil {
+		r.eventRecorder.Event(plan, corev1.EventTypeWarning, "FailedUpdateStatus", err.Error())
+		return reconcile.Result{}, err
+	}
+	return result, nil
+}
+
+func (r *ReconcileScalingPlan) updateStatusIfNeeded(statusOriginal *datadoghqv1alpha1.ScalingPlanStatus, plan *datadoghqv1alpha1.ScalingPlan) error {
+	if apiequality.Semantic.DeepEqual(statusOriginal, &plan.Status) {
+		return nil
+	}
+	return r.client.Status().Update(context.TODO(), plan)
+}
+
+// describeStep returns a human-readable summary of the overrides of a step.
+func describeStep(step datadoghqv1alpha1.ScalingPlanStep) string {
+	switch {
+	case step.Replicas != nil && step.MinReplicas != nil:
+		return fmt.Sprintf("replicas set to %d, minReplicas set to %d", *step.Replicas, *step.MinReplicas)
+	case step.Replicas != nil:
+		return fmt.Sprintf("replicas set to %d", *step.Replicas)
+	case step.MinReplicas != nil:
+		return fmt.Sprintf("minReplicas set to %d", *step.MinReplicas)
+	}
+	return "the WPA is back to its own spec"
+}
+
+// setCondition sets the Valid condition of the plan to the specified value with the given reason and message.
+// The message and args are treated like a format string.
+func setCondition(plan *datadoghqv1alpha1.ScalingPlan, status corev1.ConditionStatus, reason, message string, args ...interface{}) {
+	var existingCond *autoscalingv2.HorizontalPodAutoscalerCondition
+	for i, condition := range plan.Status.Conditions {
+		if condition.Type == validCondition {
+			// can't take a pointer to an iteration variable
+			existingCond = &plan.Status.Conditions[i]
+			break
+		}
+	}
+	if existingCond == nil {
+		plan.Status.Conditions = append(plan.Status.Conditions, autoscalingv2.HorizontalPodAutoscalerCondition{Type: validCondition})
+		existingCond = &plan.Status.Conditions[len(plan.Status.Conditions)-1]
+	}
+	if existingCond.Status != status {
+		existingCond.LastTransitionTime = metav1.Now()
+	}
+	existingCond.Status = status
+	existingCond.Reason = reason
+	existingCond.Message = fmt.Sprintf(message, args...)
+}
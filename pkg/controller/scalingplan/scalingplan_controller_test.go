@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package scalingplan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) metav1.Time { return metav1.NewTime(now.Add(d).Truncate(time.Second)) }
+	wpa := &v1alpha1.WatermarkPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+
+	tests := []struct {
+		name        string
+		wpa         string
+		steps       []v1alpha1.ScalingPlanStep
+		wantActive  int32
+		wantNext    *metav1.Time
+		wantRequeue bool
+		wantReason  string
+		wantEvent   string
+		wantStatus  corev1.ConditionStatus
+	}{
+		{
+			name: "active step",
+			wpa:  "foo",
+			steps: []v1alpha1.ScalingPlanStep{
+				{At: at(-time.Hour), MinReplicas: v1alpha1.NewInt32(4)},
+				{At: at(time.Hour), Replicas: v1alpha1.NewInt32(8)},
+			},
+			wantActive:  0,
+			wantNext:    &metav1.Time{Time: at(time.Hour).Time},
+			wantRequeue: true,
+			wantReason:  "StepsScheduled",
+			wantEvent:   "Normal StepStarted Step 0 of the plan applies to the WPA foo: minReplicas set to 4",
+			wantStatus:  corev1.ConditionTrue,
+		},
+		{
+			name: "last step",
+			wpa:  "foo",
+			steps: []v1alpha1.ScalingPlanStep{
+				{At: at(-2 * time.Hour), Replicas: v1alpha1.NewInt32(8)},
+				{At: at(-time.Hour)},
+			},
+			wantActive: 1,
+			wantReason: "StepsScheduled",
+			wantEvent:  "Normal StepStarted Step 1 of the plan applies to the WPA foo: the WPA is back to its own spec",
+			wantStatus: corev1.ConditionTrue,
+		},
+		{
+			name:        "missing WPA before the first step",
+			wpa:         "bar",
+			steps:       []v1alpha1.ScalingPlanStep{{At: at(time.Hour), Replicas: v1alpha1.NewInt32(8)}},
+			wantActive:  -1,
+			wantNext:    &metav1.Time{Time: at(time.Hour).Time},
+			wantRequeue: true,
+			wantReason:  "MissingWPA",
+			wantStatus:  corev1.ConditionFalse,
+		},
+		{
+			name:       "invalid plan",
+			wpa:        "foo",
+			wantActive: -1,
+			wantReason: "FailedSpecCheck",
+			wantEvent:  "Warning FailedSpecCheck the Spec.Steps should have at least one step",
+			wantStatus: corev1.ConditionFalse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &v1alpha1.ScalingPlan{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "plan"},
+				Spec:       v1alpha1.ScalingPlanSpec{WPA: tt.wpa, Steps: tt.steps},
+			}
+			s := runtime.NewScheme()
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.ScalingPlan{}, &v1alpha1.WatermarkPodAutoscaler{})
+			recorder := record.NewFakeRecorder(10)
+			r := &ReconcileScalingPlan{client: fake.NewFakeClientWithScheme(s, plan, wpa), eventRecorder: recorder}
+
+			result, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "plan"}})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRequeue, result.RequeueAfter > 0)
+
+			updated := &v1alpha1.ScalingPlan{}
+			require.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "plan"}, updated))
+			require.Equal(t, tt.wantActive, updated.Status.ActiveStep)
+			if tt.wantNext == nil {
+				require.Nil(t, updated.Status.NextStepTime)
+			} else {
+				require.True(t, tt.wantNext.Equal(updated.Status.NextStepTime))
+			}
+			require.Len(t, updated.Status.Conditions, 1)
+			require.Equal(t, tt.wantStatus, updated.Status.Conditions[0].Status)
+			require.Equal(t, tt.wantReason, updated.Status.Conditions[0].Reason)
+			if tt.wantEvent == "" {
+				require.Empty(t, recorder.Events)
+			} else {
+				require.Equal(t, tt.wantEvent, <-recorder.Events)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+	"sync"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+)
+
+const (
+	defaultAdaptiveToleranceFactor = 1.0
+	defaultAdaptiveMaxTolerance    = 0.5
+)
+
+// metricHistory keeps the recent values of the metrics of the WPAs with an adaptive tolerance.
+type metricHistory struct {
+	mu     sync.Mutex
+	values map[string][]float64
+}
+
+func newMetricHistory() *metricHistory {
+	return &metricHistory{values: map[string][]float64{}}
+}
+
+// record adds a value to the history of the metric, keeping the last `size` ones, and returns them.
+func (h *metricHistory) record(key string, value float64, size int) []float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	values := append(h.values[key], value)
+	if len(values) > size {
+		values = values[len(values)-size:]
+	}
+	h.values[key] = values
+	return append([]float64(nil), values...)
+}
+
+// adaptiveTolerance returns the tolerance of a metric given its recent values: the coefficient of variation
+// multiplied by the factor, no lower than the tolerance of the spec and no higher than the maximum.
+func adaptiveTolerance(spec *v1alpha1.AdaptiveToleranceSpec, tolerance float64, values []float64) float64 {
+	if len(values) < 2 {
+		return tolerance
+	}
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if mean == 0 {
+		return tolerance
+	}
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	factor := spec.Factor
+	if factor == 0 {
+		factor = defaultAdaptiveToleranceFactor
+	}
+	maxTolerance := spec.MaxTolerance
+	if maxTolerance == 0 {
+		maxTolerance = defaultAdaptiveMaxTolerance
+	}
+	adaptive := math.Min(factor*math.Sqrt(variance)/mean, maxTolerance)
+	return math.Max(adaptive, tolerance)
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricHistory(t *testing.T) {
+	history := newMetricHistory()
+	require.Equal(t, []float64{1}, history.record("foo", 1, 3))
+	history.record("foo", 2, 3)
+	history.record("foo", 3, 3)
+	require.Equal(t, []float64{2, 3, 4}, history.record("foo", 4, 3))
+	require.Equal(t, []float64{10}, history.record("bar", 10, 3))
+}
+
+func TestAdaptiveTolerance(t *testing.T) {
+	spec := &v1alpha1.AdaptiveToleranceSpec{Samples: 4}
+
+	require.Equal(t, 0.1, adaptiveTolerance(spec, 0.1, []float64{100}), "a single value has no variation")
+	require.Equal(t, 0.1, adaptiveTolerance(spec, 0.1, []float64{0, 0}))
+	require.Equal(t, 0.1, adaptiveTolerance(spec, 0.1, []float64{100, 102, 98, 100}), "the tolerance of the spec is a floor")
+	// mean 100, standard deviation 20
+	require.InDelta(t, 0.2, adaptiveTolerance(spec, 0.1, []float64{80, 120, 80, 120}), 1e-9)
+
+	spec.Factor = 2
+	require.InDelta(t, 0.4, adaptiveTolerance(spec, 0.1, []float64{80, 120, 80, 120}), 1e-9)
+	require.Equal(t, defaultAdaptiveMaxTolerance, adaptiveTolerance(spec, 0.1, []float64{10, 190, 10, 190}))
+	spec.MaxTolerance = 0.3
+	require.Equal(t, 0.3, adaptiveTolerance(spec, 0.1, []float64{80, 120, 80, 120}))
+}
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// updateIdleSince records in the status since when all the metrics of the WPA are below the idle threshold.
+func updateIdleSince(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, statuses []autoscalingv2.MetricStatus, now time.Time) {
+	if !metricsIdle(statuses, wpa.Spec.Baseline.IdleThreshold.MilliValue()) {
+		wpa.Status.IdleSince = nil
+		return
+	}
+	if wpa.Status.IdleSince == nil {
+		idleSince := metav1.NewTime(now)
+		wpa.Status.IdleSince = &idleSince
+	}
+}
+
+// someMetricsFailed returns whether the last replica count was computed without some of the metrics, which may not
+// be idle.
+func someMetricsFailed(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) bool {
+	for _, condition := range wpa.Status.Conditions {
+		if condition.Type == autoscalingv2.ScalingActive {
+			return condition.Reason == "SomeMetricsFailed"
+		}
+	}
+	return false
+}
+
+func metricsIdle(statuses []autoscalingv2.MetricStatus, threshold int64) bool {
+	idle := false
+	for _, status := range statuses {
+		var value int64
+		switch {
+		case status.External != nil:
+			value = status.External.CurrentValue.MilliValue()
+		case status.Resource != nil:
+			value = status.Resource.CurrentAverageValue.MilliValue()
+		default:
+			continue
+		}
+		if value >= threshold {
+			return false
+		}
+		idle = true
+	}
+	return idle
+}
+
+// baselineReplicas returns the baseline of the WPA, within its bounds, if the metrics have been idle for the window.
+func baselineReplicas(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) (int32, bool) {
+	baseline := wpa.Spec.Baseline
+	if wpa.Status.IdleSince == nil || now.Sub(wpa.Status.IdleSince.Time) < time.Duration(baseline.IdleWindowSeconds)*time.Second {
+		return 0, false
+	}
+	replicas := baseline.Replicas
+	if wpa.Spec.MinReplicas != nil && replicas < *wpa.Spec.MinReplicas {
+		replicas = *wpa.Spec.MinReplicas
+	}
+	if replicas > wpa.Spec.MaxReplicas {
+		replicas = wpa.Spec.MaxReplicas
+	}
+	logger.Info("The metrics are idle, returning to the baseline", "idleSince", wpa.Status.IdleSince.Time, "baselineReplicas", replicas)
+	return replicas, true
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func externalStatus(milliValue int64) autoscalingv2.MetricStatus {
+	return autoscalingv2.MetricStatus{
+		Type:     autoscalingv2.ExternalMetricSourceType,
+		External: &autoscalingv2.ExternalMetricStatus{CurrentValue: *resource.NewMilliQuantity(milliValue, resource.DecimalSI)},
+	}
+}
+
+func TestReturnToBaseline(t *testing.T) {
+	logger := logf.Log.WithName("TestReturnToBaseline")
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			MinReplicas: getReplicas(2),
+			MaxReplicas: 50,
+			Baseline: &v1alpha1.BaselineSpec{
+				Replicas:          1,
+				IdleThreshold:     resource.MustParse("1"),
+				IdleWindowSeconds: 600,
+			},
+		},
+	})
+
+	updateIdleSince(wpa, []autoscalingv2.MetricStatus{externalStatus(500), externalStatus(2000)}, start)
+	require.Nil(t, wpa.Status.IdleSince, "all the metrics should be below the threshold")
+
+	updateIdleSince(wpa, []autoscalingv2.MetricStatus{externalStatus(500), {}}, start)
+	require.NotNil(t, wpa.Status.IdleSince)
+	updateIdleSince(wpa, []autoscalingv2.MetricStatus{externalStatus(0)}, start.Add(5*time.Minute))
+	require.Equal(t, start, wpa.Status.IdleSince.Time, "the start of the idle period should be kept")
+
+	_, idle := baselineReplicas(logger, wpa, start.Add(5*time.Minute))
+	require.False(t, idle, "the metrics should be idle for the whole window")
+	replicas, idle := baselineReplicas(logger, wpa, start.Add(10*time.Minute))
+	require.True(t, idle)
+	require.Equal(t, int32(2), replicas, "the minReplicas should take precedence over the baseline")
+
+	updateIdleSince(wpa, []autoscalingv2.MetricStatus{externalStatus(1000)}, start.Add(11*time.Minute))
+	require.Nil(t, wpa.Status.IdleSince)
+	_, idle = baselineReplicas(logger, wpa, start.Add(11*time.Minute))
+	require.False(t, idle)
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const defaultColorLabel = "color"
+
+// liveColorFirst moves the workloads of the live color, the one the selector of the Service of the pair routes the
+// traffic to, ahead of the other ones so that the recommendation is computed on it.
+func (r *ReconcileWatermarkPodAutoscaler) liveColorFirst(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, targets []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	label := colorLabel(wpa.Spec.BlueGreen)
+	service := &corev1.Service{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Spec.BlueGreen.ServiceName}, service); err != nil {
+		return nil, fmt.Errorf("unable to get the Service %s of the blue/green pair: %v", wpa.Spec.BlueGreen.ServiceName, err)
+	}
+	live, found := service.Spec.Selector[label]
+	if !found {
+		return nil, fmt.Errorf("the selector of the Service %s has no %s label", service.Name, label)
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].GetLabels()[label] == live && targets[j].GetLabels()[label] != live
+	})
+	if targets[0].GetLabels()[label] != live {
+		return nil, fmt.Errorf("no %s of the live color %s matches the selector of the scale target reference", wpa.Spec.ScaleTargetRef.Kind, live)
+	}
+	logger.Info("Resolved the live color of the blue/green pair", "color", live, "scaleTargetRef", targets[0].GetName())
+	return targets, nil
+}
+
+func colorLabel(spec *datadoghqv1alpha1.BlueGreenSpec) string {
+	if spec.ColorLabel == "" {
+		return defaultColorLabel
+	}
+	return spec.ColorLabel
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestResolveBlueGreenTargets(t *testing.T) {
+	newDeployment := func(name, color string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testingNamespace,
+				Name:      name,
+				Labels:    map[string]string{"app": "checkout", "color": color},
+			},
+		}
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "checkout"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "checkout", "color": "green"}},
+	}
+	r := &ReconcileWatermarkPodAutoscaler{
+		client: fake.NewFakeClientWithScheme(scheme.Scheme,
+			newDeployment("checkout-blue", "blue"),
+			newDeployment("checkout-green", "green"),
+			service,
+		),
+	}
+	newWPA := func() *v1alpha1.WatermarkPodAutoscaler {
+		return test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+				ScaleTargetRef: v1alpha1.CrossVersionObjectReference{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+					Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "checkout"}},
+				},
+				BlueGreen: &v1alpha1.BlueGreenSpec{ServiceName: "checkout"},
+			},
+		})
+	}
+	logger := logf.Log.WithName(t.Name())
+
+	wpa := newWPA()
+	require.NoError(t, r.resolveScaleTargets(logger, wpa))
+	require.Equal(t, "checkout-green", wpa.Spec.ScaleTargetRef.Name, "the recommendation is computed on the live color")
+	require.Len(t, wpa.Spec.ScaleTargetRefs, 1)
+	require.Equal(t, "checkout-blue", wpa.Spec.ScaleTargetRefs[0].Name)
+	require.Equal(t, selectedTargetWeight, wpa.Spec.ScaleTargetRefs[0].Weight, "the idle color has as many replicas as the live one")
+
+	service.Spec.Selector["color"] = "blue"
+	require.NoError(t, r.client.Update(context.TODO(), service))
+	wpa = newWPA()
+	require.NoError(t, r.resolveScaleTargets(logger, wpa))
+	require.Equal(t, "checkout-blue", wpa.Spec.ScaleTargetRef.Name, "the switch of the Service is followed")
+	require.Equal(t, "checkout-green", wpa.Spec.ScaleTargetRefs[0].Name)
+
+	service.Spec.Selector["color"] = "red"
+	require.NoError(t, r.client.Update(context.TODO(), service))
+	require.EqualError(t, r.resolveScaleTargets(logger, newWPA()), "no Deployment of the live color red matches the selector of the scale target reference")
+
+	wpa = newWPA()
+	wpa.Spec.BlueGreen.ColorLabel = "slot"
+	require.EqualError(t, r.resolveScaleTargets(logger, wpa), "the selector of the Service checkout has no slot label")
+
+	wpa = newWPA()
+	wpa.Spec.BlueGreen.ServiceName = "unknown"
+	require.Error(t, r.resolveScaleTargets(logger, wpa))
+}
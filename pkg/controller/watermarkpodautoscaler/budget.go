@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// capDesiredReplicasWithBudget uses the cost model of the WPA as an additional ceiling on the number of replicas.
+// The minReplicas still take precedence over the budget.
+func capDesiredReplicasWithBudget(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, desiredReplicas int32) int32 {
+	budget := wpa.Spec.Budget
+	costPerReplica := float64(budget.CostPerReplicaHour.MilliValue())
+	maxReplicas := int32(math.Floor(float64(budget.MaxCostPerHour.MilliValue()) / costPerReplica))
+	if wpa.Spec.MinReplicas != nil && maxReplicas < *wpa.Spec.MinReplicas {
+		maxReplicas = *wpa.Spec.MinReplicas
+	}
+
+	capped := desiredReplicas
+	if desiredReplicas > maxReplicas {
+		capped = maxReplicas
+		setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, "LimitedByBudget", "the desired replica count is above what the budget of %s per hour allows (%d)", budget.MaxCostPerHour.String(), maxReplicas)
+		logger.Info("Capping the desired replicas to the budget", "desiredReplicas", desiredReplicas, "budgetMaxReplicas", maxReplicas)
+	}
+	projectedCost.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Set(float64(capped) * costPerReplica / 1000)
+	return capped
+}
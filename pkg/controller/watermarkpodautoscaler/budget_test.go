@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestCapDesiredReplicasWithBudget(t *testing.T) {
+	tests := []struct {
+		name            string
+		minReplicas     *int32
+		costPerReplica  string
+		maxCost         string
+		desiredReplicas int32
+		want            int32
+		wantCondition   string
+	}{
+		{
+			name:            "within budget",
+			minReplicas:     getReplicas(1),
+			costPerReplica:  "0.5",
+			maxCost:         "10",
+			desiredReplicas: 15,
+			want:            15,
+		},
+		{
+			name:            "exactly at the budget",
+			minReplicas:     getReplicas(1),
+			costPerReplica:  "0.5",
+			maxCost:         "10",
+			desiredReplicas: 20,
+			want:            20,
+		},
+		{
+			name:            "above budget",
+			minReplicas:     getReplicas(1),
+			costPerReplica:  "0.3",
+			maxCost:         "3",
+			desiredReplicas: 15,
+			want:            10,
+			wantCondition:   "the desired replica count is above what the budget of 3 per hour allows (10)",
+		},
+		{
+			name:            "partial replicas are rounded down",
+			minReplicas:     getReplicas(1),
+			costPerReplica:  "0.4",
+			maxCost:         "3",
+			desiredReplicas: 8,
+			want:            7,
+			wantCondition:   "the desired replica count is above what the budget of 3 per hour allows (7)",
+		},
+		{
+			name:            "minReplicas takes precedence",
+			minReplicas:     getReplicas(5),
+			costPerReplica:  "1",
+			maxCost:         "2",
+			desiredReplicas: 6,
+			want:            5,
+			wantCondition:   "the desired replica count is above what the budget of 2 per hour allows (5)",
+		},
+		{
+			name:            "no minReplicas",
+			costPerReplica:  "1",
+			maxCost:         "2",
+			desiredReplicas: 6,
+			want:            2,
+			wantCondition:   "the desired replica count is above what the budget of 2 per hour allows (2)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+					ScaleTargetRef: testCrossVersionObjectRef,
+					MinReplicas:    tt.minReplicas,
+					MaxReplicas:    20,
+					Budget: &v1alpha1.BudgetSpec{
+						CostPerReplicaHour: resource.MustParse(tt.costPerReplica),
+						MaxCostPerHour:     resource.MustParse(tt.maxCost),
+					},
+				},
+			})
+			assert.Equal(t, tt.want, capDesiredReplicasWithBudget(logf.Log.WithName(tt.name), wpa, tt.desiredReplicas))
+			condition := ""
+			for _, c := range wpa.Status.Conditions {
+				if c.Type == autoscalingv2.ScalingLimited && c.Reason == "LimitedByBudget" {
+					condition = c.Message
+				}
+			}
+			assert.Equal(t, tt.wantCondition, condition)
+		})
+	}
+}
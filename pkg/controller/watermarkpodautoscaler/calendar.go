@@ -0,0 +1,250 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	calendarDateLayout    = "2006-01-02"
+	icsDateLayout         = "20060102"
+	calendarRefreshPeriod = time.Hour
+	calendarFetchTimeout  = 10 * time.Second
+	// calendarRetryBaseDelay is the delay before fetching a feed again after a failure, doubling at every
+	// consecutive failure up to the calendarRefreshPeriod.
+	calendarRetryBaseDelay = time.Minute
+	// maxCalendarBodySize bounds the size of the feeds.
+	maxCalendarBodySize = 4 << 20
+	// maxEventDays bounds the number of days of a single event, to protect against malformed feeds.
+	maxEventDays = 366
+)
+
+// calendarCache keeps the special days of the iCalendar feeds, so that they are not downloaded at every reconciliation.
+type calendarCache struct {
+	sync.Mutex
+	httpClient *http.Client
+	entries    map[string]calendarEntry
+}
+
+type calendarEntry struct {
+	days     map[string]bool
+	err      error
+	failures int
+	next     time.Time
+}
+
+func newCalendarCache() *calendarCache {
+	return &calendarCache{
+		httpClient: &http.Client{Timeout: calendarFetchTimeout},
+		entries:    map[string]calendarEntry{},
+	}
+}
+
+// specialDays returns the special days of the feed, refreshed every calendarRefreshPeriod.
+// If the feed can't be refreshed, the last known days are returned along with the error until the next attempt,
+// which backs off exponentially.
+func (c *calendarCache) specialDays(url string, now time.Time) (map[string]bool, error) {
+	c.Lock()
+	entry, found := c.entries[url]
+	if found && now.Before(entry.next) {
+		c.Unlock()
+		return entry.days, entry.err
+	}
+	// the feed is fetched without holding the lock, the other reconciles keep using the last days in the meantime
+	entry.next = now.Add(calendarFetchTimeout)
+	c.entries[url] = entry
+	c.Unlock()
+
+	days, err := fetchSpecialDays(c.httpClient, url)
+
+	c.Lock()
+	defer c.Unlock()
+	entry = c.entries[url]
+	if err != nil {
+		entry.err = err
+		entry.failures++
+		entry.next = now.Add(calendarRetryDelay(entry.failures))
+		c.entries[url] = entry
+		return entry.days, err
+	}
+	c.entries[url] = calendarEntry{days: days, next: now.Add(calendarRefreshPeriod)}
+	return days, nil
+}
+
+// calendarRetryDelay returns the delay before fetching a feed again after consecutive failures.
+func calendarRetryDelay(failures int) time.Duration {
+	delay := calendarRetryBaseDelay
+	for i := 1; i < failures && delay < calendarRefreshPeriod; i++ {
+		delay *= 2
+	}
+	if delay > calendarRefreshPeriod {
+		return calendarRefreshPeriod
+	}
+	return delay
+}
+
+func fetchSpecialDays(httpClient *http.Client, url string) (map[string]bool, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the calendar %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch the calendar %s: %s", url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCalendarBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the calendar %s: %v", url, err)
+	}
+	if len(body) > maxCalendarBodySize {
+		return nil, fmt.Errorf("the calendar %s is larger than %d bytes", url, maxCalendarBodySize)
+	}
+	return parseSpecialDays(string(body))
+}
+
+// isSpecialDay returns whether the calendar of the WPA marks the day of the time now as special.
+// Failures to read the calendar are reported and the day is then considered as a regular one.
+func (r *ReconcileWatermarkPodAutoscaler) isSpecialDay(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) bool {
+	calendar := wpa.Spec.Calendar
+	if calendar == nil {
+		return false
+	}
+	days, err := r.readSpecialDays(wpa.Namespace, calendar, now)
+	if err != nil {
+		logger.Info("Error while reading the calendar", "error", err)
+		r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "FailedReadCalendar", err.Error())
+	}
+	loc, err := time.LoadLocation(calendar.TimeZone)
+	if err != nil {
+		return false
+	}
+	return days[now.In(loc).Format(calendarDateLayout)]
+}
+
+func (r *ReconcileWatermarkPodAutoscaler) readSpecialDays(namespace string, calendar *datadoghqv1alpha1.SpecialDaysCalendar, now time.Time) (map[string]bool, error) {
+	if calendar.ConfigMapKeyRef == nil {
+		if r.calendars == nil {
+			return fetchSpecialDays(&http.Client{Timeout: calendarFetchTimeout}, calendar.URL)
+		}
+		return r.calendars.specialDays(calendar.URL, now)
+	}
+	ref := calendar.ConfigMapKeyRef
+	configMap := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, configMap); err != nil {
+		return nil, fmt.Errorf("unable to get the ConfigMap %s: %v", ref.Name, err)
+	}
+	value, found := configMap.Data[ref.Key]
+	if !found {
+		return nil, fmt.Errorf("the key %s is not found in the ConfigMap %s", ref.Key, ref.Name)
+	}
+	return parseSpecialDays(value)
+}
+
+// parseSpecialDays reads either an iCalendar document or a list of days formatted as YYYY-MM-DD.
+// In the latter, empty lines and lines starting with # are ignored.
+func parseSpecialDays(content string) (map[string]bool, error) {
+	if strings.HasPrefix(strings.TrimSpace(content), "BEGIN:VCALENDAR") {
+		return parseICS(content)
+	}
+	days := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		day, err := time.Parse(calendarDateLayout, line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid day %q: %v", line, err)
+		}
+		days[day.Format(calendarDateLayout)] = true
+	}
+	return days, scanner.Err()
+}
+
+// parseICS returns the days covered by the events of an iCalendar document.
+// The dates of the events are read as written, recurrence rules are not supported.
+func parseICS(content string) (map[string]bool, error) {
+	// unfold the content lines, see RFC 5545 section 3.1
+	content = strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(content)
+	days := map[string]bool{}
+	var inEvent bool
+	var start, end time.Time
+	var endIncluded bool
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			continue
+		}
+		name, value := strings.ToUpper(strings.Split(line[:sep], ";")[0]), line[sep+1:]
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent, start, end, endIncluded = true, time.Time{}, time.Time{}, false
+		case name == "END" && value == "VEVENT":
+			if start.IsZero() {
+				return nil, fmt.Errorf("an event has no DTSTART")
+			}
+			if end.IsZero() {
+				end, endIncluded = start, true
+			}
+			if endIncluded {
+				end = end.AddDate(0, 0, 1)
+			}
+			for day, n := start, 0; day.Before(end) && n < maxEventDays; day, n = day.AddDate(0, 0, 1), n+1 {
+				days[day.Format(calendarDateLayout)] = true
+			}
+			inEvent = false
+		case inEvent && (name == "DTSTART" || name == "DTEND"):
+			if len(value) < len(icsDateLayout) {
+				return nil, fmt.Errorf("invalid %s %q", name, value)
+			}
+			day, err := time.Parse(icsDateLayout, value[:len(icsDateLayout)])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %v", name, value, err)
+			}
+			if name == "DTSTART" {
+				start = day
+				continue
+			}
+			// the end of an event is exclusive, unless it is a time within the last day
+			end, endIncluded = day, len(value) > len(icsDateLayout) && !strings.HasPrefix(value[len(icsDateLayout):], "T000000")
+		}
+	}
+	return days, scanner.Err()
+}
+
+// applyCalendarBounds overrides the replica bounds of the spec with the ones of the calendar.
+func applyCalendarBounds(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, calendar *datadoghqv1alpha1.SpecialDaysCalendar) {
+	if calendar.MinReplicas != nil {
+		spec.MinReplicas = datadoghqv1alpha1.NewInt32(*calendar.MinReplicas)
+		if spec.MaxReplicas < *calendar.MinReplicas {
+			spec.MaxReplicas = *calendar.MinReplicas
+		}
+	}
+	if calendar.MaxReplicas != nil {
+		spec.MaxReplicas = *calendar.MaxReplicas
+		if spec.MinReplicas != nil && *spec.MinReplicas > *calendar.MaxReplicas {
+			spec.MinReplicas = datadoghqv1alpha1.NewInt32(*calendar.MaxReplicas)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const testingICS = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+SUMMARY:Black Friday
+DTSTART;VALUE=DATE:20191129
+DTEND;VALUE=DATE:20191201
+END:VEVENT
+BEGIN:VEVENT
+SUMMARY:Sales
+ of the evening
+DTSTART:20191224T180000Z
+DTEND:20191225T020000Z
+END:VEVENT
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20200101
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParseSpecialDays(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "list of days",
+			content: "# holidays\n2019-12-25\n\n 2020-01-01 \n",
+			want:    []string{"2019-12-25", "2020-01-01"},
+		},
+		{
+			name:    "invalid day",
+			content: "2019-12-32\n",
+			wantErr: true,
+		},
+		{
+			name:    "iCalendar",
+			content: testingICS,
+			want:    []string{"2019-11-29", "2019-11-30", "2019-12-24", "2019-12-25", "2020-01-01"},
+		},
+		{
+			name:    "iCalendar event without start",
+			content: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTEND:20191225\nEND:VEVENT\nEND:VCALENDAR\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := parseSpecialDays(tt.content)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			want := map[string]bool{}
+			for _, day := range tt.want {
+				want[day] = true
+			}
+			require.Equal(t, want, days)
+		})
+	}
+}
+
+func TestIsSpecialDay(t *testing.T) {
+	fetches := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fetches++
+		fmt.Fprint(w, testingICS)
+	}))
+	defer server.Close()
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "calendar"},
+		Data:       map[string]string{"days": "2019-12-24\n"},
+	}
+	calendars := newCalendarCache()
+	calendars.httpClient = server.Client()
+	r := &ReconcileWatermarkPodAutoscaler{
+		client:        fake.NewFakeClient(configMap),
+		eventRecorder: record.NewFakeRecorder(10),
+		calendars:     calendars,
+	}
+	tests := []struct {
+		name     string
+		calendar *v1alpha1.SpecialDaysCalendar
+		now      time.Time
+		want     bool
+	}{
+		{
+			name: "no calendar",
+			now:  time.Date(2019, time.November, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "special day of the feed",
+			calendar: &v1alpha1.SpecialDaysCalendar{URL: server.URL},
+			now:      time.Date(2019, time.November, 29, 12, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "regular day of the feed",
+			calendar: &v1alpha1.SpecialDaysCalendar{URL: server.URL},
+			now:      time.Date(2019, time.November, 28, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "special day of the ConfigMap",
+			calendar: &v1alpha1.SpecialDaysCalendar{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "calendar"}, Key: "days"}},
+			now:      time.Date(2019, time.December, 24, 12, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "missing key of the ConfigMap",
+			calendar: &v1alpha1.SpecialDaysCalendar{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "calendar"}, Key: "missing"}},
+			now:      time.Date(2019, time.December, 24, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{Calendar: tt.calendar},
+			})
+			require.Equal(t, tt.want, r.isSpecialDay(logf.Log.WithName(tt.name), wpa, tt.now))
+		})
+	}
+	require.Equal(t, 1, fetches)
+}
+
+func TestCalendarCacheBackoff(t *testing.T) {
+	fetches := 0
+	status := http.StatusOK
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fetches++
+		w.WriteHeader(status)
+		fmt.Fprint(w, "2019-12-24\n")
+	}))
+	defer server.Close()
+	calendars := newCalendarCache()
+	calendars.httpClient = server.Client()
+	start := time.Date(2019, time.December, 1, 12, 0, 0, 0, time.UTC)
+
+	days, err := calendars.specialDays(server.URL, start)
+	require.NoError(t, err)
+	require.True(t, days["2019-12-24"])
+
+	status = http.StatusServiceUnavailable
+	refresh := start.Add(calendarRefreshPeriod)
+	days, err = calendars.specialDays(server.URL, refresh)
+	require.Error(t, err)
+	require.True(t, days["2019-12-24"], "the last known days are kept")
+	_, err = calendars.specialDays(server.URL, refresh.Add(59*time.Second))
+	require.Error(t, err)
+	require.Equal(t, 2, fetches, "the feed is not fetched again before the retry delay")
+
+	_, err = calendars.specialDays(server.URL, refresh.Add(time.Minute))
+	require.Error(t, err)
+	require.Equal(t, 3, fetches)
+	_, _ = calendars.specialDays(server.URL, refresh.Add(2*time.Minute))
+	require.Equal(t, 3, fetches, "the retry delay doubles at every failure")
+
+	require.Equal(t, time.Minute, calendarRetryDelay(1))
+	require.Equal(t, 4*time.Minute, calendarRetryDelay(3))
+	require.Equal(t, calendarRefreshPeriod, calendarRetryDelay(100))
+}
+
+func TestApplyCalendarBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar v1alpha1.SpecialDaysCalendar
+		wantMin  int32
+		wantMax  int32
+	}{
+		{
+			name:     "no bounds",
+			calendar: v1alpha1.SpecialDaysCalendar{Profile: "sales"},
+			wantMin:  2,
+			wantMax:  10,
+		},
+		{
+			name:     "both bounds",
+			calendar: v1alpha1.SpecialDaysCalendar{MinReplicas: getReplicas(5), MaxReplicas: getReplicas(20)},
+			wantMin:  5,
+			wantMax:  20,
+		},
+		{
+			name:     "minimum above the maximum of the WPA",
+			calendar: v1alpha1.SpecialDaysCalendar{MinReplicas: getReplicas(15)},
+			wantMin:  15,
+			wantMax:  15,
+		},
+		{
+			name:     "maximum below the minimum of the WPA",
+			calendar: v1alpha1.SpecialDaysCalendar{MaxReplicas: getReplicas(1)},
+			wantMin:  1,
+			wantMax:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := v1alpha1.WatermarkPodAutoscalerSpec{MinReplicas: getReplicas(2), MaxReplicas: 10}
+			applyCalendarBounds(&spec, &tt.calendar)
+			require.Equal(t, tt.wantMin, *spec.MinReplicas)
+			require.Equal(t, tt.wantMax, spec.MaxReplicas)
+		})
+	}
+}
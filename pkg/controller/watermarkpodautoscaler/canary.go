@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	canaryCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "Canary"
+
+	defaultCanaryWindow = 300 * time.Second
+)
+
+// applyCanary only applies the canaryPercent of a change of replicas, and holds the target at the canary replicas
+// until the end of the canaryWindowSeconds. The rest of the change is then applied if the recommendation still goes
+// in the direction of the canary, otherwise the target is rolled back to its replicas before the canary, once rescale
+// allows to scale the target. It returns the replicas to scale the target to, whether to scale it, and the reason of
+// the scaling if the canary drives it.
+func applyCanary(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas int32, rescale bool, now time.Time) (int32, bool, string) {
+	canary := wpa.Status.Canary
+	if canary != nil {
+		end := canary.StartTime.Add(canaryWindow(wpa))
+		if now.Before(end) {
+			setCondition(wpa, canaryCondition, corev1.ConditionTrue, "CanaryInProgress", "the target is held at %d replicas until %s before scaling from %d to %d replicas", currentReplicas, end.Format(time.RFC3339), canary.FromReplicas, canary.ToReplicas)
+			logger.Info("Observing the canary", "fromReplicas", canary.FromReplicas, "toReplicas", canary.ToReplicas, "until", end)
+			return currentReplicas, false, ""
+		}
+		if !rescale {
+			setCondition(wpa, canaryCondition, corev1.ConditionTrue, "CanaryInProgress", "the window ended at %s, the target is held at %d replicas until it can be scaled", end.Format(time.RFC3339), currentReplicas)
+			logger.Info("Holding the canary until the target can be scaled", "fromReplicas", canary.FromReplicas, "toReplicas", canary.ToReplicas)
+			return currentReplicas, false, ""
+		}
+		wpa.Status.Canary = nil
+		upscale := canary.ToReplicas > canary.FromReplicas
+		if (upscale && desiredReplicas > canary.FromReplicas) || (!upscale && desiredReplicas < canary.FromReplicas) {
+			setCondition(wpa, canaryCondition, corev1.ConditionFalse, "CanaryConfirmed", "the recommendation of %d replicas confirmed the canary, applying the rest of the change", desiredReplicas)
+			logger.Info("The canary is confirmed", "fromReplicas", canary.FromReplicas, "desiredReplicas", desiredReplicas)
+			return desiredReplicas, desiredReplicas != currentReplicas, fmt.Sprintf("Canary confirmed, applying the rest of the change from %d replicas", canary.FromReplicas)
+		}
+		setCondition(wpa, canaryCondition, corev1.ConditionFalse, "CanaryRolledBack", "the recommendation of %d replicas did not confirm the canary, rolling back to %d replicas", desiredReplicas, canary.FromReplicas)
+		logger.Info("The canary is rolled back", "fromReplicas", canary.FromReplicas, "desiredReplicas", desiredReplicas)
+		return canary.FromReplicas, canary.FromReplicas != currentReplicas, fmt.Sprintf("Canary not confirmed by the recommendation of %d replicas, rolling back", desiredReplicas)
+	}
+
+	if !rescale || wpa.Spec.CanaryPercent == 0 || wpa.Spec.DryRun {
+		return desiredReplicas, rescale, ""
+	}
+	delta := math.Abs(float64(desiredReplicas - currentReplicas))
+	step := int32(math.Ceil(delta * float64(wpa.Spec.CanaryPercent) / 100))
+	if float64(step) >= delta {
+		// the change is too small to be split
+		return desiredReplicas, rescale, ""
+	}
+	canaryReplicas := currentReplicas + step
+	if desiredReplicas < currentReplicas {
+		canaryReplicas = currentReplicas - step
+	}
+	wpa.Status.Canary = &datadoghqv1alpha1.CanaryStatus{
+		FromReplicas: currentReplicas,
+		ToReplicas:   desiredReplicas,
+		StartTime:    metav1.NewTime(now),
+	}
+	setCondition(wpa, canaryCondition, corev1.ConditionTrue, "CanaryStarted", "applying %d%% of the change from %d to %d replicas", wpa.Spec.CanaryPercent, currentReplicas, desiredReplicas)
+	logger.Info("Applying a canary", "currentReplicas", currentReplicas, "desiredReplicas", desiredReplicas, "canaryReplicas", canaryReplicas)
+	return canaryReplicas, true, fmt.Sprintf("Canary of %d%% of the change to %d replicas", wpa.Spec.CanaryPercent, desiredReplicas)
+}
+
+// abortCanary drops the canary in progress, if any, when the target is scaled regardless of it.
+func abortCanary(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) {
+	canary := wpa.Status.Canary
+	if canary == nil {
+		return
+	}
+	wpa.Status.Canary = nil
+	setCondition(wpa, canaryCondition, corev1.ConditionFalse, "CanaryAborted", "an emergency upscale superseded the canary from %d to %d replicas", canary.FromReplicas, canary.ToReplicas)
+	logger.Info("The canary is aborted by an emergency upscale", "fromReplicas", canary.FromReplicas, "toReplicas", canary.ToReplicas)
+}
+
+func canaryWindow(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) time.Duration {
+	if wpa.Spec.CanaryWindowSeconds == 0 {
+		return defaultCanaryWindow
+	}
+	return time.Duration(wpa.Spec.CanaryWindowSeconds) * time.Second
+}
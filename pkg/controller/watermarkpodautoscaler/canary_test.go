@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestApplyCanary(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	newWPA := func() *v1alpha1.WatermarkPodAutoscaler {
+		return test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+				CanaryPercent:       25,
+				CanaryWindowSeconds: 600,
+			},
+		})
+	}
+
+	t.Run("upscale confirmed", func(t *testing.T) {
+		wpa := newWPA()
+		replicas, rescale, reason := applyCanary(logger, wpa, 10, 18, true, start)
+		require.Equal(t, int32(12), replicas, "25% of the change of 8 replicas")
+		require.True(t, rescale)
+		require.Equal(t, "Canary of 25% of the change to 18 replicas", reason)
+		require.Equal(t, &v1alpha1.CanaryStatus{FromReplicas: 10, ToReplicas: 18, StartTime: wpa.Status.Canary.StartTime}, wpa.Status.Canary)
+		require.True(t, start.Equal(wpa.Status.Canary.StartTime.Time))
+
+		replicas, rescale, _ = applyCanary(logger, wpa, 12, 20, true, start.Add(5*time.Minute))
+		require.Equal(t, int32(12), replicas, "the target is held at the canary replicas during the window")
+		require.False(t, rescale)
+		require.Equal(t, "CanaryInProgress", wpa.Status.Conditions[0].Reason)
+
+		replicas, rescale, _ = applyCanary(logger, wpa, 12, 16, false, start.Add(10*time.Minute))
+		require.Equal(t, int32(12), replicas, "the canary is held while the target can't be scaled")
+		require.False(t, rescale)
+		require.NotNil(t, wpa.Status.Canary)
+
+		replicas, rescale, reason = applyCanary(logger, wpa, 12, 16, true, start.Add(11*time.Minute))
+		require.Equal(t, int32(16), replicas, "the latest recommendation is applied")
+		require.True(t, rescale)
+		require.Equal(t, "Canary confirmed, applying the rest of the change from 10 replicas", reason)
+		require.Nil(t, wpa.Status.Canary)
+		require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+		require.Equal(t, "CanaryConfirmed", wpa.Status.Conditions[0].Reason)
+	})
+
+	t.Run("downscale rolled back", func(t *testing.T) {
+		wpa := newWPA()
+		replicas, rescale, _ := applyCanary(logger, wpa, 10, 2, true, start)
+		require.Equal(t, int32(8), replicas)
+		require.True(t, rescale)
+
+		replicas, rescale, reason := applyCanary(logger, wpa, 8, 11, true, start.Add(10*time.Minute))
+		require.Equal(t, int32(10), replicas, "the target is rolled back to its replicas before the canary")
+		require.True(t, rescale)
+		require.Equal(t, "Canary not confirmed by the recommendation of 11 replicas, rolling back", reason)
+		require.Nil(t, wpa.Status.Canary)
+		require.Equal(t, "CanaryRolledBack", wpa.Status.Conditions[0].Reason)
+	})
+
+	t.Run("change too small", func(t *testing.T) {
+		wpa := newWPA()
+		replicas, rescale, reason := applyCanary(logger, wpa, 10, 11, true, start)
+		require.Equal(t, int32(11), replicas)
+		require.True(t, rescale)
+		require.Empty(t, reason)
+		require.Nil(t, wpa.Status.Canary)
+	})
+
+	t.Run("no scaling", func(t *testing.T) {
+		wpa := newWPA()
+		replicas, rescale, _ := applyCanary(logger, wpa, 10, 18, false, start)
+		require.Equal(t, int32(18), replicas)
+		require.False(t, rescale)
+		require.Nil(t, wpa.Status.Canary)
+	})
+}
+
+func TestAbortCanary(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+	abortCanary(logger, wpa)
+	require.Empty(t, wpa.Status.Conditions, "no canary in progress")
+
+	wpa.Status.Canary = &v1alpha1.CanaryStatus{FromReplicas: 10, ToReplicas: 18}
+	abortCanary(logger, wpa)
+	require.Nil(t, wpa.Status.Canary)
+	require.Equal(t, "CanaryAborted", wpa.Status.Conditions[0].Reason)
+}
+
+func TestSetStatusKeepsCanary(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+	wpa.Status.Canary = &v1alpha1.CanaryStatus{FromReplicas: 10, ToReplicas: 18}
+
+	setStatus(wpa, 12, 12, nil, true)
+	require.Equal(t, int32(12), wpa.Status.CurrentReplicas)
+	require.NotNil(t, wpa.Status.Canary, "the canary in progress is kept")
+	require.NotNil(t, wpa.Status.LastScaleTime)
+}
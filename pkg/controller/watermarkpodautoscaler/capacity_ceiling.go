@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"math"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultCapacityCeilingPollInterval = 60 * time.Second
+	capacityCeilingTimeout             = 10 * time.Second
+	// capacityCeilingExpiryIntervals is the number of poll intervals after which the last ceiling polled expires
+	// while the endpoint fails.
+	capacityCeilingExpiryIntervals = 3
+)
+
+// pollCapacityCeiling refreshes the maximum number of replicas served by the capacityCeiling endpoint of the WPA
+// once its poll interval is over. The last polled value is kept while the endpoint fails, until it expires.
+func (r *ReconcileWatermarkPodAutoscaler) pollCapacityCeiling(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) {
+	ceiling := wpa.Spec.CapacityCeiling
+	if ceiling == nil {
+		wpa.Status.CapacityCeiling = nil
+		return
+	}
+	interval := defaultCapacityCeilingPollInterval
+	if ceiling.PollIntervalSeconds > 0 {
+		interval = time.Duration(ceiling.PollIntervalSeconds) * time.Second
+	}
+	if status := wpa.Status.CapacityCeiling; status != nil && now.Sub(status.LastPollTime.Time) < interval {
+		return
+	}
+
+	values, err := fetchHTTPValues(context.TODO(), r.secretReader(), r.httpClient, wpa.Namespace, &ceiling.HTTPMetricSource)
+	if err != nil {
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedGetCapacityCeiling", "Unable to poll the capacity ceiling: %v", err)
+		if status := wpa.Status.CapacityCeiling; status != nil && now.Sub(status.LastPollTime.Time) >= capacityCeilingExpiryIntervals*interval {
+			logger.Info("Unable to poll the capacity ceiling, the last one expired", "error", err, "lastPollTime", status.LastPollTime)
+			wpa.Status.CapacityCeiling = nil
+			return
+		}
+		logger.Info("Unable to poll the capacity ceiling, keeping the last one", "error", err)
+		return
+	}
+	lowest := values[0]
+	for _, value := range values[1:] {
+		if value < lowest {
+			lowest = value
+		}
+	}
+	var maxReplicas int32
+	switch {
+	case lowest <= 0:
+		maxReplicas = 0
+	case lowest/1000 >= math.MaxInt32:
+		maxReplicas = math.MaxInt32
+	default:
+		maxReplicas = int32(lowest / 1000)
+	}
+	logger.Info("Polled the capacity ceiling", "maxReplicas", maxReplicas)
+	wpa.Status.CapacityCeiling = &datadoghqv1alpha1.CapacityCeilingStatus{MaxReplicas: maxReplicas, LastPollTime: metav1.NewTime(now)}
+}
+
+// capDesiredReplicasWithCapacityCeiling uses the last maximum number of replicas polled from the capacityCeiling
+// endpoint as an additional ceiling on the number of replicas. The minReplicas still take precedence over it.
+func capDesiredReplicasWithCapacityCeiling(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, desiredReplicas int32) int32 {
+	status := wpa.Status.CapacityCeiling
+	if status == nil || status.MaxReplicas >= wpa.Spec.MaxReplicas {
+		return desiredReplicas
+	}
+	maxReplicas := status.MaxReplicas
+	if wpa.Spec.MinReplicas != nil && maxReplicas < *wpa.Spec.MinReplicas {
+		maxReplicas = *wpa.Spec.MinReplicas
+	}
+	if desiredReplicas <= maxReplicas {
+		return desiredReplicas
+	}
+	setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, "LimitedByCapacityCeiling", "the desired replica count is above the capacity ceiling of %d replicas polled at %s", status.MaxReplicas, status.LastPollTime.Format(time.RFC3339))
+	logger.Info("Capping the desired replicas to the capacity ceiling", "desiredReplicas", desiredReplicas, "capacityCeiling", status.MaxReplicas)
+	return maxReplicas
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestPollCapacityCeiling(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	polls := 0
+	status := http.StatusOK
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"pools": [{"name": "a", "maxReplicas": 12}, {"name": "b", "maxReplicas": 8}]}`))
+	}))
+	defer server.Close()
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			CapacityCeiling: &v1alpha1.CapacityCeilingSpec{
+				HTTPMetricSource:    v1alpha1.HTTPMetricSource{URL: server.URL, JSONPath: "{.pools[*].maxReplicas}"},
+				PollIntervalSeconds: 30,
+			},
+		},
+	})
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClient(), httpClient: server.Client(), eventRecorder: recorder}
+	start := time.Unix(1000, 0)
+
+	r.pollCapacityCeiling(logger, wpa, start)
+	require.Equal(t, &v1alpha1.CapacityCeilingStatus{MaxReplicas: 8, LastPollTime: metav1.NewTime(start)}, wpa.Status.CapacityCeiling, "the lowest value is used")
+
+	r.pollCapacityCeiling(logger, wpa, start.Add(29*time.Second))
+	require.Equal(t, 1, polls, "the endpoint is polled once per interval")
+
+	status = http.StatusServiceUnavailable
+	r.pollCapacityCeiling(logger, wpa, start.Add(30*time.Second))
+	require.Equal(t, 2, polls)
+	require.Equal(t, int32(8), wpa.Status.CapacityCeiling.MaxReplicas, "the last ceiling is kept while the endpoint fails")
+	require.Contains(t, <-recorder.Events, "FailedGetCapacityCeiling")
+
+	r.pollCapacityCeiling(logger, wpa, start.Add(89*time.Second))
+	require.Equal(t, int32(8), wpa.Status.CapacityCeiling.MaxReplicas)
+	r.pollCapacityCeiling(logger, wpa, start.Add(90*time.Second))
+	require.Nil(t, wpa.Status.CapacityCeiling, "the last ceiling expires after 3 poll intervals")
+	require.Len(t, recorder.Events, 2)
+
+	wpa.Spec.CapacityCeiling = nil
+	r.pollCapacityCeiling(logger, wpa, start.Add(31*time.Second))
+	require.Nil(t, wpa.Status.CapacityCeiling)
+}
+
+func TestPollCapacityCeilingOverflow(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"maxReplicas": 5000000000}`))
+	}))
+	defer server.Close()
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			CapacityCeiling: &v1alpha1.CapacityCeilingSpec{HTTPMetricSource: v1alpha1.HTTPMetricSource{URL: server.URL, JSONPath: "{.maxReplicas}"}},
+		},
+	})
+	r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClient(), httpClient: server.Client(), eventRecorder: record.NewFakeRecorder(10)}
+
+	r.pollCapacityCeiling(logf.Log.WithName(t.Name()), wpa, time.Now())
+	require.Equal(t, int32(math.MaxInt32), wpa.Status.CapacityCeiling.MaxReplicas, "the ceiling is clamped rather than overflowing")
+}
+
+func TestCapDesiredReplicasWithCapacityCeiling(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{MinReplicas: v1alpha1.NewInt32(4), MaxReplicas: 20},
+	})
+	require.Equal(t, int32(15), capDesiredReplicasWithCapacityCeiling(logger, wpa, 15), "no ceiling polled yet")
+
+	wpa.Status.CapacityCeiling = &v1alpha1.CapacityCeilingStatus{MaxReplicas: 10}
+	require.Equal(t, int32(9), capDesiredReplicasWithCapacityCeiling(logger, wpa, 9))
+	require.False(t, isConditionTrue(wpa, autoscalingv2.ScalingLimited))
+	require.Equal(t, int32(10), capDesiredReplicasWithCapacityCeiling(logger, wpa, 15))
+	require.True(t, isConditionTrue(wpa, autoscalingv2.ScalingLimited))
+	require.Equal(t, "LimitedByCapacityCeiling", wpa.Status.Conditions[len(wpa.Status.Conditions)-1].Reason)
+
+	wpa.Status.CapacityCeiling.MaxReplicas = 2
+	require.Equal(t, int32(4), capDesiredReplicasWithCapacityCeiling(logger, wpa, 15), "the minReplicas take precedence")
+
+	wpa.Status.CapacityCeiling.MaxReplicas = 30
+	require.Equal(t, int32(20), capDesiredReplicasWithCapacityCeiling(logger, wpa, 20), "the maxReplicas is the binding constraint")
+}
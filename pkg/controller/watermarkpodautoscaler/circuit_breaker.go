@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	metricsProviderCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "MetricsProviderAvailable"
+
+	externalMetricsAPI = "external"
+	resourceMetricsAPI = "resource"
+)
+
+var (
+	metricsProviderFailureThreshold int
+	metricsProviderCoolOff          time.Duration
+
+	// errCircuitOpen is returned instead of querying a metrics API while its circuit breaker is open.
+	errCircuitOpen = errors.New("circuit breaker open")
+)
+
+func init() {
+	flag.IntVar(&metricsProviderFailureThreshold, "metrics-provider-failure-threshold", 5, "Number of consecutive failures of a metrics API after which it is no longer queried for a cool-off period, 0 to always query it")
+	flag.DurationVar(&metricsProviderCoolOff, "metrics-provider-cool-off", 30*time.Second, "Duration during which a failing metrics API is no longer queried")
+}
+
+// circuitBreaker stops the queries to a metrics API of a provider after consecutive failures, so that hundreds of
+// WPAs don't flood a failing provider with doomed requests. Once the cool-off is over, queries are let through
+// again, and the first failure opens the circuit again.
+type circuitBreaker struct {
+	api       string
+	provider  string
+	threshold int
+	coolOff   time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// newCircuitBreaker returns the circuit breaker of the API of the provider, the API server serving it.
+func newCircuitBreaker(api, provider string, threshold int, coolOff time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		api:       api,
+		provider:  provider,
+		threshold: threshold,
+		coolOff:   coolOff,
+	}
+}
+
+// allow returns an error wrapping errCircuitOpen if the API should not be queried.
+func (b *circuitBreaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if now.Before(b.openUntil) {
+		return fmt.Errorf("%w after %d consecutive failures of the %s metrics API of %s, retrying in %v", errCircuitOpen, b.failures, b.api, b.provider, b.openUntil.Sub(now).Round(time.Second))
+	}
+	return nil
+}
+
+// record updates the state of the circuit with the result of a query. A metric that is not found is the mistake of
+// a WPA, not a failure of the provider, which answered.
+func (b *circuitBreaker) record(err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil || classifyMetricError(err) == metricNotFound {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		circuitOpen.With(prometheus.Labels{apiPromLabel: b.api, providerPromLabel: b.provider}).Set(0)
+		return
+	}
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = now.Add(b.coolOff)
+		circuitOpen.With(prometheus.Labels{apiPromLabel: b.api, providerPromLabel: b.provider}).Set(1)
+	}
+}
+
+// setMetricsProviderCondition reports an open circuit breaker in the conditions of the WPA. The condition is only
+// added once a circuit opened, and set back to true by the next successful query.
+func setMetricsProviderCondition(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, err error) {
+	if errors.Is(err, errCircuitOpen) {
+		setCondition(wpa, metricsProviderCondition, corev1.ConditionFalse, "CircuitBreakerOpen", "the metrics provider is not queried: %v", err)
+		return
+	}
+	if err != nil {
+		return
+	}
+	for _, condition := range wpa.Status.Conditions {
+		if condition.Type == metricsProviderCondition {
+			setCondition(wpa, metricsProviderCondition, corev1.ConditionTrue, "MetricsProviderAvailable", "the metrics provider returned the metrics")
+			return
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	failure := fmt.Errorf("unable to fetch metrics from external metrics API")
+	b := newCircuitBreaker(externalMetricsAPI, "https://10.0.0.1", 3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.allow(start))
+		b.record(failure, start)
+	}
+	b.record(nil, start)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.allow(start), "the failures should be consecutive")
+		b.record(failure, start)
+	}
+
+	err := b.allow(start.Add(30 * time.Second))
+	require.True(t, errors.Is(err, errCircuitOpen))
+	require.Equal(t, "circuit breaker open after 3 consecutive failures of the external metrics API of https://10.0.0.1, retrying in 30s", err.Error())
+
+	require.NoError(t, b.allow(start.Add(time.Minute)), "the API should be queried again after the cool-off")
+	b.record(failure, start.Add(time.Minute))
+	require.Error(t, b.allow(start.Add(time.Minute+time.Second)), "a single failure should open the circuit again")
+
+	b.record(nil, start.Add(2*time.Minute))
+	require.NoError(t, b.allow(start.Add(2*time.Minute)))
+
+	notFound := newCircuitBreaker(externalMetricsAPI, "https://10.0.0.1", 1, time.Minute)
+	notFound.record(fmt.Errorf("unable to get external metric: no metrics returned from external metrics API"), start)
+	require.NoError(t, notFound.allow(start), "a metric that is not found should not open the circuit")
+
+	disabled := newCircuitBreaker(externalMetricsAPI, "", 0, time.Minute)
+	for i := 0; i < 10; i++ {
+		disabled.record(failure, start)
+	}
+	require.NoError(t, disabled.allow(start))
+}
+
+func TestSetMetricsProviderCondition(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+
+	setMetricsProviderCondition(wpa, nil)
+	setMetricsProviderCondition(wpa, fmt.Errorf("no metrics returned from external metrics API"))
+	require.Empty(t, wpa.Status.Conditions, "the condition should only be added once a circuit opened")
+
+	setMetricsProviderCondition(wpa, fmt.Errorf("unable to get external metric: %w", errCircuitOpen))
+	require.Len(t, wpa.Status.Conditions, 1)
+	require.Equal(t, metricsProviderCondition, wpa.Status.Conditions[0].Type)
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+
+	setMetricsProviderCondition(wpa, nil)
+	require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status)
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"flag"
+	"fmt"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+)
+
+var (
+	defaultTolerance                       float64
+	defaultDownscaleForbiddenWindowSeconds int
+	defaultUpscaleForbiddenWindowSeconds   int
+	defaultScaleUpLimitFactor              float64
+	defaultScaleDownLimitFactor            float64
+	defaultReadinessDelaySeconds           int
+)
+
+func init() {
+	flag.Float64Var(&defaultTolerance, "default-tolerance", 0, "Tolerance of the WPAs not setting one, 0 for the built-in default")
+	flag.IntVar(&defaultDownscaleForbiddenWindowSeconds, "default-downscale-forbidden-window-seconds", 0, "downscaleForbiddenWindowSeconds of the WPAs not setting one, 0 for the built-in default")
+	flag.IntVar(&defaultUpscaleForbiddenWindowSeconds, "default-upscale-forbidden-window-seconds", 0, "upscaleForbiddenWindowSeconds of the WPAs not setting one, 0 for the built-in default")
+	flag.Float64Var(&defaultScaleUpLimitFactor, "default-scale-up-limit-factor", 0, "scaleUpLimitFactor of the WPAs not setting one, 0 for the built-in default")
+	flag.Float64Var(&defaultScaleDownLimitFactor, "default-scale-down-limit-factor", 0, "scaleDownLimitFactor of the WPAs not setting one, 0 for the built-in default")
+	flag.IntVar(&defaultReadinessDelaySeconds, "default-readiness-delay-seconds", 0, "readinessDelay of the WPAs not setting one, 0 to count the pods as ready as soon as they are")
+}
+
+// checkControllerDefaults validates the defaults of the controller against the bounds of the settings of the WPAs,
+// so that an invalid flag fails the startup rather than every WPA it applies to.
+func checkControllerDefaults() error {
+	if defaultTolerance < 0 || defaultTolerance >= 1 {
+		return fmt.Errorf("--default-tolerance should be in ]0, 1[, or 0 for the built-in default, currently %v", defaultTolerance)
+	}
+	if defaultDownscaleForbiddenWindowSeconds < 0 || defaultUpscaleForbiddenWindowSeconds < 0 {
+		return fmt.Errorf("--default-downscale-forbidden-window-seconds and --default-upscale-forbidden-window-seconds should be positive, currently %d and %d", defaultDownscaleForbiddenWindowSeconds, defaultUpscaleForbiddenWindowSeconds)
+	}
+	if f := defaultScaleUpLimitFactor; f != 0 && (f < 1 || f > 100) {
+		return fmt.Errorf("--default-scale-up-limit-factor should be between 1 and 100, or 0 for the built-in default, currently %v", f)
+	}
+	if f := defaultScaleDownLimitFactor; f != 0 && (f < 1 || f > 100) {
+		return fmt.Errorf("--default-scale-down-limit-factor should be between 1 and 100, or 0 for the built-in default, currently %v", f)
+	}
+	if defaultReadinessDelaySeconds < 0 {
+		return fmt.Errorf("--default-readiness-delay-seconds should be positive, currently %d", defaultReadinessDelaySeconds)
+	}
+	return nil
+}
+
+// withControllerDefaults returns a copy of the WPA with the settings neither it nor its policies set taken from the
+// defaults of the controller, so that the fleet-wide defaults are applied before the built-in ones.
+func withControllerDefaults(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) *datadoghqv1alpha1.WatermarkPodAutoscaler {
+	defaultWPA := wpa.DeepCopy()
+	applySpecDefaults(&defaultWPA.Spec, &datadoghqv1alpha1.WatermarkPodAutoscalerPolicyDefaults{
+		Tolerance:                       defaultTolerance,
+		DownscaleForbiddenWindowSeconds: int32(defaultDownscaleForbiddenWindowSeconds),
+		UpscaleForbiddenWindowSeconds:   int32(defaultUpscaleForbiddenWindowSeconds),
+		ScaleUpLimitFactor:              defaultScaleUpLimitFactor,
+		ScaleDownLimitFactor:            defaultScaleDownLimitFactor,
+	})
+	if defaultWPA.Spec.ReadinessDelaySeconds == 0 {
+		defaultWPA.Spec.ReadinessDelaySeconds = int32(defaultReadinessDelaySeconds)
+	}
+	return defaultWPA
+}
+
+// missingControllerDefaults returns the names of the options without a built-in default that the WPA doesn't set and
+// that fall back to the defaults of the controller.
+func missingControllerDefaults(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) []string {
+	var missing []string
+	if wpa.Spec.ReadinessDelaySeconds == 0 && defaultReadinessDelaySeconds > 0 {
+		missing = append(missing, "readinessDelay")
+	}
+	return missing
+}
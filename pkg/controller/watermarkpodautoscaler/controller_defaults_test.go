@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithControllerDefaults(t *testing.T) {
+	defer func(tolerance float64, downscaleWindow, readinessDelay int) {
+		defaultTolerance, defaultDownscaleForbiddenWindowSeconds, defaultReadinessDelaySeconds = tolerance, downscaleWindow, readinessDelay
+	}(defaultTolerance, defaultDownscaleForbiddenWindowSeconds, defaultReadinessDelaySeconds)
+	defaultTolerance = 0.2
+	defaultDownscaleForbiddenWindowSeconds = 900
+	defaultReadinessDelaySeconds = 30
+
+	policies := []v1alpha1.WatermarkPodAutoscalerPolicy{
+		newTestPolicy("a", v1alpha1.WatermarkPodAutoscalerPolicySpec{
+			Defaults: &v1alpha1.WatermarkPodAutoscalerPolicyDefaults{DownscaleForbiddenWindowSeconds: 600},
+		}),
+	}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{ScaleUpLimitFactor: 10},
+	})
+	require.Equal(t, []string{"readinessDelay"}, missingControllerDefaults(wpa))
+
+	defaultWPA := v1alpha1.DefaultWatermarkPodAutoscaler(withControllerDefaults(withPolicyDefaults(wpa, policies)))
+
+	require.Equal(t, 0.2, defaultWPA.Spec.Tolerance)
+	require.Equal(t, int32(600), defaultWPA.Spec.DownscaleForbiddenWindowSeconds, "the policies should take precedence over the controller")
+	require.Equal(t, int32(30), defaultWPA.Spec.ReadinessDelaySeconds)
+	require.Equal(t, float64(10), defaultWPA.Spec.ScaleUpLimitFactor)
+	require.Equal(t, int32(60), defaultWPA.Spec.UpscaleForbiddenWindowSeconds, "the built-in defaults should apply last")
+	require.Empty(t, missingControllerDefaults(defaultWPA))
+	require.Equal(t, int32(0), wpa.Spec.ReadinessDelaySeconds)
+
+	defaultReadinessDelaySeconds = 0
+	require.Empty(t, missingControllerDefaults(wpa))
+}
+
+func TestCheckControllerDefaults(t *testing.T) {
+	defer func(tolerance, scaleUpLimitFactor float64, downscaleWindow int) {
+		defaultTolerance, defaultScaleUpLimitFactor, defaultDownscaleForbiddenWindowSeconds = tolerance, scaleUpLimitFactor, downscaleWindow
+	}(defaultTolerance, defaultScaleUpLimitFactor, defaultDownscaleForbiddenWindowSeconds)
+
+	require.NoError(t, checkControllerDefaults(), "the built-in defaults are kept")
+	defaultTolerance = 1.5
+	require.EqualError(t, checkControllerDefaults(), "--default-tolerance should be in ]0, 1[, or 0 for the built-in default, currently 1.5")
+	defaultTolerance = 0.2
+	defaultScaleUpLimitFactor = -1
+	require.Error(t, checkControllerDefaults())
+	defaultScaleUpLimitFactor = 10
+	defaultDownscaleForbiddenWindowSeconds = -60
+	require.Error(t, checkControllerDefaults())
+	defaultDownscaleForbiddenWindowSeconds = 600
+	require.NoError(t, checkControllerDefaults())
+}
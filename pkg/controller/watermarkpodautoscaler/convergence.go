@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+)
+
+const defaultConvergenceMinRatio = 2.0
+
+// stepDesiredReplicas only adds a fraction of the gap to the desired replicas when they are far above the current ones.
+// The next steps are taken by the following reconcile cycles, once the metrics are evaluated again.
+func stepDesiredReplicas(logger logr.Logger, convergence *datadoghqv1alpha1.SteppedConvergenceSpec, currentReplicas, desiredReplicas int32) int32 {
+	minRatio := convergence.MinRatio
+	if minRatio == 0 {
+		minRatio = defaultConvergenceMinRatio
+	}
+	if currentReplicas == 0 || float64(desiredReplicas) < minRatio*float64(currentReplicas) {
+		return desiredReplicas
+	}
+	stepped := currentReplicas + int32(math.Ceil(convergence.StepFraction*float64(desiredReplicas-currentReplicas)))
+	logger.Info("Stepping the upscale", "currentReplicas", currentReplicas, "desiredReplicas", desiredReplicas, "steppedReplicas", stepped)
+	return stepped
+}
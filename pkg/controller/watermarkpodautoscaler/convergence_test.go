@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/stretchr/testify/require"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestStepDesiredReplicas(t *testing.T) {
+	logger := logf.Log.WithName("TestStepDesiredReplicas")
+	tests := []struct {
+		name            string
+		convergence     v1alpha1.SteppedConvergenceSpec
+		currentReplicas int32
+		desiredReplicas int32
+		want            int32
+	}{
+		{
+			name:            "below the default ratio",
+			convergence:     v1alpha1.SteppedConvergenceSpec{StepFraction: 0.25},
+			currentReplicas: 10,
+			desiredReplicas: 19,
+			want:            19,
+		},
+		{
+			name:            "above the default ratio",
+			convergence:     v1alpha1.SteppedConvergenceSpec{StepFraction: 0.25},
+			currentReplicas: 10,
+			desiredReplicas: 200,
+			want:            58,
+		},
+		{
+			name:            "step rounded up",
+			convergence:     v1alpha1.SteppedConvergenceSpec{StepFraction: 0.1, MinRatio: 1.5},
+			currentReplicas: 10,
+			desiredReplicas: 15,
+			want:            11,
+		},
+		{
+			name:            "below a custom ratio",
+			convergence:     v1alpha1.SteppedConvergenceSpec{StepFraction: 0.5, MinRatio: 5},
+			currentReplicas: 10,
+			desiredReplicas: 40,
+			want:            40,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, stepDesiredReplicas(logger, &tt.convergence, tt.currentReplicas, tt.desiredReplicas))
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	sparklineWidth  = 120
+	sparklineHeight = 24
+)
+
+var dashboardBindAddress string
+
+func init() {
+	flag.StringVar(&dashboardBindAddress, "dashboard-bind-address", "", "Address the read-only web dashboard of the WPAs binds to, e.g. :8083, disabled when empty")
+}
+
+// dashboardWPA is a row of the dashboard.
+type dashboardWPA struct {
+	Namespace       string
+	Name            string
+	Target          string
+	CurrentReplicas int32
+	DesiredReplicas int32
+	MinReplicas     int32
+	MaxReplicas     int32
+	Metrics         []datadoghqv1alpha1.MetricDetailStatus
+	// Sparkline holds the points of the SVG polyline of the replicas of the last reconciles, empty with less than 2.
+	Sparkline  string
+	Conditions []dashboardCondition
+	Cooldowns  []dashboardCooldown
+}
+
+type dashboardCondition struct {
+	Type     string
+	Status   corev1.ConditionStatus
+	Reason   string
+	Message  string
+	Limiting bool
+}
+
+// dashboardCooldown is the time left until the WPA is allowed to scale in a direction again.
+type dashboardCooldown struct {
+	Direction string
+	Remaining time.Duration
+}
+
+// dashboardServer serves a read-only HTML page listing the WPAs, the values of their metrics against the watermarks,
+// the replicas of their last reconciles, their conditions and their forbidden windows. ?namespace= filters the WPAs.
+type dashboardServer struct {
+	address    string
+	reconciler *ReconcileWatermarkPodAutoscaler
+}
+
+func (s *dashboardServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+	namespace := req.URL.Query().Get("namespace")
+	wpas, err := s.list(namespace, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to list the WPAs: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err = dashboardTemplate.Execute(w, map[string]interface{}{"Namespace": namespace, "WPAs": wpas, "Refresh": int(defaultSyncPeriod.Seconds())}); err != nil {
+		log.Error(err, "Unable to render the dashboard")
+	}
+}
+
+// list returns the rows of the WPAs of the namespace reconciled by this controller, of all the namespaces when it is
+// empty, sorted by namespace and name.
+func (s *dashboardServer) list(namespace string, now time.Time) ([]dashboardWPA, error) {
+	wpaList := &datadoghqv1alpha1.WatermarkPodAutoscalerList{}
+	if err := s.reconciler.client.List(context.TODO(), wpaList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	policies, err := s.reconciler.listPolicies()
+	if err != nil {
+		// the forbidden windows of the WPAs are then the ones of the controller
+		log.Info("Unable to list the WatermarkPodAutoscalerPolicies for the dashboard", "error", err)
+	}
+	timelines := s.reconciler.timeline.get(namespace)
+	var wpas []dashboardWPA
+	for i := range wpaList.Items {
+		wpa := &wpaList.Items[i]
+		if !util.OwnsNamespace(wpa.Namespace) {
+			continue
+		}
+		defaultWPA := datadoghqv1alpha1.DefaultWatermarkPodAutoscaler(withControllerDefaults(withPolicyDefaults(wpa, policies)))
+		row := dashboardWPA{
+			Namespace:       wpa.Namespace,
+			Name:            wpa.Name,
+			Target:          fmt.Sprintf("%s/%s", wpa.Spec.ScaleTargetRef.Kind, wpa.Spec.ScaleTargetRef.Name),
+			CurrentReplicas: wpa.Status.CurrentReplicas,
+			DesiredReplicas: wpa.Status.DesiredReplicas,
+			MaxReplicas:     defaultWPA.Spec.MaxReplicas,
+			Metrics:         wpa.Status.MetricDetails,
+			Sparkline:       sparkline(timelines[types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}.String()]),
+			Cooldowns:       cooldowns(defaultWPA, now),
+		}
+		if defaultWPA.Spec.MinReplicas != nil {
+			row.MinReplicas = *defaultWPA.Spec.MinReplicas
+		}
+		for _, cond := range wpa.Status.Conditions {
+			limitingStatus := corev1.ConditionTrue
+			if healthyWhenTrue[cond.Type] {
+				limitingStatus = corev1.ConditionFalse
+			}
+			row.Conditions = append(row.Conditions, dashboardCondition{
+				Type:     string(cond.Type),
+				Status:   cond.Status,
+				Reason:   cond.Reason,
+				Message:  cond.Message,
+				Limiting: cond.Status == limitingStatus,
+			})
+		}
+		wpas = append(wpas, row)
+	}
+	sort.Slice(wpas, func(i, j int) bool {
+		if wpas[i].Namespace != wpas[j].Namespace {
+			return wpas[i].Namespace < wpas[j].Namespace
+		}
+		return wpas[i].Name < wpas[j].Name
+	})
+	return wpas, nil
+}
+
+// cooldowns returns the forbidden windows of the WPA still running since its last scale event.
+func cooldowns(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) []dashboardCooldown {
+	if wpa.Status.LastScaleTime == nil {
+		return nil
+	}
+	var result []dashboardCooldown
+	for _, window := range []struct {
+		direction string
+		seconds   int32
+	}{
+		{"upscale", wpa.Spec.UpscaleForbiddenWindowSeconds},
+		{"downscale", wpa.Spec.DownscaleForbiddenWindowSeconds},
+	} {
+		remaining := wpa.Status.LastScaleTime.Add(time.Duration(window.seconds) * time.Second).Sub(now)
+		if remaining > 0 {
+			result = append(result, dashboardCooldown{Direction: window.direction, Remaining: remaining.Round(time.Second)})
+		}
+	}
+	return result
+}
+
+// sparkline returns the points of the polyline of the applied replicas of the entries, scaled to the size of the
+// sparkline.
+func sparkline(entries []timelineEntry) string {
+	if len(entries) < 2 {
+		return ""
+	}
+	lowest, highest := entries[0].AppliedReplicas, entries[0].AppliedReplicas
+	for _, entry := range entries {
+		if entry.AppliedReplicas < lowest {
+			lowest = entry.AppliedReplicas
+		}
+		if entry.AppliedReplicas > highest {
+			highest = entry.AppliedReplicas
+		}
+	}
+	points := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		x := float64(i) * sparklineWidth / float64(len(entries)-1)
+		// a constant number of replicas is drawn in the middle
+		y := float64(sparklineHeight) / 2
+		if highest > lowest {
+			y = sparklineHeight - float64(entry.AppliedReplicas-lowest)*sparklineHeight/float64(highest-lowest)
+		}
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return strings.Join(points, " ")
+}
+
+// Start implements manager.Runnable, it serves the dashboard until the stop channel is closed.
+func (s *dashboardServer) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.Handle("/", s)
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: mux}
+	go func() {
+		<-stop
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Error(err, "Unable to shut down the dashboard")
+		}
+	}()
+	log.Info("Serving the dashboard", "address", s.address)
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<title>WatermarkPodAutoscalers</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+.metrics td { border: none; padding: 0 8px 0 0; }
+.out { color: #c0392b; font-weight: bold; }
+.limiting { color: #c0392b; }
+.ok { color: #777; }
+polyline { fill: none; stroke: #632ca6; stroke-width: 1.5; }
+</style>
+</head>
+<body>
+<h1>WatermarkPodAutoscalers{{if .Namespace}} in {{.Namespace}}{{end}}</h1>
+<table>
+<tr><th>WPA</th><th>Target</th><th>Replicas</th><th>Metrics</th><th>History</th><th>Conditions</th><th>Cooldowns</th></tr>
+{{- range .WPAs}}
+<tr>
+<td><a href="?namespace={{.Namespace}}">{{.Namespace}}</a>/{{.Name}}</td>
+<td>{{.Target}}</td>
+<td>{{.CurrentReplicas}} &rarr; {{.DesiredReplicas}}<br><span class="ok">[{{.MinReplicas}}, {{.MaxReplicas}}]</span></td>
+<td><table class="metrics">
+{{- range .Metrics}}
+<tr><td>{{.Name}}</td><td{{if not .WithinBounds}} class="out"{{end}}>{{.AdjustedValue.String}}</td><td class="ok">[{{.EffectiveLowWatermark.String}}, {{.EffectiveHighWatermark.String}}]</td></tr>
+{{- end}}
+</table></td>
+<td>{{if .Sparkline}}<svg width="120" height="24" viewBox="0 -1 120 26"><polyline points="{{.Sparkline}}"/></svg>{{end}}</td>
+<td>
+{{- range .Conditions}}
+<div class="{{if .Limiting}}limiting{{else}}ok{{end}}" title="{{.Message}}">{{.Type}}={{.Status}}{{if .Reason}} ({{.Reason}}){{end}}</div>
+{{- end}}
+</td>
+<td>
+{{- range .Cooldowns}}
+<div>{{.Direction}} in {{.Remaining}}</div>
+{{- end}}
+</td>
+</tr>
+{{- else}}
+<tr><td colspan="7">No WPA</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDashboard(t *testing.T) {
+	// the fake client keeps the times to the second
+	now := time.Now().Truncate(time.Second)
+	lastScale := metav1.NewTime(now.Add(-30 * time.Second))
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, "b", &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef:                  v1alpha1.CrossVersionObjectReference{Kind: "Deployment", Name: "app"},
+			MinReplicas:                     v1alpha1.NewInt32(2),
+			MaxReplicas:                     12,
+			UpscaleForbiddenWindowSeconds:   60,
+			DownscaleForbiddenWindowSeconds: 300,
+		},
+		Status: &v1alpha1.WatermarkPodAutoscalerStatus{
+			LastScaleTime:   &lastScale,
+			CurrentReplicas: 5,
+			DesiredReplicas: 5,
+			MetricDetails: []v1alpha1.MetricDetailStatus{
+				{Name: "queue.lag", AdjustedValue: resource.MustParse("120"), EffectiveLowWatermark: resource.MustParse("50"), EffectiveHighWatermark: resource.MustParse("100")},
+			},
+		},
+	})
+	setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "BackoffUpscale", "<too early>")
+	setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionTrue, "ValidMetricFound", "")
+	other := test.NewWatermarkPodAutoscaler(testingNamespace, "a", nil)
+	elsewhere := test.NewWatermarkPodAutoscaler("other", "c", nil)
+
+	s := runtime.NewScheme()
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{}, &v1alpha1.WatermarkPodAutoscalerList{}, &v1alpha1.WatermarkPodAutoscalerPolicy{}, &v1alpha1.WatermarkPodAutoscalerPolicyList{})
+	r := &ReconcileWatermarkPodAutoscaler{
+		client:   fake.NewFakeClientWithScheme(s, wpa, other, elsewhere),
+		timeline: newRecommendationTimeline(10),
+	}
+	r.recordTimeline(wpa, decisionWithinBounds, true, 3, 3)
+	r.recordTimeline(wpa, decisionScaledUp, true, 5, 5)
+	dashboard := &dashboardServer{reconciler: r}
+
+	wpas, err := dashboard.list(testingNamespace, now)
+	require.NoError(t, err)
+	require.Len(t, wpas, 2, "only the WPAs of the namespace are listed")
+	require.Equal(t, "a", wpas[0].Name, "the WPAs are sorted by name")
+	require.Empty(t, wpas[0].Sparkline, "a WPA without history has no sparkline")
+
+	row := wpas[1]
+	require.Equal(t, "Deployment/app", row.Target)
+	require.Equal(t, int32(2), row.MinReplicas)
+	require.Equal(t, int32(12), row.MaxReplicas)
+	require.Equal(t, "0.0,24.0 120.0,0.0", row.Sparkline)
+	require.Equal(t, []dashboardCondition{
+		{Type: "AbleToScale", Status: corev1.ConditionFalse, Reason: "BackoffUpscale", Message: "<too early>", Limiting: true},
+		{Type: "ScalingActive", Status: corev1.ConditionTrue, Reason: "ValidMetricFound"},
+	}, row.Conditions)
+	require.Equal(t, []dashboardCooldown{{Direction: "upscale", Remaining: 30 * time.Second}, {Direction: "downscale", Remaining: 270 * time.Second}}, row.Cooldowns)
+
+	rec := httptest.NewRecorder()
+	dashboard.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	require.Contains(t, body, "other</a>/c")
+	require.Contains(t, body, `<td class="out">120</td>`, "the metrics out of their watermarks are highlighted")
+	require.Contains(t, body, "&lt;too early&gt;", "the messages are escaped")
+	require.Contains(t, body, "downscale in 4m")
+
+	rec = httptest.NewRecorder()
+	dashboard.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	rec = httptest.NewRecorder()
+	dashboard.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The outcomes of the reconciles, exported as the decision label of the decisions counter.
+const (
+	decisionScaledUp     = "scaled_up"
+	decisionScaledDown   = "scaled_down"
+	decisionWithinBounds = "within_bounds"
+	decisionBackoffUp    = "backoff_up"
+	decisionBackoffDown  = "backoff_down"
+	decisionCappedMax    = "capped_max"
+	decisionCappedMin    = "capped_min"
+	decisionMetricError  = "metric_error"
+	decisionDryRun       = "dry_run"
+)
+
+var scalingDecisions = []string{
+	decisionScaledUp,
+	decisionScaledDown,
+	decisionWithinBounds,
+	decisionBackoffUp,
+	decisionBackoffDown,
+	decisionCappedMax,
+	decisionCappedMin,
+	decisionMetricError,
+	decisionDryRun,
+}
+
+// scalingDecision returns the outcome of a reconcile given the replicas proposed by the algorithm, if any, and the
+// replicas the target is scaled to when rescale is true.
+func scalingDecision(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, proposed bool, proposedReplicas, currentReplicas, desiredReplicas int32, rescale bool) string {
+	switch {
+	case desiredReplicas == currentReplicas && proposed && proposedReplicas > wpa.Spec.MaxReplicas:
+		return decisionCappedMax
+	case desiredReplicas == currentReplicas && proposed && wpa.Spec.MinReplicas != nil && proposedReplicas < *wpa.Spec.MinReplicas:
+		return decisionCappedMin
+	case desiredReplicas == currentReplicas:
+		return decisionWithinBounds
+	case rescale && wpa.Spec.DryRun:
+		return decisionDryRun
+	case rescale && desiredReplicas > currentReplicas:
+		return decisionScaledUp
+	case rescale:
+		return decisionScaledDown
+	case desiredReplicas > currentReplicas:
+		return decisionBackoffUp
+	default:
+		return decisionBackoffDown
+	}
+}
+
+func countDecision(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, decision string) {
+	decisions.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, decisionPromLabel: decision, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Inc()
+}
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	defaultDecisionHistoryMaxDecisions = 100
+	defaultDecisionHistoryMaxSizeBytes = 256 * 1024
+	// decisionHistoryKeyFormat names the keys of the ConfigMap after the time of the decisions, so that they sort by time.
+	decisionHistoryKeyFormat = "20060102T150405.000Z.json"
+)
+
+// recordedDecision is a scaling decision persisted to the decision history of a WPA.
+type recordedDecision struct {
+	Timestamp       time.Time `json:"timestamp"`
+	CurrentReplicas int32     `json:"currentReplicas"`
+	// ProposedReplicas is nil when the algorithm didn't propose a number of replicas.
+	ProposedReplicas *int32   `json:"proposedReplicas,omitempty"`
+	DesiredReplicas  int32    `json:"desiredReplicas"`
+	Decision         string   `json:"decision"`
+	LimitingReasons  []string `json:"limitingReasons,omitempty"`
+}
+
+// decisionOutcome is what identifies a decision of a WPA, the consecutive reconciles with the same outcome being
+// persisted once.
+type decisionOutcome struct {
+	decision        string
+	currentReplicas int32
+	desiredReplicas int32
+}
+
+// decisionHistories keeps the outcome of the last decision persisted for the WPAs with a decision history.
+type decisionHistories struct {
+	mu       sync.Mutex
+	outcomes map[types.NamespacedName]decisionOutcome
+}
+
+func newDecisionHistories() *decisionHistories {
+	return &decisionHistories{outcomes: map[types.NamespacedName]decisionOutcome{}}
+}
+
+// changed records the outcome of a decision and returns whether it differs from the previous one.
+func (d *decisionHistories) changed(key types.NamespacedName, outcome decisionOutcome) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	previous, found := d.outcomes[key]
+	d.outcomes[key] = outcome
+	return !found || previous != outcome
+}
+
+func (d *decisionHistories) forget(key types.NamespacedName) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.outcomes, key)
+}
+
+// recordDecisionHistory persists the decision to the ConfigMap of the WPA with a decision history, unless it has the
+// same outcome as the previous reconcile, so that a WPA holding its replicas doesn't write at every sync period.
+func (r *ReconcileWatermarkPodAutoscaler) recordDecisionHistory(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, decision string, proposed bool, proposedReplicas, currentReplicas, desiredReplicas int32, now time.Time) {
+	if r.decisionHistories == nil {
+		return
+	}
+	key := types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}
+	if wpa.Spec.DecisionHistory == nil {
+		r.decisionHistories.forget(key)
+		return
+	}
+	if !r.decisionHistories.changed(key, decisionOutcome{decision: decision, currentReplicas: currentReplicas, desiredReplicas: desiredReplicas}) {
+		return
+	}
+	record := recordedDecision{
+		Timestamp:       now,
+		CurrentReplicas: currentReplicas,
+		DesiredReplicas: desiredReplicas,
+		Decision:        decision,
+		LimitingReasons: limitingReasons(wpa),
+	}
+	if proposed {
+		record.ProposedReplicas = &proposedReplicas
+	}
+	if err := r.writeDecisionHistory(wpa, record); err != nil {
+		// the decision is persisted again at the next reconcile
+		r.decisionHistories.forget(key)
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedDecisionHistory", "unable to persist the %s decision: %v", decision, err)
+		logger.Info("Unable to persist the decision", "decision", decision, "error", err)
+	}
+}
+
+// writeDecisionHistory adds the decision to the ConfigMap of the WPA, and removes the oldest decisions beyond
+// maxDecisions or maxSizeBytes.
+func (r *ReconcileWatermarkPodAutoscaler) writeDecisionHistory(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, record recordedDecision) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	key := record.Timestamp.UTC().Format(decisionHistoryKeyFormat)
+	return r.writeOwnedConfigMap(wpa, decisionHistoryConfigMapName(wpa), key, string(data), func(data map[string]string) {
+		rotateDecisionHistory(data, wpa.Spec.DecisionHistory)
+	})
+}
+
+// rotateDecisionHistory removes the oldest decisions until at most maxDecisions are kept, and their size is within
+// maxSizeBytes. The latest decision is always kept.
+func rotateDecisionHistory(data map[string]string, spec *datadoghqv1alpha1.DecisionHistorySpec) {
+	maxDecisions := defaultDecisionHistoryMaxDecisions
+	if spec.MaxDecisions > 0 {
+		maxDecisions = int(spec.MaxDecisions)
+	}
+	maxSize := defaultDecisionHistoryMaxSizeBytes
+	if spec.MaxSizeBytes > 0 {
+		maxSize = int(spec.MaxSizeBytes)
+	}
+	keys := make([]string, 0, len(data))
+	size := 0
+	for k, v := range data {
+		keys = append(keys, k)
+		size += len(k) + len(v)
+	}
+	sort.Strings(keys)
+	for i := 0; i < len(keys)-1 && (len(keys)-i > maxDecisions || size > maxSize); i++ {
+		size -= len(keys[i]) + len(data[keys[i]])
+		delete(data, keys[i])
+	}
+}
+
+func decisionHistoryConfigMapName(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) string {
+	if wpa.Spec.DecisionHistory.ConfigMapName != "" {
+		return wpa.Spec.DecisionHistory.ConfigMapName
+	}
+	return wpa.Name + "-decision-history"
+}
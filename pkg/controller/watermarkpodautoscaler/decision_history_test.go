@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestRotateDecisionHistory(t *testing.T) {
+	data := map[string]string{"a.json": "1", "b.json": "2", "c.json": "3", "d.json": "4"}
+	rotateDecisionHistory(data, &v1alpha1.DecisionHistorySpec{MaxDecisions: 2})
+	require.Equal(t, map[string]string{"c.json": "3", "d.json": "4"}, data, "the oldest decisions are removed beyond maxDecisions")
+
+	data = map[string]string{"a.json": strings.Repeat("1", 600), "b.json": strings.Repeat("2", 600), "c.json": strings.Repeat("3", 600)}
+	rotateDecisionHistory(data, &v1alpha1.DecisionHistorySpec{MaxSizeBytes: 1024})
+	require.Len(t, data, 1, "the oldest decisions are removed beyond maxSizeBytes")
+	require.Contains(t, data, "c.json")
+
+	data = map[string]string{"a.json": strings.Repeat("1", 2048)}
+	rotateDecisionHistory(data, &v1alpha1.DecisionHistorySpec{MaxSizeBytes: 1024})
+	require.Len(t, data, 1, "the latest decision is always kept")
+}
+
+func TestRecordDecisionHistory(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, "test", &test.NewWatermarkPodAutoscalerOptions{})
+	wpa.Spec.DecisionHistory = &v1alpha1.DecisionHistorySpec{MaxDecisions: 2}
+
+	s := runtime.NewScheme()
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.ConfigMap{})
+	r := &ReconcileWatermarkPodAutoscaler{
+		client:            fake.NewFakeClientWithScheme(s),
+		eventRecorder:     record.NewFakeRecorder(10),
+		decisionHistories: newDecisionHistories(),
+	}
+	getDecisions := func() map[string]string {
+		configMap := &corev1.ConfigMap{}
+		require.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Namespace: testingNamespace, Name: "test-decision-history"}, configMap))
+		require.Len(t, configMap.OwnerReferences, 1, "the ConfigMap is garbage collected with the WPA")
+		return configMap.Data
+	}
+
+	r.recordDecisionHistory(logf.Log, wpa, decisionScaledUp, true, 8, 5, 8, start)
+	decisions := getDecisions()
+	require.Len(t, decisions, 1)
+	decision := &recordedDecision{}
+	require.NoError(t, json.Unmarshal([]byte(decisions["20200101T120000.000Z.json"]), decision))
+	require.Equal(t, decisionScaledUp, decision.Decision)
+	require.Equal(t, int32(8), *decision.ProposedReplicas)
+	require.Equal(t, int32(5), decision.CurrentReplicas)
+	require.Equal(t, int32(8), decision.DesiredReplicas)
+
+	r.recordDecisionHistory(logf.Log, wpa, decisionWithinBounds, false, 0, 8, 8, start.Add(time.Minute))
+	r.recordDecisionHistory(logf.Log, wpa, decisionWithinBounds, false, 0, 8, 8, start.Add(2*time.Minute))
+	decisions = getDecisions()
+	require.Len(t, decisions, 2, "the consecutive decisions with the same outcome are persisted once")
+	require.Contains(t, decisions, "20200101T120100.000Z.json")
+
+	r.recordDecisionHistory(logf.Log, wpa, decisionScaledDown, true, 6, 8, 6, start.Add(3*time.Minute))
+	decisions = getDecisions()
+	require.Len(t, decisions, 2, "the oldest decisions are removed beyond maxDecisions")
+	require.Contains(t, decisions, "20200101T120100.000Z.json")
+	require.Contains(t, decisions, "20200101T120300.000Z.json")
+
+	require.NoError(t, r.client.Create(context.TODO(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "app-config"}}))
+	wpa.Spec.DecisionHistory.ConfigMapName = "app-config"
+	r.recordDecisionHistory(logf.Log, wpa, decisionScaledUp, true, 8, 6, 8, start.Add(4*time.Minute))
+	require.Contains(t, <-r.eventRecorder.(*record.FakeRecorder).Events, "FailedDecisionHistory", "the ConfigMaps not controlled by the WPA are left untouched")
+
+	wpa.Spec.DecisionHistory = nil
+	r.recordDecisionHistory(logf.Log, wpa, decisionWithinBounds, false, 0, 6, 6, start.Add(5*time.Minute))
+	require.Empty(t, r.decisionHistories.outcomes, "the last outcome is dropped when the decision history is disabled")
+}
@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScalingDecision(t *testing.T) {
+	tests := []struct {
+		name             string
+		dryRun           bool
+		proposed         bool
+		proposedReplicas int32
+		currentReplicas  int32
+		desiredReplicas  int32
+		rescale          bool
+		want             string
+	}{
+		{name: "upscale", proposed: true, proposedReplicas: 6, currentReplicas: 4, desiredReplicas: 6, rescale: true, want: decisionScaledUp},
+		{name: "downscale", proposed: true, proposedReplicas: 2, currentReplicas: 4, desiredReplicas: 2, rescale: true, want: decisionScaledDown},
+		{name: "unchanged", proposed: true, proposedReplicas: 4, currentReplicas: 4, desiredReplicas: 4, want: decisionWithinBounds},
+		{name: "upscale in the forbidden window", proposed: true, proposedReplicas: 6, currentReplicas: 4, desiredReplicas: 6, want: decisionBackoffUp},
+		{name: "downscale in the forbidden window", proposed: true, proposedReplicas: 2, currentReplicas: 4, desiredReplicas: 2, want: decisionBackoffDown},
+		{name: "already at maxReplicas", proposed: true, proposedReplicas: 12, currentReplicas: 10, desiredReplicas: 10, want: decisionCappedMax},
+		{name: "already at minReplicas", proposed: true, proposedReplicas: 1, currentReplicas: 2, desiredReplicas: 2, want: decisionCappedMin},
+		{name: "above maxReplicas", currentReplicas: 12, desiredReplicas: 10, rescale: true, want: decisionScaledDown},
+		{name: "dry run", dryRun: true, proposed: true, proposedReplicas: 6, currentReplicas: 4, desiredReplicas: 6, rescale: true, want: decisionDryRun},
+		{name: "dry run without change", dryRun: true, proposed: true, proposedReplicas: 4, currentReplicas: 4, desiredReplicas: 4, rescale: true, want: decisionWithinBounds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+					MinReplicas: getReplicas(2),
+					MaxReplicas: 10,
+					DryRun:      tt.dryRun,
+				},
+			})
+			require.Equal(t, tt.want, scalingDecision(wpa, tt.proposed, tt.proposedReplicas, tt.currentReplicas, tt.desiredReplicas, tt.rescale))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"sort"
+	"strconv"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	metricsclient "k8s.io/kubernetes/pkg/controller/podautoscaler/metrics"
+)
+
+const (
+	// podDeletionCostAnnotation is used by the ReplicaSet controller to pick the pods to remove first when downscaling.
+	podDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+)
+
+// isDeletionCostCovered returns whether all the pods of the target carry the pod-deletion-cost annotation.
+// If they don't, the AbleToScale condition of the WPA is updated to reflect that the downscale is delayed.
+func (r *ReconcileWatermarkPodAutoscaler) isDeletionCostCovered(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale) bool {
+	selector, err := labels.Parse(scale.Status.Selector)
+	if err != nil {
+		logger.Error(err, "Could not parse the labels of the target")
+		return false
+	}
+	pods, err := r.podLister.Pods(wpa.Namespace).List(selector)
+	if err != nil {
+		logger.Error(err, "Could not list the pods of the target")
+		return false
+	}
+
+	missing := countPodsMissingDeletionCost(pods)
+	if missing > 0 {
+		setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "WaitingForDeletionCost", "%d out of %d pods are missing the %s annotation", missing, len(pods), podDeletionCostAnnotation)
+		logger.Info("Delaying downscale until all the pods have a deletion cost", "missing", missing, "pods", len(pods))
+		return false
+	}
+	return true
+}
+
+func countPodsMissingDeletionCost(pods []*corev1.Pod) int {
+	missing := 0
+	for _, pod := range pods {
+		// Pods being deleted are not considered by the ReplicaSet controller.
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, found := pod.Annotations[podDeletionCostAnnotation]; !found {
+			missing++
+		}
+	}
+	return missing
+}
+
+// writeDeletionCosts annotates the pods with a deletion cost matching their rank when sorted by
+// increasing value of the metric, so that the least loaded pods are removed first when downscaling.
+// Ranks are used rather than the raw values as the annotation only accepts int32 values.
+func (r *ReconcileWatermarkPodAutoscaler) writeDeletionCosts(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, podMetrics metricsclient.PodMetricsInfo) {
+	if r.podAnnotator == nil {
+		return
+	}
+	skipped := 0
+	for name, cost := range rankPodsByValue(podMetrics) {
+		pod, err := r.podLister.Pods(wpa.Namespace).Get(name)
+		if err != nil {
+			logger.V(2).Info("Could not get pod to write its deletion cost", "pod", name, "error", err)
+			continue
+		}
+		written, err := r.podAnnotator.annotate(pod, podDeletionCostAnnotation, strconv.Itoa(cost))
+		if err != nil {
+			logger.Info("Could not write the deletion cost of the pod", "pod", name, "error", err)
+			continue
+		}
+		if !written {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		logger.Info("Deletion cost annotations were rate limited, they will be written during the next reconcile loops", "skipped", skipped)
+	}
+}
+
+// rankPodsByValue returns the rank of each pod when sorted by increasing value.
+func rankPodsByValue(podMetrics metricsclient.PodMetricsInfo) map[string]int {
+	names := make([]string, 0, len(podMetrics))
+	for name := range podMetrics {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if podMetrics[names[i]].Value == podMetrics[names[j]].Value {
+			return names[i] < names[j]
+		}
+		return podMetrics[names[i]].Value < podMetrics[names[j]].Value
+	})
+	ranks := make(map[string]int, len(names))
+	for i, name := range names {
+		ranks[name] = i
+	}
+	return ranks
+}
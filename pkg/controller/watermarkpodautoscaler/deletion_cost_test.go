@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsclient "k8s.io/kubernetes/pkg/controller/podautoscaler/metrics"
+)
+
+func TestCountPodsMissingDeletionCost(t *testing.T) {
+	annotated := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "annotated", Annotations: map[string]string{podDeletionCostAnnotation: "10"}}}
+	notAnnotated := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "not-annotated"}}
+	terminating := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "terminating", DeletionTimestamp: &metav1.Time{}}}
+
+	tests := []struct {
+		name string
+		pods []*corev1.Pod
+		want int
+	}{
+		{
+			name: "no pods",
+			pods: nil,
+			want: 0,
+		},
+		{
+			name: "all pods annotated",
+			pods: []*corev1.Pod{annotated, annotated},
+			want: 0,
+		},
+		{
+			name: "one pod missing the annotation",
+			pods: []*corev1.Pod{annotated, notAnnotated},
+			want: 1,
+		},
+		{
+			name: "terminating pods are not considered",
+			pods: []*corev1.Pod{annotated, terminating},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, countPodsMissingDeletionCost(tt.pods))
+		})
+	}
+}
+
+func TestRankPodsByValue(t *testing.T) {
+	podMetrics := metricsclient.PodMetricsInfo{
+		"busy":  metricsclient.PodMetric{Value: 900, Timestamp: time.Now()},
+		"idle":  metricsclient.PodMetric{Value: 10, Timestamp: time.Now()},
+		"b-avg": metricsclient.PodMetric{Value: 400, Timestamp: time.Now()},
+		"a-avg": metricsclient.PodMetric{Value: 400, Timestamp: time.Now()},
+	}
+	want := map[string]int{
+		"idle":  0,
+		"a-avg": 1,
+		"b-avg": 2,
+		"busy":  3,
+	}
+	assert.Equal(t, want, rankPodsByValue(podMetrics))
+}
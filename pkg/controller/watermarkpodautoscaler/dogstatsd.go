@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	sigmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	dogstatsdNamespace = "watermarkpodautoscaler."
+	// reconcileTimeMetric is the histogram of the durations of the reconciles kept by controller-runtime.
+	reconcileTimeMetric = "controller_runtime_reconcile_time_seconds"
+	// maximum sizes of the datagrams sent to the agent, as recommended for UDP and Unix sockets.
+	dogstatsdUDPPacketSize  = 1432
+	dogstatsdUnixPacketSize = 8192
+)
+
+var (
+	dogstatsdAddress       string
+	dogstatsdFlushInterval time.Duration
+	dogstatsdTags          string
+)
+
+func init() {
+	flag.StringVar(&dogstatsdAddress, "dogstatsd-address", "", "Address of the DogStatsD server the metrics of the WPAs are sent to, udp://<host>:<port> or unix://<socket path>, disabled when empty")
+	flag.DurationVar(&dogstatsdFlushInterval, "dogstatsd-flush-interval", 15*time.Second, "Interval between two flushes of the metrics of the WPAs to DogStatsD")
+	flag.StringVar(&dogstatsdTags, "dogstatsd-tags", "", "Tags added to all the metrics sent to DogStatsD, separated by commas, e.g. env:prod,cluster:main")
+}
+
+// dogstatsdSink sends the metrics of the controller to a DogStatsD server, typically the Datadog agent running on
+// the node, for the clusters without a Prometheus. The gauges are sent as gauges, and the counters and the count and
+// sum of the histograms as the increase since the previous flush.
+type dogstatsdSink struct {
+	network    string
+	address    string
+	packetSize int
+	interval   time.Duration
+	tags       []string
+	gatherer   prometheus.Gatherer
+	// previous are the values of the counters at the previous flush, keyed by metric name and tags.
+	previous map[string]float64
+}
+
+func newDogstatsdSink(rawAddress string, interval time.Duration, tags string) (*dogstatsdSink, error) {
+	u, err := url.Parse(rawAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DogStatsD address %s: %v", rawAddress, err)
+	}
+	s := &dogstatsdSink{interval: interval, gatherer: sigmetrics.Registry, previous: map[string]float64{}}
+	switch {
+	case u.Scheme == "udp" && u.Host != "":
+		s.network, s.address, s.packetSize = "udp", u.Host, dogstatsdUDPPacketSize
+	case u.Scheme == "unix" && u.Path != "":
+		s.network, s.address, s.packetSize = "unixgram", u.Path, dogstatsdUnixPacketSize
+	default:
+		return nil, fmt.Errorf("invalid DogStatsD address %s: udp://<host>:<port> or unix://<socket path> is expected", rawAddress)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid DogStatsD flush interval %s: it should be positive", interval)
+	}
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			s.tags = append(s.tags, tag)
+		}
+	}
+	return s, nil
+}
+
+// Start implements manager.Runnable, it flushes the metrics at every interval until the stop channel is closed.
+func (s *dogstatsdSink) Start(stop <-chan struct{}) error {
+	log.Info("Sending the metrics to DogStatsD", "network", s.network, "address", s.address, "interval", s.interval)
+	pushPeriodically(stop, s.interval, func(time.Time) {
+		if err := s.flush(); err != nil {
+			log.Error(err, "Unable to send the metrics to DogStatsD", "address", s.address)
+		}
+	})
+	return nil
+}
+
+// flush sends the current values of the metrics, the socket being opened at every flush so that the agent can be
+// restarted in between.
+func (s *dogstatsdSink) flush() error {
+	families, err := s.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("unable to gather the metrics: %v", err)
+	}
+	lines := s.lines(families)
+	if len(lines) == 0 {
+		return nil
+	}
+	conn, err := net.Dial(s.network, s.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for _, packet := range dogstatsdPackets(lines, s.packetSize) {
+		if _, err = conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lines returns the DogStatsD lines of the metrics of the controller, the ones of its subsystem and the durations
+// of the reconciles.
+func (s *dogstatsdSink) lines(families []*dto.MetricFamily) []string {
+	var lines []string
+	gauge := func(name string, tags []string, value float64) {
+		lines = append(lines, dogstatsdLine(name, value, "g", tags))
+	}
+	count := func(name string, tags []string, value float64) {
+		key := name + "|" + strings.Join(tags, ",")
+		delta := value - s.previous[key]
+		if delta < 0 {
+			// the counter was reset
+			delta = value
+		}
+		s.previous[key] = value
+		if delta != 0 {
+			lines = append(lines, dogstatsdLine(name, delta, "c", tags))
+		}
+	}
+	for _, family := range families {
+		name := family.GetName()
+		if !strings.HasPrefix(name, subsystem+"_") && name != reconcileTimeMetric {
+			continue
+		}
+		name = dogstatsdNamespace + name
+		for _, m := range family.GetMetric() {
+			tags := s.metricTags(m.GetLabel())
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				gauge(name, tags, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				gauge(name, tags, m.GetUntyped().GetValue())
+			case dto.MetricType_COUNTER:
+				count(name, tags, m.GetCounter().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				count(name+".count", tags, float64(m.GetHistogram().GetSampleCount()))
+				count(name+".sum", tags, m.GetHistogram().GetSampleSum())
+			case dto.MetricType_SUMMARY:
+				count(name+".count", tags, float64(m.GetSummary().GetSampleCount()))
+				count(name+".sum", tags, m.GetSummary().GetSampleSum())
+			}
+		}
+	}
+	return lines
+}
+
+func (s *dogstatsdSink) metricTags(pairs []*dto.LabelPair) []string {
+	tags := make([]string, 0, len(pairs)+len(s.tags))
+	for _, pair := range pairs {
+		tags = append(tags, pair.GetName()+":"+pair.GetValue())
+	}
+	tags = append(tags, s.tags...)
+	sort.Strings(tags)
+	return tags
+}
+
+// dogstatsdLine formats a metric in the DogStatsD protocol: <name>:<value>|<type>|#<tag>,<tag>.
+func dogstatsdLine(name string, value float64, metricType string, tags []string) string {
+	line := name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + metricType
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line
+}
+
+// dogstatsdPackets groups the lines, separated by newlines, into packets of at most size bytes. A line longer than
+// size is sent alone.
+func dogstatsdPackets(lines []string, size int) [][]byte {
+	var packets [][]byte
+	var packet bytes.Buffer
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > size {
+			packets = append(packets, append([]byte(nil), packet.Bytes()...))
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() > 0 {
+		packets = append(packets, packet.Bytes())
+	}
+	return packets
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDogstatsdSinkLines(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: subsystem, Name: "value"}, []string{wpaNamePromLabel})
+	gauge.WithLabelValues("foo").Set(12.5)
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Subsystem: subsystem, Name: "decisions"}, []string{decisionPromLabel})
+	counter.WithLabelValues("scaled").Add(3)
+	reconcileTime := prometheus.NewHistogram(prometheus.HistogramOpts{Name: reconcileTimeMetric})
+	reconcileTime.Observe(0.25)
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "go_other"})
+	registry.MustRegister(gauge, counter, reconcileTime, other)
+
+	s, err := newDogstatsdSink("udp://localhost:8125", time.Second, "env:prod, cluster:main")
+	require.NoError(t, err)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"watermarkpodautoscaler.controller_runtime_reconcile_time_seconds.count:1|c|#cluster:main,env:prod",
+		"watermarkpodautoscaler.controller_runtime_reconcile_time_seconds.sum:0.25|c|#cluster:main,env:prod",
+		"watermarkpodautoscaler.wpa_controller_decisions:3|c|#cluster:main,decision:scaled,env:prod",
+		"watermarkpodautoscaler.wpa_controller_value:12.5|g|#cluster:main,env:prod,wpa_name:foo",
+	}, s.lines(families))
+
+	// only the increase of the counters since the previous flush is sent
+	counter.WithLabelValues("scaled").Add(2)
+	families, err = registry.Gather()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"watermarkpodautoscaler.wpa_controller_decisions:2|c|#cluster:main,decision:scaled,env:prod",
+		"watermarkpodautoscaler.wpa_controller_value:12.5|g|#cluster:main,env:prod,wpa_name:foo",
+	}, s.lines(families))
+}
+
+func TestDogstatsdPackets(t *testing.T) {
+	lines := []string{"a:1|g", "b:2|g", strings.Repeat("c", 20), "d:4|g"}
+	packets := dogstatsdPackets(lines, 12)
+	require.Equal(t, [][]byte{[]byte("a:1|g\nb:2|g"), []byte(strings.Repeat("c", 20)), []byte("d:4|g")}, packets)
+}
+
+func TestDogstatsdSinkFlush(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := newDogstatsdSink("udp://"+conn.LocalAddr().String(), time.Second, "")
+	require.NoError(t, err)
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: subsystem, Name: "min_replicas"})
+	gauge.Set(3)
+	registry.MustRegister(gauge)
+	s.gatherer = registry
+	require.NoError(t, s.flush())
+
+	buf := make([]byte, dogstatsdUDPPacketSize)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, "watermarkpodautoscaler.wpa_controller_min_replicas:3|g", string(buf[:n]))
+
+	_, err = newDogstatsdSink("tcp://localhost:8125", time.Second, "")
+	require.EqualError(t, err, "invalid DogStatsD address tcp://localhost:8125: udp://<host>:<port> or unix://<socket path> is expected")
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const replicaDriftCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "ReplicaDrift"
+
+// detectReplicaDrift compares the replicas of the target to the ones the controller last applied, the current
+// replicas being adopted when none were applied yet. It returns whether the target started drifting.
+func detectReplicaDrift(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, replicas int32) bool {
+	promLabels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+	if wpa.Status.LastAppliedReplicas == 0 {
+		wpa.Status.LastAppliedReplicas = replicas
+	}
+	drift := replicas - wpa.Status.LastAppliedReplicas
+	replicaDrift.With(promLabels).Set(float64(drift))
+	if drift == 0 {
+		setCondition(wpa, replicaDriftCondition, corev1.ConditionFalse, "NoDrift", "the target has the %d replicas last applied by the WPA", replicas)
+		return false
+	}
+	started := !isConditionTrue(wpa, replicaDriftCondition)
+	setCondition(wpa, replicaDriftCondition, corev1.ConditionTrue, "ScaledOutsideWPA", "the target has %d replicas, the WPA last applied %d replicas", replicas, wpa.Status.LastAppliedReplicas)
+	return started
+}
+
+// correctReplicaDrift returns the replicas the controller last applied, within the minReplicas and the maxReplicas,
+// when the target was scaled outside of the WPA.
+func correctReplicaDrift(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, replicas int32) (int32, bool) {
+	applied := wpa.Status.LastAppliedReplicas
+	if applied == 0 || applied == replicas {
+		return replicas, false
+	}
+	if wpa.Spec.MinReplicas != nil && applied < *wpa.Spec.MinReplicas {
+		applied = *wpa.Spec.MinReplicas
+	}
+	if applied > wpa.Spec.MaxReplicas {
+		applied = wpa.Spec.MaxReplicas
+	}
+	logger.Info("Correcting the replicas changed outside of the WPA", "replicas", replicas, "lastAppliedReplicas", wpa.Status.LastAppliedReplicas, "desiredReplicas", applied)
+	return applied, true
+}
+
+// recordAppliedReplicas keeps track of the replicas the controller applied to the target.
+func recordAppliedReplicas(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, replicas int32) {
+	wpa.Status.LastAppliedReplicas = replicas
+	setCondition(wpa, replicaDriftCondition, corev1.ConditionFalse, "NoDrift", "the target has the %d replicas last applied by the WPA", replicas)
+	replicaDrift.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Set(0)
+}
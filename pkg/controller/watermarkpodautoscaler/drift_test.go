@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestReplicaDrift(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+
+	require.False(t, detectReplicaDrift(wpa, 5))
+	require.Equal(t, int32(5), wpa.Status.LastAppliedReplicas, "the replicas of the target are adopted")
+	require.Equal(t, replicaDriftCondition, wpa.Status.Conditions[0].Type)
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+
+	recordAppliedReplicas(wpa, 8)
+	require.False(t, detectReplicaDrift(wpa, 8))
+
+	require.True(t, detectReplicaDrift(wpa, 20), "the target was scaled outside of the WPA")
+	require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status)
+	require.Equal(t, "ScaledOutsideWPA", wpa.Status.Conditions[0].Reason)
+	require.False(t, detectReplicaDrift(wpa, 20), "the drift was already reported")
+	require.Equal(t, int32(8), wpa.Status.LastAppliedReplicas)
+
+	recordAppliedReplicas(wpa, 12)
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+	require.False(t, detectReplicaDrift(wpa, 12))
+}
+
+func TestCorrectReplicaDrift(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			MinReplicas: getReplicas(4),
+			MaxReplicas: 10,
+			DriftPolicy: v1alpha1.DriftPolicyCorrect,
+		},
+	})
+
+	_, drifting := correctReplicaDrift(logger, wpa, 6)
+	require.False(t, drifting, "nothing was applied by the controller yet")
+
+	wpa.Status.LastAppliedReplicas = 6
+	_, drifting = correctReplicaDrift(logger, wpa, 6)
+	require.False(t, drifting)
+
+	replicas, drifting := correctReplicaDrift(logger, wpa, 20)
+	require.True(t, drifting)
+	require.Equal(t, int32(6), replicas)
+
+	wpa.Spec.MaxReplicas = 5
+	replicas, _ = correctReplicaDrift(logger, wpa, 20)
+	require.Equal(t, int32(5), replicas, "the replicas are bounded by the maxReplicas")
+}
+
+func TestSetStatusKeepsAppliedReplicas(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+	recordAppliedReplicas(wpa, 8)
+
+	setStatus(wpa, 8, 8, nil, true)
+	require.Equal(t, int32(8), wpa.Status.LastAppliedReplicas)
+}
@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	defaultDryRunReportWindowSeconds = 3600
+	defaultDryRunReportMaxReports    = 168
+	// maxDryRunScalesPerReport bounds the scales detailed in a report, so that a week of reports fits in a ConfigMap.
+	maxDryRunScalesPerReport = 10
+	// dryRunReportKeyFormat names the keys of the ConfigMap after the start of the windows, so that they sort by time.
+	dryRunReportKeyFormat = "20060102T150405Z.json"
+)
+
+// dryRunReport summarizes what a WPA in dry-run mode would have done over a window.
+type dryRunReport struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Evaluations int       `json:"evaluations"`
+	// Decisions counts the outcomes of the evaluations, such as dry_run for a scale that would have been applied.
+	Decisions map[string]int `json:"decisions"`
+	// LimitingReasons counts the evaluations held back by each reason, such as a forbidden window or a cap.
+	LimitingReasons map[string]int `json:"limitingReasons,omitempty"`
+	// ProposedScales counts the evaluations whose desired replicas differ from the current ones, the first of them
+	// being detailed in Scales.
+	ProposedScales int                           `json:"proposedScales"`
+	Scales         []dryRunScale                 `json:"scales,omitempty"`
+	Metrics        map[string]*dryRunMetricTrace `json:"metrics,omitempty"`
+}
+
+// dryRunScale is an evaluation whose desired replicas differ from the current ones.
+type dryRunScale struct {
+	Timestamp       time.Time `json:"timestamp"`
+	CurrentReplicas int32     `json:"currentReplicas"`
+	// ProposedReplicas is nil when the algorithm didn't propose a number of replicas.
+	ProposedReplicas *int32   `json:"proposedReplicas,omitempty"`
+	DesiredReplicas  int32    `json:"desiredReplicas"`
+	Decision         string   `json:"decision"`
+	LimitingReasons  []string `json:"limitingReasons,omitempty"`
+}
+
+// dryRunMetricTrace summarizes the values of a metric compared to its watermarks over a window.
+type dryRunMetricTrace struct {
+	Samples      int               `json:"samples"`
+	OutOfBounds  int               `json:"outOfBounds"`
+	Min          resource.Quantity `json:"min"`
+	Max          resource.Quantity `json:"max"`
+	Last         resource.Quantity `json:"last"`
+	LowWatermark resource.Quantity `json:"lowWatermark"`
+	// HighWatermark is the last effective high watermark, as the low one.
+	HighWatermark resource.Quantity `json:"highWatermark"`
+}
+
+// dryRunReports keeps the report of the ongoing window of the WPAs with a dry-run report.
+type dryRunReports struct {
+	mu      sync.Mutex
+	reports map[types.NamespacedName]*dryRunReport
+}
+
+func newDryRunReports() *dryRunReports {
+	return &dryRunReports{reports: map[types.NamespacedName]*dryRunReport{}}
+}
+
+// observe adds an evaluation to the report of the window of now. It returns the report of the previous window when
+// it is over.
+func (d *dryRunReports) observe(key types.NamespacedName, window time.Duration, now time.Time, add func(*dryRunReport)) *dryRunReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var finished *dryRunReport
+	report := d.reports[key]
+	if report != nil && !now.Before(report.End) {
+		finished, report = report, nil
+	}
+	if report == nil {
+		start := now.Truncate(window)
+		report = &dryRunReport{Start: start, End: start.Add(window), Decisions: map[string]int{}}
+		d.reports[key] = report
+	}
+	add(report)
+	return finished
+}
+
+func (d *dryRunReports) forget(key types.NamespacedName) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.reports, key)
+}
+
+// add accounts for an evaluation of the WPA in the report.
+func (report *dryRunReport) add(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, decision string, proposed bool, proposedReplicas, currentReplicas, desiredReplicas int32, now time.Time) {
+	report.Evaluations++
+	report.Decisions[decision]++
+	reasons := limitingReasons(wpa)
+	for _, reason := range reasons {
+		if report.LimitingReasons == nil {
+			report.LimitingReasons = map[string]int{}
+		}
+		report.LimitingReasons[reason]++
+	}
+	if desiredReplicas != currentReplicas || proposed && proposedReplicas != currentReplicas {
+		report.ProposedScales++
+		if len(report.Scales) < maxDryRunScalesPerReport {
+			scale := dryRunScale{Timestamp: now, CurrentReplicas: currentReplicas, DesiredReplicas: desiredReplicas, Decision: decision, LimitingReasons: reasons}
+			if proposed {
+				scale.ProposedReplicas = &proposedReplicas
+			}
+			report.Scales = append(report.Scales, scale)
+		}
+	}
+	for _, detail := range wpa.Status.MetricDetails {
+		if report.Metrics == nil {
+			report.Metrics = map[string]*dryRunMetricTrace{}
+		}
+		trace, found := report.Metrics[detail.Name]
+		if !found {
+			trace = &dryRunMetricTrace{Min: detail.AdjustedValue, Max: detail.AdjustedValue}
+			report.Metrics[detail.Name] = trace
+		}
+		trace.Samples++
+		if !detail.WithinBounds {
+			trace.OutOfBounds++
+		}
+		if detail.AdjustedValue.Cmp(trace.Min) < 0 {
+			trace.Min = detail.AdjustedValue
+		}
+		if detail.AdjustedValue.Cmp(trace.Max) > 0 {
+			trace.Max = detail.AdjustedValue
+		}
+		trace.Last = detail.AdjustedValue
+		trace.LowWatermark = detail.EffectiveLowWatermark
+		trace.HighWatermark = detail.EffectiveHighWatermark
+	}
+}
+
+// recordDryRunReport adds the evaluation to the report of the WPA in dry-run mode, and writes the report of the
+// previous window to the ConfigMap once it is over. The ongoing window is dropped when the WPA leaves the dry-run mode.
+func (r *ReconcileWatermarkPodAutoscaler) recordDryRunReport(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, decision string, proposed bool, proposedReplicas, currentReplicas, desiredReplicas int32, now time.Time) {
+	if r.dryRunReports == nil {
+		return
+	}
+	key := types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}
+	spec := wpa.Spec.DryRunReport
+	if !wpa.Spec.DryRun || spec == nil {
+		r.dryRunReports.forget(key)
+		return
+	}
+	window := time.Duration(defaultDryRunReportWindowSeconds) * time.Second
+	if spec.WindowSeconds > 0 {
+		window = time.Duration(spec.WindowSeconds) * time.Second
+	}
+	finished := r.dryRunReports.observe(key, window, now, func(report *dryRunReport) {
+		report.add(wpa, decision, proposed, proposedReplicas, currentReplicas, desiredReplicas, now)
+	})
+	if finished == nil {
+		return
+	}
+	if err := r.writeDryRunReport(wpa, finished); err != nil {
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedDryRunReport", "unable to write the dry-run report of the window starting at %s: %v", finished.Start.Format(time.RFC3339), err)
+		logger.Info("Unable to write the dry-run report", "start", finished.Start, "error", err)
+	}
+}
+
+// writeDryRunReport adds the report to the ConfigMap of the WPA, and removes the oldest reports beyond maxReports.
+func (r *ReconcileWatermarkPodAutoscaler) writeDryRunReport(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, report *dryRunReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	key := report.Start.UTC().Format(dryRunReportKeyFormat)
+	maxReports := int(defaultDryRunReportMaxReports)
+	if wpa.Spec.DryRunReport.MaxReports > 0 {
+		maxReports = int(wpa.Spec.DryRunReport.MaxReports)
+	}
+	return r.writeOwnedConfigMap(wpa, dryRunReportConfigMapName(wpa), key, string(data), func(data map[string]string) {
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i := 0; i < len(keys)-maxReports; i++ {
+			delete(data, keys[i])
+		}
+	})
+}
+
+func dryRunReportConfigMapName(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) string {
+	if wpa.Spec.DryRunReport.ConfigMapName != "" {
+		return wpa.Spec.DryRunReport.ConfigMapName
+	}
+	return wpa.Name + "-dry-run-report"
+}
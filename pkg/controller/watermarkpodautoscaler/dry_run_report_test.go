@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestDryRunReportAdd(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, "test", &test.NewWatermarkPodAutoscalerOptions{})
+	report := &dryRunReport{Decisions: map[string]int{}}
+
+	wpa.Status.MetricDetails = []v1alpha1.MetricDetailStatus{{Name: "cpu", AdjustedValue: resource.MustParse("50"), EffectiveLowWatermark: resource.MustParse("40"), EffectiveHighWatermark: resource.MustParse("60"), WithinBounds: true}}
+	report.add(wpa, decisionWithinBounds, true, 5, 5, 5, now)
+
+	wpa.Status.MetricDetails = []v1alpha1.MetricDetailStatus{{Name: "cpu", AdjustedValue: resource.MustParse("90"), EffectiveLowWatermark: resource.MustParse("40"), EffectiveHighWatermark: resource.MustParse("60")}}
+	setCondition(wpa, "ScalingLimited", corev1.ConditionTrue, "ScaleUpLimit", "")
+	report.add(wpa, decisionDryRun, true, 10, 5, 8, now.Add(time.Minute))
+
+	require.Equal(t, 2, report.Evaluations)
+	require.Equal(t, map[string]int{decisionWithinBounds: 1, decisionDryRun: 1}, report.Decisions)
+	require.Equal(t, map[string]int{"ScaleUpLimit": 1}, report.LimitingReasons)
+	require.Equal(t, 1, report.ProposedScales)
+	require.Len(t, report.Scales, 1)
+	require.Equal(t, int32(10), *report.Scales[0].ProposedReplicas)
+	require.Equal(t, int32(8), report.Scales[0].DesiredReplicas)
+	require.Equal(t, []string{"ScaleUpLimit"}, report.Scales[0].LimitingReasons)
+
+	trace := report.Metrics["cpu"]
+	require.Equal(t, 2, trace.Samples)
+	require.Equal(t, 1, trace.OutOfBounds)
+	require.Equal(t, "50", trace.Min.String())
+	require.Equal(t, "90", trace.Max.String())
+	require.Equal(t, "90", trace.Last.String())
+
+	for i := 0; i < maxDryRunScalesPerReport; i++ {
+		report.add(wpa, decisionDryRun, true, 10, 5, 8, now)
+	}
+	require.Equal(t, maxDryRunScalesPerReport+1, report.ProposedScales, "all the scales are counted")
+	require.Len(t, report.Scales, maxDryRunScalesPerReport, "only the first scales are detailed")
+}
+
+func TestRecordDryRunReport(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, "test", &test.NewWatermarkPodAutoscalerOptions{})
+	wpa.Spec.DryRun = true
+	wpa.Spec.DryRunReport = &v1alpha1.DryRunReportSpec{WindowSeconds: 60, MaxReports: 2}
+
+	s := runtime.NewScheme()
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.ConfigMap{})
+	r := &ReconcileWatermarkPodAutoscaler{
+		client:        fake.NewFakeClientWithScheme(s),
+		eventRecorder: record.NewFakeRecorder(10),
+		dryRunReports: newDryRunReports(),
+	}
+	getReports := func() map[string]string {
+		configMap := &corev1.ConfigMap{}
+		require.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Namespace: testingNamespace, Name: "test-dry-run-report"}, configMap))
+		require.Len(t, configMap.OwnerReferences, 1, "the ConfigMap is garbage collected with the WPA")
+		return configMap.Data
+	}
+
+	r.recordDryRunReport(logf.Log, wpa, decisionDryRun, true, 8, 5, 8, start.Add(10*time.Second))
+	r.recordDryRunReport(logf.Log, wpa, decisionBackoffUp, true, 8, 5, 5, start.Add(20*time.Second))
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: testingNamespace, Name: "test-dry-run-report"}, &corev1.ConfigMap{})
+	require.Error(t, err, "the report is only written once its window is over")
+
+	r.recordDryRunReport(logf.Log, wpa, decisionWithinBounds, true, 5, 5, 5, start.Add(70*time.Second))
+	reports := getReports()
+	require.Len(t, reports, 1)
+	report := &dryRunReport{}
+	require.NoError(t, json.Unmarshal([]byte(reports["20200101T120000Z.json"]), report))
+	require.Equal(t, 2, report.Evaluations)
+	require.Equal(t, 2, report.ProposedScales)
+	require.Equal(t, map[string]int{decisionDryRun: 1, decisionBackoffUp: 1}, report.Decisions)
+
+	r.recordDryRunReport(logf.Log, wpa, decisionWithinBounds, true, 5, 5, 5, start.Add(130*time.Second))
+	r.recordDryRunReport(logf.Log, wpa, decisionWithinBounds, true, 5, 5, 5, start.Add(190*time.Second))
+	reports = getReports()
+	require.Len(t, reports, 2, "the oldest reports are removed beyond maxReports")
+	require.Contains(t, reports, "20200101T120100Z.json")
+	require.Contains(t, reports, "20200101T120200Z.json")
+
+	wpa.Spec.DryRun = false
+	r.recordDryRunReport(logf.Log, wpa, decisionScaledUp, true, 8, 5, 8, start.Add(250*time.Second))
+	require.Empty(t, r.dryRunReports.reports, "the ongoing window is dropped when leaving the dry-run mode")
+}
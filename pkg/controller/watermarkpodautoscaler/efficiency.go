@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// accountedReplicas is the last accounting of the replica-hours of a WPA in the counters.
+type accountedReplicas struct {
+	time     time.Time
+	replicas int32
+}
+
+// replicaHoursAccounting keeps the last accounting of the WPAs in the counters in memory: the status read at the next
+// reconcile is the previous one when its update was coalesced, which would count the same period twice.
+type replicaHoursAccounting struct {
+	mu          sync.Mutex
+	accountings map[types.NamespacedName]accountedReplicas
+}
+
+func newReplicaHoursAccounting() *replicaHoursAccounting {
+	return &replicaHoursAccounting{accountings: map[types.NamespacedName]accountedReplicas{}}
+}
+
+// account records the current replicas of the target and returns the previous accounting, if any.
+func (a *replicaHoursAccounting) account(key types.NamespacedName, replicas int32, now time.Time) (accountedReplicas, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	previous, found := a.accountings[key]
+	if found && !now.After(previous.time) {
+		return accountedReplicas{}, false
+	}
+	a.accountings[key] = accountedReplicas{time: now, replicas: replicas}
+	return previous, found
+}
+
+func (a *replicaHoursAccounting) forget(key types.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.accountings, key)
+}
+
+// accountReplicaHours adds the replica-hours used by the target since the last accounting, at the replicas it had
+// at the previous reconcile, and the ones it would have used at the maxReplicas of the spec. The accounting in the
+// status is based on the time of the last accounting kept in the status, so a status update skipped is caught up by
+// the next one, while the counters are based on the last accounting kept in memory, so a period is never counted twice.
+func (r *ReconcileWatermarkPodAutoscaler) accountReplicaHours(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas int32, now time.Time) {
+	r.accountReplicaSeconds(wpa, currentReplicas, now)
+	efficiency := wpa.Status.Efficiency
+	if efficiency == nil {
+		wpa.Status.Efficiency = &datadoghqv1alpha1.EfficiencyStatus{Since: metav1.NewTime(now), LastUpdateTime: metav1.NewTime(now)}
+		return
+	}
+	elapsed := now.Sub(efficiency.LastUpdateTime.Time)
+	if elapsed <= 0 {
+		return
+	}
+	efficiency.ReplicaHours += float64(wpa.Status.CurrentReplicas) * elapsed.Hours()
+	efficiency.MaxReplicaHours += float64(wpa.Spec.MaxReplicas) * elapsed.Hours()
+	efficiency.LastUpdateTime = metav1.NewTime(now)
+}
+
+// accountReplicaSeconds adds the replica-seconds used since the last accounting of the controller, at the replicas the
+// target had then, to the counters, which start counting at the first reconcile of the WPA.
+func (r *ReconcileWatermarkPodAutoscaler) accountReplicaSeconds(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas int32, now time.Time) {
+	if r.replicaHours == nil {
+		return
+	}
+	previous, found := r.replicaHours.account(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}, currentReplicas, now)
+	if !found {
+		return
+	}
+	elapsed := now.Sub(previous.time)
+	promLabels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+	replicaSeconds.With(promLabels).Add(float64(previous.replicas) * elapsed.Seconds())
+	maxReplicaSeconds.With(promLabels).Add(float64(wpa.Spec.MaxReplicas) * elapsed.Seconds())
+}
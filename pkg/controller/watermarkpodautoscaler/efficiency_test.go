@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAccountReplicaHours(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{MaxReplicas: 20},
+	})
+	defer cleanupAssociatedMetrics(wpa, false)
+	r := &ReconcileWatermarkPodAutoscaler{replicaHours: newReplicaHoursAccounting()}
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	r.accountReplicaHours(wpa, 5, start)
+	require.Equal(t, &v1alpha1.EfficiencyStatus{Since: metav1.NewTime(start), LastUpdateTime: metav1.NewTime(start)}, wpa.Status.Efficiency)
+
+	wpa.Status.CurrentReplicas = 5
+	r.accountReplicaHours(wpa, 10, start.Add(2*time.Hour))
+	wpa.Status.CurrentReplicas = 10
+	r.accountReplicaHours(wpa, 10, start.Add(3*time.Hour))
+	r.accountReplicaHours(wpa, 10, start.Add(3*time.Hour))
+	require.Equal(t, &v1alpha1.EfficiencyStatus{
+		Since:           metav1.NewTime(start),
+		LastUpdateTime:  metav1.NewTime(start.Add(3 * time.Hour)),
+		ReplicaHours:    20,
+		MaxReplicaHours: 60,
+	}, wpa.Status.Efficiency)
+
+	promLabels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+	require.Equal(t, float64(20*3600), counterValue(t, replicaSeconds.With(promLabels)))
+	require.Equal(t, float64(60*3600), counterValue(t, maxReplicaSeconds.With(promLabels)))
+
+	// The update of the status was coalesced, the next reconcile reads the previous status.
+	coalesced := wpa.Status.Efficiency.DeepCopy()
+	r.accountReplicaHours(wpa, 10, start.Add(4*time.Hour))
+	wpa.Status.Efficiency = coalesced
+	r.accountReplicaHours(wpa, 10, start.Add(5*time.Hour))
+	require.Equal(t, float64(40), wpa.Status.Efficiency.ReplicaHours, "the status catches up from the last status written")
+	require.Equal(t, float64(40*3600), counterValue(t, replicaSeconds.With(promLabels)), "the counters don't count the same period twice")
+	require.Equal(t, float64(100*3600), counterValue(t, maxReplicaSeconds.With(promLabels)))
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, counter.Write(m))
+	return m.GetCounter().GetValue()
+}
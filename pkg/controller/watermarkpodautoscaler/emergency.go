@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// aboveEmergencyWatermark returns whether the usage, compared the same way as to the high watermark, is above the
+// emergency high watermark of the metric.
+func aboveEmergencyWatermark(watermark *resource.Quantity, adjustedUsage float64) bool {
+	return watermark != nil && adjustedUsage > float64(watermark.MilliValue())
+}
+
+// emergencyReplicas lifts the scale-up limit factor from the normalized replicas when a metric is above its
+// emergency high watermark, the proposed replicas being only bounded by the maxReplicas.
+func emergencyReplicas(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, proposedReplicas, normalizedReplicas int32) int32 {
+	if proposedReplicas <= normalizedReplicas || proposedReplicas <= currentReplicas {
+		return normalizedReplicas
+	}
+	replicas := proposedReplicas
+	if replicas > wpa.Spec.MaxReplicas {
+		replicas = wpa.Spec.MaxReplicas
+	}
+	setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionFalse, "EmergencyScale", "a metric is above its emergency high watermark, the scale-up limit factor is ignored")
+	logger.Info("Emergency scale, ignoring the scale-up limit factor", "proposedReplicas", proposedReplicas, "normalizedReplicas", normalizedReplicas, "desiredReplicas", replicas)
+	return replicas
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEmergencyReplicas(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	tests := []struct {
+		name       string
+		current    int32
+		proposed   int32
+		normalized int32
+		want       int32
+		emergency  bool
+	}{
+		{
+			name:       "scale-up limit lifted",
+			current:    10,
+			proposed:   40,
+			normalized: 15,
+			want:       40,
+			emergency:  true,
+		},
+		{
+			name:       "bounded by the maxReplicas",
+			current:    10,
+			proposed:   80,
+			normalized: 15,
+			want:       50,
+			emergency:  true,
+		},
+		{
+			name:       "not limited",
+			current:    10,
+			proposed:   12,
+			normalized: 12,
+			want:       12,
+		},
+		{
+			name:       "raised to the minReplicas",
+			current:    1,
+			proposed:   1,
+			normalized: 2,
+			want:       2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{MaxReplicas: 50},
+			})
+			require.Equal(t, tt.want, emergencyReplicas(logger, wpa, tt.current, tt.proposed, tt.normalized))
+			if tt.emergency {
+				require.Equal(t, autoscalingv2.ScalingLimited, wpa.Status.Conditions[0].Type)
+				require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+				require.Equal(t, "EmergencyScale", wpa.Status.Conditions[0].Reason)
+			} else {
+				require.Empty(t, wpa.Status.Conditions)
+			}
+		})
+	}
+}
+
+func TestExternalMetricEmergency(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric := v1alpha1.MetricSpec{
+		Type: v1alpha1.ExternalMetricSourceType,
+		External: &v1alpha1.ExternalMetricSource{
+			MetricName:             "loadbalancer.request.per.seconds",
+			MetricSelector:         &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			HighWatermark:          resource.NewMilliQuantity(85000, resource.DecimalSI),
+			LowWatermark:           resource.NewMilliQuantity(75000, resource.DecimalSI),
+			EmergencyHighWatermark: resource.NewMilliQuantity(150000, resource.DecimalSI),
+		},
+	}
+	wpa := &v1alpha1.WatermarkPodAutoscaler{
+		Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+			Algorithm: "average",
+			Tolerance: 0.01,
+			Metrics:   []v1alpha1.MetricSpec{metric},
+		},
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for _, tt := range []struct {
+		level     int64
+		emergency bool
+	}{
+		{level: 500000, emergency: false},
+		{level: 800000, emergency: true},
+	} {
+		tc := &replicaCalcTestCase{
+			scale:  makeScale(5, map[string]string{"name": "test-pod"}),
+			metric: &metricInfo{spec: metric, levels: []int64{tt.level}},
+		}
+		replicaCalculation, err := tc.newSyncedReplicaCalculator(t, stop).GetExternalMetricReplicas(context.TODO(), logf.Log, tc.scale, metric, wpa)
+		require.NoError(t, err)
+		require.Equal(t, tt.emergency, replicaCalculation.emergency, "usage of %d per replica", tt.level/5)
+	}
+}
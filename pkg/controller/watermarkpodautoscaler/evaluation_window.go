@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// breach is a scale in the same direction recommended since a given time, for the same number of current replicas.
+type breach struct {
+	direction       string
+	currentReplicas int32
+	since           time.Time
+}
+
+// evaluationWindows keeps, for the WPAs with an evaluation window, since when their metrics recommend a scale.
+type evaluationWindows struct {
+	mu       sync.Mutex
+	breaches map[types.NamespacedName]breach
+}
+
+func newEvaluationWindows() *evaluationWindows {
+	return &evaluationWindows{breaches: map[types.NamespacedName]breach{}}
+}
+
+// observe records that a scale in the direction is recommended and returns since when it is. The breach starts over
+// when the direction or the current replicas change, as the watermarks are then evaluated against another state.
+func (w *evaluationWindows) observe(key types.NamespacedName, direction string, currentReplicas int32, now time.Time) time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	b, ok := w.breaches[key]
+	if !ok || b.direction != direction || b.currentReplicas != currentReplicas {
+		b = breach{direction: direction, currentReplicas: currentReplicas, since: now}
+		w.breaches[key] = b
+	}
+	return b.since
+}
+
+func (w *evaluationWindows) forget(key types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.breaches, key)
+}
+
+// sustainScale keeps the current replicas until the metrics have recommended a scale in the same direction for the
+// whole evaluation window of that direction, downscaleEvaluationWindowSeconds or upscaleEvaluationWindowSeconds.
+func (r *ReconcileWatermarkPodAutoscaler) sustainScale(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas int32, now time.Time) int32 {
+	if r.evaluationWindows == nil {
+		return desiredReplicas
+	}
+	key := types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}
+	var direction, reason, watermark string
+	var window time.Duration
+	switch {
+	case desiredReplicas < currentReplicas:
+		direction, reason, watermark = scaleDirectionDown, "DownscaleNotSustained", "below their low watermark"
+		window = time.Duration(wpa.Spec.DownscaleEvaluationWindowSeconds) * time.Second
+	case desiredReplicas > currentReplicas:
+		direction, reason, watermark = scaleDirectionUp, "UpscaleNotSustained", "above their high watermark"
+		window = time.Duration(wpa.Spec.UpscaleEvaluationWindowSeconds) * time.Second
+	}
+	if window == 0 {
+		r.evaluationWindows.forget(key)
+		return desiredReplicas
+	}
+	since := r.evaluationWindows.observe(key, direction, currentReplicas, now)
+	if now.Sub(since) >= window {
+		return desiredReplicas
+	}
+	setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, reason, "the metrics have been %s since %s, the %sscale to %d replicas waits for the evaluation window of %v", watermark, since.Format(time.RFC3339), direction, desiredReplicas, window)
+	logger.Info("Scale not sustained for the evaluation window", "direction", direction, "since", since, "window", window, "currentReplicas", currentReplicas, "desiredReplicas", desiredReplicas)
+	return currentReplicas
+}
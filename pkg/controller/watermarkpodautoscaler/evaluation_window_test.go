@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestSustainDownscale(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{DownscaleEvaluationWindowSeconds: 300},
+	})
+	r := &ReconcileWatermarkPodAutoscaler{evaluationWindows: newEvaluationWindows()}
+	start := time.Unix(1000, 0)
+
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 8, start))
+	require.True(t, isConditionTrue(wpa, autoscalingv2.ScalingLimited))
+	require.Equal(t, "DownscaleNotSustained", wpa.Status.Conditions[len(wpa.Status.Conditions)-1].Reason)
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 7, start.Add(299*time.Second)), "the window is not over")
+	require.Equal(t, int32(7), r.sustainScale(logger, wpa, 10, 7, start.Add(300*time.Second)), "the downscale was sustained for the whole window")
+
+	// a single reconcile within the watermarks starts the window over
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 10, start.Add(301*time.Second)))
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 8, start.Add(302*time.Second)))
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 8, start.Add(601*time.Second)))
+	require.Equal(t, int32(8), r.sustainScale(logger, wpa, 10, 8, start.Add(602*time.Second)))
+
+	// so does a change of the current replicas
+	require.Equal(t, int32(8), r.sustainScale(logger, wpa, 8, 6, start.Add(603*time.Second)))
+	require.Equal(t, int32(6), r.sustainScale(logger, wpa, 8, 6, start.Add(903*time.Second)))
+
+	other := wpa.DeepCopy()
+	other.Name = "other"
+	require.Equal(t, int32(8), r.sustainScale(logger, other, 8, 6, start.Add(903*time.Second)), "the windows are kept per WPA")
+
+	r.evaluationWindows.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	require.Equal(t, int32(8), r.sustainScale(logger, wpa, 8, 6, start.Add(904*time.Second)))
+}
+
+func TestSustainUpscale(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{UpscaleEvaluationWindowSeconds: 120},
+	})
+	r := &ReconcileWatermarkPodAutoscaler{evaluationWindows: newEvaluationWindows()}
+	start := time.Unix(1000, 0)
+
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 15, start))
+	require.True(t, isConditionTrue(wpa, autoscalingv2.ScalingLimited))
+	require.Equal(t, "UpscaleNotSustained", wpa.Status.Conditions[len(wpa.Status.Conditions)-1].Reason)
+	require.Equal(t, int32(8), r.sustainScale(logger, wpa, 10, 8, start.Add(60*time.Second)), "the downscales are not delayed without a downscale window")
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 15, start.Add(61*time.Second)), "a reversal starts the window over")
+	require.Equal(t, int32(10), r.sustainScale(logger, wpa, 10, 12, start.Add(180*time.Second)))
+	require.Equal(t, int32(12), r.sustainScale(logger, wpa, 10, 12, start.Add(181*time.Second)))
+}
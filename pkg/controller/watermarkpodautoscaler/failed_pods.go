@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// podReasonEvicted is the reason of the pods evicted by the kubelet.
+const podReasonEvicted = "Evicted"
+
+// reportFailedPods exports the number of Failed pods of the target, and emits a warning event when their share of
+// the pods is above the threshold of the failedPods of the WPA.
+func (r *ReconcileWatermarkPodAutoscaler) reportFailedPods(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale) {
+	promLabels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+	if wpa.Spec.FailedPods == nil {
+		failedPods.Delete(promLabels)
+		return
+	}
+	selector, err := labels.Parse(scale.Status.Selector)
+	if err != nil {
+		logger.Error(err, "Could not parse the labels of the target")
+		return
+	}
+	pods, err := r.podLister.Pods(wpa.Namespace).List(selector)
+	if err != nil {
+		logger.Error(err, "Could not list the pods of the target")
+		return
+	}
+
+	failed := countFailedPods(pods)
+	failedPods.With(promLabels).Set(float64(failed))
+	threshold := wpa.Spec.FailedPods.WarningThresholdPercent
+	if threshold == nil || len(pods) == 0 || failed*100 <= int(*threshold)*len(pods) {
+		return
+	}
+	r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedPods", "%d of the %d pods of %s are Failed, above the threshold of %d%%", failed, len(pods), wpa.Spec.ScaleTargetRef.Name, *threshold)
+	logger.Info("Failed pods above the threshold", "failedPods", failed, "pods", len(pods), "thresholdPercent", *threshold)
+}
+
+func countFailedPods(pods []*corev1.Pod) int {
+	failed := 0
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodFailed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// isFinished returns whether the pod completed or was evicted by the kubelet, and will never run again.
+func isFinished(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == podReasonEvicted
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestPodInPhase(name string, phase corev1.PodPhase, reason string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testingNamespace, Labels: map[string]string{"app": "test"}},
+		Status:     corev1.PodStatus{Phase: phase, Reason: reason},
+	}
+}
+
+func TestIsFinished(t *testing.T) {
+	require.True(t, isFinished(newTestPodInPhase("completed", corev1.PodSucceeded, "")))
+	require.True(t, isFinished(newTestPodInPhase("evicted", corev1.PodFailed, podReasonEvicted)))
+	require.False(t, isFinished(newTestPodInPhase("crashed", corev1.PodFailed, "")), "the other failures are still accounted for")
+	require.False(t, isFinished(newTestPodInPhase("running", corev1.PodRunning, "")))
+}
+
+func TestExcludedFinishedPods(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	c := NewReplicaCalculator(nil, nil, nil)
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+	evicted := newTestPodInPhase("evicted", corev1.PodFailed, podReasonEvicted)
+
+	require.False(t, c.excludedPods(context.TODO(), logger, wpa)(evicted), "the finished pods are only excluded on demand")
+
+	wpa.Spec.FailedPods = &v1alpha1.FailedPodsSpec{ExcludeFinished: true}
+	excluded := c.excludedPods(context.TODO(), logger, wpa)
+	require.True(t, excluded(evicted))
+	require.True(t, excluded(newTestPodInPhase("completed", corev1.PodSucceeded, "")))
+	require.False(t, excluded(newTestPodInPhase("running", corev1.PodRunning, "")))
+}
+
+func TestReportFailedPods(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pod := range []*corev1.Pod{
+		newTestPodInPhase("evicted-1", corev1.PodFailed, podReasonEvicted),
+		newTestPodInPhase("evicted-2", corev1.PodFailed, podReasonEvicted),
+		newTestPodInPhase("crashed", corev1.PodFailed, ""),
+		newTestPodInPhase("running", corev1.PodRunning, ""),
+	} {
+		require.NoError(t, indexer.Add(pod))
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &ReconcileWatermarkPodAutoscaler{podLister: corelisters.NewPodLister(indexer), eventRecorder: recorder}
+	scale := &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Selector: "app=test"}}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{FailedPods: &v1alpha1.FailedPodsSpec{WarningThresholdPercent: v1alpha1.NewInt32(80)}},
+	})
+
+	r.reportFailedPods(logger, wpa, scale)
+	require.Equal(t, float64(3), gaugeValue(t, failedPods.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind})))
+	require.Empty(t, recorder.Events, "75% of the pods are Failed, below the threshold")
+
+	wpa.Spec.FailedPods.WarningThresholdPercent = v1alpha1.NewInt32(50)
+	r.reportFailedPods(logger, wpa, scale)
+	require.Contains(t, <-recorder.Events, "FailedPods")
+}
+
+func TestFailedPodsValidity(t *testing.T) {
+	for threshold, valid := range map[int32]bool{0: false, 1: true, 100: true, 101: false} {
+		wpa := v1alpha1.DefaultWatermarkPodAutoscaler(test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{ScaleTargetRef: testCrossVersionObjectRef, MaxReplicas: 5, FailedPods: &v1alpha1.FailedPodsSpec{WarningThresholdPercent: v1alpha1.NewInt32(threshold)}},
+		}))
+		require.Equal(t, valid, v1alpha1.CheckWPAValidity(wpa) == nil, "warningThresholdPercent %d", threshold)
+	}
+	wpa := v1alpha1.DefaultWatermarkPodAutoscaler(test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{ScaleTargetRef: testCrossVersionObjectRef, MaxReplicas: 5, FailedPods: &v1alpha1.FailedPodsSpec{ExcludeFinished: true}},
+	}))
+	require.NoError(t, v1alpha1.CheckWPAValidity(wpa), "the threshold is optional")
+}
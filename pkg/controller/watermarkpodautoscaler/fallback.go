@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"sort"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	fallbackCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "MetricFallback"
+
+	defaultFallbackAfterFailures = 3
+)
+
+// metricFallbacks returns, for each metric having a fallback, the number of consecutive failures after which
+// its fallbacks are evaluated instead.
+func metricFallbacks(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) map[string]int32 {
+	fallbacks := map[string]int32{}
+	for _, metric := range wpa.Spec.Metrics {
+		if metric.FallbackFor == "" {
+			continue
+		}
+		after := metric.FallbackAfterFailures
+		if after == 0 {
+			after = defaultFallbackAfterFailures
+		}
+		if current, ok := fallbacks[metric.FallbackFor]; !ok || after < current {
+			fallbacks[metric.FallbackFor] = after
+		}
+	}
+	return fallbacks
+}
+
+// metricsEvaluationOrder returns the indexes of the metrics of the WPA, the fallbacks last so that they are only
+// evaluated once the metrics they stand in for failed.
+func metricsEvaluationOrder(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) []int {
+	order := make([]int, len(wpa.Spec.Metrics))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return wpa.Spec.Metrics[order[i]].FallbackFor == "" && wpa.Spec.Metrics[order[j]].FallbackFor != ""
+	})
+	return order
+}
+
+// shouldFallBack records a failure of a metric having a fallback, and returns whether it failed for more than
+// the allowed number of consecutive reconciles.
+func shouldFallBack(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, fallbacks map[string]int32, name string) bool {
+	after, ok := fallbacks[name]
+	if !ok {
+		return false
+	}
+	failures := int32(1)
+	for i := range wpa.Status.MetricFailures {
+		if wpa.Status.MetricFailures[i].MetricName == name {
+			wpa.Status.MetricFailures[i].ConsecutiveFailures++
+			failures = wpa.Status.MetricFailures[i].ConsecutiveFailures
+		}
+	}
+	if failures == 1 {
+		wpa.Status.MetricFailures = append(wpa.Status.MetricFailures, datadoghqv1alpha1.MetricFailureStatus{MetricName: name, ConsecutiveFailures: failures})
+	}
+	logger.Info("Metric having a fallback failed", "metricName", name, "consecutiveFailures", failures, "fallbackAfterFailures", after)
+	return failures > after
+}
+
+// resetMetricFailures forgets the failures of a metric computed successfully.
+func resetMetricFailures(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, name string) {
+	var failures []datadoghqv1alpha1.MetricFailureStatus
+	for _, failure := range wpa.Status.MetricFailures {
+		if failure.MetricName != name {
+			failures = append(failures, failure)
+		}
+	}
+	wpa.Status.MetricFailures = failures
+}
+
+// setFallbackCondition reports whether fallbacks stand in for some metrics of the WPA.
+func setFallbackCondition(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, fallbacks map[string]int32, fallenBack map[string]bool) {
+	if len(fallbacks) == 0 {
+		return
+	}
+	if len(fallenBack) == 0 {
+		setCondition(wpa, fallbackCondition, corev1.ConditionFalse, "PrimaryMetricsAvailable", "the metrics having a fallback were computed")
+		return
+	}
+	names := make([]string, 0, len(fallenBack))
+	for name := range fallenBack {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	setCondition(wpa, fallbackCondition, corev1.ConditionTrue, "FallbackMetricsUsed", "the metrics %v failed for too many consecutive reconciles, their fallbacks are used instead", names)
+}
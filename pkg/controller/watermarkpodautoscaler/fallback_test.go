@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestComputeReplicasWithFallback(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			Metrics: []v1alpha1.MetricSpec{
+				{
+					Type: v1alpha1.ResourceMetricSourceType,
+					Resource: &v1alpha1.ResourceMetricSource{
+						Name:           corev1.ResourceCPU,
+						MetricSelector: &metav1.LabelSelector{},
+						HighWatermark:  resource.NewMilliQuantity(800, resource.DecimalSI),
+						LowWatermark:   resource.NewMilliQuantity(400, resource.DecimalSI),
+					},
+					FallbackFor:           "queue.lag",
+					FallbackAfterFailures: 2,
+				},
+				{
+					Type: v1alpha1.ExternalMetricSourceType,
+					External: &v1alpha1.ExternalMetricSource{
+						MetricName:     "queue.lag",
+						MetricSelector: &metav1.LabelSelector{},
+						HighWatermark:  resource.NewQuantity(100, resource.DecimalSI),
+						LowWatermark:   resource.NewQuantity(50, resource.DecimalSI),
+					},
+				},
+			},
+			MaxReplicas: 20,
+		},
+	})
+	scale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 4}, Status: autoscalingv1.ScaleStatus{Replicas: 4}}
+	primaryErr := errors.New("unable to get external metric")
+	evaluated := map[v1alpha1.MetricSourceType]int{}
+	r := &ReconcileWatermarkPodAutoscaler{
+		eventRecorder: record.NewFakeRecorder(10),
+		replicaCalc: &fakeReplicaCalculator{
+			replicasFunc: func(metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
+				evaluated[metric.Type]++
+				if metric.Type == v1alpha1.ResourceMetricSourceType {
+					return ReplicaCalculation{replicaCount: 6, highWatermark: metric.Resource.HighWatermark, lowWatermark: metric.Resource.LowWatermark}, nil
+				}
+				if primaryErr != nil {
+					return ReplicaCalculation{}, primaryErr
+				}
+				return ReplicaCalculation{replicaCount: 9, highWatermark: metric.External.HighWatermark, lowWatermark: metric.External.LowWatermark}, nil
+			},
+		},
+	}
+
+	for failures := int32(1); failures <= 2; failures++ {
+		_, _, _, _, _, err := r.computeReplicasForMetrics(logger, wpa, scale)
+		require.Error(t, err)
+		require.Equal(t, []v1alpha1.MetricFailureStatus{{MetricName: "queue.lag", ConsecutiveFailures: failures}}, wpa.Status.MetricFailures)
+	}
+	require.Zero(t, evaluated[v1alpha1.ResourceMetricSourceType], "the fallback is not evaluated while the primary metric is allowed to fail")
+
+	replicas, metric, _, _, _, err := r.computeReplicasForMetrics(logger, wpa, scale)
+	require.NoError(t, err)
+	require.Equal(t, int32(6), replicas)
+	require.Equal(t, "cpu{map[]}", metric)
+	require.True(t, isConditionTrue(wpa, fallbackCondition))
+
+	primaryErr = nil
+	replicas, _, statuses, _, _, err := r.computeReplicasForMetrics(logger, wpa, scale)
+	require.NoError(t, err)
+	require.Equal(t, int32(9), replicas)
+	require.Len(t, statuses, 1, "the fallback that was not evaluated has no status")
+	require.Equal(t, "queue.lag", statuses[0].External.MetricName)
+	require.Empty(t, wpa.Status.MetricFailures)
+	require.Equal(t, 1, evaluated[v1alpha1.ResourceMetricSourceType], "the fallback is not evaluated once the primary metric recovered")
+	require.False(t, isConditionTrue(wpa, fallbackCondition))
+}
+
+func TestMetricFallbacks(t *testing.T) {
+	external := func(name, fallbackFor string) v1alpha1.MetricSpec {
+		return v1alpha1.MetricSpec{Type: v1alpha1.ExternalMetricSourceType, External: &v1alpha1.ExternalMetricSource{MetricName: name}, FallbackFor: fallbackFor}
+	}
+	require.Equal(t, []int{1, 2, 0}, metricsEvaluationOrder(&v1alpha1.WatermarkPodAutoscaler{Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+		Metrics: []v1alpha1.MetricSpec{external("cpu", "lag"), external("lag", ""), external("rps", "")},
+	}}))
+	require.Equal(t, map[string]int32{"lag": defaultFallbackAfterFailures}, metricFallbacks(&v1alpha1.WatermarkPodAutoscaler{Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+		Metrics: []v1alpha1.MetricSpec{external("cpu", "lag"), external("lag", "")},
+	}}))
+}
+
+func TestSetStatusKeepsMetricFailures(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+	wpa.Status.MetricFailures = []v1alpha1.MetricFailureStatus{{MetricName: "queue.lag", ConsecutiveFailures: 2}}
+
+	setStatus(wpa, 8, 8, nil, false)
+	require.Equal(t, []v1alpha1.MetricFailureStatus{{MetricName: "queue.lag", ConsecutiveFailures: 2}}, wpa.Status.MetricFailures, "the failures are counted across the reconciles")
+}
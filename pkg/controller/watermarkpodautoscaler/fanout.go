@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"fmt"
+	"math"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// scaleAdditionalTargets keeps the scaleTargetRefs of the WPA in lock-step with its scaleTargetRef.
+// Failing to scale one of them does not prevent the others from being scaled.
+func (r *ReconcileWatermarkPodAutoscaler) scaleAdditionalTargets(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, replicas int32) {
+	for _, ref := range wpa.Spec.ScaleTargetRefs {
+		reference := fmt.Sprintf("%s/%s/%s", ref.Kind, wpa.Namespace, ref.Name)
+		scale, targetGR, err := r.getScaleForTarget(wpa.Namespace, ref.CrossVersionObjectReference)
+		if err != nil {
+			r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedGetScale", "Could not get the scale of %s: %v", reference, err)
+			logger.Info("Could not get the scale of an additional target", "reference", reference, "error", err)
+			continue
+		}
+		desiredReplicas := weightedReplicas(replicas, ref.Weight)
+		if scale.Spec.Replicas != desiredReplicas {
+			if err = r.checkTargetOwnership(wpa, ref.CrossVersionObjectReference); err != nil {
+				r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "TargetNotOwned", err.Error())
+				logger.Info("Not allowed to scale an additional target", "reference", reference, "error", err)
+				continue
+			}
+			scale.Spec.Replicas = desiredReplicas
+			if _, err = r.scaleClient.Scales(wpa.Namespace).Update(targetGR, scale); err != nil {
+				r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedRescale", "New size of %s: %d; error: %v", reference, desiredReplicas, err)
+				logger.Info("Could not scale an additional target", "reference", reference, "error", err)
+				continue
+			}
+			r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "SuccessfulRescale", "New size of %s: %d; reason: lock-step with %s", reference, desiredReplicas, wpa.Spec.ScaleTargetRef.Name)
+			logger.Info("Successful rescale of an additional target", "reference", reference, "desiredReplicas", desiredReplicas)
+		}
+		replicaEffective.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: ref.Name, resourceKindPromLabel: ref.Kind}).Set(float64(desiredReplicas))
+	}
+}
+
+// getScaleForTarget resolves the mappings of the target before fetching its scale.
+func (r *ReconcileWatermarkPodAutoscaler) getScaleForTarget(namespace string, ref datadoghqv1alpha1.CrossVersionObjectReference) (*autoscalingv1.Scale, schema.GroupResource, error) {
+	targetGV, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, schema.GroupResource{}, fmt.Errorf("invalid API version in scale target reference: %v", err)
+	}
+	scale, targetGR, err := r.getScaleForGroupKind(namespace, ref.Name, schema.GroupKind{Group: targetGV.Group, Kind: ref.Kind})
+	if scale == nil {
+		return nil, targetGR, err
+	}
+	return scale, targetGR, nil
+}
+
+// weightedReplicas returns the number of replicas of a target with the given weight, rounded up so that
+// a target is never scaled to zero.
+func weightedReplicas(replicas, weight int32) int32 {
+	return int32(math.Ceil(float64(replicas) * float64(weight) / 100))
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakescale "k8s.io/client-go/scale/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// newFakeScales returns a fake scale client serving the scales of the Deployments with the given replicas, and
+// recording the replicas of their updates.
+func newFakeScales(replicas map[string]int32) (*fakescale.FakeScaleClient, map[string][]int32) {
+	updates := map[string][]int32{}
+	scales := &fakescale.FakeScaleClient{}
+	scales.AddReactor("get", "deployments", func(action core.Action) (bool, runtime.Object, error) {
+		name := action.(core.GetAction).GetName()
+		current, found := replicas[name]
+		if !found {
+			return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), name)
+		}
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: name},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: current},
+			Status:     autoscalingv1.ScaleStatus{Replicas: current, Selector: "app=" + name},
+		}, nil
+	})
+	scales.AddReactor("update", "deployments", func(action core.Action) (bool, runtime.Object, error) {
+		scale := action.(core.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		replicas[scale.Name] = scale.Spec.Replicas
+		updates[scale.Name] = append(updates[scale.Name], scale.Spec.Replicas)
+		return true, scale, nil
+	})
+	return scales, updates
+}
+
+func TestReconcileWPAScalesAdditionalTargets(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	ref := func(name string, weight int32) v1alpha1.WeightedCrossVersionObjectReference {
+		return v1alpha1.WeightedCrossVersionObjectReference{CrossVersionObjectReference: v1alpha1.CrossVersionObjectReference{Kind: "Deployment", Name: name, APIVersion: "apps/v1"}, Weight: weight}
+	}
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		backoff     bool
+		wantUpdates map[string][]int32
+	}{
+		{
+			name:        "the additional targets follow a rescale",
+			wantUpdates: map[string][]int32{"app": {6}, "half": {3}, "double": {12}},
+		},
+		{
+			name:        "the additional targets are kept in lock-step without rescale",
+			backoff:     true,
+			wantUpdates: map[string][]int32{"half": {2}},
+		},
+		{
+			name:        "the additional targets are not scaled in dry run",
+			dryRun:      true,
+			wantUpdates: map[string][]int32{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := v1alpha1.DefaultWatermarkPodAutoscaler(test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+					ScaleTargetRef:  v1alpha1.CrossVersionObjectReference{Kind: "Deployment", Name: "app", APIVersion: "apps/v1"},
+					ScaleTargetRefs: []v1alpha1.WeightedCrossVersionObjectReference{ref("half", 50), ref("missing", 100), ref("double", 200)},
+					MaxReplicas:     20,
+					DryRun:          tt.dryRun,
+					Metrics: []v1alpha1.MetricSpec{
+						{
+							Type: v1alpha1.ExternalMetricSourceType,
+							External: &v1alpha1.ExternalMetricSource{
+								MetricName:     "queue.lag",
+								MetricSelector: &metav1.LabelSelector{},
+								HighWatermark:  resource.NewQuantity(100, resource.DecimalSI),
+								LowWatermark:   resource.NewQuantity(50, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			}))
+			if tt.backoff {
+				wpa.Status.LastScaleTime = &metav1.Time{Time: time.Now()}
+			}
+			scales, updates := newFakeScales(map[string]int32{"app": 4, "half": 1, "double": 8})
+			eventRecorder := record.NewFakeRecorder(100)
+			r := &ReconcileWatermarkPodAutoscaler{
+				client:        fake.NewFakeClientWithScheme(s, wpa),
+				scaleClient:   scales,
+				restMapper:    testrestmapper.TestOnlyStaticRESTMapper(s),
+				scheme:        s,
+				eventRecorder: eventRecorder,
+				replicaCalc: &fakeReplicaCalculator{
+					replicasFunc: func(metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
+						return ReplicaCalculation{replicaCount: 6, highWatermark: metric.External.HighWatermark, lowWatermark: metric.External.LowWatermark}, nil
+					},
+				},
+			}
+
+			require.NoError(t, r.reconcileWPA(logf.Log.WithName(tt.name), wpa))
+			require.Equal(t, tt.wantUpdates, updates)
+			var events []string
+			for len(eventRecorder.Events) > 0 {
+				events = append(events, <-eventRecorder.Events)
+			}
+			if !tt.dryRun {
+				require.Contains(t, strings.Join(events, "\n"), "Warning FailedGetScale Could not get the scale of Deployment/"+testingNamespace+"/missing", "a missing target does not prevent the others from being scaled")
+			}
+		})
+	}
+}
+
+func TestWeightedReplicas(t *testing.T) {
+	require.Equal(t, int32(10), weightedReplicas(10, 100))
+	require.Equal(t, int32(5), weightedReplicas(10, 50))
+	require.Equal(t, int32(2), weightedReplicas(3, 50))
+	require.Equal(t, int32(1), weightedReplicas(1, 10))
+	require.Equal(t, int32(10), weightedReplicas(4, 250))
+}
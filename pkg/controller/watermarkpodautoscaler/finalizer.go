@@ -11,6 +11,7 @@ import (
 	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
 	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
 	logr "github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -50,7 +51,33 @@ func (r *ReconcileWatermarkPodAutoscaler) handleFinalizer(reqLogger logr.Logger,
 }
 
 func (r *ReconcileWatermarkPodAutoscaler) finalizeWPA(reqLogger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) {
-	cleanupAssociatedMetrics(wpa, false)
+	// The metrics are labelled with the names of the workloads matched by the selectors.
+	resolved := wpa.DeepCopy()
+	if err := r.resolveScaleTargets(reqLogger, resolved); err != nil {
+		reqLogger.Info("Could not resolve the scale targets, some metrics may not be cleaned up", "error", err)
+	}
+	cleanupAssociatedMetrics(resolved, false)
+	if r.recommendations != nil {
+		r.recommendations.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	}
+	if r.evaluationWindows != nil {
+		r.evaluationWindows.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	}
+	r.health.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	r.timeline.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	if r.dryRunReports != nil {
+		r.dryRunReports.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	}
+	if r.decisionHistories != nil {
+		r.decisionHistories.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	}
+	r.metricRetries.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	if r.replicaHours != nil {
+		r.replicaHours.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	}
+	if r.remoteClusters != nil {
+		r.remoteClusters.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+	}
 	reqLogger.Info("Successfully finalized WatermarkPodAutoscaler")
 }
 
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	flappingCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "Flapping"
+
+	defaultFlappingFactor = 2.0
+	maxFlappingTolerance  = 0.5
+
+	scaleDirectionUp   = "up"
+	scaleDirectionDown = "down"
+)
+
+// applyFlappingDetection forgets the reversals older than the window, and widens the forbidden windows and the
+// tolerance of the spec, in memory, while the WPA is flapping. It returns whether the WPA started flapping.
+func applyFlappingDetection(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) bool {
+	detection := wpa.Spec.FlappingDetection
+	promLabels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+	if detection == nil {
+		if wpa.Spec.Hysteresis == nil {
+			wpa.Status.LastScaleDirection = ""
+		}
+		wpa.Status.ScaleReversals = nil
+		flapping.Delete(promLabels)
+		return false
+	}
+
+	window := time.Duration(detection.WindowSeconds) * time.Second
+	var reversals []metav1.Time
+	for _, reversal := range wpa.Status.ScaleReversals {
+		if now.Sub(reversal.Time) < window {
+			reversals = append(reversals, reversal)
+		}
+	}
+	wpa.Status.ScaleReversals = reversals
+
+	if int32(len(reversals)) < detection.Reversals {
+		setCondition(wpa, flappingCondition, corev1.ConditionFalse, "StableScaling", "the scaling direction changed %d times in the last %v", len(reversals), window)
+		flapping.With(promLabels).Set(0)
+		return false
+	}
+
+	started := true
+	for _, condition := range wpa.Status.Conditions {
+		if condition.Type == flappingCondition && condition.Status == corev1.ConditionTrue {
+			started = false
+		}
+	}
+	factor := detection.Factor
+	if factor == 0 {
+		factor = defaultFlappingFactor
+	}
+	wpa.Spec.DownscaleForbiddenWindowSeconds = int32(math.Ceil(factor * float64(wpa.Spec.DownscaleForbiddenWindowSeconds)))
+	wpa.Spec.UpscaleForbiddenWindowSeconds = int32(math.Ceil(factor * float64(wpa.Spec.UpscaleForbiddenWindowSeconds)))
+	wpa.Spec.Tolerance = math.Min(factor*wpa.Spec.Tolerance, math.Max(wpa.Spec.Tolerance, maxFlappingTolerance))
+	setCondition(wpa, flappingCondition, corev1.ConditionTrue, "FrequentReversals", "the scaling direction changed %d times in the last %v, the watermarks are likely too tight: the forbidden windows are now %ds/%ds and the tolerance %v", len(reversals), window, wpa.Spec.DownscaleForbiddenWindowSeconds, wpa.Spec.UpscaleForbiddenWindowSeconds, wpa.Spec.Tolerance)
+	flapping.With(promLabels).Set(1)
+	return started
+}
+
+// recordScaleDirection keeps track of the direction of a rescale, and of the time it reversed the previous one.
+func recordScaleDirection(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas int32, now time.Time) {
+	if (wpa.Spec.FlappingDetection == nil && wpa.Spec.Hysteresis == nil) || desiredReplicas == currentReplicas {
+		return
+	}
+	direction := scaleDirectionUp
+	if desiredReplicas < currentReplicas {
+		direction = scaleDirectionDown
+	}
+	if wpa.Spec.FlappingDetection != nil && wpa.Status.LastScaleDirection != "" && wpa.Status.LastScaleDirection != direction {
+		wpa.Status.ScaleReversals = append(wpa.Status.ScaleReversals, metav1.NewTime(now))
+	}
+	wpa.Status.LastScaleDirection = direction
+}
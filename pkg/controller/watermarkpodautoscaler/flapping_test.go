@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFlappingDetection(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			DownscaleForbiddenWindowSeconds: 60,
+			UpscaleForbiddenWindowSeconds:   30,
+			Tolerance:                       0.1,
+			FlappingDetection: &v1alpha1.FlappingDetectionSpec{
+				Reversals:     2,
+				WindowSeconds: 600,
+			},
+		},
+	})
+
+	recordScaleDirection(wpa, 5, 10, start)
+	recordScaleDirection(wpa, 10, 12, start.Add(time.Minute))
+	require.Empty(t, wpa.Status.ScaleReversals, "scaling twice in the same direction is not a reversal")
+	recordScaleDirection(wpa, 12, 8, start.Add(2*time.Minute))
+	require.Len(t, wpa.Status.ScaleReversals, 1)
+
+	require.False(t, applyFlappingDetection(wpa, start.Add(3*time.Minute)))
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+	require.Equal(t, int32(60), wpa.Spec.DownscaleForbiddenWindowSeconds)
+
+	recordScaleDirection(wpa, 8, 11, start.Add(4*time.Minute))
+	require.True(t, applyFlappingDetection(wpa, start.Add(5*time.Minute)), "the WPA started flapping")
+	require.Equal(t, flappingCondition, wpa.Status.Conditions[0].Type)
+	require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status)
+	require.Equal(t, int32(120), wpa.Spec.DownscaleForbiddenWindowSeconds)
+	require.Equal(t, int32(60), wpa.Spec.UpscaleForbiddenWindowSeconds)
+	require.Equal(t, 0.2, wpa.Spec.Tolerance)
+
+	wpa.Spec.Tolerance = 0.4
+	require.False(t, applyFlappingDetection(wpa, start.Add(6*time.Minute)), "the WPA was already flapping")
+	require.Equal(t, maxFlappingTolerance, wpa.Spec.Tolerance)
+
+	require.False(t, applyFlappingDetection(wpa, start.Add(13*time.Minute)))
+	require.Len(t, wpa.Status.ScaleReversals, 1, "the reversals older than the window are forgotten")
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+}
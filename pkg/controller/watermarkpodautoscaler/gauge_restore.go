@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// gaugeRestorer sets the gauges of the WPAs from their spec and status once the caches are synced, so that the
+// dashboards and alerts don't miss them after a restart of the controller until every WPA is reconciled again.
+type gaugeRestorer struct {
+	client client.Client
+}
+
+// Start implements manager.Runnable, it restores the gauges once and returns.
+func (g *gaugeRestorer) Start(stop <-chan struct{}) error {
+	g.restore()
+	return nil
+}
+
+func (g *gaugeRestorer) restore() {
+	wpas := &datadoghqv1alpha1.WatermarkPodAutoscalerList{}
+	if err := g.client.List(context.TODO(), wpas); err != nil {
+		log.Error(err, "Could not list the WPAs to restore their metrics")
+		return
+	}
+	restored := 0
+	for i := range wpas.Items {
+		wpa := &wpas.Items[i]
+		// the WPAs never reconciled have no status to restore the gauges from
+		if wpa.DeletionTimestamp != nil || wpa.Status.LastSuccessfulReconcile == nil || !util.OwnsNamespace(wpa.Namespace) {
+			continue
+		}
+		restoreGauges(datadoghqv1alpha1.DefaultWatermarkPodAutoscaler(wpa))
+		restored++
+	}
+	log.Info("Restored the metrics of the WPAs from their status", "count", restored)
+}
+
+// restoreGauges sets the replica, watermark and value gauges of a WPA to the ones of its last successful reconcile.
+// The watermarks are only restored when they are set in the spec, the other ones being resolved at each reconcile.
+func restoreGauges(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) {
+	promLabelsForWpa := prometheus.Labels{
+		wpaNamePromLabel:           wpa.Name,
+		resourceNamespacePromLabel: wpa.Namespace,
+		resourceNamePromLabel:      wpa.Spec.ScaleTargetRef.Name,
+		resourceKindPromLabel:      wpa.Spec.ScaleTargetRef.Kind,
+	}
+	if wpa.Spec.MinReplicas != nil {
+		replicaMin.With(promLabelsForWpa).Set(float64(*wpa.Spec.MinReplicas))
+	}
+	replicaMax.With(promLabelsForWpa).Set(float64(wpa.Spec.MaxReplicas))
+	replicaEffective.With(promLabelsForWpa).Set(float64(wpa.Status.DesiredReplicas))
+
+	var proposal int32
+	values := map[string]float64{}
+	for _, detail := range wpa.Status.MetricDetails {
+		values[detail.Name] = float64(detail.AdjustedValue.MilliValue())
+		if detail.ProposedReplicas > proposal {
+			proposal = detail.ProposedReplicas
+		}
+	}
+	if proposal > 0 {
+		replicaProposal.With(promLabelsForWpa).Set(float64(proposal))
+	}
+
+	for _, metric := range wpa.Spec.Metrics {
+		var name string
+		var low, high float64
+		watermarks := false
+		switch {
+		case metric.External != nil:
+			name = metric.External.MetricName
+			if metric.External.LowWatermark != nil && metric.External.HighWatermark != nil {
+				low, high, watermarks = float64(metric.External.LowWatermark.MilliValue()), float64(metric.External.HighWatermark.MilliValue()), true
+			}
+		case metric.Resource != nil:
+			name = string(metric.Resource.Name)
+			if metric.Resource.LowWatermark != nil && metric.Resource.HighWatermark != nil {
+				low, high, watermarks = float64(metric.Resource.LowWatermark.MilliValue()), float64(metric.Resource.HighWatermark.MilliValue()), true
+			}
+		default:
+			continue
+		}
+		labelsWithMetricName := prometheus.Labels{metricNamePromLabel: name}
+		for label, value := range promLabelsForWpa {
+			labelsWithMetricName[label] = value
+		}
+		if watermarks {
+			lowwm.With(labelsWithMetricName).Set(low)
+			lowwmV2.With(labelsWithMetricName).Set(low)
+			highwm.With(labelsWithMetricName).Set(high)
+			highwmV2.With(labelsWithMetricName).Set(high)
+		}
+		if v, found := values[name]; found {
+			value.With(labelsWithMetricName).Set(v)
+		}
+	}
+}
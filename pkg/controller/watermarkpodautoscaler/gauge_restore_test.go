@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGaugeRestorer(t *testing.T) {
+	reconciled := metav1.Now()
+	newWPA := func(name string, status *v1alpha1.WatermarkPodAutoscalerStatus) *v1alpha1.WatermarkPodAutoscaler {
+		return test.NewWatermarkPodAutoscaler(testingNamespace, name, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+				ScaleTargetRef: v1alpha1.CrossVersionObjectReference{Kind: "Deployment", Name: name},
+				MinReplicas:    v1alpha1.NewInt32(2),
+				MaxReplicas:    12,
+				Metrics: []v1alpha1.MetricSpec{
+					{
+						Type: v1alpha1.ExternalMetricSourceType,
+						External: &v1alpha1.ExternalMetricSource{
+							MetricName:    "queue.lag",
+							HighWatermark: resource.NewQuantity(100, resource.DecimalSI),
+							LowWatermark:  resource.NewQuantity(50, resource.DecimalSI),
+						},
+					},
+				},
+			},
+			Status: status,
+		})
+	}
+	restored := newWPA("restored", &v1alpha1.WatermarkPodAutoscalerStatus{
+		LastSuccessfulReconcile: &reconciled,
+		DesiredReplicas:         6,
+		MetricDetails: []v1alpha1.MetricDetailStatus{
+			{Name: "queue.lag", AdjustedValue: *resource.NewQuantity(120, resource.DecimalSI), ProposedReplicas: 7},
+		},
+	})
+	neverReconciled := newWPA("never-reconciled", &v1alpha1.WatermarkPodAutoscalerStatus{})
+	defer cleanupAssociatedMetrics(restored, false)
+
+	s := runtime.NewScheme()
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{}, &v1alpha1.WatermarkPodAutoscalerList{})
+	g := &gaugeRestorer{client: fake.NewFakeClientWithScheme(s, restored, neverReconciled)}
+	g.restore()
+
+	labels := wpaPromLabels(restored)
+	require.Equal(t, 2.0, gaugeValue(t, replicaMin.With(labels)))
+	require.Equal(t, 12.0, gaugeValue(t, replicaMax.With(labels)))
+	require.Equal(t, 6.0, gaugeValue(t, replicaEffective.With(labels)))
+	require.Equal(t, 7.0, gaugeValue(t, replicaProposal.With(labels)))
+	labels[metricNamePromLabel] = "queue.lag"
+	require.Equal(t, 50000.0, gaugeValue(t, lowwmV2.With(labels)))
+	require.Equal(t, 100000.0, gaugeValue(t, highwmV2.With(labels)))
+	require.Equal(t, 120000.0, gaugeValue(t, value.With(labels)))
+
+	require.False(t, replicaEffective.Delete(wpaPromLabels(neverReconciled)), "the WPAs never reconciled should be skipped")
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// minWPAsForReconcileHealth is the number of WPAs under which the reconciles are not taken into account in the
+// health of the controller, so that a single misconfigured WPA doesn't get it restarted.
+const minWPAsForReconcileHealth = 3
+
+var maxFailingReconcileRatio float64
+
+func init() {
+	flag.Float64Var(&maxFailingReconcileRatio, "max-failing-reconcile-ratio", 0.8, "Ratio of the WPAs whose last reconcile failed above which the controller reports itself unhealthy, 0 to disable the check")
+}
+
+// reconcileHealth keeps track of the WPAs whose last reconcile failed.
+type reconcileHealth struct {
+	mu      sync.Mutex
+	failing map[types.NamespacedName]bool
+}
+
+func newReconcileHealth() *reconcileHealth {
+	return &reconcileHealth{failing: make(map[types.NamespacedName]bool)}
+}
+
+func (h *reconcileHealth) record(key types.NamespacedName, err error) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failing[key] = err != nil
+}
+
+func (h *reconcileHealth) forget(key types.NamespacedName) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failing, key)
+}
+
+// check implements healthz.Checker, it fails when the last reconcile of most WPAs failed.
+func (h *reconcileHealth) check(_ *http.Request) error {
+	if maxFailingReconcileRatio <= 0 {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.failing) < minWPAsForReconcileHealth {
+		return nil
+	}
+	failing := 0
+	for _, failed := range h.failing {
+		if failed {
+			failing++
+		}
+	}
+	if float64(failing)/float64(len(h.failing)) > maxFailingReconcileRatio {
+		return fmt.Errorf("the last reconcile of %d out of %d WPAs failed", failing, len(h.failing))
+	}
+	return nil
+}
+
+// addHealthChecks reports the reconciles and the reachability of the API server in the liveness of the controller,
+// and the synchronization of its caches in its readiness.
+func addHealthChecks(mgr manager.Manager, health *reconcileHealth, discoveryClient discovery.ServerVersionInterface, podsSynced cache.InformerSynced) error {
+	if err := mgr.AddHealthzCheck("reconciles", health.check); err != nil {
+		return err
+	}
+	if err := mgr.AddHealthzCheck("discovery", discoveryCheck(discoveryClient)); err != nil {
+		return err
+	}
+	return mgr.AddReadyzCheck("caches", cachesSyncedCheck(mgr, podsSynced))
+}
+
+func discoveryCheck(client discovery.ServerVersionInterface) healthz.Checker {
+	return func(_ *http.Request) error {
+		if _, err := client.ServerVersion(); err != nil {
+			return fmt.Errorf("the API server can't be reached: %v", err)
+		}
+		return nil
+	}
+}
+
+func cachesSyncedCheck(mgr manager.Manager, podsSynced cache.InformerSynced) healthz.Checker {
+	// A closed channel makes WaitForCacheSync return right away.
+	closed := make(chan struct{})
+	close(closed)
+	return func(_ *http.Request) error {
+		if !podsSynced() {
+			return fmt.Errorf("the pods are not synced yet")
+		}
+		if !mgr.GetCache().WaitForCacheSync(closed) {
+			return fmt.Errorf("the caches of the manager are not synced yet")
+		}
+		return nil
+	}
+}
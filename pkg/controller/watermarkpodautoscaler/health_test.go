@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileHealth(t *testing.T) {
+	h := newReconcileHealth()
+	key := func(name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: testingNamespace, Name: name}
+	}
+	failure := errors.New("unable to get the scale of the target")
+
+	h.record(key("a"), failure)
+	h.record(key("b"), failure)
+	require.NoError(t, h.check(nil), "a couple of misconfigured WPAs don't make the controller unhealthy")
+
+	h.record(key("c"), failure)
+	h.record(key("d"), nil)
+	require.NoError(t, h.check(nil), "3 out of 4 is below the ratio")
+
+	h.record(key("d"), failure)
+	require.EqualError(t, h.check(nil), "the last reconcile of 4 out of 4 WPAs failed")
+
+	h.forget(key("d"))
+	h.record(key("c"), nil)
+	require.NoError(t, h.check(nil))
+
+	var disabled *reconcileHealth
+	disabled.record(key("a"), failure)
+	disabled.forget(key("a"))
+}
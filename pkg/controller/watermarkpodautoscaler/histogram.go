@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// defaultBucketLabel is the label holding the upper bound of the buckets of the histograms, as in Prometheus.
+const defaultBucketLabel = "le"
+
+// bucketLabel returns the label of the series holding the upper bound of their bucket.
+func bucketLabel(histogram *v1alpha1.HistogramMetricSpec) string {
+	if histogram.BucketLabel == "" {
+		return defaultBucketLabel
+	}
+	return histogram.BucketLabel
+}
+
+// getExternalMetricQuantile returns the quantile, as a milli-value, of the histogram whose buckets are the series of
+// the external metric across the calculator and its shards.
+func (c *ReplicaCalculator) getExternalMetricQuantile(ctx context.Context, metricName, namespace string, selector labels.Selector, histogram *v1alpha1.HistogramMetricSpec, shards []*ReplicaCalculator) ([]int64, time.Time, error) {
+	buckets, timestamp, err := c.getExternalMetricBuckets(ctx, metricName, namespace, selector, bucketLabel(histogram))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for _, shard := range shards {
+		shardBuckets, shardTimestamp, err := shard.getExternalMetricBuckets(ctx, metricName, namespace, selector, bucketLabel(histogram))
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("unable to get the metric of a shard: %w", err)
+		}
+		for bound, count := range shardBuckets {
+			buckets[bound] += count
+		}
+		if shardTimestamp.Before(timestamp) {
+			timestamp = shardTimestamp
+		}
+	}
+	quantile, err := histogramQuantile(buckets, histogram.Quantile)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to compute the p%d of the metric %s: %v", histogram.Quantile, metricName, err)
+	}
+	return []int64{int64(quantile * 1000)}, timestamp, nil
+}
+
+// getExternalMetricBuckets queries the external metrics API, unless its circuit breaker is open, and exports its
+// latency.
+func (c *ReplicaCalculator) getExternalMetricBuckets(ctx context.Context, metricName, namespace string, selector labels.Selector, label string) (map[string]int64, time.Time, error) {
+	if err := c.externalBreaker.allow(time.Now()); err != nil {
+		return nil, time.Time{}, err
+	}
+	var buckets map[string]int64
+	var timestamp time.Time
+	start := time.Now()
+	err := callWithContext(ctx, func() (err error) {
+		buckets, timestamp, err = c.metricsClient.GetExternalMetricBuckets(metricName, namespace, selector, label)
+		return err
+	})
+	observeMetricsProviderLatency(externalMetricsAPI, start)
+	c.externalBreaker.record(err, time.Now())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return buckets, timestamp, nil
+}
+
+// histogramBucket is a bucket of a cumulative histogram, counting the observations lower than or equal to its bound.
+type histogramBucket struct {
+	bound float64
+	count float64
+}
+
+// histogramQuantile returns the percentile of the cumulative histogram, keyed by the upper bound of its buckets, in
+// the unit of the bounds. As with the histogram_quantile of Prometheus, the observations are assumed to be spread
+// linearly within their bucket, the lowest one starting at 0, and a quantile in the `+Inf` bucket is the highest
+// finite bound.
+func histogramQuantile(buckets map[string]int64, quantile int32) (float64, error) {
+	sorted := make([]histogramBucket, 0, len(buckets))
+	for bound, count := range buckets {
+		value, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid bucket bound %q", bound)
+		}
+		sorted = append(sorted, histogramBucket{bound: value, count: float64(count)})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].bound < sorted[j].bound })
+	// The buckets read at slightly different times can be out of order.
+	for i := 1; i < len(sorted); i++ {
+		sorted[i].count = math.Max(sorted[i].count, sorted[i-1].count)
+	}
+	if len(sorted) == 0 || sorted[len(sorted)-1].count == 0 {
+		return 0, fmt.Errorf("no observation in the buckets")
+	}
+	rank := float64(quantile) / 100 * sorted[len(sorted)-1].count
+	i := sort.Search(len(sorted), func(i int) bool { return sorted[i].count >= rank })
+	if math.IsInf(sorted[i].bound, 1) {
+		if i == 0 {
+			return 0, fmt.Errorf("no finite bucket bound")
+		}
+		return sorted[i-1].bound, nil
+	}
+	lowerBound, lowerCount := 0.0, 0.0
+	if i > 0 {
+		lowerBound, lowerCount = sorted[i-1].bound, sorted[i-1].count
+	} else if sorted[i].bound <= 0 {
+		return sorted[i].bound, nil
+	}
+	return lowerBound + (sorted[i].bound-lowerBound)*(rank-lowerCount)/(sorted[i].count-lowerCount), nil
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	latencies := map[string]int64{"0.1": 50000, "0.5": 90000, "1": 100000, "+Inf": 100000}
+	tests := []struct {
+		name     string
+		buckets  map[string]int64
+		quantile int32
+		want     float64
+		wantErr  string
+	}{
+		{name: "first bucket", buckets: latencies, quantile: 50, want: 0.1},
+		{name: "interpolated within its bucket", buckets: latencies, quantile: 95, want: 0.75},
+		{name: "highest observation", buckets: latencies, quantile: 100, want: 1},
+		{name: "in the +Inf bucket", buckets: map[string]int64{"0.1": 50000, "+Inf": 100000}, quantile: 95, want: 0.1},
+		{name: "buckets out of order", buckets: map[string]int64{"0.1": 60000, "0.5": 55000, "+Inf": 100000}, quantile: 30, want: 0.05},
+		{name: "without +Inf bucket", buckets: map[string]int64{"10": 20000, "20": 40000}, quantile: 75, want: 15},
+		{name: "no observation", buckets: map[string]int64{"0.1": 0, "+Inf": 0}, quantile: 95, wantErr: "no observation in the buckets"},
+		{name: "no bucket", buckets: map[string]int64{}, quantile: 95, wantErr: "no observation in the buckets"},
+		{name: "invalid bound", buckets: map[string]int64{"fast": 10}, quantile: 95, wantErr: `invalid bucket bound "fast"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := histogramQuantile(tt.buckets, tt.quantile)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestGetExternalMetricQuantile(t *testing.T) {
+	now := time.Now()
+	newCalculator := func(buckets map[string]int64, timestamp time.Time) *ReplicaCalculator {
+		return NewReplicaCalculator(fakeMetricsClient{
+			getExternalMetricBuckets: func(metricName string, namespace string, selector labels.Selector, bucketLabel string) (map[string]int64, time.Time, error) {
+				require.Equal(t, "latency", metricName)
+				require.Equal(t, "bucket", bucketLabel)
+				return buckets, timestamp, nil
+			},
+		}, nil, nil)
+	}
+	c := newCalculator(map[string]int64{"0.1": 40000, "1": 50000, "+Inf": 50000}, now)
+	shard := newCalculator(map[string]int64{"0.1": 0, "1": 50000, "+Inf": 50000}, now.Add(-time.Minute))
+	histogram := &v1alpha1.HistogramMetricSpec{Quantile: 50, BucketLabel: "bucket"}
+
+	metrics, timestamp, err := c.getExternalMetricQuantile(context.TODO(), "latency", testingNamespace, labels.Everything(), histogram, []*ReplicaCalculator{shard})
+	require.NoError(t, err)
+	require.Equal(t, []int64{250}, metrics, "the buckets of the shards are merged, and the quantile is a milli-value")
+	require.Equal(t, now.Add(-time.Minute), timestamp)
+
+	require.Equal(t, "le", bucketLabel(&v1alpha1.HistogramMetricSpec{Quantile: 95}))
+}
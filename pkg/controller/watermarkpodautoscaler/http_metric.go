@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxHTTPMetricBodySize bounds the size of the JSON documents read for the metrics.
+const maxHTTPMetricBodySize = 1 << 20
+
+// getHTTPMetric fetches the JSON document of the source and returns the values selected by its JSONPath, as milli
+// values like the ones of the External Metrics Provider. The timestamp is the time of the response.
+func (c *ReplicaCalculator) getHTTPMetric(ctx context.Context, namespace string, source *v1alpha1.HTTPMetricSource) ([]int64, time.Time, error) {
+	reader := c.client
+	if c.apiReader != nil {
+		reader = c.apiReader
+	}
+	values, err := fetchHTTPValues(ctx, reader, c.httpClient, namespace, source)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return values, time.Now(), nil
+}
+
+// fetchHTTPValues fetches the JSON document of the source and returns the values selected by its JSONPath, as milli
+// values. The Secret of the Authorization header is read from the namespace.
+func fetchHTTPValues(ctx context.Context, reader client.Reader, httpClient *http.Client, namespace string, source *v1alpha1.HTTPMetricSource) ([]int64, error) {
+	parser := jsonpath.New("metric")
+	if err := parser.Parse(source.JSONPath); err != nil {
+		return nil, fmt.Errorf("invalid jsonPath %q: %v", source.JSONPath, err)
+	}
+	req, err := http.NewRequest(http.MethodGet, source.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if ref := source.AuthorizationSecretRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err = reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			return nil, fmt.Errorf("unable to get the Secret %s: %v", ref.Name, err)
+		}
+		value, found := secret.Data[ref.Key]
+		if !found {
+			return nil, fmt.Errorf("the key %s is not found in the Secret %s", ref.Key, ref.Name)
+		}
+		req.Header.Set("Authorization", strings.TrimSpace(string(value)))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, source.URL)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHTTPMetricBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the response of %s: %v", source.URL, err)
+	}
+	var document interface{}
+	if err = json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON document from %s: %v", source.URL, err)
+	}
+
+	results, err := parser.FindResults(document)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find %s in the document from %s: %v", source.JSONPath, source.URL, err)
+	}
+	var values []int64
+	for _, result := range results {
+		for _, v := range result {
+			value, err := httpMetricValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value selected by %s in the document from %s: %v", source.JSONPath, source.URL, err)
+			}
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no value selected by %s in the document from %s", source.JSONPath, source.URL)
+	}
+	return values, nil
+}
+
+// httpMetricValue converts a number, or a string holding a quantity, of a JSON document to a milli value.
+func httpMetricValue(v reflect.Value) (int64, error) {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Float64:
+		return int64(v.Float() * 1000), nil
+	case reflect.String:
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(v.String()))
+		if err != nil {
+			return 0, err
+		}
+		return quantity.MilliValue(), nil
+	}
+	return 0, fmt.Errorf("expected a number or a quantity, got %v", v.Kind())
+}
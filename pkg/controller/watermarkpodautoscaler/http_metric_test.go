@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetHTTPMetric(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"queues": [{"name": "a", "depth": 12}, {"name": "b", "depth": "1500m"}], "status": "ok"}`))
+	}))
+	defer server.Close()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "queue-api"},
+		Data:       map[string][]byte{"token": []byte("Bearer s3cr3t\n")},
+	}
+	c := &ReplicaCalculator{client: fake.NewFakeClient(secret), httpClient: server.Client()}
+	auth := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "queue-api"}, Key: "token"}
+
+	tests := []struct {
+		name    string
+		source  *v1alpha1.HTTPMetricSource
+		want    []int64
+		wantErr string
+	}{
+		{
+			name:   "numbers and quantities",
+			source: &v1alpha1.HTTPMetricSource{URL: server.URL, JSONPath: "{.queues[*].depth}", AuthorizationSecretRef: auth},
+			want:   []int64{12000, 1500},
+		},
+		{
+			name:    "not a number",
+			source:  &v1alpha1.HTTPMetricSource{URL: server.URL, JSONPath: "{.status}", AuthorizationSecretRef: auth},
+			wantErr: "invalid value selected by {.status} in the document from " + server.URL + ": quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'",
+		},
+		{
+			name:    "missing authorization",
+			source:  &v1alpha1.HTTPMetricSource{URL: server.URL, JSONPath: "{.queues[*].depth}"},
+			wantErr: "unexpected status 401 Unauthorized from " + server.URL,
+		},
+		{
+			name:    "missing Secret",
+			source:  &v1alpha1.HTTPMetricSource{URL: server.URL, JSONPath: "{.queues[*].depth}", AuthorizationSecretRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "other"}, Key: "token"}},
+			wantErr: `unable to get the Secret other: secrets "other" not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _, err := c.getHTTPMetric(context.TODO(), testingNamespace, tt.source)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, values)
+		})
+	}
+}
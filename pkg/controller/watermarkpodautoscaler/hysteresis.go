@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// applyHysteresis widens the watermarks of the spec, in memory, during the hysteresis after the last scale event:
+// the low watermarks are lowered after an upscale, and the high watermarks raised after a downscale, so that the
+// new number of replicas is not reversed right away.
+func applyHysteresis(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) {
+	hysteresis := wpa.Spec.Hysteresis
+	if hysteresis == nil || wpa.Status.LastScaleTime == nil {
+		return
+	}
+	duration := time.Duration(hysteresis.DurationSeconds) * time.Second
+	if now.Sub(wpa.Status.LastScaleTime.Time) >= duration {
+		return
+	}
+	var factor float64
+	switch wpa.Status.LastScaleDirection {
+	case scaleDirectionUp:
+		factor = 1 - float64(hysteresis.Percent)/100
+	case scaleDirectionDown:
+		factor = 1 + float64(hysteresis.Percent)/100
+	default:
+		return
+	}
+	for _, metric := range wpa.Spec.Metrics {
+		switch {
+		case metric.External != nil:
+			widenWatermarks(wpa.Status.LastScaleDirection, factor, &metric.External.LowWatermark, &metric.External.HighWatermark, metric.External.WatermarkSteps)
+		case metric.Resource != nil:
+			widenWatermarks(wpa.Status.LastScaleDirection, factor, &metric.Resource.LowWatermark, &metric.Resource.HighWatermark, metric.Resource.WatermarkSteps)
+			utilization := metric.Resource.LowWatermarkUtilization
+			if wpa.Status.LastScaleDirection == scaleDirectionDown {
+				utilization = metric.Resource.HighWatermarkUtilization
+			}
+			if utilization != nil {
+				*utilization = int32(float64(*utilization) * factor)
+			}
+		}
+	}
+	logger.Info("Widening the watermarks after the last scale event", "direction", wpa.Status.LastScaleDirection, "lastScaleTime", wpa.Status.LastScaleTime, "percent", hysteresis.Percent, "duration", duration)
+}
+
+// widenWatermarks multiplies the low watermarks by the factor after an upscale, and the high ones after a downscale.
+func widenWatermarks(direction string, factor float64, lowMark, highMark **resource.Quantity, steps []datadoghqv1alpha1.WatermarkStep) {
+	if direction == scaleDirectionUp {
+		*lowMark = scaleQuantity(*lowMark, factor)
+		for i := range steps {
+			steps[i].LowWatermark = *scaleQuantity(&steps[i].LowWatermark, factor)
+		}
+		return
+	}
+	*highMark = scaleQuantity(*highMark, factor)
+	for i := range steps {
+		steps[i].HighWatermark = *scaleQuantity(&steps[i].HighWatermark, factor)
+	}
+}
+
+func scaleQuantity(quantity *resource.Quantity, factor float64) *resource.Quantity {
+	if quantity == nil {
+		return nil
+	}
+	return resource.NewMilliQuantity(int64(float64(quantity.MilliValue())*factor), quantity.Format)
+}
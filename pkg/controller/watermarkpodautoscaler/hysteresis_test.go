@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestApplyHysteresis(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	newWPA := func() *v1alpha1.WatermarkPodAutoscaler {
+		return test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+				Hysteresis: &v1alpha1.HysteresisSpec{Percent: 20, DurationSeconds: 300},
+				Metrics: []v1alpha1.MetricSpec{
+					{
+						Type: v1alpha1.ExternalMetricSourceType,
+						External: &v1alpha1.ExternalMetricSource{
+							MetricName:    "queue_length",
+							LowWatermark:  resource.NewQuantity(50, resource.DecimalSI),
+							HighWatermark: resource.NewQuantity(100, resource.DecimalSI),
+							WatermarkSteps: []v1alpha1.WatermarkStep{
+								{MinReplicas: 10, LowWatermark: resource.MustParse("500"), HighWatermark: resource.MustParse("1000")},
+							},
+						},
+					},
+					{
+						Type: v1alpha1.ResourceMetricSourceType,
+						Resource: &v1alpha1.ResourceMetricSource{
+							Name:                     "cpu",
+							LowWatermarkUtilization:  v1alpha1.NewInt32(40),
+							HighWatermarkUtilization: v1alpha1.NewInt32(80),
+						},
+					},
+				},
+			},
+		})
+	}
+
+	t.Run("low watermarks lowered after an upscale", func(t *testing.T) {
+		wpa := newWPA()
+		recordScaleDirection(wpa, 5, 10, start)
+		wpa.Status.LastScaleTime = &metav1.Time{Time: start}
+		applyHysteresis(logger, wpa, start.Add(time.Minute))
+		external := wpa.Spec.Metrics[0].External
+		require.Equal(t, int64(40000), external.LowWatermark.MilliValue())
+		require.Equal(t, int64(100000), external.HighWatermark.MilliValue())
+		require.Equal(t, int64(400000), external.WatermarkSteps[0].LowWatermark.MilliValue())
+		require.Equal(t, int32(32), *wpa.Spec.Metrics[1].Resource.LowWatermarkUtilization)
+		require.Equal(t, int32(80), *wpa.Spec.Metrics[1].Resource.HighWatermarkUtilization)
+	})
+
+	t.Run("high watermarks raised after a downscale", func(t *testing.T) {
+		wpa := newWPA()
+		recordScaleDirection(wpa, 10, 5, start)
+		wpa.Status.LastScaleTime = &metav1.Time{Time: start}
+		applyHysteresis(logger, wpa, start.Add(time.Minute))
+		external := wpa.Spec.Metrics[0].External
+		require.Equal(t, int64(50000), external.LowWatermark.MilliValue())
+		require.Equal(t, int64(120000), external.HighWatermark.MilliValue())
+		require.Equal(t, int64(1200000), external.WatermarkSteps[0].HighWatermark.MilliValue())
+		require.Equal(t, int32(96), *wpa.Spec.Metrics[1].Resource.HighWatermarkUtilization)
+	})
+
+	t.Run("watermarks unchanged once the duration is over", func(t *testing.T) {
+		wpa := newWPA()
+		recordScaleDirection(wpa, 5, 10, start)
+		wpa.Status.LastScaleTime = &metav1.Time{Time: start}
+		applyHysteresis(logger, wpa, start.Add(5*time.Minute))
+		require.Equal(t, int64(50000), wpa.Spec.Metrics[0].External.LowWatermark.MilliValue())
+		require.Equal(t, int32(40), *wpa.Spec.Metrics[1].Resource.LowWatermarkUtilization)
+	})
+}
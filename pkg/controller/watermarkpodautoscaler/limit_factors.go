@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+)
+
+// metricProposal is the number of replicas proposed by a metric of the WPA.
+type metricProposal struct {
+	spec     datadoghqv1alpha1.MetricSpec
+	replicas int32
+}
+
+// applyMetricLimitFactors overrides, in memory, the scale limit factors of the WPA with the ones of the metrics
+// recommending a scale in each direction. A metric without a factor uses the one of the WPA, and the strictest
+// factor of the metrics recommending a scale in the direction is applied.
+func applyMetricLimitFactors(logger logr.Logger, spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, currentReplicas int32, proposals []metricProposal) {
+	scaleUpLimitFactor, scaleDownLimitFactor := 0.0, 0.0
+	for _, proposal := range proposals {
+		switch {
+		case proposal.replicas > currentReplicas:
+			scaleUpLimitFactor = strictestLimitFactor(scaleUpLimitFactor, proposal.spec.ScaleUpLimitFactor, spec.ScaleUpLimitFactor)
+		case proposal.replicas < currentReplicas:
+			scaleDownLimitFactor = strictestLimitFactor(scaleDownLimitFactor, proposal.spec.ScaleDownLimitFactor, spec.ScaleDownLimitFactor)
+		}
+	}
+	if scaleUpLimitFactor != 0 && scaleUpLimitFactor != spec.ScaleUpLimitFactor {
+		logger.Info("Applying the scaleUpLimitFactor of the metrics", "scaleUpLimitFactor", scaleUpLimitFactor)
+		spec.ScaleUpLimitFactor = scaleUpLimitFactor
+	}
+	if scaleDownLimitFactor != 0 && scaleDownLimitFactor != spec.ScaleDownLimitFactor {
+		logger.Info("Applying the scaleDownLimitFactor of the metrics", "scaleDownLimitFactor", scaleDownLimitFactor)
+		spec.ScaleDownLimitFactor = scaleDownLimitFactor
+	}
+}
+
+// strictestLimitFactor returns the lowest of the current factor and the one of a metric, falling back to the
+// one of the WPA. A current factor of 0 is not set yet.
+func strictestLimitFactor(current, metricFactor, wpaFactor float64) float64 {
+	factor := metricFactor
+	if factor == 0 {
+		factor = wpaFactor
+	}
+	if current == 0 || factor < current {
+		return factor
+	}
+	return current
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestApplyMetricLimitFactors(t *testing.T) {
+	latency := v1alpha1.MetricSpec{ScaleUpLimitFactor: 100}
+	cost := v1alpha1.MetricSpec{ScaleDownLimitFactor: 5}
+	cpu := v1alpha1.MetricSpec{}
+	tests := []struct {
+		name          string
+		proposals     []metricProposal
+		wantScaleUp   float64
+		wantScaleDown float64
+	}{
+		{
+			name:          "factors of the WPA without overrides",
+			proposals:     []metricProposal{{spec: cpu, replicas: 15}},
+			wantScaleUp:   50,
+			wantScaleDown: 20,
+		},
+		{
+			name:          "fast expansion on a latency breach",
+			proposals:     []metricProposal{{spec: latency, replicas: 20}, {spec: cost, replicas: 8}},
+			wantScaleUp:   100,
+			wantScaleDown: 5,
+		},
+		{
+			name:          "strictest of the metrics recommending an upscale",
+			proposals:     []metricProposal{{spec: latency, replicas: 20}, {spec: cpu, replicas: 12}},
+			wantScaleUp:   50,
+			wantScaleDown: 20,
+		},
+		{
+			name:          "gentle contraction on the cost metric",
+			proposals:     []metricProposal{{spec: cost, replicas: 5}, {spec: cpu, replicas: 8}, {spec: latency, replicas: 10}},
+			wantScaleUp:   50,
+			wantScaleDown: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &v1alpha1.WatermarkPodAutoscalerSpec{ScaleUpLimitFactor: 50, ScaleDownLimitFactor: 20}
+			applyMetricLimitFactors(logf.Log.WithName(tt.name), spec, 10, tt.proposals)
+			require.Equal(t, tt.wantScaleUp, spec.ScaleUpLimitFactor)
+			require.Equal(t, tt.wantScaleDown, spec.ScaleDownLimitFactor)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"strconv"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+)
+
+// verbosityAnnotation raises the verbosity of the logs of a single WPA, e.g. "4" to log its decisions in details.
+const verbosityAnnotation = "wpa.datadoghq.com/verbosity"
+
+// verboseLogger logs the messages up to its verbosity at the info level, regardless of the level the
+// controller was started with.
+type verboseLogger struct {
+	logr.Logger
+	verbosity int
+}
+
+func (l verboseLogger) V(level int) logr.InfoLogger {
+	if level <= l.verbosity {
+		return l.Logger
+	}
+	return l.Logger.V(level)
+}
+
+func (l verboseLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithValues(keysAndValues...), verbosity: l.verbosity}
+}
+
+func (l verboseLogger) WithName(name string) logr.Logger {
+	return verboseLogger{Logger: l.Logger.WithName(name), verbosity: l.verbosity}
+}
+
+// loggerForWPA returns the logger of the WPA, with the verbosity raised by its annotation if any.
+func loggerForWPA(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) logr.Logger {
+	value, found := wpa.Annotations[verbosityAnnotation]
+	if !found {
+		return logger
+	}
+	verbosity, err := strconv.Atoi(value)
+	if err != nil || verbosity < 0 {
+		logger.Info("Invalid verbosity annotation, it should be a positive integer", "annotation", verbosityAnnotation, "value", value)
+		return logger
+	}
+	return verboseLogger{Logger: logger.WithValues("verbosity", verbosity), verbosity: verbosity}
+}
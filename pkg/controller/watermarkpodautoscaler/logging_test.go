@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger records the messages logged at the info level, the V(n) messages with n > 0 being dropped.
+type recordingLogger struct {
+	messages *[]string
+	level    int
+}
+
+func (l recordingLogger) Info(msg string, _ ...interface{}) {
+	if l.level == 0 {
+		*l.messages = append(*l.messages, msg)
+	}
+}
+func (l recordingLogger) Enabled() bool { return l.level == 0 }
+func (l recordingLogger) Error(_ error, msg string, _ ...interface{}) {
+	*l.messages = append(*l.messages, msg)
+}
+func (l recordingLogger) V(level int) logr.InfoLogger {
+	return recordingLogger{messages: l.messages, level: level}
+}
+func (l recordingLogger) WithValues(...interface{}) logr.Logger { return l }
+func (l recordingLogger) WithName(string) logr.Logger           { return l }
+
+func TestLoggerForWPA(t *testing.T) {
+	var messages []string
+	base := recordingLogger{messages: &messages}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+
+	loggerForWPA(base, wpa).V(4).Info("decision")
+	require.Empty(t, messages, "the verbosity of the controller applies by default")
+
+	wpa.Annotations[verbosityAnnotation] = "4"
+	logger := loggerForWPA(base, wpa).WithValues("foo", "bar")
+	logger.V(4).Info("decision")
+	logger.V(5).Info("details")
+	require.Equal(t, []string{"decision"}, messages)
+
+	messages = nil
+	wpa.Annotations[verbosityAnnotation] = "verbose"
+	loggerForWPA(base, wpa).V(4).Info("decision")
+	require.Equal(t, []string{"Invalid verbosity annotation, it should be a positive integer"}, messages)
+}
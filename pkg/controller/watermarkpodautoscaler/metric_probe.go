@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const metricAvailabilityCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "MetricsAvailable"
+
+// metricAvailability tells a metric missing from the metrics provider from a failure of the provider.
+type metricAvailability string
+
+const (
+	metricAvailable       metricAvailability = "available"
+	metricNotFound        metricAvailability = "not_found"
+	metricProviderFailing metricAvailability = "provider_failing"
+	// metricAvailabilityUnknown is the availability of the errors unrelated to the metrics provider.
+	metricAvailabilityUnknown metricAvailability = ""
+)
+
+var (
+	metricProbeInterval time.Duration
+
+	// errMetricNotFound is returned instead of querying a metric the last probe didn't find.
+	errMetricNotFound = errors.New("metric not found")
+)
+
+func init() {
+	flag.DurationVar(&metricProbeInterval, "metric-probe-interval", time.Minute, "Interval at which the external metrics of the WPAs are probed, to tell the missing metrics from the failures of the metrics provider, 0 to disable the probes")
+}
+
+// classifyMetricError returns the availability of a metric given the error of its query.
+func classifyMetricError(err error) metricAvailability {
+	switch {
+	case err == nil:
+		return metricAvailable
+	case errors.Is(err, errMetricNotFound):
+		return metricNotFound
+	case errors.Is(err, errCircuitOpen), errors.Is(err, context.DeadlineExceeded):
+		return metricProviderFailing
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "no metrics returned from external metrics API"):
+		return metricNotFound
+	case strings.Contains(msg, "unable to fetch metrics from external metrics API"):
+		if strings.Contains(msg, "not found") || strings.Contains(msg, "could not find the requested resource") {
+			return metricNotFound
+		}
+		return metricProviderFailing
+	}
+	return metricAvailabilityUnknown
+}
+
+// metricProbe is the outcome of the last probe of a metric.
+type metricProbe struct {
+	availability metricAvailability
+	err          string
+	time         time.Time
+}
+
+// metricProbes keeps the outcome of the last probe of the external metrics, keyed by namespace, name and selector.
+type metricProbes struct {
+	mu       sync.RWMutex
+	interval time.Duration
+	probes   map[string]metricProbe
+}
+
+func newMetricProbes(interval time.Duration) *metricProbes {
+	return &metricProbes{interval: interval, probes: map[string]metricProbe{}}
+}
+
+func metricProbeKey(namespace string, source *datadoghqv1alpha1.ExternalMetricSource) string {
+	return fmt.Sprintf("%s/%s{%v}", namespace, source.MetricName, datadoghqv1alpha1.MetricSelectorLabels(source.MetricSelector))
+}
+
+// set replaces the outcomes of the probes, forgetting the metrics no longer configured.
+func (p *metricProbes) set(probes map[string]metricProbe) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.probes = probes
+}
+
+// missing returns an error wrapping errMetricNotFound if the last probe of the metric, no older than two intervals,
+// didn't find it.
+func (p *metricProbes) missing(namespace string, source *datadoghqv1alpha1.ExternalMetricSource, now time.Time) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	probe, found := p.probes[metricProbeKey(namespace, source)]
+	if !found || probe.availability != metricNotFound || now.Sub(probe.time) > 2*p.interval {
+		return nil
+	}
+	return fmt.Errorf("%w by the probe at %s, the metric is not queried until it is found: %s", errMetricNotFound, probe.time.Format(time.RFC3339), probe.err)
+}
+
+// metricProber periodically queries the external metrics of the WPAs, once per metric, so that a missing metric
+// is no longer queried at every reconcile until it appears.
+type metricProber struct {
+	client        client.Client
+	metricsClient MetricsClient
+	probes        *metricProbes
+}
+
+// Start implements manager.Runnable.
+func (p *metricProber) Start(stop <-chan struct{}) error {
+	wait.Until(func() { p.probe(time.Now()) }, p.probes.interval, stop)
+	return nil
+}
+
+func (p *metricProber) probe(now time.Time) {
+	wpas := &datadoghqv1alpha1.WatermarkPodAutoscalerList{}
+	if err := p.client.List(context.TODO(), wpas); err != nil {
+		log.Error(err, "Could not list the WPAs to probe their metrics")
+		return
+	}
+	probes := map[string]metricProbe{}
+	for _, wpa := range wpas.Items {
+		// the metrics of the remote clusters are served by their own providers
+		if wpa.DeletionTimestamp != nil || wpa.Spec.RemoteCluster != nil || !util.OwnsNamespace(wpa.Namespace) {
+			continue
+		}
+		for _, metric := range wpa.Spec.Metrics {
+			if metric.External == nil || metric.External.HTTP != nil {
+				continue
+			}
+			key := metricProbeKey(wpa.Namespace, metric.External)
+			if _, probed := probes[key]; probed {
+				continue
+			}
+			probes[key] = p.probeMetric(wpa.Namespace, metric.External, now)
+		}
+	}
+	p.probes.set(probes)
+}
+
+func (p *metricProber) probeMetric(namespace string, source *datadoghqv1alpha1.ExternalMetricSource, now time.Time) metricProbe {
+	selector, err := metav1.LabelSelectorAsSelector(source.MetricSelector)
+	if err == nil {
+		_, _, err = p.metricsClient.GetExternalMetric(source.MetricName, namespace, selector)
+	}
+	probe := metricProbe{availability: classifyMetricError(err), time: now}
+	if err != nil {
+		probe.err = err.Error()
+		log.V(1).Info("Probed an unavailable metric", "namespace", namespace, "metricName", source.MetricName, "availability", probe.availability, "error", err)
+	}
+	return probe
+}
+
+// setMetricAvailabilityCondition reports a missing metric, or a failing metrics provider, in the conditions of the
+// WPA. As for the circuit breaker, the condition is only added once a metric was unavailable, and set back to true by
+// the next successful query.
+func setMetricAvailabilityCondition(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, metricName string, availability metricAvailability) {
+	switch availability {
+	case metricNotFound:
+		setCondition(wpa, metricAvailabilityCondition, corev1.ConditionFalse, "MetricNotFound", "the metric %s does not exist in the metrics provider", metricName)
+	case metricProviderFailing:
+		setCondition(wpa, metricAvailabilityCondition, corev1.ConditionFalse, "MetricsProviderFailing", "the metrics provider failed to return the metric %s, the scaling resumes once it recovers", metricName)
+	case metricAvailable:
+		for _, condition := range wpa.Status.Conditions {
+			if condition.Type == metricAvailabilityCondition {
+				setCondition(wpa, metricAvailabilityCondition, corev1.ConditionTrue, "MetricsAvailable", "the metrics provider returned the metrics")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClassifyMetricError(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want metricAvailability
+	}{
+		{nil, metricAvailable},
+		{fmt.Errorf("unable to get external metric: %w", errMetricNotFound), metricNotFound},
+		{fmt.Errorf("unable to get external metric: no metrics returned from external metrics API"), metricNotFound},
+		{fmt.Errorf("unable to fetch metrics from external metrics API: the server could not find the requested resource"), metricNotFound},
+		{fmt.Errorf("unable to fetch metrics from external metrics API: connection refused"), metricProviderFailing},
+		{fmt.Errorf("unable to get external metric: %w", errCircuitOpen), metricProviderFailing},
+		{context.DeadlineExceeded, metricProviderFailing},
+		{errors.New("unable to get the number of ready pods"), metricAvailabilityUnknown},
+	} {
+		require.Equal(t, tt.want, classifyMetricError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestMetricProber(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	newWPA := func(name string, metricNames ...string) *v1alpha1.WatermarkPodAutoscaler {
+		var metrics []v1alpha1.MetricSpec
+		for _, metricName := range metricNames {
+			metrics = append(metrics, v1alpha1.MetricSpec{
+				Type: v1alpha1.ExternalMetricSourceType,
+				External: &v1alpha1.ExternalMetricSource{
+					MetricName:     metricName,
+					MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"service": "checkout"}},
+				},
+			})
+		}
+		return test.NewWatermarkPodAutoscaler(testingNamespace, name, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{Metrics: metrics},
+		})
+	}
+	queries := map[string]int{}
+	metricsClient := fakeMetricsClient{
+		getExternalMetrics: func(metricName string, namespace string, selector labels.Selector) ([]int64, time.Time, error) {
+			queries[metricName]++
+			switch metricName {
+			case "missing":
+				return nil, time.Time{}, fmt.Errorf("no metrics returned from external metrics API")
+			case "failing":
+				return nil, time.Time{}, fmt.Errorf("unable to fetch metrics from external metrics API: connection refused")
+			}
+			return []int64{1000}, now, nil
+		},
+	}
+	s := runtime.NewScheme()
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{}, &v1alpha1.WatermarkPodAutoscalerList{})
+	p := &metricProber{
+		client:        fake.NewFakeClientWithScheme(s, newWPA("checkout", "requests", "missing"), newWPA("checkout-canary", "requests", "failing")),
+		metricsClient: metricsClient,
+		probes:        newMetricProbes(time.Minute),
+	}
+	p.probe(now)
+	require.Equal(t, map[string]int{"requests": 1, "missing": 1, "failing": 1}, queries, "each metric should be probed once")
+
+	source := func(metricName string) *v1alpha1.ExternalMetricSource {
+		return newWPA("", metricName).Spec.Metrics[0].External
+	}
+	require.NoError(t, p.probes.missing(testingNamespace, source("requests"), now))
+	require.NoError(t, p.probes.missing(testingNamespace, source("failing"), now), "a failing provider should still be queried")
+	err := p.probes.missing(testingNamespace, source("missing"), now.Add(time.Minute))
+	require.True(t, errors.Is(err, errMetricNotFound))
+	require.Equal(t, metricNotFound, classifyMetricError(fmt.Errorf("unable to get external metric: %w", err)))
+	require.NoError(t, p.probes.missing(testingNamespace, source("missing"), now.Add(3*time.Minute)), "an outdated probe should be ignored")
+
+	var nilProbes *metricProbes
+	require.NoError(t, nilProbes.missing(testingNamespace, source("missing"), now), "the probes may be disabled")
+}
+
+func TestSetMetricAvailabilityCondition(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+
+	setMetricAvailabilityCondition(wpa, "requests", metricAvailable)
+	setMetricAvailabilityCondition(wpa, "requests", metricAvailabilityUnknown)
+	require.Empty(t, wpa.Status.Conditions, "the condition should only be added once a metric was unavailable")
+
+	setMetricAvailabilityCondition(wpa, "requests", metricNotFound)
+	require.Len(t, wpa.Status.Conditions, 1)
+	require.Equal(t, metricAvailabilityCondition, wpa.Status.Conditions[0].Type)
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+	require.Equal(t, "MetricNotFound", wpa.Status.Conditions[0].Reason)
+
+	setMetricAvailabilityCondition(wpa, "requests", metricProviderFailing)
+	require.Equal(t, "MetricsProviderFailing", wpa.Status.Conditions[0].Reason)
+
+	setMetricAvailabilityCondition(wpa, "requests", metricAvailable)
+	require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status)
+}
@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	metricRetryBaseDelay time.Duration
+	metricRetryMaxDelay  time.Duration
+
+	// errMetricBackoff is returned instead of computing a failed metric until its next retry.
+	errMetricBackoff = errors.New("metric backing off")
+)
+
+func init() {
+	flag.DurationVar(&metricRetryBaseDelay, "metric-retry-base-delay", defaultSyncPeriod, "Delay before computing a metric of a WPA again after it failed, doubled at every consecutive failure, 0 to compute the failed metrics at every reconcile")
+	flag.DurationVar(&metricRetryMaxDelay, "metric-retry-max-delay", 5*time.Minute, "Maximum delay before computing a failed metric of a WPA again")
+}
+
+type metricRetryKey struct {
+	wpa    types.NamespacedName
+	metric string
+}
+
+// metricRetry is the number of consecutive failures of a metric and the time it can be computed again.
+type metricRetry struct {
+	failures int
+	next     time.Time
+}
+
+// metricRetries backs off the metrics of the WPAs independently, so that a failing metric is retried less and less
+// often while the other metrics of the WPA keep serving recommendations.
+type metricRetries struct {
+	base time.Duration
+	max  time.Duration
+
+	mu      sync.Mutex
+	retries map[metricRetryKey]metricRetry
+}
+
+func newMetricRetries(base, max time.Duration) *metricRetries {
+	return &metricRetries{base: base, max: max, retries: map[metricRetryKey]metricRetry{}}
+}
+
+// allow returns an error wrapping errMetricBackoff if the metric should not be computed yet.
+func (m *metricRetries) allow(wpa types.NamespacedName, metric string, now time.Time) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	retry, found := m.retries[metricRetryKey{wpa: wpa, metric: metric}]
+	if !found || !now.Before(retry.next) {
+		return nil
+	}
+	return fmt.Errorf("%w after %d consecutive failures, retrying in %v", errMetricBackoff, retry.failures, retry.next.Sub(now).Round(time.Second))
+}
+
+// record updates the backoff of a metric with the result of its computation.
+func (m *metricRetries) record(wpa types.NamespacedName, metric string, err error, now time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := metricRetryKey{wpa: wpa, metric: metric}
+	if err == nil || m.base <= 0 {
+		delete(m.retries, key)
+		return
+	}
+	retry := m.retries[key]
+	retry.failures++
+	delay := m.base
+	for i := 1; i < retry.failures && delay < m.max; i++ {
+		delay *= 2
+	}
+	if m.max > 0 && delay > m.max {
+		delay = m.max
+	}
+	retry.next = now.Add(delay)
+	m.retries[key] = retry
+}
+
+// forget drops the backoffs of the metrics of a WPA.
+func (m *metricRetries) forget(wpa types.NamespacedName) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.retries {
+		if key.wpa == wpa {
+			delete(m.retries, key)
+		}
+	}
+}
+
+// failedMetric is a metric that could not be computed during a reconcile, and the ScalingActive condition reporting it
+// if no metric of the WPA could be computed.
+type failedMetric struct {
+	name    string
+	reason  string
+	message string
+	err     error
+}
+
+// failedMetricNames returns the names of the failed metrics.
+func failedMetricNames(failed []failedMetric) []string {
+	names := make([]string, 0, len(failed))
+	for _, metric := range failed {
+		names = append(names, metric.name)
+	}
+	return names
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestMetricRetries(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	failure := errors.New("unable to fetch metrics from external metrics API")
+	wpa := types.NamespacedName{Namespace: testingNamespace, Name: testingWPAName}
+	m := newMetricRetries(10*time.Second, 30*time.Second)
+
+	require.NoError(t, m.allow(wpa, "queue.lag", start))
+	m.record(wpa, "queue.lag", failure, start)
+	err := m.allow(wpa, "queue.lag", start.Add(5*time.Second))
+	require.True(t, errors.Is(err, errMetricBackoff))
+	require.Equal(t, "metric backing off after 1 consecutive failures, retrying in 5s", err.Error())
+	require.NoError(t, m.allow(wpa, "cpu", start), "the other metrics should not back off")
+
+	now := start.Add(10 * time.Second)
+	require.NoError(t, m.allow(wpa, "queue.lag", now))
+	m.record(wpa, "queue.lag", failure, now)
+	require.Error(t, m.allow(wpa, "queue.lag", now.Add(19*time.Second)), "the delay should double")
+	require.NoError(t, m.allow(wpa, "queue.lag", now.Add(20*time.Second)))
+
+	now = now.Add(20 * time.Second)
+	m.record(wpa, "queue.lag", failure, now)
+	require.NoError(t, m.allow(wpa, "queue.lag", now.Add(30*time.Second)), "the delay should be capped")
+
+	m.record(wpa, "queue.lag", nil, now)
+	require.NoError(t, m.allow(wpa, "queue.lag", now))
+
+	m.record(wpa, "queue.lag", failure, now)
+	m.forget(wpa)
+	require.NoError(t, m.allow(wpa, "queue.lag", now))
+
+	disabled := newMetricRetries(0, time.Minute)
+	disabled.record(wpa, "queue.lag", failure, now)
+	require.NoError(t, disabled.allow(wpa, "queue.lag", now))
+}
+
+func TestComputeReplicasWithFailedMetrics(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	external := func(name string) v1alpha1.MetricSpec {
+		return v1alpha1.MetricSpec{
+			Type: v1alpha1.ExternalMetricSourceType,
+			External: &v1alpha1.ExternalMetricSource{
+				MetricName:     name,
+				MetricSelector: &metav1.LabelSelector{},
+				HighWatermark:  resource.NewQuantity(100, resource.DecimalSI),
+				LowWatermark:   resource.NewQuantity(50, resource.DecimalSI),
+			},
+		}
+	}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			Metrics:     []v1alpha1.MetricSpec{external("queue.lag"), external("requests")},
+			MaxReplicas: 20,
+		},
+	})
+	scale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 4}, Status: autoscalingv1.ScaleStatus{Replicas: 4}}
+	queries := map[string]int{}
+	healthyReplicas := int32(6)
+	r := &ReconcileWatermarkPodAutoscaler{
+		eventRecorder: record.NewFakeRecorder(10),
+		metricRetries: newMetricRetries(time.Minute, 5*time.Minute),
+		replicaCalc: &fakeReplicaCalculator{
+			replicasFunc: func(metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
+				queries[metric.External.MetricName]++
+				if metric.External.MetricName == "queue.lag" {
+					return ReplicaCalculation{}, errors.New("unable to fetch metrics from external metrics API")
+				}
+				return ReplicaCalculation{replicaCount: healthyReplicas, highWatermark: metric.External.HighWatermark, lowWatermark: metric.External.LowWatermark}, nil
+			},
+		},
+	}
+
+	replicas, metric, statuses, _, _, err := r.computeReplicasForMetrics(logger, wpa, scale)
+	require.NoError(t, err)
+	require.Equal(t, int32(6), replicas)
+	require.Equal(t, "requests{map[]}", metric)
+	require.True(t, isConditionTrue(wpa, autoscalingv2.ScalingActive))
+	require.True(t, someMetricsFailed(wpa))
+	require.Len(t, statuses, 1, "the failed metric has no status")
+	require.Equal(t, "requests", statuses[0].External.MetricName)
+
+	healthyReplicas = 2
+	replicas, _, _, _, _, err = r.computeReplicasForMetrics(logger, wpa, scale)
+	require.NoError(t, err)
+	require.Equal(t, int32(4), replicas, "the WPA should not downscale while a metric fails")
+	require.Equal(t, map[string]int{"queue.lag": 1, "requests": 2}, queries, "the failed metric should back off")
+
+	wpa.Spec.Metrics = wpa.Spec.Metrics[:1]
+	_, _, _, _, _, err = r.computeReplicasForMetrics(logger, wpa, scale)
+	require.True(t, errors.Is(err, errMetricBackoff))
+	for _, condition := range wpa.Status.Conditions {
+		if condition.Type == autoscalingv2.ScalingActive {
+			require.Equal(t, corev1.ConditionFalse, condition.Status, "the WPA should not scale without a metric")
+		}
+	}
+}
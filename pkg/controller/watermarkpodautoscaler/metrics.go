@@ -24,6 +24,9 @@ const (
 	transitionPromLabel        = "transition"
 	downscaleCappingPromLabel  = "downscale_capping"
 	upscaleCappingPromLabel    = "upscale_capping"
+	apiPromLabel               = "api"
+	providerPromLabel          = "provider"
+	decisionPromLabel          = "decision"
 )
 
 var (
@@ -117,6 +120,18 @@ var (
 			resourceNamePromLabel,
 			resourceKindPromLabel,
 		})
+	replicaProposalDelta = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "replicas_proposal_delta",
+			Help:      "Gauge for the difference between the number of replicas proposed by the WPA and the number of replicas applied to the target",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
 	replicaEffective = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: subsystem,
@@ -129,6 +144,34 @@ var (
 			resourceNamePromLabel,
 			resourceKindPromLabel,
 		})
+	// replicaHistogram is observed at every reconcile, so that its buckets account for the time spent at each
+	// number of replicas, one sync period per observation.
+	replicaHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystem,
+			Name:      "replicas_applied",
+			Help:      "Histogram of the number of replicas applied to the target of a given WPA, observed at every reconcile",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	decisions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "decisions",
+			Help:      "Counter of the reconciles of a given WPA by outcome of the scaling decision",
+		},
+		[]string{
+			wpaNamePromLabel,
+			decisionPromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
 	restrictedScaling = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: subsystem,
@@ -154,6 +197,146 @@ var (
 			resourceNamePromLabel,
 			resourceKindPromLabel,
 		})
+	unschedulablePods = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "unschedulable_pods",
+			Help:      "Gauge for the number of pods of the target that can't be scheduled",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	failedPods = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "failed_pods",
+			Help:      "Gauge for the number of pods of the target in the Failed phase, including the evicted ones",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	unhealthyNodePods = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "unhealthy_node_pods",
+			Help:      "Gauge for the number of pods of the target not ready because of their node",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	projectedCost = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "projected_cost_per_hour",
+			Help:      "Gauge for the hourly cost of the target at the desired number of replicas, according to the budget of the WPA",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	circuitOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "metrics_provider_circuit_open",
+			Help:      "Gauge set to 1 while the circuit breaker of a metrics API is open",
+		},
+		[]string{
+			apiPromLabel,
+			providerPromLabel,
+		})
+	metricsProviderLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystem,
+			Name:      "metrics_provider_latency_seconds",
+			Help:      "Histogram of the latency of the queries to a metrics API",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{
+			apiPromLabel,
+		})
+	flapping = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "flapping",
+			Help:      "Gauge set to 1 while the scaling direction of a given WPA reverses too often",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	suppressedReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "suppressed_replicas",
+			Help:      "Gauge for the desired replicas of a given WPA in the direction suppressed by its scaling mode, 0 if none",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	staleWPA = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "stale",
+			Help:      "Gauge set to 1 when a given WPA was not reconciled successfully for several sync periods, 0 otherwise",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	replicaDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "replica_drift",
+			Help:      "Gauge for the difference between the replicas of the target and the ones last applied by a given WPA, 0 if none",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	replicaSeconds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "replica_seconds",
+			Help:      "Counter of the replica-seconds used by the target of a given WPA",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
+	maxReplicaSeconds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "max_replica_seconds",
+			Help:      "Counter of the replica-seconds the target of a given WPA would have used at its maxReplicas",
+		},
+		[]string{
+			wpaNamePromLabel,
+			resourceNamespacePromLabel,
+			resourceNamePromLabel,
+			resourceKindPromLabel,
+		})
 	replicaMax = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Subsystem: subsystem,
@@ -175,11 +358,26 @@ func init() {
 	sigmetrics.Registry.MustRegister(lowwm)
 	sigmetrics.Registry.MustRegister(lowwmV2)
 	sigmetrics.Registry.MustRegister(replicaProposal)
+	sigmetrics.Registry.MustRegister(replicaProposalDelta)
 	sigmetrics.Registry.MustRegister(replicaEffective)
+	sigmetrics.Registry.MustRegister(replicaHistogram)
+	sigmetrics.Registry.MustRegister(decisions)
 	sigmetrics.Registry.MustRegister(restrictedScaling)
 	sigmetrics.Registry.MustRegister(transitionCountdown)
 	sigmetrics.Registry.MustRegister(replicaMin)
 	sigmetrics.Registry.MustRegister(replicaMax)
+	sigmetrics.Registry.MustRegister(unschedulablePods)
+	sigmetrics.Registry.MustRegister(failedPods)
+	sigmetrics.Registry.MustRegister(unhealthyNodePods)
+	sigmetrics.Registry.MustRegister(projectedCost)
+	sigmetrics.Registry.MustRegister(circuitOpen)
+	sigmetrics.Registry.MustRegister(metricsProviderLatency)
+	sigmetrics.Registry.MustRegister(flapping)
+	sigmetrics.Registry.MustRegister(suppressedReplicas)
+	sigmetrics.Registry.MustRegister(staleWPA)
+	sigmetrics.Registry.MustRegister(replicaDrift)
+	sigmetrics.Registry.MustRegister(replicaSeconds)
+	sigmetrics.Registry.MustRegister(maxReplicaSeconds)
 }
 
 func cleanupAssociatedMetrics(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, onlyMetricsSpecific bool) {
@@ -192,9 +390,30 @@ func cleanupAssociatedMetrics(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, onl
 
 	if !onlyMetricsSpecific {
 		replicaProposal.Delete(promLabelsForWpa)
+		replicaProposalDelta.Delete(promLabelsForWpa)
 		replicaEffective.Delete(promLabelsForWpa)
+		replicaHistogram.Delete(promLabelsForWpa)
 		replicaMin.Delete(promLabelsForWpa)
 		replicaMax.Delete(promLabelsForWpa)
+		unschedulablePods.Delete(promLabelsForWpa)
+		failedPods.Delete(promLabelsForWpa)
+		unhealthyNodePods.Delete(promLabelsForWpa)
+		projectedCost.Delete(promLabelsForWpa)
+		flapping.Delete(promLabelsForWpa)
+		suppressedReplicas.Delete(promLabelsForWpa)
+		staleWPA.Delete(promLabelsForWpa)
+		replicaDrift.Delete(promLabelsForWpa)
+		replicaSeconds.Delete(promLabelsForWpa)
+		maxReplicaSeconds.Delete(promLabelsForWpa)
+		for _, ref := range wpa.Spec.ScaleTargetRefs {
+			replicaEffective.Delete(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: ref.Name, resourceKindPromLabel: ref.Kind})
+		}
+
+		for _, decision := range scalingDecisions {
+			promLabelsForWpa[decisionPromLabel] = decision
+			decisions.Delete(promLabelsForWpa)
+		}
+		delete(promLabelsForWpa, decisionPromLabel)
 
 		promLabelsForWpa[reasonPromLabel] = downscaleCappingPromLabel
 		restrictedScaling.Delete(promLabelsForWpa)
@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	metricsclient "k8s.io/kubernetes/pkg/controller/podautoscaler/metrics"
+	resourceclient "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+	externalclient "k8s.io/metrics/pkg/client/external_metrics"
+)
+
+var metricsClientTimeout time.Duration
+
+func init() {
+	flag.DurationVar(&metricsClientTimeout, "metrics-client-timeout", 10*time.Second, "Timeout of the requests to the resource and external metrics APIs")
+}
+
+// callWithContext runs the query unless the context is already done. The requests of the metrics clients don't take a
+// context, so the query isn't abandoned in a goroutine when the context expires while it runs: the clients are given
+// the same timeout as the contexts of the reconcile loop instead, which bounds the query.
+func callWithContext(ctx context.Context, query func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return query()
+}
+
+// MetricsClient extends the upstream MetricsClient with the ability to retrieve
+// the resource usage of a single container of the pods.
+type MetricsClient interface {
+	metricsclient.MetricsClient
+
+	// GetContainerResourceMetric gets the given resource metric (and an associated oldest timestamp)
+	// of the named container, for all pods matching the specified selector in the given namespace
+	GetContainerResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, container string) (metricsclient.PodMetricsInfo, time.Time, error)
+
+	// GetWeightedResourceMetric gets the given resource metric (and an associated oldest timestamp) of the pods
+	// matching the specified selector in the given namespace, as the sum of the usage of the weighted containers
+	GetWeightedResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, weights map[string]int32) (metricsclient.PodMetricsInfo, time.Time, error)
+
+	// GetExternalMetricBuckets gets the values of the series of the given external metric matching the specified
+	// selector, summed by the value of their bucketLabel
+	GetExternalMetricBuckets(metricName string, namespace string, selector labels.Selector, bucketLabel string) (map[string]int64, time.Time, error)
+}
+
+// NewRESTMetricsClient returns a MetricsClient relying on the resource, custom and external metrics APIs.
+func NewRESTMetricsClient(resourceClient resourceclient.PodMetricsesGetter, customClient customclient.CustomMetricsClient, externalClient externalclient.ExternalMetricsClient) MetricsClient {
+	return &restMetricsClient{
+		MetricsClient:  metricsclient.NewRESTMetricsClient(resourceClient, customClient, externalClient),
+		resourceClient: resourceClient,
+		externalClient: externalClient,
+	}
+}
+
+type restMetricsClient struct {
+	metricsclient.MetricsClient
+	resourceClient resourceclient.PodMetricsesGetter
+	externalClient externalclient.ExternalMetricsClient
+}
+
+// GetContainerResourceMetric only considers the usage of the container named `container`.
+// Pods that do not report a usage for this container are left out of the result.
+func (c *restMetricsClient) GetContainerResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, container string) (metricsclient.PodMetricsInfo, time.Time, error) {
+	metrics, err := c.resourceClient.PodMetricses(namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to fetch metrics from resource metrics API: %v", err)
+	}
+
+	if len(metrics.Items) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from resource metrics API")
+	}
+
+	res := make(metricsclient.PodMetricsInfo, len(metrics.Items))
+	for _, m := range metrics.Items {
+		for _, cm := range m.Containers {
+			if cm.Name != container {
+				continue
+			}
+			resValue, found := cm.Usage[resource]
+			if !found {
+				log.V(2).Info("Missing resource metric for container", "resource", resource, "container", container, "namespace", namespace, "pod", m.Name)
+				break
+			}
+			res[m.Name] = metricsclient.PodMetric{
+				Timestamp: m.Timestamp.Time,
+				Window:    m.Window.Duration,
+				Value:     resValue.MilliValue(),
+			}
+			break
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from resource metrics API for container %s", container)
+	}
+
+	return res, metrics.Items[0].Timestamp.Time, nil
+}
+
+// GetWeightedResourceMetric sums the usage of the containers of each pod, multiplied by their weight in percent.
+// The containers without a weight are left out, as are the pods that do not report a usage for any of them.
+func (c *restMetricsClient) GetWeightedResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, weights map[string]int32) (metricsclient.PodMetricsInfo, time.Time, error) {
+	metrics, err := c.resourceClient.PodMetricses(namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to fetch metrics from resource metrics API: %v", err)
+	}
+
+	if len(metrics.Items) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from resource metrics API")
+	}
+
+	res := make(metricsclient.PodMetricsInfo, len(metrics.Items))
+	for _, m := range metrics.Items {
+		podSum := int64(0)
+		found := false
+		for _, cm := range m.Containers {
+			weight, weighted := weights[cm.Name]
+			if !weighted {
+				continue
+			}
+			resValue, ok := cm.Usage[resource]
+			if !ok {
+				log.V(2).Info("Missing resource metric for container", "resource", resource, "container", cm.Name, "namespace", namespace, "pod", m.Name)
+				continue
+			}
+			podSum += resValue.MilliValue() * int64(weight) / 100
+			found = true
+		}
+		if !found {
+			continue
+		}
+		res[m.Name] = metricsclient.PodMetric{
+			Timestamp: m.Timestamp.Time,
+			Window:    m.Window.Duration,
+			Value:     podSum,
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from resource metrics API for the weighted containers")
+	}
+
+	return res, metrics.Items[0].Timestamp.Time, nil
+}
+
+// GetExternalMetricBuckets sums the values of the series by the value of their bucketLabel, the series of the same
+// bucket coming for instance from several hosts. The series without the label are left out.
+func (c *restMetricsClient) GetExternalMetricBuckets(metricName string, namespace string, selector labels.Selector, bucketLabel string) (map[string]int64, time.Time, error) {
+	metrics, err := c.externalClient.NamespacedMetrics(namespace).List(metricName, selector)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to fetch metrics from external metrics API: %v", err)
+	}
+
+	if len(metrics.Items) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from external metrics API")
+	}
+
+	res := make(map[string]int64)
+	for _, m := range metrics.Items {
+		bound, found := m.MetricLabels[bucketLabel]
+		if !found {
+			log.V(2).Info("Missing bucket label for external metric", "metricName", metricName, "bucketLabel", bucketLabel, "labels", m.MetricLabels)
+			continue
+		}
+		res[bound] += m.Value.MilliValue()
+	}
+
+	if len(res) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from external metrics API with the %s label", bucketLabel)
+	}
+
+	return res, metrics.Items[0].Timestamp.Time, nil
+}
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"flag"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const metricsProviderLatencyCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "MetricsProviderResponsive"
+
+var metricsProviderLatencyThreshold time.Duration
+
+func init() {
+	flag.DurationVar(&metricsProviderLatencyThreshold, "metrics-provider-latency-threshold", 3*time.Second, "Latency of the metrics APIs above which the WPAs report a slow metrics provider in their conditions, 0 to disable")
+}
+
+// observeMetricsProviderLatency exports the latency of a query to a metrics API started at start.
+func observeMetricsProviderLatency(api string, start time.Time) {
+	metricsProviderLatency.With(prometheus.Labels{apiPromLabel: api}).Observe(time.Since(start).Seconds())
+}
+
+// setMetricsProviderLatencyCondition reports the slowest query of the reconcile in the conditions of the WPA when it
+// is above the threshold. As for the circuit breaker, the condition is only added once a query was slow, and set
+// back to true by the next reconcile whose queries are all under the threshold.
+func setMetricsProviderLatencyCondition(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, metricName string, latency time.Duration) {
+	if metricsProviderLatencyThreshold <= 0 {
+		return
+	}
+	if latency > metricsProviderLatencyThreshold {
+		setCondition(wpa, metricsProviderLatencyCondition, corev1.ConditionFalse, "SlowMetricsProvider", "the metrics provider took %v to return %s, above the threshold of %v", latency.Round(time.Millisecond), metricName, metricsProviderLatencyThreshold)
+		return
+	}
+	for _, condition := range wpa.Status.Conditions {
+		if condition.Type == metricsProviderLatencyCondition {
+			setCondition(wpa, metricsProviderLatencyCondition, corev1.ConditionTrue, "MetricsProviderResponsive", "the metrics provider returned the metrics within %v", metricsProviderLatencyThreshold)
+			return
+		}
+	}
+}
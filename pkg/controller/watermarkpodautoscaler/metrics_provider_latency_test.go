@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestObserveMetricsProviderLatency(t *testing.T) {
+	sampleCount := func() uint64 {
+		m := &dto.Metric{}
+		require.NoError(t, metricsProviderLatency.With(prometheus.Labels{apiPromLabel: externalMetricsAPI}).(prometheus.Histogram).Write(m))
+		return m.GetHistogram().GetSampleCount()
+	}
+	before := sampleCount()
+	observeMetricsProviderLatency(externalMetricsAPI, time.Now().Add(-time.Second))
+	require.Equal(t, before+1, sampleCount())
+}
+
+func TestSetMetricsProviderLatencyCondition(t *testing.T) {
+	defer func(threshold time.Duration) { metricsProviderLatencyThreshold = threshold }(metricsProviderLatencyThreshold)
+	metricsProviderLatencyThreshold = 2 * time.Second
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{})
+
+	setMetricsProviderLatencyCondition(wpa, "queue_length", time.Second)
+	require.Empty(t, wpa.Status.Conditions, "the condition should only be added once a query was slow")
+
+	setMetricsProviderLatencyCondition(wpa, "queue_length", 3*time.Second)
+	require.Len(t, wpa.Status.Conditions, 1)
+	require.Equal(t, metricsProviderLatencyCondition, wpa.Status.Conditions[0].Type)
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+	require.Equal(t, "SlowMetricsProvider", wpa.Status.Conditions[0].Reason)
+	require.Contains(t, wpa.Status.Conditions[0].Message, "queue_length")
+
+	setMetricsProviderLatencyCondition(wpa, "queue_length", time.Second)
+	require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status)
+
+	metricsProviderLatencyThreshold = 0
+	setMetricsProviderLatencyCondition(wpa, "queue_length", time.Minute)
+	require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status, "a threshold of 0 disables the condition")
+}
@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// minReadyDuration returns the minReadySeconds of the target Deployment if the WPA honors it, 0 otherwise.
+func (c *ReplicaCalculator) minReadyDuration(ctx context.Context, logger logr.Logger, wpa *v1alpha1.WatermarkPodAutoscaler) time.Duration {
+	if !wpa.Spec.HonorMinReadySeconds || c.client == nil || wpa.Spec.ScaleTargetRef.Kind != "Deployment" {
+		return 0
+	}
+	deploy := &appsv1.Deployment{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Spec.ScaleTargetRef.Name}, deploy); err != nil {
+		logger.Info("Could not get the minReadySeconds of the target Deployment", "error", err)
+		return 0
+	}
+	return time.Duration(deploy.Spec.MinReadySeconds) * time.Second
+}
+
+// isAvailable returns whether the pod has been Ready for at least minReady, as the Deployment controller
+// counts the available pods.
+func isAvailable(pod *corev1.Pod, minReady time.Duration, now time.Time) bool {
+	if minReady == 0 {
+		return true
+	}
+	_, condition := getPodCondition(&pod.Status, corev1.PodReady)
+	return condition != nil && condition.Status == corev1.ConditionTrue && !condition.LastTransitionTime.Add(minReady).After(now)
+}
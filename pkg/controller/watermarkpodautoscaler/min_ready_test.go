@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestMinReadyDuration(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	deploy := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "foo"},
+		Spec:       appsv1.DeploymentSpec{MinReadySeconds: 30},
+	}
+	c := NewReplicaCalculator(nil, nil, fake.NewFakeClientWithScheme(scheme.Scheme, deploy))
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef: v1alpha1.CrossVersionObjectReference{Kind: "Deployment", Name: "foo", APIVersion: "apps/v1"},
+		},
+	})
+
+	require.Equal(t, time.Duration(0), c.minReadyDuration(context.TODO(), logger, wpa), "minReadySeconds is only honored on demand")
+	wpa.Spec.HonorMinReadySeconds = true
+	require.Equal(t, 30*time.Second, c.minReadyDuration(context.TODO(), logger, wpa))
+	wpa.Spec.ScaleTargetRef.Name = "bar"
+	require.Equal(t, time.Duration(0), c.minReadyDuration(context.TODO(), logger, wpa), "a missing Deployment doesn't hold the pods back")
+}
+
+func TestIsAvailable(t *testing.T) {
+	now := time.Now()
+	newPod := func(status corev1.ConditionStatus, readySince time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: status, LastTransitionTime: metav1.NewTime(now.Add(-readySince))},
+				},
+			},
+		}
+	}
+
+	require.True(t, isAvailable(newPod(corev1.ConditionTrue, 10*time.Second), 0, now))
+	require.False(t, isAvailable(newPod(corev1.ConditionTrue, 10*time.Second), 30*time.Second, now))
+	require.True(t, isAvailable(newPod(corev1.ConditionTrue, 30*time.Second), 30*time.Second, now))
+	require.False(t, isAvailable(newPod(corev1.ConditionFalse, time.Minute), 30*time.Second, now))
+	require.False(t, isAvailable(&corev1.Pod{}, 30*time.Second, now))
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+)
+
+type metricSample struct {
+	value     float64
+	timestamp time.Time
+}
+
+// metricSamples keeps the last two values of the external metrics, so that a missing value can be estimated
+// and a fast increase projected.
+type metricSamples struct {
+	mu      sync.Mutex
+	samples map[string][]metricSample
+}
+
+func newMetricSamples() *metricSamples {
+	return &metricSamples{samples: map[string][]metricSample{}}
+}
+
+// metricSampleKey identifies an external metric of a WPA.
+func metricSampleKey(wpa *v1alpha1.WatermarkPodAutoscaler, metric *v1alpha1.ExternalMetricSource) string {
+	return fmt.Sprintf("%s/%s/%s{%v}", wpa.Namespace, wpa.Name, metric.MetricName, metric.MetricSelector)
+}
+
+func (m *metricSamples) record(key string, value float64, timestamp time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	samples := m.samples[key]
+	if len(samples) > 0 && !timestamp.After(samples[len(samples)-1].timestamp) {
+		// The provider returned the same datapoint again.
+		return
+	}
+	samples = append(samples, metricSample{value: value, timestamp: timestamp})
+	if len(samples) > 2 {
+		samples = samples[len(samples)-2:]
+	}
+	m.samples[key] = samples
+}
+
+// estimate returns the value of the metric at `now` according to the policy, and false when the last value
+// is older than the maximum gap.
+func (m *metricSamples) estimate(key string, policy *v1alpha1.MissingDatapointsPolicy, now time.Time) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	samples := m.samples[key]
+	if len(samples) == 0 {
+		return 0, false
+	}
+	last := samples[len(samples)-1]
+	if now.Sub(last.timestamp) > time.Duration(policy.MaxGapSeconds)*time.Second {
+		delete(m.samples, key)
+		return 0, false
+	}
+	if policy.Strategy != v1alpha1.MissingDatapointsLinear || len(samples) < 2 {
+		return last.value, true
+	}
+	previous := samples[0]
+	slope := (last.value - previous.value) / last.timestamp.Sub(previous.timestamp).Seconds()
+	value := last.value + slope*now.Sub(last.timestamp).Seconds()
+	if value < 0 {
+		value = 0
+	}
+	return value, true
+}
+
+// project returns the value of the metric `horizonSeconds` after the last one, following the trend of the last
+// two values, and false if the metric does not rise faster than the slope.
+func (m *metricSamples) project(key string, rate *v1alpha1.RateOfChangeSpec) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	samples := m.samples[key]
+	if len(samples) < 2 {
+		return 0, false
+	}
+	previous, last := samples[0], samples[1]
+	slope := (last.value - previous.value) / last.timestamp.Sub(previous.timestamp).Seconds()
+	if slope <= float64(rate.Slope.MilliValue()) {
+		return 0, false
+	}
+	return last.value + slope*float64(rate.HorizonSeconds), true
+}
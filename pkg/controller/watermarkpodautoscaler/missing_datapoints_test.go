@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMetricSamplesEstimate(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	carryForward := &v1alpha1.MissingDatapointsPolicy{Strategy: v1alpha1.MissingDatapointsCarryForward, MaxGapSeconds: 60}
+	linear := &v1alpha1.MissingDatapointsPolicy{Strategy: v1alpha1.MissingDatapointsLinear, MaxGapSeconds: 60}
+
+	samples := newMetricSamples()
+	_, ok := samples.estimate("foo", carryForward, start)
+	require.False(t, ok, "nothing can be estimated without a previous value")
+
+	samples.record("foo", 100, start)
+	value, ok := samples.estimate("foo", linear, start.Add(15*time.Second))
+	require.True(t, ok)
+	require.Equal(t, float64(100), value, "a single value is carried forward")
+
+	samples.record("foo", 130, start.Add(15*time.Second))
+	samples.record("foo", 130, start.Add(15*time.Second))
+	value, ok = samples.estimate("foo", carryForward, start.Add(30*time.Second))
+	require.True(t, ok)
+	require.Equal(t, float64(130), value)
+	value, ok = samples.estimate("foo", linear, start.Add(30*time.Second))
+	require.True(t, ok)
+	require.Equal(t, float64(160), value)
+
+	samples.record("bar", 100, start)
+	samples.record("bar", 10, start.Add(15*time.Second))
+	value, ok = samples.estimate("bar", linear, start.Add(30*time.Second))
+	require.True(t, ok)
+	require.Equal(t, float64(0), value, "the estimation can't be negative")
+
+	_, ok = samples.estimate("foo", carryForward, start.Add(76*time.Second))
+	require.False(t, ok, "the last value is older than the maximum gap")
+	_, ok = samples.estimate("foo", carryForward, start.Add(30*time.Second))
+	require.False(t, ok, "the stale values are forgotten")
+}
+
+func TestMetricSamplesProject(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	rate := &v1alpha1.RateOfChangeSpec{Slope: resource.MustParse("2"), HorizonSeconds: 60}
+
+	samples := newMetricSamples()
+	samples.record("foo", 10000, start)
+	_, ok := samples.project("foo", rate)
+	require.False(t, ok, "a single value has no trend")
+
+	samples.record("foo", 40000, start.Add(15*time.Second))
+	_, ok = samples.project("foo", rate)
+	require.False(t, ok, "the metric rises exactly at the slope")
+
+	samples.record("foo", 100000, start.Add(30*time.Second))
+	value, ok := samples.project("foo", rate)
+	require.True(t, ok)
+	require.Equal(t, float64(340000), value)
+
+	samples.record("foo", 50000, start.Add(45*time.Second))
+	_, ok = samples.project("foo", rate)
+	require.False(t, ok, "a decreasing metric is not projected")
+}
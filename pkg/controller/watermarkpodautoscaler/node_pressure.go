@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const nodePressureCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "NodePressure"
+
+// capDesiredReplicasWithUnhealthyNodes prevents downscaling the target while some of its pods are not ready
+// because of their node, as the capacity is lost to the infrastructure rather than unneeded.
+func (r *ReconcileWatermarkPodAutoscaler) capDesiredReplicasWithUnhealthyNodes(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+	selector, err := labels.Parse(scale.Status.Selector)
+	if err != nil {
+		logger.Error(err, "Could not parse the labels of the target")
+		return desiredReplicas
+	}
+	pods, err := r.podLister.Pods(wpa.Namespace).List(selector)
+	if err != nil {
+		logger.Error(err, "Could not list the pods of the target")
+		return desiredReplicas
+	}
+	nodes := &corev1.NodeList{}
+	if err = r.client.List(context.TODO(), nodes); err != nil {
+		logger.Error(err, "Could not list the nodes")
+		return desiredReplicas
+	}
+
+	unhealthy := countUnhealthyNodePods(pods, nodes.Items)
+	unhealthyNodePods.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Set(float64(unhealthy))
+	if unhealthy == 0 {
+		setCondition(wpa, nodePressureCondition, corev1.ConditionFalse, "HealthyNodes", "no pod of the target is unready because of its node")
+		return desiredReplicas
+	}
+	setCondition(wpa, nodePressureCondition, corev1.ConditionTrue, "PodsOnUnhealthyNodes", "%d pods of the target are not ready because of their node, downscale is paused", unhealthy)
+	if desiredReplicas >= currentReplicas {
+		return desiredReplicas
+	}
+	logger.Info("Pausing downscale while pods are not ready because of their node", "unhealthyNodePods", unhealthy, "desiredReplicas", desiredReplicas)
+	return currentReplicas
+}
+
+func countUnhealthyNodePods(pods []*corev1.Pod, nodes []corev1.Node) int {
+	unhealthyNodes := sets.NewString()
+	for i := range nodes {
+		if isUnhealthy(&nodes[i]) {
+			unhealthyNodes.Insert(nodes[i].Name)
+		}
+	}
+	unhealthy := 0
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && unhealthyNodes.Has(pod.Spec.NodeName) && !isPodReady(pod) {
+			unhealthy++
+		}
+	}
+	return unhealthy
+}
+
+// isUnhealthy returns whether the node is not ready, or under memory, disk or PID pressure.
+func isUnhealthy(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		switch condition.Type {
+		case corev1.NodeReady:
+			if condition.Status != corev1.ConditionTrue {
+				return true
+			}
+		case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+			if condition.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	_, condition := getPodCondition(&pod.Status, corev1.PodReady)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestNode(name string, conditions ...corev1.NodeCondition) *corev1.Node {
+	return &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.NodeStatus{Conditions: conditions},
+	}
+}
+
+func newTestPodOnNode(name, node string, ready corev1.ConditionStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+		},
+	}
+}
+
+func TestCountUnhealthyNodePods(t *testing.T) {
+	nodes := []corev1.Node{
+		*newTestNode("healthy", corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}),
+		*newTestNode("notready", corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}),
+		*newTestNode("memory", corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}, corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue}),
+	}
+	pods := []*corev1.Pod{
+		newTestPodOnNode("app-failure", "healthy", corev1.ConditionFalse),
+		newTestPodOnNode("lost", "notready", corev1.ConditionFalse),
+		newTestPodOnNode("evicting", "memory", corev1.ConditionFalse),
+		newTestPodOnNode("serving", "memory", corev1.ConditionTrue),
+	}
+
+	require.Equal(t, 2, countUnhealthyNodePods(pods, nodes), "only the unready pods on unhealthy nodes are counted")
+}
+
+func TestExcludedPodsOnUnhealthyNodes(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	c := NewReplicaCalculator(nil, nil, fake.NewFakeClientWithScheme(scheme.Scheme,
+		newTestNode("healthy", corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}),
+		newTestNode("notready", corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionFalse}),
+	))
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+	lost := newTestPodOnNode("lost", "notready", corev1.ConditionFalse)
+
+	require.False(t, c.excludedPods(context.TODO(), logger, wpa)(lost), "the node pressure is only considered on demand")
+
+	wpa.Spec.NodePressureAware = true
+	excluded := c.excludedPods(context.TODO(), logger, wpa)
+	require.True(t, excluded(lost))
+	require.False(t, excluded(newTestPodOnNode("serving", "notready", corev1.ConditionTrue)))
+	require.False(t, excluded(newTestPodOnNode("app-failure", "healthy", corev1.ConditionFalse)))
+}
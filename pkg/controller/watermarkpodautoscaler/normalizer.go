@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Normalizer brings the replicas recommended for a WPA within a limit, such as its scaling rate or a budget,
+// and returns them unchanged when they are already within it.
+type Normalizer interface {
+	Normalize(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32
+}
+
+// NormalizerFunc is a function used as a Normalizer.
+type NormalizerFunc func(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32
+
+// Normalize calls f.
+func (f NormalizerFunc) Normalize(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+	return f(logger, wpa, scale, currentReplicas, desiredReplicas)
+}
+
+// NormalizerChain applies its normalizers in order, each one to the replicas returned by the previous one.
+type NormalizerChain []Normalizer
+
+// Normalize returns the replicas once normalized by all the normalizers of the chain.
+func (c NormalizerChain) Normalize(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+	for _, normalizer := range c {
+		desiredReplicas = normalizer.Normalize(logger, wpa, scale, currentReplicas, desiredReplicas)
+	}
+	return desiredReplicas
+}
+
+// scaleLimitsNormalizer keeps the replicas between the minReplicas and the maxReplicas of the WPA, and within its
+// scale-up and scale-down limit factors.
+var scaleLimitsNormalizer = NormalizerFunc(normalizeDesiredReplicas)
+
+// normalizeDesiredReplicas takes the metrics desired replicas value and normalizes it based on the appropriate conditions (i.e. < maxReplicas, >
+// minReplicas, etc...)
+func normalizeDesiredReplicas(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, currentReplicas int32, prenormalizedDesiredReplicas int32) int32 {
+	var minReplicas int32
+	if wpa.Spec.MinReplicas != nil {
+		minReplicas = *wpa.Spec.MinReplicas
+	} else {
+		minReplicas = 0
+	}
+
+	desiredReplicas, condition, reason := convertDesiredReplicasWithRules(logger, wpa, currentReplicas, prenormalizedDesiredReplicas, minReplicas, wpa.Spec.MaxReplicas)
+
+	if desiredReplicas == prenormalizedDesiredReplicas {
+		setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionFalse, condition, reason)
+	} else {
+		setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, condition, reason)
+	}
+
+	return desiredReplicas
+}
+
+// capNormalizers returns the chain of the caps enabled in the spec of the WPA, applied once the replicas are within
+// its scale limits.
+func (r *ReconcileWatermarkPodAutoscaler) capNormalizers(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) NormalizerChain {
+	var chain NormalizerChain
+	if wpa.Spec.ResourceQuotaAware {
+		chain = append(chain, NormalizerFunc(func(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+			if desiredReplicas <= currentReplicas {
+				return desiredReplicas
+			}
+			return r.capDesiredReplicasWithResourceQuota(logger, wpa, scale, currentReplicas, desiredReplicas)
+		}))
+	}
+	if wpa.Spec.PauseUpscaleOnUnschedulablePods {
+		chain = append(chain, NormalizerFunc(r.capDesiredReplicasWithUnschedulablePods))
+	}
+	if wpa.Spec.NodePressureAware {
+		chain = append(chain, NormalizerFunc(r.capDesiredReplicasWithUnhealthyNodes))
+	}
+	if wpa.Spec.Budget != nil {
+		chain = append(chain, NormalizerFunc(func(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, _, desiredReplicas int32) int32 {
+			return capDesiredReplicasWithBudget(logger, wpa, desiredReplicas)
+		}))
+	}
+	if wpa.Spec.CapacityCeiling != nil {
+		chain = append(chain, NormalizerFunc(func(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, _, desiredReplicas int32) int32 {
+			return capDesiredReplicasWithCapacityCeiling(logger, wpa, desiredReplicas)
+		}))
+	}
+	chain = append(chain, NormalizerFunc(func(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, _, desiredReplicas int32) int32 {
+		return r.capDesiredReplicasWithGroups(logger, wpa, desiredReplicas)
+	}))
+	if wpa.Spec.StatefulSet != nil {
+		chain = append(chain, NormalizerFunc(func(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+			return r.capDesiredReplicasForStatefulSet(logger, wpa, currentReplicas, desiredReplicas)
+		}))
+	}
+	return append(chain, NormalizerFunc(func(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+		return capDesiredReplicasWithScalingMode(logger, wpa, currentReplicas, desiredReplicas)
+	}))
+}
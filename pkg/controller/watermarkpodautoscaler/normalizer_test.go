@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestNormalizerChain(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			MinReplicas:        getReplicas(2),
+			MaxReplicas:        50,
+			ScaleUpLimitFactor: 100,
+			Budget: &v1alpha1.BudgetSpec{
+				CostPerReplicaHour: resource.MustParse("1"),
+				MaxCostPerHour:     resource.MustParse("15"),
+			},
+		},
+	})
+	var applied []string
+	recorder := func(name string) Normalizer {
+		return NormalizerFunc(func(_ logr.Logger, _ *v1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, _, desiredReplicas int32) int32 {
+			applied = append(applied, name)
+			return desiredReplicas
+		})
+	}
+
+	chain := NormalizerChain{recorder("first"), scaleLimitsNormalizer, recorder("second"), NormalizerFunc(func(logger logr.Logger, wpa *v1alpha1.WatermarkPodAutoscaler, _ *autoscalingv1.Scale, _, desiredReplicas int32) int32 {
+		return capDesiredReplicasWithBudget(logger, wpa, desiredReplicas)
+	})}
+	require.Equal(t, int32(12), chain.Normalize(logger, wpa, nil, 10, 12), "within the scale limits and the budget")
+	require.Equal(t, int32(15), chain.Normalize(logger, wpa, nil, 10, 18), "capped by the budget")
+	require.Equal(t, int32(15), chain.Normalize(logger, wpa, nil, 10, 40), "capped by the scale-up limit, then by the budget")
+	require.Equal(t, []string{"first", "second", "first", "second", "first", "second"}, applied)
+	require.Equal(t, int32(8), NormalizerChain(nil).Normalize(logger, wpa, nil, 10, 8), "an empty chain keeps the replicas")
+}
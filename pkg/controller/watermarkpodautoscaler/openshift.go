@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	deploymentConfigKind = "DeploymentConfig"
+)
+
+var (
+	deploymentConfigGVK = schema.GroupVersionKind{Group: "apps.openshift.io", Version: "v1", Kind: deploymentConfigKind}
+)
+
+// getDeploymentConfig retrieves an OpenShift DeploymentConfig. It is not part of the client-go scheme,
+// so it is read as an unstructured object.
+func (r *ReconcileWatermarkPodAutoscaler) getDeploymentConfig(namespace, name string) (*unstructured.Unstructured, error) {
+	dc := &unstructured.Unstructured{}
+	dc.SetGroupVersionKind(deploymentConfigGVK)
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, dc); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// setDeploymentConfigSelector fills the selector of the scale of a DeploymentConfig.
+// Depending on the version of OpenShift, the scale subresource of the DeploymentConfigs does not always
+// report the selector of the pods, in which case all the pods of the namespace would be considered.
+func (r *ReconcileWatermarkPodAutoscaler) setDeploymentConfigSelector(namespace, name string, scale *autoscalingv1.Scale) error {
+	dc, err := r.getDeploymentConfig(namespace, name)
+	if err != nil {
+		return fmt.Errorf("unable to get the DeploymentConfig %s/%s: %v", namespace, name, err)
+	}
+	selector, err := deploymentConfigSelector(dc)
+	if err != nil {
+		return err
+	}
+	scale.Status.Selector = selector
+	return nil
+}
+
+func deploymentConfigSelector(dc *unstructured.Unstructured) (string, error) {
+	selector, found, err := unstructured.NestedStringMap(dc.Object, "spec", "selector")
+	if err != nil {
+		return "", fmt.Errorf("invalid selector for the DeploymentConfig %s/%s: %v", dc.GetNamespace(), dc.GetName(), err)
+	}
+	if !found || len(selector) == 0 {
+		return "", fmt.Errorf("no selector defined for the DeploymentConfig %s/%s", dc.GetNamespace(), dc.GetName())
+	}
+	return labels.SelectorFromSet(selector).String(), nil
+}
+
+// deploymentConfigAsDeployment only converts the fields of a DeploymentConfig used to follow its rollouts.
+func deploymentConfigAsDeployment(dc *unstructured.Unstructured) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{}
+	deploy.Generation = dc.GetGeneration()
+	if replicas, found, _ := unstructured.NestedInt64(dc.Object, "spec", "replicas"); found {
+		specReplicas := int32(replicas)
+		deploy.Spec.Replicas = &specReplicas
+	}
+	status := func(field string) int64 {
+		value, _, _ := unstructured.NestedInt64(dc.Object, "status", field)
+		return value
+	}
+	deploy.Status.ObservedGeneration = status("observedGeneration")
+	deploy.Status.Replicas = int32(status("replicas"))
+	deploy.Status.UpdatedReplicas = int32(status("updatedReplicas"))
+	deploy.Status.AvailableReplicas = int32(status("availableReplicas"))
+	deploy.Status.UnavailableReplicas = int32(status("unavailableReplicas"))
+	conditions, _, _ := unstructured.NestedSlice(dc.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != string(appsv1.DeploymentProgressing) {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		deploy.Status.Conditions = append(deploy.Status.Conditions, appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionStatus(status), Reason: reason})
+	}
+	return deploy
+}
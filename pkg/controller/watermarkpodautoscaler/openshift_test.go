@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newDeploymentConfig(spec, status map[string]interface{}) *unstructured.Unstructured {
+	dc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   spec,
+		"status": status,
+	}}
+	dc.SetGroupVersionKind(deploymentConfigGVK)
+	dc.SetNamespace("bar")
+	dc.SetName("foo")
+	dc.SetGeneration(3)
+	return dc
+}
+
+func TestDeploymentConfigSelector(t *testing.T) {
+	dc := newDeploymentConfig(map[string]interface{}{
+		"selector": map[string]interface{}{"app": "foo", "deploymentconfig": "foo"},
+	}, nil)
+	selector, err := deploymentConfigSelector(dc)
+	require.NoError(t, err)
+	require.Equal(t, "app=foo,deploymentconfig=foo", selector)
+
+	_, err = deploymentConfigSelector(newDeploymentConfig(map[string]interface{}{}, nil))
+	require.Error(t, err)
+}
+
+func TestDeploymentConfigAsDeployment(t *testing.T) {
+	dc := newDeploymentConfig(map[string]interface{}{
+		"replicas": int64(3),
+	}, map[string]interface{}{
+		"observedGeneration":  int64(3),
+		"replicas":            int64(4),
+		"updatedReplicas":     int64(3),
+		"availableReplicas":   int64(4),
+		"unavailableReplicas": int64(0),
+	})
+	deploy := deploymentConfigAsDeployment(dc)
+	require.Equal(t, int32(3), *deploy.Spec.Replicas)
+	require.Equal(t, int32(4), deploy.Status.Replicas)
+	require.True(t, isDeploymentRollingOut(deploy))
+
+	unstructured.SetNestedSlice(dc.Object, []interface{}{
+		map[string]interface{}{"type": "Available", "status": "True"},
+		map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+	}, "status", "conditions")
+	require.False(t, isDeploymentRollingOut(deploymentConfigAsDeployment(dc)), "the rollout exceeded its progress deadline")
+
+	unstructured.SetNestedField(dc.Object, int64(3), "status", "replicas")
+	unstructured.SetNestedField(dc.Object, int64(3), "status", "availableReplicas")
+	unstructured.RemoveNestedField(dc.Object, "status", "conditions")
+	require.False(t, isDeploymentRollingOut(deploymentConfigAsDeployment(dc)))
+}
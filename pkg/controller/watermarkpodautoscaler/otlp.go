@@ -0,0 +1,367 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/version"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	sigmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	otlpServiceName = "watermarkpodautoscaler"
+	// otlpCumulative is the AGGREGATION_TEMPORALITY_CUMULATIVE of the OTLP protocol, the temporality of the counters
+	// and histograms of the Prometheus registry.
+	otlpCumulative = 2
+)
+
+// otlpExporter pushes the metrics of the controller to an OTLP/HTTP endpoint, such as an OpenTelemetry collector,
+// encoded in JSON. It is configured with the standard OTEL_EXPORTER_OTLP_* environment variables.
+type otlpExporter struct {
+	endpoint   string
+	headers    map[string]string
+	interval   time.Duration
+	attributes []otlpKeyValue
+	gatherer   prometheus.Gatherer
+	client     *http.Client
+	start      time.Time
+}
+
+// newOTLPExporterFromEnv returns the exporter configured by the environment, nil when no OTLP endpoint is set:
+//   - OTEL_EXPORTER_OTLP_METRICS_ENDPOINT is the URL the metrics are posted to, OTEL_EXPORTER_OTLP_ENDPOINT the base
+//     URL of the endpoint, to which /v1/metrics is appended.
+//   - OTEL_EXPORTER_OTLP_METRICS_HEADERS or OTEL_EXPORTER_OTLP_HEADERS are the headers of the requests, as key=value
+//     pairs separated by commas, typically set from a Secret.
+//   - OTEL_METRIC_EXPORT_INTERVAL is the interval between two exports in milliseconds, 60000 by default.
+//   - OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES set the attributes of the resource of the metrics.
+func newOTLPExporterFromEnv(getenv func(string) string) (*otlpExporter, error) {
+	endpoint := getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT")
+	if endpoint == "" {
+		if base := getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = strings.TrimSuffix(base, "/") + "/v1/metrics"
+		}
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s: %v", endpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s: an http or https URL is expected", endpoint)
+	}
+
+	rawHeaders := getenv("OTEL_EXPORTER_OTLP_METRICS_HEADERS")
+	if rawHeaders == "" {
+		rawHeaders = getenv("OTEL_EXPORTER_OTLP_HEADERS")
+	}
+	headers, err := parseOTLPKeyValues(rawHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP headers: %v", err)
+	}
+
+	interval := 60 * time.Second
+	if raw := getenv("OTEL_METRIC_EXPORT_INTERVAL"); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms <= 0 {
+			return nil, fmt.Errorf("invalid OTEL_METRIC_EXPORT_INTERVAL %s: a positive number of milliseconds is expected", raw)
+		}
+		interval = time.Duration(ms) * time.Millisecond
+	}
+
+	resource, err := parseOTLPKeyValues(getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_RESOURCE_ATTRIBUTES: %v", err)
+	}
+	if name := getenv("OTEL_SERVICE_NAME"); name != "" {
+		resource["service.name"] = name
+	}
+	if resource["service.name"] == "" {
+		resource["service.name"] = otlpServiceName
+	}
+	attributes := make([]otlpKeyValue, 0, len(resource))
+	for key, value := range resource {
+		attributes = append(attributes, otlpString(key, value))
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].Key < attributes[j].Key })
+
+	return &otlpExporter{
+		endpoint:   endpoint,
+		headers:    headers,
+		interval:   interval,
+		attributes: attributes,
+		gatherer:   sigmetrics.Registry,
+		client:     &http.Client{Timeout: interval},
+		start:      time.Now(),
+	}, nil
+}
+
+// parseOTLPKeyValues parses the key=value pairs, separated by commas, of the OTEL_* environment variables. The values
+// are URL-decoded.
+func parseOTLPKeyValues(raw string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", strings.TrimSpace(pair))
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %v", key, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// Start implements manager.Runnable, it exports the metrics at every interval until the stop channel is closed.
+func (e *otlpExporter) Start(stop <-chan struct{}) error {
+	log.Info("Exporting the metrics to the OTLP endpoint", "endpoint", e.endpoint, "interval", e.interval)
+	pushPeriodically(stop, e.interval, func(now time.Time) {
+		if err := e.push(now); err != nil {
+			log.Error(err, "Unable to export the metrics to the OTLP endpoint", "endpoint", e.endpoint)
+		}
+	})
+	return nil
+}
+
+// push sends the current values of the metrics of the WPAs to the endpoint.
+func (e *otlpExporter) push(now time.Time) error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("unable to gather the metrics: %v", err)
+	}
+	metrics := otlpMetrics(families, e.start, now)
+	if len(metrics) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(otlpExportRequest{ResourceMetrics: []otlpResourceMetrics{{
+		Resource:     otlpResource{Attributes: e.attributes},
+		ScopeMetrics: []otlpScopeMetrics{{Scope: otlpScope{Name: otlpServiceName, Version: version.Version}, Metrics: metrics}},
+	}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		if message := strings.TrimSpace(string(body)); message != "" {
+			return fmt.Errorf("unexpected status %s from the OTLP endpoint: %s", resp.Status, message)
+		}
+		return fmt.Errorf("unexpected status %s from the OTLP endpoint", resp.Status)
+	}
+	return nil
+}
+
+// The following types are the JSON encoding of the ExportMetricsServiceRequest of OTLP. The 64-bit integers are
+// encoded as strings, as required by the JSON mapping of protobuf.
+type otlpExportRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Gauge       *otlpGauge     `json:"gauge,omitempty"`
+	Sum         *otlpSum       `json:"sum,omitempty"`
+	Histogram   *otlpHistogram `json:"histogram,omitempty"`
+	Summary     *otlpSummary   `json:"summary,omitempty"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+type otlpSummary struct {
+	DataPoints []otlpSummaryDataPoint `json:"dataPoints"`
+}
+
+type otlpNumberDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          float64        `json:"asDouble"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	Count             string         `json:"count"`
+	Sum               float64        `json:"sum"`
+	BucketCounts      []string       `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+type otlpSummaryDataPoint struct {
+	Attributes        []otlpKeyValue      `json:"attributes,omitempty"`
+	StartTimeUnixNano string              `json:"startTimeUnixNano"`
+	TimeUnixNano      string              `json:"timeUnixNano"`
+	Count             string              `json:"count"`
+	Sum               float64             `json:"sum"`
+	QuantileValues    []otlpQuantileValue `json:"quantileValues"`
+}
+
+type otlpQuantileValue struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+// otlpMetrics converts the metric families of the controller, the ones of its subsystem, into OTLP metrics. The
+// counters are cumulative sums and the cumulative buckets of the histograms are converted to the count of each
+// bucket, all of them starting at the time the exporter was created.
+func otlpMetrics(families []*dto.MetricFamily, start, now time.Time) []otlpMetric {
+	startNano := strconv.FormatInt(start.UnixNano(), 10)
+	nowNano := strconv.FormatInt(now.UnixNano(), 10)
+	var metrics []otlpMetric
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), subsystem+"_") {
+			continue
+		}
+		metric := otlpMetric{Name: family.GetName(), Description: family.GetHelp()}
+		for _, m := range family.GetMetric() {
+			attributes := make([]otlpKeyValue, 0, len(m.GetLabel()))
+			for _, pair := range m.GetLabel() {
+				attributes = append(attributes, otlpString(pair.GetName(), pair.GetValue()))
+			}
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				if metric.Gauge == nil {
+					metric.Gauge = &otlpGauge{}
+				}
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, otlpNumberDataPoint{Attributes: attributes, TimeUnixNano: nowNano, AsDouble: m.GetGauge().GetValue()})
+			case dto.MetricType_UNTYPED:
+				if metric.Gauge == nil {
+					metric.Gauge = &otlpGauge{}
+				}
+				metric.Gauge.DataPoints = append(metric.Gauge.DataPoints, otlpNumberDataPoint{Attributes: attributes, TimeUnixNano: nowNano, AsDouble: m.GetUntyped().GetValue()})
+			case dto.MetricType_COUNTER:
+				if metric.Sum == nil {
+					metric.Sum = &otlpSum{AggregationTemporality: otlpCumulative, IsMonotonic: true}
+				}
+				metric.Sum.DataPoints = append(metric.Sum.DataPoints, otlpNumberDataPoint{Attributes: attributes, StartTimeUnixNano: startNano, TimeUnixNano: nowNano, AsDouble: m.GetCounter().GetValue()})
+			case dto.MetricType_HISTOGRAM:
+				if metric.Histogram == nil {
+					metric.Histogram = &otlpHistogram{AggregationTemporality: otlpCumulative}
+				}
+				h := m.GetHistogram()
+				point := otlpHistogramDataPoint{
+					Attributes:        attributes,
+					StartTimeUnixNano: startNano,
+					TimeUnixNano:      nowNano,
+					Count:             strconv.FormatUint(h.GetSampleCount(), 10),
+					Sum:               h.GetSampleSum(),
+					BucketCounts:      []string{},
+					ExplicitBounds:    []float64{},
+				}
+				previous := uint64(0)
+				for _, b := range h.GetBucket() {
+					point.ExplicitBounds = append(point.ExplicitBounds, b.GetUpperBound())
+					point.BucketCounts = append(point.BucketCounts, strconv.FormatUint(b.GetCumulativeCount()-previous, 10))
+					previous = b.GetCumulativeCount()
+				}
+				point.BucketCounts = append(point.BucketCounts, strconv.FormatUint(h.GetSampleCount()-previous, 10))
+				metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, point)
+			case dto.MetricType_SUMMARY:
+				if metric.Summary == nil {
+					metric.Summary = &otlpSummary{}
+				}
+				s := m.GetSummary()
+				point := otlpSummaryDataPoint{
+					Attributes:        attributes,
+					StartTimeUnixNano: startNano,
+					TimeUnixNano:      nowNano,
+					Count:             strconv.FormatUint(s.GetSampleCount(), 10),
+					Sum:               s.GetSampleSum(),
+					QuantileValues:    []otlpQuantileValue{},
+				}
+				for _, q := range s.GetQuantile() {
+					point.QuantileValues = append(point.QuantileValues, otlpQuantileValue{Quantile: q.GetQuantile(), Value: q.GetValue()})
+				}
+				metric.Summary.DataPoints = append(metric.Summary.DataPoints, point)
+			}
+		}
+		if metric.Gauge != nil || metric.Sum != nil || metric.Histogram != nil || metric.Summary != nil {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
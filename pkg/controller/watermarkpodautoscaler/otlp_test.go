@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/version"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOTLPExporterFromEnv(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		wantNil        bool
+		wantEndpoint   string
+		wantHeaders    map[string]string
+		wantInterval   time.Duration
+		wantAttributes []otlpKeyValue
+		wantErr        string
+	}{
+		{
+			name:    "disabled",
+			wantNil: true,
+		},
+		{
+			name:           "base endpoint",
+			env:            map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318/"},
+			wantEndpoint:   "http://collector:4318/v1/metrics",
+			wantHeaders:    map[string]string{},
+			wantInterval:   60 * time.Second,
+			wantAttributes: []otlpKeyValue{otlpString("service.name", "watermarkpodautoscaler")},
+		},
+		{
+			name: "metrics endpoint, headers and resource",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT":         "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT": "https://otlp.example.com/ingest",
+				"OTEL_EXPORTER_OTLP_HEADERS":          "ignored=true",
+				"OTEL_EXPORTER_OTLP_METRICS_HEADERS":  "api-key=s3cr3t, x-team=sre%20team",
+				"OTEL_METRIC_EXPORT_INTERVAL":         "15000",
+				"OTEL_RESOURCE_ATTRIBUTES":            "k8s.cluster.name=prod,service.name=wpa",
+				"OTEL_SERVICE_NAME":                   "wpa-controller",
+			},
+			wantEndpoint:   "https://otlp.example.com/ingest",
+			wantHeaders:    map[string]string{"api-key": "s3cr3t", "x-team": "sre team"},
+			wantInterval:   15 * time.Second,
+			wantAttributes: []otlpKeyValue{otlpString("k8s.cluster.name", "prod"), otlpString("service.name", "wpa-controller")},
+		},
+		{
+			name:    "invalid endpoint",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "collector:4318"},
+			wantErr: "invalid OTLP endpoint collector:4318/v1/metrics: an http or https URL is expected",
+		},
+		{
+			name:    "invalid headers",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_EXPORTER_OTLP_HEADERS": "api-key"},
+			wantErr: `invalid OTLP headers: "api-key" is not a key=value pair`,
+		},
+		{
+			name:    "invalid interval",
+			env:     map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_METRIC_EXPORT_INTERVAL": "1m"},
+			wantErr: "invalid OTEL_METRIC_EXPORT_INTERVAL 1m: a positive number of milliseconds is expected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newOTLPExporterFromEnv(func(key string) string { return tt.env[key] })
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				require.Nil(t, e)
+				return
+			}
+			require.Equal(t, tt.wantEndpoint, e.endpoint)
+			require.Equal(t, tt.wantHeaders, e.headers)
+			require.Equal(t, tt.wantInterval, e.interval)
+			require.Equal(t, tt.wantAttributes, e.attributes)
+		})
+	}
+}
+
+func TestOTLPMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: subsystem, Name: "value", Help: "value"}, []string{wpaNamePromLabel})
+	gauge.WithLabelValues("foo").Set(12)
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Subsystem: subsystem, Name: "decisions", Help: "decisions"})
+	counter.Add(3)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Subsystem: subsystem, Name: "replicas_applied", Help: "replicas", Buckets: []float64{1, 2}})
+	histogram.Observe(2)
+	histogram.Observe(5)
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "go_other", Help: "other"})
+	registry.MustRegister(gauge, counter, histogram, other)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	metrics := otlpMetrics(families, time.Unix(1, 0), time.Unix(2, 0))
+	require.Equal(t, []otlpMetric{
+		{
+			Name:        "wpa_controller_decisions",
+			Description: "decisions",
+			Sum: &otlpSum{
+				DataPoints:             []otlpNumberDataPoint{{Attributes: []otlpKeyValue{}, StartTimeUnixNano: "1000000000", TimeUnixNano: "2000000000", AsDouble: 3}},
+				AggregationTemporality: otlpCumulative,
+				IsMonotonic:            true,
+			},
+		},
+		{
+			Name:        "wpa_controller_replicas_applied",
+			Description: "replicas",
+			Histogram: &otlpHistogram{
+				DataPoints: []otlpHistogramDataPoint{{
+					Attributes:        []otlpKeyValue{},
+					StartTimeUnixNano: "1000000000",
+					TimeUnixNano:      "2000000000",
+					Count:             "2",
+					Sum:               7,
+					BucketCounts:      []string{"0", "1", "1"},
+					ExplicitBounds:    []float64{1, 2},
+				}},
+				AggregationTemporality: otlpCumulative,
+			},
+		},
+		{
+			Name:        "wpa_controller_value",
+			Description: "value",
+			Gauge: &otlpGauge{
+				DataPoints: []otlpNumberDataPoint{{Attributes: []otlpKeyValue{otlpString(wpaNamePromLabel, "foo")}, TimeUnixNano: "2000000000", AsDouble: 12}},
+			},
+		},
+	}, metrics)
+}
+
+func TestOTLPExporterPush(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	e, err := newOTLPExporterFromEnv(func(key string) string {
+		return map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": server.URL, "OTEL_EXPORTER_OTLP_HEADERS": "api-key=s3cr3t"}[key]
+	})
+	require.NoError(t, err)
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: subsystem, Name: "min_replicas", Help: "min"})
+	gauge.Set(3)
+	registry.MustRegister(gauge)
+	e.gatherer = registry
+
+	require.NoError(t, e.push(time.Unix(2, 0)))
+	require.Equal(t, "/v1/metrics", received.URL.Path)
+	require.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	require.Equal(t, "s3cr3t", received.Header.Get("api-key"))
+	require.JSONEq(t, `{"resourceMetrics": [{
+		"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "watermarkpodautoscaler"}}]},
+		"scopeMetrics": [{
+			"scope": {"name": "watermarkpodautoscaler", "version": "`+version.Version+`"},
+			"metrics": [{"name": "wpa_controller_min_replicas", "description": "min", "gauge": {"dataPoints": [{"timeUnixNano": "2000000000", "asDouble": 3}]}}]
+		}]
+	}]}`, string(body))
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// writeOwnedConfigMap sets the key of the ConfigMap of the WPA, created and controlled by the WPA if it doesn't exist,
+// and lets rotate remove the oldest keys. As the name of the ConfigMap comes from the spec, an existing ConfigMap not
+// controlled by the WPA is never written to, whatever it holds.
+func (r *ReconcileWatermarkPodAutoscaler) writeOwnedConfigMap(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, name, key, value string, rotate func(data map[string]string)) error {
+	configMap := &corev1.ConfigMap{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: wpa.Namespace, Name: name}, configMap)
+	if errors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       wpa.Namespace,
+				Name:            name,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(wpa, datadoghqv1alpha1.SchemeGroupVersion.WithKind("WatermarkPodAutoscaler"))},
+			},
+			Data: map[string]string{key: value},
+		}
+		return r.client.Create(context.TODO(), configMap)
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(configMap, wpa) {
+		return fmt.Errorf("the ConfigMap %s/%s is not controlled by the WPA", wpa.Namespace, name)
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[key] = value
+	rotate(configMap.Data)
+	return r.client.Update(context.TODO(), configMap)
+}
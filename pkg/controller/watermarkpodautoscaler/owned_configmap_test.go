@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWriteOwnedConfigMap(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, "test", &test.NewWatermarkPodAutoscalerOptions{})
+	wpa.UID = "wpa-uid"
+	foreign := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "app-config"},
+		Data:       map[string]string{"config.yaml": "foo"},
+	}
+	s := runtime.NewScheme()
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.ConfigMap{})
+	r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClientWithScheme(s, foreign)}
+	keepLast := func(data map[string]string) {
+		delete(data, "a")
+	}
+	getData := func(name string) map[string]string {
+		configMap := &corev1.ConfigMap{}
+		require.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Namespace: testingNamespace, Name: name}, configMap))
+		return configMap.Data
+	}
+
+	require.NoError(t, r.writeOwnedConfigMap(wpa, "test-history", "a", "1", keepLast))
+	require.Equal(t, map[string]string{"a": "1"}, getData("test-history"), "the ConfigMap is created without rotation")
+	require.NoError(t, r.writeOwnedConfigMap(wpa, "test-history", "b", "2", keepLast))
+	require.Equal(t, map[string]string{"b": "2"}, getData("test-history"))
+
+	err := r.writeOwnedConfigMap(wpa, "app-config", "a", "1", keepLast)
+	require.EqualError(t, err, "the ConfigMap bar/app-config is not controlled by the WPA")
+	require.Equal(t, map[string]string{"config.yaml": "foo"}, getData("app-config"), "the ConfigMaps not controlled by the WPA are left untouched")
+
+	other := wpa.DeepCopy()
+	other.UID = "other-uid"
+	require.Error(t, r.writeOwnedConfigMap(other, "test-history", "c", "3", keepLast), "the ConfigMap of another WPA is left untouched")
+}
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maxOwnerDepth bounds the chains of controllers walked from a pod up to the target, e.g. Pod, ReplicaSet,
+// Deployment and the custom resource managing the Deployment.
+const maxOwnerDepth = 4
+
+// setOwnedPodsSelector fills the selector of the scale of a target that doesn't report one, as some custom resources
+// implement the scale subresource without a labelSelectorPath. The pods of the target are the ones its UID controls
+// through a chain of ownerReferences, and the selector is made of the labels they have in common, as long as it
+// doesn't match any other pod of the namespace.
+func (r *ReconcileWatermarkPodAutoscaler) setOwnedPodsSelector(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale) error {
+	ref := wpa.Spec.ScaleTargetRef
+	uid := scale.UID
+	if uid == "" {
+		target, err := r.getUnstructured(wpa.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+		if err != nil {
+			return fmt.Errorf("unable to get the %s %s to discover its pods: %v", ref.Kind, ref.Name, err)
+		}
+		uid = target.GetUID()
+	}
+	pods, err := r.podLister.Pods(wpa.Namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("unable to list the pods to discover the ones of the %s %s: %v", ref.Kind, ref.Name, err)
+	}
+	owners := map[types.UID]*metav1.OwnerReference{}
+	var owned []*corev1.Pod
+	for _, pod := range pods {
+		if r.isControlledBy(wpa.Namespace, pod, uid, owners) {
+			owned = append(owned, pod)
+		}
+	}
+	selector, err := ownedPodsSelector(owned, pods)
+	if err != nil {
+		return fmt.Errorf("unable to discover the selector of the %s %s: %v", ref.Kind, ref.Name, err)
+	}
+	scale.Status.Selector = selector
+	return nil
+}
+
+// isControlledBy returns whether the chain of the controllers of the pod reaches the UID. The controllers of the
+// owners already read are kept in owners, a nil value for an owner without controller.
+func (r *ReconcileWatermarkPodAutoscaler) isControlledBy(namespace string, pod *corev1.Pod, uid types.UID, owners map[types.UID]*metav1.OwnerReference) bool {
+	controller := metav1.GetControllerOf(pod)
+	for depth := 0; controller != nil && depth < maxOwnerDepth; depth++ {
+		if controller.UID == uid {
+			return true
+		}
+		next, found := owners[controller.UID]
+		if !found {
+			owner, err := r.getUnstructured(namespace, controller.APIVersion, controller.Kind, controller.Name)
+			if err != nil {
+				log.V(4).Info("Could not get the owner of the pod", "namespace", namespace, "pod", pod.Name, "owner", controller.Name, "error", err)
+				return false
+			}
+			next = metav1.GetControllerOf(owner)
+			owners[controller.UID] = next
+		}
+		controller = next
+	}
+	return false
+}
+
+// getUnstructured reads an object of any kind from the API server.
+func (r *ReconcileWatermarkPodAutoscaler) getUnstructured(namespace, apiVersion, kind, name string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(apiVersion, kind))
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ownedPodsSelector returns the selector of the labels the owned pods have in common, provided it only matches owned
+// pods among all the pods of the namespace.
+func ownedPodsSelector(owned, all []*corev1.Pod) (string, error) {
+	if len(owned) == 0 {
+		return "", fmt.Errorf("no pod is controlled by the target")
+	}
+	common := labels.Set{}
+	for key, value := range owned[0].Labels {
+		common[key] = value
+	}
+	for _, pod := range owned[1:] {
+		for key, value := range common {
+			if pod.Labels[key] != value {
+				delete(common, key)
+			}
+		}
+	}
+	if len(common) == 0 {
+		return "", fmt.Errorf("the %d pods controlled by the target have no label in common", len(owned))
+	}
+	ownedNames := map[string]bool{}
+	for _, pod := range owned {
+		ownedNames[pod.Name] = true
+	}
+	selector := labels.SelectorFromSet(common)
+	for _, pod := range all {
+		if !ownedNames[pod.Name] && selector.Matches(labels.Set(pod.Labels)) {
+			return "", fmt.Errorf("the labels %s common to the pods controlled by the target also match the pod %s", selector, pod.Name)
+		}
+	}
+	return selector.String(), nil
+}
@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newOwnedPod(name string, podLabels map[string]string, owner *metav1.OwnerReference) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testingNamespace, Labels: podLabels}}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func controllerRef(apiVersion, kind, name string, uid types.UID) *metav1.OwnerReference {
+	controller := true
+	return &metav1.OwnerReference{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid, Controller: &controller}
+}
+
+func TestSetOwnedPodsSelector(t *testing.T) {
+	target := &unstructured.Unstructured{}
+	target.SetAPIVersion("example.com/v1")
+	target.SetKind("Worker")
+	target.SetNamespace(testingNamespace)
+	target.SetName("worker")
+	target.SetUID("worker-uid")
+	targetRef := controllerRef("example.com/v1", "Worker", "worker", "worker-uid")
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "worker-abc", UID: "rs-uid", OwnerReferences: []metav1.OwnerReference{*targetRef}}}
+	replicaSetRef := controllerRef("apps/v1", "ReplicaSet", "worker-abc", "rs-uid")
+
+	s := runtime.NewScheme()
+	s.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.ReplicaSet{})
+	newReconciler := func(pods ...*corev1.Pod) *ReconcileWatermarkPodAutoscaler {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		for _, pod := range pods {
+			require.NoError(t, indexer.Add(pod))
+		}
+		return &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClientWithScheme(s, target, replicaSet), podLister: corelisters.NewPodLister(indexer)}
+	}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef: v1alpha1.CrossVersionObjectReference{APIVersion: "example.com/v1", Kind: "Worker", Name: "worker"},
+		},
+	})
+
+	scale := &autoscalingv1.Scale{}
+	require.EqualError(t, newReconciler().setOwnedPodsSelector(wpa, scale), "unable to discover the selector of the Worker worker: no pod is controlled by the target")
+	require.Empty(t, scale.Status.Selector, "nothing is discovered without the pods")
+
+	r := newReconciler(
+		newOwnedPod("direct", map[string]string{"app": "worker", "role": "leader"}, targetRef),
+		newOwnedPod("through-replicaset", map[string]string{"app": "worker", "role": "follower"}, replicaSetRef),
+		newOwnedPod("other", map[string]string{"app": "other"}, controllerRef("apps/v1", "ReplicaSet", "other", "other-uid")),
+		newOwnedPod("orphan", map[string]string{"role": "leader"}, nil),
+	)
+	scale = &autoscalingv1.Scale{}
+	require.NoError(t, r.setOwnedPodsSelector(wpa, scale), "the UID of the target is read when the scale doesn't report it")
+	require.Equal(t, "app=worker", scale.Status.Selector)
+
+	scale = &autoscalingv1.Scale{ObjectMeta: metav1.ObjectMeta{UID: "worker-uid"}}
+	require.NoError(t, r.setOwnedPodsSelector(wpa, scale))
+	require.Equal(t, "app=worker", scale.Status.Selector)
+
+	r = newReconciler(
+		newOwnedPod("direct", map[string]string{"app": "worker"}, targetRef),
+		newOwnedPod("lookalike", map[string]string{"app": "worker"}, nil),
+	)
+	require.EqualError(t, r.setOwnedPodsSelector(wpa, &autoscalingv1.Scale{}), "unable to discover the selector of the Worker worker: the labels app=worker common to the pods controlled by the target also match the pod lookalike")
+
+	r = newReconciler(newOwnedPod("other", map[string]string{"app": "other"}, nil))
+	require.EqualError(t, r.setOwnedPodsSelector(wpa, &autoscalingv1.Scale{}), "unable to discover the selector of the Worker worker: no pod is controlled by the target")
+}
+
+func TestOwnedPodsSelector(t *testing.T) {
+	_, err := ownedPodsSelector([]*corev1.Pod{
+		newOwnedPod("a", map[string]string{"app": "a"}, nil),
+		newOwnedPod("b", map[string]string{"app": "b"}, nil),
+	}, nil)
+	require.EqualError(t, err, "the 2 pods controlled by the target have no label in common")
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	defaultPodAnnotationQPS   = 5
+	defaultPodAnnotationBurst = 10
+)
+
+// podAnnotator writes annotations on pods while limiting the rate of calls to the API Server,
+// as a single reconcile loop can concern a large number of pods.
+type podAnnotator struct {
+	client      corev1client.PodsGetter
+	rateLimiter flowcontrol.RateLimiter
+}
+
+func newPodAnnotator(client corev1client.PodsGetter, qps float32, burst int) *podAnnotator {
+	return &podAnnotator{
+		client:      client,
+		rateLimiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+	}
+}
+
+// annotate sets the annotation on the pod, if it does not already have this value.
+// It returns false if the write was skipped because of the rate limiting, in which case
+// it is expected to be retried during the next reconcile loop.
+func (a *podAnnotator) annotate(pod *corev1.Pod, key, value string) (bool, error) {
+	if current, found := pod.Annotations[key]; found && current == value {
+		return true, nil
+	}
+	if !a.rateLimiter.TryAccept() {
+		return false, nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{key: value},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	_, err = a.client.Pods(pod.Namespace).Patch(pod.Name, types.MergePatchType, patch)
+	return err == nil, err
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodAnnotator_annotate(t *testing.T) {
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-0", Namespace: testNamespace}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: testNamespace}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: testNamespace, Annotations: map[string]string{podDeletionCostAnnotation: "2"}}},
+	}
+	client := fake.NewSimpleClientset(pods[0], pods[1], pods[2])
+	// Only allow a single write.
+	annotator := newPodAnnotator(client.CoreV1(), 0.0001, 1)
+
+	written, err := annotator.annotate(pods[0], podDeletionCostAnnotation, "0")
+	require.NoError(t, err)
+	assert.True(t, written)
+	pod, err := client.CoreV1().Pods(testNamespace).Get("pod-0", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "0", pod.Annotations[podDeletionCostAnnotation])
+
+	// The rate limiter prevents the second write.
+	written, err = annotator.annotate(pods[1], podDeletionCostAnnotation, "1")
+	require.NoError(t, err)
+	assert.False(t, written)
+	pod, err = client.CoreV1().Pods(testNamespace).Get("pod-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, pod.Annotations, podDeletionCostAnnotation)
+
+	// No call is needed when the annotation already has the expected value.
+	written, err = annotator.annotate(pods[2], podDeletionCostAnnotation, "2")
+	require.NoError(t, err)
+	assert.True(t, written)
+}
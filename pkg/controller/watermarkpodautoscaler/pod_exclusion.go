@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// excludeAnnotation marks the pods always left out of the ready pods and of the metrics.
+const excludeAnnotation = "wpa.datadoghq.com/exclude"
+
+// podExclusion returns whether a pod is left out of the ready pods and of the metrics.
+type podExclusion func(pod *corev1.Pod) bool
+
+// excludedPods returns the pods of the target the WPA leaves out of the ready pods and of the metrics.
+func (c *ReplicaCalculator) excludedPods(ctx context.Context, logger logr.Logger, wpa *v1alpha1.WatermarkPodAutoscaler) podExclusion {
+	drainingNodes, unhealthyNodes := c.classifyNodes(ctx, logger, wpa)
+	// A nil selector matches nothing.
+	selector, err := metav1.LabelSelectorAsSelector(wpa.Spec.ExcludedPodSelector)
+	if err != nil {
+		logger.Info("Invalid excludedPodSelector, only the annotated pods are excluded", "error", err)
+		selector = labels.Nothing()
+	}
+	return func(pod *corev1.Pod) bool {
+		if pod.Annotations[excludeAnnotation] == "true" || selector.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+		if wpa.Spec.ExcludeTerminatingPods && pod.DeletionTimestamp != nil {
+			return true
+		}
+		if wpa.Spec.FailedPods != nil && wpa.Spec.FailedPods.ExcludeFinished && isFinished(pod) {
+			return true
+		}
+		if drainingNodes.Has(pod.Spec.NodeName) {
+			return true
+		}
+		// The usage of the pods not ready because of their node doesn't reflect the load of the application.
+		return unhealthyNodes.Has(pod.Spec.NodeName) && !isPodReady(pod)
+	}
+}
+
+// classifyNodes returns the names of the cordoned or draining nodes and of the unhealthy nodes, for the
+// WPAs respectively excluding the pods on cordoned nodes and aware of the node pressure.
+func (c *ReplicaCalculator) classifyNodes(ctx context.Context, logger logr.Logger, wpa *v1alpha1.WatermarkPodAutoscaler) (draining, unhealthy sets.String) {
+	draining, unhealthy = sets.NewString(), sets.NewString()
+	if wpa.Spec.ExcludeCordonedNodes == nil && !wpa.Spec.NodePressureAware || c.client == nil {
+		return draining, unhealthy
+	}
+	nodes := &corev1.NodeList{}
+	if err := c.client.List(ctx, nodes); err != nil {
+		logger.Info("Could not list the nodes, the pods are not excluded based on their node", "error", err)
+		return draining, unhealthy
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if wpa.Spec.ExcludeCordonedNodes != nil && isDraining(node, wpa.Spec.ExcludeCordonedNodes.TaintKeys) {
+			draining.Insert(node.Name)
+		}
+		if wpa.Spec.NodePressureAware && isUnhealthy(node) {
+			unhealthy.Insert(node.Name)
+		}
+	}
+	if draining.Len() > 0 {
+		logger.V(2).Info("Excluding the pods on draining nodes", "nodes", draining.List())
+	}
+	if unhealthy.Len() > 0 {
+		logger.V(2).Info("Excluding the unready pods on unhealthy nodes", "nodes", unhealthy.List())
+	}
+	return draining, unhealthy
+}
+
+// isDraining returns whether the node is cordoned or has one of the taints marking it as draining.
+func isDraining(node *corev1.Node, taintKeys []string) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+	keys := sets.NewString(taintKeys...)
+	for _, taint := range node.Spec.Taints {
+		if keys.Has(taint.Key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func noPodExcluded(*corev1.Pod) bool { return false }
+
+func TestExcludedPodsOnDrainingNodes(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	newNode := func(name string, unschedulable bool, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Node"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable, Taints: taints},
+		}
+	}
+	newPod := func(node string) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{NodeName: node}}
+	}
+	c := NewReplicaCalculator(nil, nil, fake.NewFakeClientWithScheme(scheme.Scheme,
+		newNode("healthy", false),
+		newNode("cordoned", true),
+		newNode("spot", false, corev1.Taint{Key: "aws-node-termination-handler/spot-itn", Effect: corev1.TaintEffectNoSchedule}),
+		newNode("dedicated", false, corev1.Taint{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}),
+	))
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+
+	excluded := c.excludedPods(context.TODO(), logger, wpa)
+	require.False(t, excluded(newPod("cordoned")), "the pods on cordoned nodes are only excluded on demand")
+
+	wpa.Spec.ExcludeCordonedNodes = &v1alpha1.CordonedNodesSpec{TaintKeys: []string{"aws-node-termination-handler/spot-itn"}}
+	excluded = c.excludedPods(context.TODO(), logger, wpa)
+	require.False(t, excluded(newPod("healthy")))
+	require.True(t, excluded(newPod("cordoned")))
+	require.True(t, excluded(newPod("spot")))
+	require.False(t, excluded(newPod("dedicated")))
+	require.False(t, excluded(newPod("")), "a pending pod isn't bound to a draining node")
+}
+
+func TestExcludedTerminatingPods(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	c := NewReplicaCalculator(nil, nil, nil)
+	now := metav1.Now()
+	terminating := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}}
+	running := &corev1.Pod{}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+
+	require.False(t, c.excludedPods(context.TODO(), logger, wpa)(terminating), "the terminating pods are counted by default")
+
+	wpa.Spec.ExcludeTerminatingPods = true
+	excluded := c.excludedPods(context.TODO(), logger, wpa)
+	require.True(t, excluded(terminating))
+	require.False(t, excluded(running))
+}
+
+func TestExcludedPodsBySelectorOrAnnotation(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	c := NewReplicaCalculator(nil, nil, nil)
+	canary := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"track": "canary"}}}
+	debug := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{excludeAnnotation: "true"}}}
+	stable := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"track": "stable"}}}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+
+	excluded := c.excludedPods(context.TODO(), logger, wpa)
+	require.False(t, excluded(canary))
+	require.True(t, excluded(debug), "the annotated pods are always excluded")
+	require.False(t, excluded(stable))
+
+	wpa.Spec.ExcludedPodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"track": "canary"}}
+	excluded = c.excludedPods(context.TODO(), logger, wpa)
+	require.True(t, excluded(canary))
+	require.True(t, excluded(debug))
+	require.False(t, excluded(stable))
+}
@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// weightAnnotation declares the capacity of a pod relative to the other pods of the target, 1 by default, e.g. 2 for
+// a pod on a node class handling twice the throughput.
+const weightAnnotation = "wpa.datadoghq.com/weight"
+
+// podWeight returns the weight the pod is annotated with, 1 when it isn't or when the annotation isn't a positive
+// number.
+func podWeight(pod *corev1.Pod) float64 {
+	annotation, found := pod.Annotations[weightAnnotation]
+	if !found {
+		return 1
+	}
+	weight, err := strconv.ParseFloat(annotation, 64)
+	if err != nil || weight <= 0 {
+		log.V(2).Info("Invalid weight annotation, the pod is weighted 1", "namespace", pod.Namespace, "name", pod.Name, "annotation", annotation)
+		return 1
+	}
+	return weight
+}
+
+// sumPodWeights returns the sum of the weights of the named pods of the list.
+func sumPodWeights(podList []*corev1.Pod, names sets.String) float64 {
+	var sum float64
+	for _, pod := range podList {
+		if names.Has(pod.Name) {
+			sum += podWeight(pod)
+		}
+	}
+	return sum
+}
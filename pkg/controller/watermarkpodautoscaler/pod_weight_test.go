@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestWeightedPod(name string, weight string, ready corev1.ConditionStatus) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testingNamespace, Labels: map[string]string{"app": "test"}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			StartTime:  &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready, LastTransitionTime: metav1.Time{Time: time.Now().Add(-time.Hour)}}},
+		},
+	}
+	if weight != "" {
+		pod.Annotations = map[string]string{weightAnnotation: weight}
+	}
+	return pod
+}
+
+func TestPodWeight(t *testing.T) {
+	require.Equal(t, 1.0, podWeight(newTestWeightedPod("default", "", corev1.ConditionTrue)))
+	require.Equal(t, 2.5, podWeight(newTestWeightedPod("large", "2.5", corev1.ConditionTrue)))
+	require.Equal(t, 1.0, podWeight(newTestWeightedPod("invalid", "large", corev1.ConditionTrue)))
+	require.Equal(t, 1.0, podWeight(newTestWeightedPod("zero", "0", corev1.ConditionTrue)), "a pod always has some capacity")
+}
+
+func TestSumPodWeights(t *testing.T) {
+	pods := []*corev1.Pod{
+		newTestWeightedPod("small", "", corev1.ConditionTrue),
+		newTestWeightedPod("large", "3", corev1.ConditionTrue),
+		newTestWeightedPod("ignored", "2", corev1.ConditionTrue),
+	}
+	require.Equal(t, 4.0, sumPodWeights(pods, sets.NewString("small", "large")))
+}
+
+func TestGetReadyPodsWeight(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pod := range []*corev1.Pod{
+		newTestWeightedPod("small", "", corev1.ConditionTrue),
+		newTestWeightedPod("large", "3", corev1.ConditionTrue),
+		newTestWeightedPod("unready", "2", corev1.ConditionFalse),
+	} {
+		require.NoError(t, indexer.Add(pod))
+	}
+	c := NewReplicaCalculator(nil, corelisters.NewPodLister(indexer), nil)
+
+	pods, weight, err := c.getReadyPodsCount(testingNamespace, labels.SelectorFromSet(labels.Set{"app": "test"}), 0, 0, noPodExcluded)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), pods.Ready)
+	require.Equal(t, 4.0, weight, "only the ready pods are weighted")
+}
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/go-logr/logr"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	policyCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "CompliantWithPolicy"
+)
+
+// listPolicies returns the WatermarkPodAutoscalerPolicies of the cluster, sorted by name.
+func (r *ReconcileWatermarkPodAutoscaler) listPolicies() ([]datadoghqv1alpha1.WatermarkPodAutoscalerPolicy, error) {
+	policies := &datadoghqv1alpha1.WatermarkPodAutoscalerPolicyList{}
+	if err := r.client.List(context.TODO(), policies); err != nil {
+		return nil, fmt.Errorf("unable to list the WatermarkPodAutoscalerPolicies: %v", err)
+	}
+	sort.Slice(policies.Items, func(i, j int) bool { return policies.Items[i].Name < policies.Items[j].Name })
+	return policies.Items, nil
+}
+
+// withPolicyDefaults returns a copy of the WPA with the settings it doesn't set taken from the defaults of the first policy setting them.
+func withPolicyDefaults(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, policies []datadoghqv1alpha1.WatermarkPodAutoscalerPolicy) *datadoghqv1alpha1.WatermarkPodAutoscaler {
+	defaultWPA := wpa.DeepCopy()
+	for _, policy := range policies {
+		if policy.Spec.Defaults != nil {
+			applySpecDefaults(&defaultWPA.Spec, policy.Spec.Defaults)
+		}
+	}
+	return defaultWPA
+}
+
+// applySpecDefaults sets the settings the spec doesn't set to the defaults.
+func applySpecDefaults(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, defaults *datadoghqv1alpha1.WatermarkPodAutoscalerPolicyDefaults) {
+	if spec.Algorithm == "" {
+		spec.Algorithm = defaults.Algorithm
+	}
+	if spec.Tolerance == 0 {
+		spec.Tolerance = defaults.Tolerance
+	}
+	if spec.DownscaleForbiddenWindowSeconds == 0 {
+		spec.DownscaleForbiddenWindowSeconds = defaults.DownscaleForbiddenWindowSeconds
+	}
+	if spec.UpscaleForbiddenWindowSeconds == 0 {
+		spec.UpscaleForbiddenWindowSeconds = defaults.UpscaleForbiddenWindowSeconds
+	}
+	if spec.ScaleUpLimitFactor == 0 {
+		spec.ScaleUpLimitFactor = defaults.ScaleUpLimitFactor
+	}
+	if spec.ScaleDownLimitFactor == 0 {
+		spec.ScaleDownLimitFactor = defaults.ScaleDownLimitFactor
+	}
+}
+
+// enforcePolicies brings the settings of the WPA within the limits of the policies, in memory, and reports the violations
+// in the CompliantWithPolicy condition. It returns false if the WPA isn't allowed to scale.
+func (r *ReconcileWatermarkPodAutoscaler) enforcePolicies(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) (bool, error) {
+	policies, err := r.listPolicies()
+	if err != nil {
+		return false, err
+	}
+	violations, allowed := enforcePolicyLimits(&wpa.Spec, policies)
+	if len(violations) == 0 {
+		setCondition(wpa, policyCondition, corev1.ConditionTrue, "CompliantWithPolicy", "the WPA is within the limits of the policies")
+		return true, nil
+	}
+	message := strings.Join(violations, "; ")
+	logger.Info("The WPA violates the policies", "violations", message)
+	setCondition(wpa, policyCondition, corev1.ConditionFalse, "PolicyViolation", "%s", message)
+	if !allowed {
+		setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "ForbiddenByPolicy", "the WPA is not allowed to scale: %s", message)
+	}
+	return allowed, nil
+}
+
+// enforcePolicyLimits applies the most restrictive limits of the policies to the spec and returns the violations,
+// along with whether the WPA is allowed to scale.
+func enforcePolicyLimits(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, policies []datadoghqv1alpha1.WatermarkPodAutoscalerPolicy) ([]string, bool) {
+	var violations []string
+	allowed := true
+	for _, policy := range policies {
+		limits := policy.Spec.Limits
+		if limits == nil {
+			continue
+		}
+		if limits.MaxReplicas != nil && spec.MaxReplicas > *limits.MaxReplicas {
+			violations = append(violations, fmt.Sprintf("maxReplicas %d lowered to %d by the policy %s", spec.MaxReplicas, *limits.MaxReplicas, policy.Name))
+			spec.MaxReplicas = *limits.MaxReplicas
+			if spec.MinReplicas != nil && *spec.MinReplicas > spec.MaxReplicas {
+				spec.MinReplicas = datadoghqv1alpha1.NewInt32(spec.MaxReplicas)
+			}
+		}
+		if spec.DownscaleForbiddenWindowSeconds < limits.MinDownscaleForbiddenWindowSeconds {
+			violations = append(violations, fmt.Sprintf("downscaleForbiddenWindowSeconds %d raised to %d by the policy %s", spec.DownscaleForbiddenWindowSeconds, limits.MinDownscaleForbiddenWindowSeconds, policy.Name))
+			spec.DownscaleForbiddenWindowSeconds = limits.MinDownscaleForbiddenWindowSeconds
+		}
+		if spec.UpscaleForbiddenWindowSeconds < limits.MinUpscaleForbiddenWindowSeconds {
+			violations = append(violations, fmt.Sprintf("upscaleForbiddenWindowSeconds %d raised to %d by the policy %s", spec.UpscaleForbiddenWindowSeconds, limits.MinUpscaleForbiddenWindowSeconds, policy.Name))
+			spec.UpscaleForbiddenWindowSeconds = limits.MinUpscaleForbiddenWindowSeconds
+		}
+		for _, algorithm := range limits.ForbiddenAlgorithms {
+			if spec.Algorithm == algorithm {
+				violations = append(violations, fmt.Sprintf("algorithm %s forbidden by the policy %s", algorithm, policy.Name))
+				allowed = false
+			}
+		}
+	}
+	return violations, allowed
+}
+
+// requestsForPolicy returns a mapping function enqueueing all the WPAs when a policy changes.
+func requestsForPolicy(c client.Client) handler.ToRequestsFunc {
+	return func(a handler.MapObject) []reconcile.Request {
+		wpas := &datadoghqv1alpha1.WatermarkPodAutoscalerList{}
+		if err := c.List(context.TODO(), wpas); err != nil {
+			log.Error(err, "Could not list the WatermarkPodAutoscalers")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(wpas.Items))
+		for _, wpa := range wpas.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPolicy(name string, spec v1alpha1.WatermarkPodAutoscalerPolicySpec) v1alpha1.WatermarkPodAutoscalerPolicy {
+	return v1alpha1.WatermarkPodAutoscalerPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func TestWithPolicyDefaults(t *testing.T) {
+	policies := []v1alpha1.WatermarkPodAutoscalerPolicy{
+		newTestPolicy("a", v1alpha1.WatermarkPodAutoscalerPolicySpec{
+			Defaults: &v1alpha1.WatermarkPodAutoscalerPolicyDefaults{DownscaleForbiddenWindowSeconds: 600},
+		}),
+		newTestPolicy("b", v1alpha1.WatermarkPodAutoscalerPolicySpec{
+			Defaults: &v1alpha1.WatermarkPodAutoscalerPolicyDefaults{DownscaleForbiddenWindowSeconds: 900, ScaleUpLimitFactor: 30},
+		}),
+	}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{ScaleUpLimitFactor: 10},
+	})
+
+	defaultWPA := v1alpha1.DefaultWatermarkPodAutoscaler(withPolicyDefaults(wpa, policies))
+
+	require.Equal(t, int32(600), defaultWPA.Spec.DownscaleForbiddenWindowSeconds)
+	require.Equal(t, float64(10), defaultWPA.Spec.ScaleUpLimitFactor)
+	require.Equal(t, int32(60), defaultWPA.Spec.UpscaleForbiddenWindowSeconds)
+	require.Equal(t, "absolute", defaultWPA.Spec.Algorithm)
+	require.Equal(t, int32(0), wpa.Spec.DownscaleForbiddenWindowSeconds)
+	require.Equal(t, []string{"minReplicas", "algorithm", "tolerance", "scaleDownLimitFactor", "downscaleForbiddenWindowSeconds", "upscaleForbiddenWindowSeconds"}, v1alpha1.MissingDefaults(wpa))
+	require.Empty(t, v1alpha1.MissingDefaults(defaultWPA))
+}
+
+func TestEnforcePolicyLimits(t *testing.T) {
+	tests := []struct {
+		name           string
+		limits         []v1alpha1.WatermarkPodAutoscalerPolicyLimits
+		wantViolations int
+		wantAllowed    bool
+		wantMin        int32
+		wantMax        int32
+		wantDownscale  int32
+	}{
+		{
+			name:        "no policy",
+			wantAllowed: true,
+			wantMin:     5,
+			wantMax:     20,
+		},
+		{
+			name:        "within the limits",
+			limits:      []v1alpha1.WatermarkPodAutoscalerPolicyLimits{{MaxReplicas: getReplicas(50), MinDownscaleForbiddenWindowSeconds: 60}},
+			wantAllowed: true,
+			wantMin:     5,
+			wantMax:     20,
+		},
+		{
+			name: "most restrictive limits",
+			limits: []v1alpha1.WatermarkPodAutoscalerPolicyLimits{
+				{MaxReplicas: getReplicas(10)},
+				{MaxReplicas: getReplicas(4), MinDownscaleForbiddenWindowSeconds: 300},
+			},
+			wantViolations: 3,
+			wantAllowed:    true,
+			wantMin:        4,
+			wantMax:        4,
+			wantDownscale:  300,
+		},
+		{
+			name:           "forbidden algorithm",
+			limits:         []v1alpha1.WatermarkPodAutoscalerPolicyLimits{{ForbiddenAlgorithms: []string{"average"}}},
+			wantViolations: 1,
+			wantMin:        5,
+			wantMax:        20,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var policies []v1alpha1.WatermarkPodAutoscalerPolicy
+			for i := range tt.limits {
+				policies = append(policies, newTestPolicy(tt.name, v1alpha1.WatermarkPodAutoscalerPolicySpec{Limits: &tt.limits[i]}))
+			}
+			spec := v1alpha1.WatermarkPodAutoscalerSpec{MinReplicas: getReplicas(5), MaxReplicas: 20, DownscaleForbiddenWindowSeconds: 120, Algorithm: "average"}
+			violations, allowed := enforcePolicyLimits(&spec, policies)
+			require.Len(t, violations, tt.wantViolations)
+			require.Equal(t, tt.wantAllowed, allowed)
+			require.Equal(t, tt.wantMin, *spec.MinReplicas)
+			require.Equal(t, tt.wantMax, spec.MaxReplicas)
+			if tt.wantDownscale != 0 {
+				require.Equal(t, tt.wantDownscale, spec.DownscaleForbiddenWindowSeconds)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/go-logr/logr"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// applyActiveProfile overrides the spec of the WPA, in memory, with the first profile active at the time now,
+// or with the profile and the bounds of the calendar on special days, and reports them in the status.
+func (r *ReconcileWatermarkPodAutoscaler) applyActiveProfile(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) {
+	specialDay := r.isSpecialDay(logger, wpa, now)
+	if specialDay != wpa.Status.SpecialDay {
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "SpecialDay", "Special day according to the calendar: %t", specialDay)
+		wpa.Status.SpecialDay = specialDay
+	}
+
+	var profile *datadoghqv1alpha1.ScalingProfile
+	if specialDay && wpa.Spec.Calendar.Profile != "" {
+		profile = profileNamed(wpa.Spec.Profiles, wpa.Spec.Calendar.Profile)
+	} else {
+		profile = activeProfile(logger, wpa.Spec.Profiles, now)
+	}
+	name := ""
+	if profile != nil {
+		name = profile.Name
+		applyProfile(&wpa.Spec, profile)
+	}
+	if specialDay {
+		applyCalendarBounds(&wpa.Spec, wpa.Spec.Calendar)
+	}
+	if name == wpa.Status.ActiveProfile {
+		return
+	}
+	switch {
+	case name == "":
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "ProfileDeactivated", "Profile %s is no longer active", wpa.Status.ActiveProfile)
+	default:
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "ProfileActivated", "Profile %s is active", name)
+	}
+	wpa.Status.ActiveProfile = name
+}
+
+// activeProfile returns the first profile with a period including the time now, nil if none is.
+func activeProfile(logger logr.Logger, profiles []datadoghqv1alpha1.ScalingProfile, now time.Time) *datadoghqv1alpha1.ScalingProfile {
+	for i := range profiles {
+		for _, period := range profiles[i].Periods {
+			active, err := period.IsActive(now)
+			if err != nil {
+				logger.Info("Ignoring an invalid period", "profile", profiles[i].Name, "error", err)
+				continue
+			}
+			if active {
+				return &profiles[i]
+			}
+		}
+	}
+	return nil
+}
+
+func profileNamed(profiles []datadoghqv1alpha1.ScalingProfile, name string) *datadoghqv1alpha1.ScalingProfile {
+	for i := range profiles {
+		if profiles[i].Name == name {
+			return &profiles[i]
+		}
+	}
+	return nil
+}
+
+// applyProfile overrides the settings of the spec that are set in the profile.
+func applyProfile(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, profile *datadoghqv1alpha1.ScalingProfile) {
+	if profile.DownscaleForbiddenWindowSeconds != 0 {
+		spec.DownscaleForbiddenWindowSeconds = profile.DownscaleForbiddenWindowSeconds
+	}
+	if profile.UpscaleForbiddenWindowSeconds != 0 {
+		spec.UpscaleForbiddenWindowSeconds = profile.UpscaleForbiddenWindowSeconds
+	}
+	if profile.ScaleUpLimitFactor != 0 {
+		spec.ScaleUpLimitFactor = profile.ScaleUpLimitFactor
+	}
+	if profile.ScaleDownLimitFactor != 0 {
+		spec.ScaleDownLimitFactor = profile.ScaleDownLimitFactor
+	}
+	for _, watermarks := range profile.Watermarks {
+		high, low := watermarks.HighWatermark.DeepCopy(), watermarks.LowWatermark.DeepCopy()
+		for _, metric := range spec.Metrics {
+			switch {
+			case metric.External != nil && metric.External.MetricName == watermarks.MetricName:
+				metric.External.HighWatermark, metric.External.LowWatermark = &high, &low
+			case metric.Resource != nil && string(metric.Resource.Name) == watermarks.MetricName:
+				metric.Resource.HighWatermark, metric.Resource.LowWatermark = &high, &low
+				metric.Resource.HighWatermarkUtilization, metric.Resource.LowWatermarkUtilization = nil, nil
+			}
+		}
+	}
+}
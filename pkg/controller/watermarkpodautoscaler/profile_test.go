@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestActiveProfile(t *testing.T) {
+	profiles := []v1alpha1.ScalingProfile{
+		{
+			Name:    "business-hours",
+			Periods: []v1alpha1.ProfilePeriod{{Days: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}, Start: "09:00", End: "18:00"}},
+		},
+		{
+			Name:    "overnight",
+			Periods: []v1alpha1.ProfilePeriod{{Days: []string{"Friday"}, Start: "22:00", End: "06:00", TimeZone: "UTC"}},
+		},
+		{
+			Name:    "invalid",
+			Periods: []v1alpha1.ProfilePeriod{{Start: "25:00", End: "06:00"}},
+		},
+	}
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{
+			name: "during the business hours",
+			now:  time.Date(2019, time.October, 15, 10, 30, 0, 0, time.UTC), // Tuesday
+			want: "business-hours",
+		},
+		{
+			name: "end of the period excluded",
+			now:  time.Date(2019, time.October, 15, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "business hours on a Saturday",
+			now:  time.Date(2019, time.October, 19, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "overnight period before midnight",
+			now:  time.Date(2019, time.October, 18, 23, 0, 0, 0, time.UTC), // Friday
+			want: "overnight",
+		},
+		{
+			name: "overnight period after midnight",
+			now:  time.Date(2019, time.October, 19, 5, 0, 0, 0, time.UTC), // Saturday
+			want: "overnight",
+		},
+		{
+			name: "overnight period started on another day",
+			now:  time.Date(2019, time.October, 18, 5, 0, 0, 0, time.UTC), // Friday
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := activeProfile(logf.Log.WithName(tt.name), profiles, tt.now)
+			if tt.want == "" {
+				require.Nil(t, profile)
+				return
+			}
+			require.NotNil(t, profile)
+			require.Equal(t, tt.want, profile.Name)
+		})
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	high, low := resource.MustParse("80"), resource.MustParse("70")
+	spec := v1alpha1.WatermarkPodAutoscalerSpec{
+		DownscaleForbiddenWindowSeconds: 300,
+		UpscaleForbiddenWindowSeconds:   60,
+		ScaleUpLimitFactor:              50,
+		ScaleDownLimitFactor:            20,
+		Metrics: []v1alpha1.MetricSpec{
+			{
+				Type:     v1alpha1.ExternalMetricSourceType,
+				External: &v1alpha1.ExternalMetricSource{MetricName: "requests", HighWatermark: &high, LowWatermark: &low},
+			},
+			{
+				Type:     v1alpha1.ResourceMetricSourceType,
+				Resource: &v1alpha1.ResourceMetricSource{Name: corev1.ResourceCPU, HighWatermarkUtilization: v1alpha1.NewInt32(80), LowWatermarkUtilization: v1alpha1.NewInt32(70)},
+			},
+		},
+	}
+	profile := &v1alpha1.ScalingProfile{
+		Name:                 "overnight",
+		ScaleUpLimitFactor:   10,
+		ScaleDownLimitFactor: 5,
+		Watermarks: []v1alpha1.ProfileWatermarks{
+			{MetricName: "cpu", HighWatermark: resource.MustParse("500m"), LowWatermark: resource.MustParse("300m")},
+		},
+	}
+
+	applyProfile(&spec, profile)
+
+	require.Equal(t, int32(300), spec.DownscaleForbiddenWindowSeconds)
+	require.Equal(t, int32(60), spec.UpscaleForbiddenWindowSeconds)
+	require.Equal(t, float64(10), spec.ScaleUpLimitFactor)
+	require.Equal(t, float64(5), spec.ScaleDownLimitFactor)
+	require.Equal(t, "80", spec.Metrics[0].External.HighWatermark.String())
+	require.Equal(t, "500m", spec.Metrics[1].Resource.HighWatermark.String())
+	require.Equal(t, "300m", spec.Metrics[1].Resource.LowWatermark.String())
+	require.Nil(t, spec.Metrics[1].Resource.HighWatermarkUtilization)
+	require.Nil(t, spec.Metrics[1].Resource.LowWatermarkUtilization)
+}
+
+func TestProfileForbiddenWindows(t *testing.T) {
+	profiles := []v1alpha1.ScalingProfile{
+		{
+			Name:                          "peak",
+			Periods:                       []v1alpha1.ProfilePeriod{{Start: "08:00", End: "20:00"}},
+			UpscaleForbiddenWindowSeconds: 15,
+		},
+		{
+			Name:                            "overnight",
+			Periods:                         []v1alpha1.ProfilePeriod{{Start: "20:00", End: "08:00"}},
+			UpscaleForbiddenWindowSeconds:   300,
+			DownscaleForbiddenWindowSeconds: 1800,
+		},
+	}
+	tests := []struct {
+		name               string
+		now                time.Time
+		want               bool
+		upscaleCountdown   float64
+		downscaleCountdown float64
+	}{
+		{
+			name:               "aggressive during peak hours",
+			now:                time.Date(2019, time.October, 15, 10, 30, 0, 0, time.UTC),
+			want:               true,
+			downscaleCountdown: 240,
+		},
+		{
+			name:               "sluggish overnight",
+			now:                time.Date(2019, time.October, 15, 23, 30, 0, 0, time.UTC),
+			upscaleCountdown:   240,
+			downscaleCountdown: 1740,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logf.Log.WithName(tt.name)
+			lastScaleTime := metav1.NewTime(tt.now.Add(-time.Minute))
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+					UpscaleForbiddenWindowSeconds:   60,
+					DownscaleForbiddenWindowSeconds: 300,
+					Profiles:                        profiles,
+				},
+				Status: &v1alpha1.WatermarkPodAutoscalerStatus{LastScaleTime: &lastScaleTime},
+			})
+			applyProfile(&wpa.Spec, activeProfile(logger, wpa.Spec.Profiles, tt.now))
+
+			require.Equal(t, tt.want, shouldScale(logger, wpa, 3, 5, tt.now))
+			labels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+			labels[transitionPromLabel] = "upscale"
+			require.Equal(t, tt.upscaleCountdown, gaugeValue(t, transitionCountdown.With(labels)))
+			labels[transitionPromLabel] = "downscale"
+			require.Equal(t, tt.downscaleCountdown, gaugeValue(t, transitionCountdown.With(labels)))
+		})
+	}
+}
+
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, gauge.Write(m))
+	return m.GetGauge().GetValue()
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	rateLimitedCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "RateLimited"
+
+	scaleEventsWindow = time.Hour
+)
+
+// isRateLimited returns whether the target was already scaled maxScaleEventsPerHour times over the last hour,
+// and reports it in the conditions of the WPA.
+func isRateLimited(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) bool {
+	pruneScaleEvents(wpa, now)
+	events := wpa.Status.RecentScaleEvents
+	limit := *wpa.Spec.MaxScaleEventsPerHour
+	if int32(len(events)) < limit {
+		setCondition(wpa, rateLimitedCondition, corev1.ConditionFalse, "WithinRateLimit", "the target was scaled %d times in the last hour, out of %d allowed", len(events), limit)
+		return false
+	}
+	next := events[len(events)-int(limit)].Add(scaleEventsWindow)
+	setCondition(wpa, rateLimitedCondition, corev1.ConditionTrue, "TooManyScaleEvents", "the target was scaled %d times in the last hour, the next scale is allowed at %s", len(events), next.Format(time.RFC3339))
+	logger.Info("Too many scale events in the last hour", "scaleEvents", len(events), "maxScaleEventsPerHour", limit, "nextScaleTimestamp", next)
+	return true
+}
+
+// recordScaleEvent keeps track of the time of a scale event of the target.
+func recordScaleEvent(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) {
+	if wpa.Spec.MaxScaleEventsPerHour == nil {
+		wpa.Status.RecentScaleEvents = nil
+		return
+	}
+	pruneScaleEvents(wpa, now)
+	wpa.Status.RecentScaleEvents = append(wpa.Status.RecentScaleEvents, metav1.NewTime(now))
+}
+
+func pruneScaleEvents(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) {
+	var events []metav1.Time
+	for _, event := range wpa.Status.RecentScaleEvents {
+		if now.Sub(event.Time) < scaleEventsWindow {
+			events = append(events, event)
+		}
+	}
+	wpa.Status.RecentScaleEvents = events
+}
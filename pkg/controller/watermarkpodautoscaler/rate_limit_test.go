@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestRateLimit(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			MaxScaleEventsPerHour: v1alpha1.NewInt32(2),
+		},
+	})
+
+	require.False(t, isRateLimited(logger, wpa, start))
+	recordScaleEvent(wpa, start)
+	require.False(t, isRateLimited(logger, wpa, start.Add(10*time.Minute)))
+	recordScaleEvent(wpa, start.Add(10*time.Minute))
+
+	require.True(t, isRateLimited(logger, wpa, start.Add(20*time.Minute)))
+	require.Equal(t, rateLimitedCondition, wpa.Status.Conditions[0].Type)
+	require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status)
+	require.Equal(t, "the target was scaled 2 times in the last hour, the next scale is allowed at 2020-01-01T13:00:00Z", wpa.Status.Conditions[0].Message)
+
+	require.False(t, isRateLimited(logger, wpa, start.Add(time.Hour)), "the oldest event is out of the sliding window")
+	require.Len(t, wpa.Status.RecentScaleEvents, 1)
+	require.Equal(t, corev1.ConditionFalse, wpa.Status.Conditions[0].Status)
+
+	wpa.Spec.MaxScaleEventsPerHour = nil
+	recordScaleEvent(wpa, start.Add(time.Hour))
+	require.Empty(t, wpa.Status.RecentScaleEvents, "the events are forgotten once the limit is removed")
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// recommendationHistory keeps the last proposals of replicas of the WPAs.
+type recommendationHistory struct {
+	mu        sync.Mutex
+	proposals map[types.NamespacedName][]int32
+}
+
+func newRecommendationHistory() *recommendationHistory {
+	return &recommendationHistory{proposals: map[types.NamespacedName][]int32{}}
+}
+
+// record adds a proposal to the history of the WPA, keeping the last `size` ones, and returns them.
+func (h *recommendationHistory) record(key types.NamespacedName, proposal int32, size int) []int32 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	proposals := append(h.proposals[key], proposal)
+	if len(proposals) > size {
+		proposals = proposals[len(proposals)-size:]
+	}
+	h.proposals[key] = proposals
+	return append([]int32(nil), proposals...)
+}
+
+func (h *recommendationHistory) forget(key types.NamespacedName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.proposals, key)
+}
+
+// aggregateProposals records the proposal of replicas and returns the aggregation of the last ones.
+func (r *ReconcileWatermarkPodAutoscaler) aggregateProposals(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, proposedReplicas int32) int32 {
+	if r.recommendations == nil {
+		return proposedReplicas
+	}
+	history := wpa.Spec.RecommendationHistory
+	proposals := r.recommendations.record(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}, proposedReplicas, int(history.Size))
+	aggregated := aggregateRecommendations(proposals, history.Aggregation)
+	logger.Info("Aggregating the last proposals", "proposals", proposals, "aggregation", history.Aggregation, "aggregatedReplicas", aggregated)
+	return aggregated
+}
+
+func aggregateRecommendations(proposals []int32, aggregation datadoghqv1alpha1.RecommendationAggregation) int32 {
+	sorted := append([]int32(nil), proposals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if aggregation == datadoghqv1alpha1.RecommendationAggregationP90 {
+		// nearest-rank method
+		return sorted[int(math.Ceil(0.9*float64(len(sorted))))-1]
+	}
+	return sorted[len(sorted)/2]
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestAggregateRecommendations(t *testing.T) {
+	require.Equal(t, int32(5), aggregateRecommendations([]int32{5}, ""))
+	require.Equal(t, int32(5), aggregateRecommendations([]int32{4, 30, 5}, v1alpha1.RecommendationAggregationMedian))
+	require.Equal(t, int32(6), aggregateRecommendations([]int32{6, 4, 5, 30}, ""), "the higher median is kept")
+	require.Equal(t, int32(30), aggregateRecommendations([]int32{6, 4, 5, 30}, v1alpha1.RecommendationAggregationP90))
+	require.Equal(t, int32(9), aggregateRecommendations([]int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 40}, v1alpha1.RecommendationAggregationP90))
+}
+
+func TestAggregateProposals(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			RecommendationHistory: &v1alpha1.RecommendationHistorySpec{Size: 3},
+		},
+	})
+	r := &ReconcileWatermarkPodAutoscaler{recommendations: newRecommendationHistory()}
+
+	require.Equal(t, int32(10), r.aggregateProposals(logger, wpa, 10))
+	require.Equal(t, int32(10), r.aggregateProposals(logger, wpa, 10))
+	require.Equal(t, int32(10), r.aggregateProposals(logger, wpa, 50), "a single spike is filtered")
+	require.Equal(t, int32(50), r.aggregateProposals(logger, wpa, 50), "the oldest proposal is dropped")
+
+	other := wpa.DeepCopy()
+	other.Name = "other"
+	require.Equal(t, int32(3), r.aggregateProposals(logger, other, 3), "the proposals are kept per WPA")
+
+	require.Equal(t, int32(7), (&ReconcileWatermarkPodAutoscaler{}).aggregateProposals(logger, wpa, 7))
+}
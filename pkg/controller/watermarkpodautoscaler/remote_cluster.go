@@ -0,0 +1,253 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	discocache "k8s.io/client-go/discovery/cached"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/scale"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	resourceclient "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
+	"k8s.io/metrics/pkg/client/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var enableRemoteClusters bool
+
+func init() {
+	flag.BoolVar(&enableRemoteClusters, "enable-remote-clusters", false, "Allow the WPAs to scale targets in remote clusters and shards from a kubeconfig Secret of their namespace")
+}
+
+// remoteCluster holds the clients of a cluster the targets of some WPAs run in.
+type remoteCluster struct {
+	// resourceVersion is the one of the Secret the kubeconfig was read from.
+	resourceVersion string
+	scaleClient     scale.ScalesGetter
+	restMapper      *restmapper.DeferredDiscoveryRESTMapper
+	mapperResetter  *restMapperResetter
+	replicaCalc     *ReplicaCalculator
+	podLister       listerv1.PodLister
+	podsSynced      cache.InformerSynced
+	podAnnotator    *podAnnotator
+	// stop stops the pod informer of the cluster.
+	stop chan struct{}
+	// wpas are the WPAs using the clients, which are stopped once all of them are deleted.
+	wpas map[types.NamespacedName]bool
+}
+
+func newRemoteCluster(kubeconfig []byte, scheme *runtime.Scheme) (*remoteCluster, error) {
+	apiConfig, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+	if err = checkKubeconfigCredentials(apiConfig); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+	config, err := clientcmd.NewDefaultClientConfig(*apiConfig, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	restMapper := restmapper.NewDeferredDiscoveryRESTMapper(discocache.NewMemCacheClient(clientSet.Discovery()))
+	restMapper.Reset()
+	scaleClient, err := scale.NewForConfig(config, restMapper, dynamic.LegacyAPIPathResolverFunc, scale.NewDiscoveryScaleKindResolver(clientSet.Discovery()))
+	if err != nil {
+		return nil, err
+	}
+	metricsClientConfig := rest.CopyConfig(config)
+	metricsClientConfig.Timeout = metricsClientTimeout
+	resourceClient, err := resourceclient.NewForConfig(metricsClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	externalClient, err := external_metrics.NewForConfig(metricsClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+	stop := make(chan struct{})
+	podLister, podsSynced := initializePodInformer(config, stop)
+	replicaCalc := NewReplicaCalculator(NewRESTMetricsClient(resourceClient, nil, externalClient), podLister, reader)
+	replicaCalc.setMetricsProvider(config.Host)
+	return &remoteCluster{
+		scaleClient:    scaleClient,
+		restMapper:     restMapper,
+		mapperResetter: newRESTMapperResetter(restMapper, restMapperResetInterval),
+		replicaCalc:    replicaCalc,
+		podLister:      podLister,
+		podsSynced:     podsSynced,
+		podAnnotator:   newPodAnnotator(clientSet.CoreV1(), defaultPodAnnotationQPS, defaultPodAnnotationBurst),
+		stop:           stop,
+	}, nil
+}
+
+// checkKubeconfigCredentials only accepts the credentials inlined in the kubeconfig: as it is read from a Secret of the
+// namespace of the WPA, the commands, auth providers and files it refers to would run or be read by the controller.
+func checkKubeconfigCredentials(config *clientcmdapi.Config) error {
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("the cluster %s refers to a certificate-authority file, only certificate-authority-data is supported", name)
+		}
+	}
+	for name, authInfo := range config.AuthInfos {
+		switch {
+		case authInfo.Exec != nil:
+			return fmt.Errorf("the user %s runs an exec command, only inline tokens and certificates are supported", name)
+		case authInfo.AuthProvider != nil:
+			return fmt.Errorf("the user %s relies on an auth provider, only inline tokens and certificates are supported", name)
+		case authInfo.ClientCertificate != "" || authInfo.ClientKey != "":
+			return fmt.Errorf("the user %s refers to a client-certificate or client-key file, only client-certificate-data and client-key-data are supported", name)
+		case authInfo.TokenFile != "":
+			return fmt.Errorf("the user %s refers to a tokenFile, only token is supported", name)
+		}
+	}
+	return nil
+}
+
+// remoteClusters caches the clients of the remote clusters by kubeconfig Secret, they are rebuilt when the
+// Secret changes.
+type remoteClusters struct {
+	mu         sync.Mutex
+	scheme     *runtime.Scheme
+	clusters   map[string]*remoteCluster
+	newCluster func(kubeconfig []byte, scheme *runtime.Scheme) (*remoteCluster, error)
+}
+
+func newRemoteClusters(scheme *runtime.Scheme) *remoteClusters {
+	return &remoteClusters{scheme: scheme, clusters: map[string]*remoteCluster{}, newCluster: newRemoteCluster}
+}
+
+// get returns the clients of the cluster of the kubeconfig Secret, used by the WPA until it is forgotten. The clients
+// of the cluster the WPA previously referred to are released, as its remoteCluster may have changed.
+func (c *remoteClusters) get(reader client.Reader, wpa types.NamespacedName, ref corev1.SecretKeySelector) (*remoteCluster, error) {
+	key := fmt.Sprintf("%s/%s/%s", wpa.Namespace, ref.Name, ref.Key)
+	c.release(wpa, key)
+	secret := &corev1.Secret{}
+	if err := reader.Get(context.TODO(), types.NamespacedName{Namespace: wpa.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("unable to get the kubeconfig Secret %s/%s: %v", wpa.Namespace, ref.Name, err)
+	}
+	kubeconfig, found := secret.Data[ref.Key]
+	if !found {
+		return nil, fmt.Errorf("no key %s in the kubeconfig Secret %s/%s", ref.Key, wpa.Namespace, ref.Name)
+	}
+
+	c.mu.Lock()
+	cluster, found := c.clusters[key]
+	c.mu.Unlock()
+	if !found || cluster.resourceVersion != secret.ResourceVersion {
+		// Building the clients queries the discovery API of the cluster, which isn't done while holding the lock.
+		log.Info("Building the clients of a remote cluster", "secret", key)
+		built, err := c.newCluster(kubeconfig, c.scheme)
+		if err != nil {
+			return nil, fmt.Errorf("unable to build the clients of the cluster of the Secret %s: %v", key, err)
+		}
+		built.resourceVersion = secret.ResourceVersion
+		built.wpas = map[types.NamespacedName]bool{}
+		cluster = c.store(key, built)
+	}
+	c.mu.Lock()
+	cluster.wpas[wpa] = true
+	c.mu.Unlock()
+	if !cluster.podsSynced() {
+		return nil, fmt.Errorf("the pods of the cluster of the Secret %s are not synced yet", key)
+	}
+	return cluster, nil
+}
+
+// store caches the clients built for the Secret, unless clients were built concurrently for the same version of the
+// Secret, in which case they are returned instead. The clients they replace are stopped.
+func (c *remoteClusters) store(key string, built *remoteCluster) *remoteCluster {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous, found := c.clusters[key]
+	if found && previous.resourceVersion == built.resourceVersion {
+		close(built.stop)
+		return previous
+	}
+	if found {
+		close(previous.stop)
+		for wpa := range previous.wpas {
+			built.wpas[wpa] = true
+		}
+	}
+	c.clusters[key] = built
+	return built
+}
+
+// forget stops the clients only used by the deleted WPA, or by a WPA that no longer has a remote cluster.
+func (c *remoteClusters) forget(wpa types.NamespacedName) {
+	c.release(wpa, "")
+}
+
+// release unregisters the WPA from the clusters other than the one of the given Secret key, and stops the clients
+// no other WPA uses.
+func (c *remoteClusters) release(wpa types.NamespacedName, keep string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, cluster := range c.clusters {
+		if key == keep || !cluster.wpas[wpa] {
+			continue
+		}
+		delete(cluster.wpas, wpa)
+		if len(cluster.wpas) == 0 {
+			close(cluster.stop)
+			delete(c.clusters, key)
+		}
+	}
+}
+
+// forCluster returns the reconciler of the WPA, reading and scaling its targets in its remote cluster if any. The
+// WPA itself, its policies, groups and watermark sources are still read from the cluster of the controller.
+func (r *ReconcileWatermarkPodAutoscaler) forCluster(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) (*ReconcileWatermarkPodAutoscaler, error) {
+	if wpa.Spec.RemoteCluster == nil {
+		if r.remoteClusters != nil {
+			r.remoteClusters.forget(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name})
+		}
+		return r, nil
+	}
+	if r.remoteClusters == nil {
+		return nil, fmt.Errorf("the remote clusters are not enabled on this controller, see --enable-remote-clusters")
+	}
+	cluster, err := r.remoteClusters.get(r.secretReader(), types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}, wpa.Spec.RemoteCluster.KubeconfigSecretRef)
+	if err != nil {
+		return nil, err
+	}
+	return r.withCluster(cluster), nil
+}
+
+// withCluster returns a copy of the reconciler using the clients of the remote cluster.
+func (r *ReconcileWatermarkPodAutoscaler) withCluster(cluster *remoteCluster) *ReconcileWatermarkPodAutoscaler {
+	remote := *r
+	remote.scaleClient = cluster.scaleClient
+	remote.restMapper = cluster.restMapper
+	remote.mapperResetter = cluster.mapperResetter
+	remote.replicaCalc = cluster.replicaCalc
+	remote.podLister = cluster.podLister
+	remote.podAnnotator = cluster.podAnnotator
+	return &remote
+}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakescale "k8s.io/client-go/scale/fake"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestForCluster(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "remote", ResourceVersion: "1"},
+		Data:       map[string][]byte{"kubeconfig": []byte("foo")},
+	}
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, secret)
+	synced := false
+	var built []*remoteCluster
+	clusters := newRemoteClusters(scheme.Scheme)
+	clusters.newCluster = func(kubeconfig []byte, _ *runtime.Scheme) (*remoteCluster, error) {
+		require.Equal(t, "foo", string(kubeconfig))
+		cluster := &remoteCluster{scaleClient: &fakescale.FakeScaleClient{}, podsSynced: func() bool { return synced }, stop: make(chan struct{})}
+		built = append(built, cluster)
+		return cluster, nil
+	}
+	r := &ReconcileWatermarkPodAutoscaler{client: c, scaleClient: &fakescale.FakeScaleClient{}, remoteClusters: clusters}
+
+	local := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+	reconciler, err := r.forCluster(local)
+	require.NoError(t, err)
+	require.True(t, reconciler == r, "the WPAs without remote cluster use the clients of the controller")
+
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			RemoteCluster: &v1alpha1.RemoteClusterSpec{KubeconfigSecretRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "remote"}, Key: "kubeconfig"}},
+		},
+	})
+	_, err = r.forCluster(wpa)
+	require.Error(t, err, "the pods of the remote cluster are not synced yet")
+
+	synced = true
+	reconciler, err = r.forCluster(wpa)
+	require.NoError(t, err)
+	require.Len(t, built, 1, "the clients are cached")
+	require.True(t, reconciler.scaleClient == built[0].scaleClient)
+	require.True(t, reconciler.client == r.client, "the WPA is still read from the cluster of the controller")
+
+	secret.ResourceVersion = "2"
+	require.NoError(t, c.Update(context.TODO(), secret))
+	_, err = r.forCluster(wpa)
+	require.NoError(t, err)
+	require.Len(t, built, 2, "the clients are rebuilt when the Secret changes")
+	_, open := <-built[0].stop
+	require.False(t, open, "the pod informer of the previous clients is stopped")
+
+	wpa.Spec.RemoteCluster.KubeconfigSecretRef.Key = "missing"
+	_, err = r.forCluster(wpa)
+	require.Error(t, err)
+	_, open = <-built[1].stop
+	require.False(t, open, "the clients of the previous remote cluster of the WPA are stopped")
+	require.Empty(t, clusters.clusters)
+
+	_, err = (&ReconcileWatermarkPodAutoscaler{}).forCluster(wpa)
+	require.Error(t, err, "the remote clusters must be enabled on the controller")
+
+	wpa.Spec.RemoteCluster.KubeconfigSecretRef.Key = "kubeconfig"
+	_, err = r.forCluster(wpa)
+	require.NoError(t, err)
+	other := types.NamespacedName{Namespace: testingNamespace, Name: "other"}
+	_, err = clusters.get(c, other, wpa.Spec.RemoteCluster.KubeconfigSecretRef)
+	require.NoError(t, err)
+	require.Len(t, built, 3)
+	wpa.Spec.RemoteCluster = nil
+	reconciler, err = r.forCluster(wpa)
+	require.NoError(t, err)
+	require.True(t, reconciler == r)
+	select {
+	case <-built[2].stop:
+		t.Fatal("the clients are still used by another WPA")
+	default:
+	}
+	require.False(t, built[2].wpas[types.NamespacedName{Namespace: testingNamespace, Name: testingWPAName}], "the WPA without remote cluster is unregistered")
+	clusters.forget(other)
+	_, open = <-built[2].stop
+	require.False(t, open, "the clients are stopped once the WPAs using them are deleted")
+	require.Empty(t, clusters.clusters)
+}
+
+func TestCheckKubeconfigCredentials(t *testing.T) {
+	kubeconfig := func(user string) []byte {
+		return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com
+    certificate-authority-data: Zm9v
+users:
+- name: remote
+  user:
+` + user + `
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+`)
+	}
+	tests := []struct {
+		name    string
+		user    string
+		wantErr bool
+	}{
+		{name: "inline token", user: "    token: foo"},
+		{name: "inline certificate", user: "    client-certificate-data: Zm9v\n    client-key-data: Zm9v"},
+		{name: "exec", user: "    exec:\n      apiVersion: client.authentication.k8s.io/v1beta1\n      command: /bin/sh", wantErr: true},
+		{name: "auth provider", user: "    auth-provider:\n      name: gcp", wantErr: true},
+		{name: "certificate file", user: "    client-certificate: /etc/passwd\n    client-key: /etc/passwd", wantErr: true},
+		{name: "token file", user: "    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := clientcmd.Load(kubeconfig(tt.user))
+			require.NoError(t, err)
+			err = checkKubeconfigCredentials(config)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	config, err := clientcmd.Load(kubeconfig("    token: foo"))
+	require.NoError(t, err)
+	config.Clusters["remote"].CertificateAuthority = "/etc/ssl/ca.crt"
+	require.Error(t, checkKubeconfigCredentials(config), "the files of the controller can't be referred to")
+}
@@ -0,0 +1,243 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	sigmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	remoteWriteURL             string
+	remoteWriteInterval        time.Duration
+	remoteWriteBearerTokenFile string
+)
+
+func init() {
+	flag.StringVar(&remoteWriteURL, "remote-write-url", "", "URL of a Prometheus remote-write endpoint the metrics of the WPAs are pushed to, disabled when empty")
+	flag.DurationVar(&remoteWriteInterval, "remote-write-interval", 30*time.Second, "Interval between two pushes of the metrics of the WPAs to the remote-write endpoint")
+	flag.StringVar(&remoteWriteBearerTokenFile, "remote-write-bearer-token-file", "", "File holding the bearer token sent to the remote-write endpoint, read before each push")
+}
+
+// remoteWriter pushes the metrics of the controller to a Prometheus remote-write endpoint, for the clusters where
+// the metrics endpoint of the controller can't be scraped.
+type remoteWriter struct {
+	url             string
+	interval        time.Duration
+	bearerTokenFile string
+	gatherer        prometheus.Gatherer
+	client          *http.Client
+}
+
+func newRemoteWriter(rawURL string, interval time.Duration, bearerTokenFile string) (*remoteWriter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote-write URL %s: %v", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote-write URL %s: an http or https URL is expected", rawURL)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid remote-write interval %s: it should be positive", interval)
+	}
+	return &remoteWriter{
+		url:             rawURL,
+		interval:        interval,
+		bearerTokenFile: bearerTokenFile,
+		gatherer:        sigmetrics.Registry,
+		client:          &http.Client{Timeout: interval},
+	}, nil
+}
+
+// Start implements manager.Runnable, it pushes the metrics at every interval until the stop channel is closed.
+func (w *remoteWriter) Start(stop <-chan struct{}) error {
+	log.Info("Pushing the metrics to the remote-write endpoint", "url", w.url, "interval", w.interval)
+	pushPeriodically(stop, w.interval, func(now time.Time) {
+		if err := w.push(now); err != nil {
+			log.Error(err, "Unable to push the metrics to the remote-write endpoint", "url", w.url)
+		}
+	})
+	return nil
+}
+
+// pushPeriodically calls push at every interval until the stop channel is closed.
+func pushPeriodically(stop <-chan struct{}, interval time.Duration, push func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			push(now)
+		}
+	}
+}
+
+// push sends the current values of the metrics of the WPAs to the endpoint.
+func (w *remoteWriter) push(now time.Time) error {
+	families, err := w.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("unable to gather the metrics: %v", err)
+	}
+	series := remoteWriteSeries(families, now)
+	if len(series) == 0 {
+		return nil
+	}
+	body, err := encodeWriteRequest(series)
+	if err != nil {
+		return fmt.Errorf("unable to encode the metrics: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.bearerTokenFile != "" {
+		token, err := ioutil.ReadFile(w.bearerTokenFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the bearer token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		if message := strings.TrimSpace(string(body)); message != "" {
+			return fmt.Errorf("unexpected status %s from the remote-write endpoint: %s", resp.Status, message)
+		}
+		return fmt.Errorf("unexpected status %s from the remote-write endpoint", resp.Status)
+	}
+	return nil
+}
+
+// remoteWriteRequest, remoteWriteTimeSeries, remoteWriteLabel and remoteWriteSample are the WriteRequest,
+// TimeSeries, Label and Sample messages of the prompb package of Prometheus, encoded by the protobuf library. A time
+// series holds a single sample.
+type remoteWriteRequest struct {
+	Timeseries []*remoteWriteTimeSeries `protobuf:"bytes,1,rep,name=timeseries,proto3"`
+}
+
+func (m *remoteWriteRequest) Reset()         { *m = remoteWriteRequest{} }
+func (m *remoteWriteRequest) String() string { return proto.CompactTextString(m) }
+func (*remoteWriteRequest) ProtoMessage()    {}
+
+type remoteWriteTimeSeries struct {
+	Labels  []*remoteWriteLabel  `protobuf:"bytes,1,rep,name=labels,proto3"`
+	Samples []*remoteWriteSample `protobuf:"bytes,2,rep,name=samples,proto3"`
+}
+
+func (m *remoteWriteTimeSeries) Reset()         { *m = remoteWriteTimeSeries{} }
+func (m *remoteWriteTimeSeries) String() string { return proto.CompactTextString(m) }
+func (*remoteWriteTimeSeries) ProtoMessage()    {}
+
+type remoteWriteLabel struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *remoteWriteLabel) Reset()         { *m = remoteWriteLabel{} }
+func (m *remoteWriteLabel) String() string { return proto.CompactTextString(m) }
+func (*remoteWriteLabel) ProtoMessage()    {}
+
+type remoteWriteSample struct {
+	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3"`
+	Timestamp int64   `protobuf:"varint,2,opt,name=timestamp,proto3"`
+}
+
+func (m *remoteWriteSample) Reset()         { *m = remoteWriteSample{} }
+func (m *remoteWriteSample) String() string { return proto.CompactTextString(m) }
+func (*remoteWriteSample) ProtoMessage()    {}
+
+// remoteWriteSeries converts the metric families of the controller, the ones of its subsystem, into time series.
+// The histograms and summaries are split into their _bucket or quantile, _sum and _count series like on the metrics
+// endpoint.
+func remoteWriteSeries(families []*dto.MetricFamily, now time.Time) []*remoteWriteTimeSeries {
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+	var series []*remoteWriteTimeSeries
+	add := func(name string, pairs []*dto.LabelPair, value float64, extra ...*remoteWriteLabel) {
+		labels := make([]*remoteWriteLabel, 0, len(pairs)+len(extra)+1)
+		labels = append(labels, &remoteWriteLabel{Name: "__name__", Value: name})
+		for _, pair := range pairs {
+			labels = append(labels, &remoteWriteLabel{Name: pair.GetName(), Value: pair.GetValue()})
+		}
+		labels = append(labels, extra...)
+		sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+		series = append(series, &remoteWriteTimeSeries{Labels: labels, Samples: []*remoteWriteSample{{Value: value, Timestamp: timestamp}}})
+	}
+	for _, family := range families {
+		name := family.GetName()
+		if !strings.HasPrefix(name, subsystem+"_") {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				add(name, m.GetLabel(), m.GetGauge().GetValue())
+			case dto.MetricType_COUNTER:
+				add(name, m.GetLabel(), m.GetCounter().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetLabel(), m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.GetBucket() {
+					add(name+"_bucket", m.GetLabel(), float64(b.GetCumulativeCount()), &remoteWriteLabel{Name: "le", Value: formatFloat(b.GetUpperBound())})
+				}
+				add(name+"_bucket", m.GetLabel(), float64(h.GetSampleCount()), &remoteWriteLabel{Name: "le", Value: "+Inf"})
+				add(name+"_sum", m.GetLabel(), h.GetSampleSum())
+				add(name+"_count", m.GetLabel(), float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add(name, m.GetLabel(), q.GetValue(), &remoteWriteLabel{Name: "quantile", Value: formatFloat(q.GetQuantile())})
+				}
+				add(name+"_sum", m.GetLabel(), s.GetSampleSum())
+				add(name+"_count", m.GetLabel(), float64(s.GetSampleCount()))
+			}
+		}
+	}
+	return series
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest returns the time series as a WriteRequest message compressed in the snappy block format, the
+// body expected by the remote-write endpoints.
+func encodeWriteRequest(series []*remoteWriteTimeSeries) ([]byte, error) {
+	data, err := proto.Marshal(&remoteWriteRequest{Timeseries: series})
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, data), nil
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeWriteRequest(t *testing.T) {
+	series := []*remoteWriteTimeSeries{{
+		Labels:  []*remoteWriteLabel{{Name: "__name__", Value: "a"}},
+		Samples: []*remoteWriteSample{{Value: 1, Timestamp: 2}},
+	}}
+	// The WriteRequest as encoded by the prompb package of Prometheus.
+	message := []byte{
+		0x0a, 28, // time series
+		0x0a, 13, // label
+		0x0a, 8, '_', '_', 'n', 'a', 'm', 'e', '_', '_',
+		0x12, 1, 'a',
+		0x12, 11, // sample
+		0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f,
+		0x10, 2,
+	}
+	// The message as compressed by the snappy package: its length, then a literal of 30 bytes.
+	want := append([]byte{30, 29 << 2}, message...)
+	got, err := encodeWriteRequest(series)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestRemoteWriteSeries(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{Subsystem: subsystem, Name: "value"}, []string{wpaNamePromLabel, metricNamePromLabel})
+	gauge.WithLabelValues("foo", "queue").Set(12)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Subsystem: subsystem, Name: "replicas_applied", Buckets: []float64{1, 2}})
+	histogram.Observe(2)
+	other := prometheus.NewGauge(prometheus.GaugeOpts{Name: "go_other"})
+	registry.MustRegister(gauge, histogram, other)
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	now := time.Unix(1600000000, 0)
+	series := remoteWriteSeries(families, now)
+	got := map[string]float64{}
+	for _, ts := range series {
+		require.Len(t, ts.Samples, 1)
+		require.Equal(t, int64(1600000000000), ts.Samples[0].Timestamp)
+		key := ""
+		for _, l := range ts.Labels {
+			key += l.Name + "=" + l.Value + ","
+		}
+		got[key] = ts.Samples[0].Value
+	}
+	require.Equal(t, map[string]float64{
+		"__name__=wpa_controller_replicas_applied_bucket,le=1,":         0,
+		"__name__=wpa_controller_replicas_applied_bucket,le=2,":         1,
+		"__name__=wpa_controller_replicas_applied_bucket,le=+Inf,":      1,
+		"__name__=wpa_controller_replicas_applied_sum,":                 2,
+		"__name__=wpa_controller_replicas_applied_count,":               1,
+		"__name__=wpa_controller_value,metric_name=queue,wpa_name=foo,": 12,
+	}, got)
+}
+
+func TestRemoteWriterPush(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	tokenFile, err := ioutil.TempFile("", "token")
+	require.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+	_, err = tokenFile.WriteString("s3cr3t\n")
+	require.NoError(t, err)
+	require.NoError(t, tokenFile.Close())
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Subsystem: subsystem, Name: "min_replicas"})
+	gauge.Set(3)
+	registry.MustRegister(gauge)
+
+	w, err := newRemoteWriter(server.URL, time.Second, tokenFile.Name())
+	require.NoError(t, err)
+	w.gatherer = registry
+	now := time.Unix(1600000000, 0)
+	require.NoError(t, w.push(now))
+	require.Equal(t, http.MethodPost, received.Method)
+	require.Equal(t, "snappy", received.Header.Get("Content-Encoding"))
+	require.Equal(t, "application/x-protobuf", received.Header.Get("Content-Type"))
+	require.Equal(t, "0.1.0", received.Header.Get("X-Prometheus-Remote-Write-Version"))
+	require.Equal(t, "Bearer s3cr3t", received.Header.Get("Authorization"))
+	data, err := snappy.Decode(nil, body)
+	require.NoError(t, err)
+	request := &remoteWriteRequest{}
+	require.NoError(t, proto.Unmarshal(data, request))
+	require.Equal(t, &remoteWriteRequest{Timeseries: []*remoteWriteTimeSeries{{
+		Labels:  []*remoteWriteLabel{{Name: "__name__", Value: "wpa_controller_min_replicas"}},
+		Samples: []*remoteWriteSample{{Value: 3, Timestamp: 1600000000000}},
+	}}}, request)
+
+	status = http.StatusBadRequest
+	require.EqualError(t, w.push(now), "unexpected status 400 Bad Request from the remote-write endpoint")
+
+	_, err = newRemoteWriter("ftp://example.com", time.Second, "")
+	require.EqualError(t, err, "invalid remote-write URL ftp://example.com: an http or https URL is expected")
+}
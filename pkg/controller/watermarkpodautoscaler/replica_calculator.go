@@ -6,8 +6,12 @@
 package watermarkpodautoscaler
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
@@ -22,6 +26,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	metricsclient "k8s.io/kubernetes/pkg/controller/podautoscaler/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	averageAlgorithm = "average"
+	// averageSpecReplicasAlgorithm averages the usage over the replicas in the spec of the target instead of
+	// the ready ones, so that the average does not spike while pods are starting or terminating.
+	averageSpecReplicasAlgorithm = "averageSpecReplicas"
 )
 
 // ReplicaCalculation is used to compute the scaling recommendation.
@@ -29,55 +41,143 @@ type ReplicaCalculation struct {
 	replicaCount int32
 	utilization  int64
 	timestamp    time.Time
+	// lowWatermark and highWatermark are the watermarks the utilization was compared to.
+	lowWatermark  *resource.Quantity
+	highWatermark *resource.Quantity
+	// estimated is set when the provider did not return the metric, and its value was estimated from the previous ones.
+	estimated bool
+	// podMetrics holds the values of the ready pods, only available for Resource metrics.
+	podMetrics metricsclient.PodMetricsInfo
+	// emergency is set when the utilization is above the emergency high watermark of the metric.
+	emergency bool
+	// rawValue is the value of the metric before it is averaged, and tolerance the one it was compared with.
+	rawValue  int64
+	tolerance float64
+	// latency is the time taken by the metrics provider to return the metric.
+	latency time.Duration
+	// pods counts how the pods of the target were accounted for, it is also set when the computation fails.
+	pods *v1alpha1.PodsStatus
+}
+
+// NewReplicaCalculation returns the ReplicaCalculation of a metric proposing replicaCount replicas for its
+// utilization, to implement a ReplicaCalculatorItf outside of this package.
+func NewReplicaCalculation(replicaCount int32, utilization int64, timestamp time.Time) ReplicaCalculation {
+	return ReplicaCalculation{replicaCount: replicaCount, utilization: utilization, timestamp: timestamp, rawValue: utilization}
 }
 
 // ReplicaCalculatorItf interface for ReplicaCalculator
 type ReplicaCalculatorItf interface {
-	GetExternalMetricReplicas(logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error)
-	GetResourceReplicas(logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error)
+	GetExternalMetricReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error)
+	GetResourceReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error)
 }
 
 // ReplicaCalculator is responsible for calculation of the number of replicas
 // It contains all the needed information
 type ReplicaCalculator struct {
-	metricsClient metricsclient.MetricsClient
+	metricsClient MetricsClient
 	podLister     corelisters.PodLister
+	client        client.Reader
+	httpClient    *http.Client
+	samples       *metricSamples
+	history       *metricHistory
+	probes        *metricProbes
+	// apiReader reads the Secrets from the API server, the client is used when nil.
+	apiReader client.Reader
+
+	externalBreaker *circuitBreaker
+	resourceBreaker *circuitBreaker
 }
 
 // NewReplicaCalculator returns a ReplicaCalculator object reference
-func NewReplicaCalculator(metricsClient metricsclient.MetricsClient, podLister corelisters.PodLister) *ReplicaCalculator {
+func NewReplicaCalculator(metricsClient MetricsClient, podLister corelisters.PodLister, client client.Reader) *ReplicaCalculator {
 	return &ReplicaCalculator{
 		metricsClient: metricsClient,
 		podLister:     podLister,
+		client:        client,
+		httpClient:    &http.Client{},
+		samples:       newMetricSamples(),
+		history:       newMetricHistory(),
+
+		externalBreaker: newCircuitBreaker(externalMetricsAPI, "", metricsProviderFailureThreshold, metricsProviderCoolOff),
+		resourceBreaker: newCircuitBreaker(resourceMetricsAPI, "", metricsProviderFailureThreshold, metricsProviderCoolOff),
 	}
 }
 
+// setMetricsProvider keys the circuit breakers of the calculator by the API server serving its metrics APIs, so that
+// a failing remote cluster doesn't stop the queries to the others.
+func (c *ReplicaCalculator) setMetricsProvider(host string) {
+	c.externalBreaker = newCircuitBreaker(externalMetricsAPI, host, metricsProviderFailureThreshold, metricsProviderCoolOff)
+	c.resourceBreaker = newCircuitBreaker(resourceMetricsAPI, host, metricsProviderFailureThreshold, metricsProviderCoolOff)
+}
+
 // GetExternalMetricReplicas calculates the desired replica count based on a
 // target metric value (as a milli-value) for the external metric in the given
 // namespace, and the current replica count.
-func (c *ReplicaCalculator) GetExternalMetricReplicas(logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
+func (c *ReplicaCalculator) GetExternalMetricReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
+	return c.getExternalMetricReplicas(ctx, logger, target, metric, wpa, nil)
+}
+
+// getExternalMetricReplicas also counts the ready pods, and aggregates the series of the metric, of the calculators
+// of the other clusters the workload is sharded across.
+func (c *ReplicaCalculator) getExternalMetricReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler, shards []*ReplicaCalculator) (ReplicaCalculation, error) {
 	lbl, err := labels.Parse(target.Status.Selector)
 	if err != nil {
 		log.Error(err, "Could not parse the labels of the target")
 	}
-	currentReadyReplicas, err := c.getReadyPodsCount(metav1.NamespaceAll, lbl, time.Duration(wpa.Spec.ReadinessDelaySeconds)*time.Second)
+	pods, weight, err := c.getReadyPodsCount(metav1.NamespaceAll, lbl, time.Duration(wpa.Spec.ReadinessDelaySeconds)*time.Second, c.minReadyDuration(ctx, logger, wpa), c.excludedPods(ctx, logger, wpa))
 	if err != nil {
-		return ReplicaCalculation{}, fmt.Errorf("unable to get the number of ready pods across all namespaces for %v: %s", lbl, err.Error())
+		return ReplicaCalculation{pods: pods}, fmt.Errorf("unable to get the number of ready pods across all namespaces for %v: %s", lbl, err.Error())
+	}
+	for _, shard := range shards {
+		shardPods, shardWeight, err := shard.getReadyPodsCount(metav1.NamespaceAll, lbl, time.Duration(wpa.Spec.ReadinessDelaySeconds)*time.Second, shard.minReadyDuration(ctx, logger, wpa), shard.excludedPods(ctx, logger, wpa))
+		if err != nil {
+			return ReplicaCalculation{pods: pods}, fmt.Errorf("unable to get the number of ready pods of a shard for %v: %s", lbl, err.Error())
+		}
+		pods.Total += shardPods.Total
+		pods.Ready += shardPods.Ready
+		pods.Ignored += shardPods.Ignored
+		weight += shardWeight
 	}
+	currentReadyReplicas := pods.Ready
+	currentReplicas := currentReadyReplicas
 	averaged := 1.0
-	if wpa.Spec.Algorithm == "average" {
-		averaged = float64(currentReadyReplicas)
+	switch {
+	case wpa.Spec.Algorithm == averageAlgorithm:
+		// The usage is averaged over the capacity of the ready pods, each one weighted by its annotation.
+		averaged = weight
+	case wpa.Spec.Algorithm == averageSpecReplicasAlgorithm && target.Spec.Replicas > 0:
+		// The recommendation is proportional to the replicas the usage is averaged over.
+		currentReplicas = target.Spec.Replicas
+		averaged = float64(currentReplicas)
 	}
 
 	metricName := metric.External.MetricName
 	selector := metric.External.MetricSelector
 	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
-		return ReplicaCalculation{}, err
+		return ReplicaCalculation{pods: pods}, err
 	}
 
-	metrics, timestamp, err := c.metricsClient.GetExternalMetric(metricName, wpa.Namespace, labelSelector)
-	if err != nil {
+	var metrics []int64
+	var timestamp time.Time
+	start := time.Now()
+	if metric.External.HTTP != nil {
+		metrics, timestamp, err = c.getHTTPMetric(ctx, wpa.Namespace, metric.External.HTTP)
+	} else if err = c.probes.missing(wpa.Namespace, metric.External, time.Now()); err == nil {
+		if metric.External.Histogram != nil {
+			// the quantile is the only value, whatever the seriesAggregation
+			metrics, timestamp, err = c.getExternalMetricQuantile(ctx, metricName, wpa.Namespace, labelSelector, metric.External.Histogram, shards)
+		} else {
+			metrics, timestamp, err = c.getShardedExternalMetric(ctx, metricName, wpa.Namespace, labelSelector, shards)
+		}
+	}
+	latency := time.Since(start)
+	var usage float64
+	estimated := false
+	if err != nil && metric.External.MissingDatapoints != nil {
+		usage, estimated = c.samples.estimate(metricSampleKey(wpa, metric.External), metric.External.MissingDatapoints, time.Now())
+	}
+	if err != nil && !estimated {
 		// When we add official support for several metrics, move this Delete to only occur if no metric is available at all.
 		labelsWithReason := prometheus.Labels{
 			wpaNamePromLabel:           wpa.Name,
@@ -91,34 +191,48 @@ func (c *ReplicaCalculator) GetExternalMetricReplicas(logger logr.Logger, target
 		labelsWithReason[reasonPromLabel] = "within_bounds"
 		restrictedScaling.Delete(labelsWithReason)
 		value.Delete(prometheus.Labels{wpaNamePromLabel: wpa.Name, metricNamePromLabel: metricName})
-		return ReplicaCalculation{0, 0, time.Time{}}, fmt.Errorf("unable to get external metric %s/%s/%+v: %s", wpa.Namespace, metricName, selector, err)
+		return ReplicaCalculation{pods: pods}, fmt.Errorf("unable to get external metric %s/%s/%+v: %w", wpa.Namespace, metricName, selector, err)
 	}
-	logger.V(4).Info("Metrics from the External Metrics Provider", "metrics", metrics)
-
-	var sum int64
-	for _, val := range metrics {
-		sum += val
+	if estimated {
+		logger.Info("Estimating the missing value of the metric", "metricName", metricName, "strategy", metric.External.MissingDatapoints.Strategy, "value", usage, "error", err)
+		timestamp = time.Now()
+	} else {
+		logger.V(4).Info("Metrics from the External Metrics Provider", "metrics", metrics)
+		usage = aggregateSeries(metrics, metric.External.SeriesAggregation)
+		if metric.External.MissingDatapoints != nil || metric.External.RateOfChange != nil {
+			c.samples.record(metricSampleKey(wpa, metric.External), usage, timestamp)
+		}
+		if metric.External.RateOfChange != nil {
+			if projected, ok := c.samples.project(metricSampleKey(wpa, metric.External), metric.External.RateOfChange); ok {
+				logger.Info("The metric rises faster than the slope, projecting its value", "metricName", metricName, "value", usage, "projectedValue", projected, "horizonSeconds", metric.External.RateOfChange.HorizonSeconds)
+				usage = projected
+			}
+		}
 	}
 
 	// if the average algorithm is used, the metrics retrieved has to be divided by the number of available replicas.
-	adjustedUsage := float64(sum) / averaged
-	replicaCount, utilizationQuantity := getReplicaCount(logger, currentReadyReplicas, wpa, metricName, adjustedUsage, metric.External.LowWatermark, metric.External.HighWatermark)
-	return ReplicaCalculation{replicaCount, utilizationQuantity, timestamp}, nil
+	adjustedUsage := usage / averaged
+	lowMark, highMark := watermarksForReplicas(currentReplicas, metric.External.LowWatermark, metric.External.HighWatermark, metric.External.WatermarkSteps)
+	tolerance := c.tolerance(logger, wpa, metricSampleKey(wpa, metric.External), adjustedUsage)
+	replicaCount, utilizationQuantity := getReplicaCount(logger, currentReplicas, wpa, metricName, adjustedUsage, averaged, tolerance, lowMark, highMark, metric.External.ScaleUpStrategy)
+	return ReplicaCalculation{pods: pods, replicaCount: replicaCount, utilization: utilizationQuantity, timestamp: timestamp, lowWatermark: lowMark, highWatermark: highMark, estimated: estimated, emergency: aboveEmergencyWatermark(metric.External.EmergencyHighWatermark, adjustedUsage), rawValue: int64(usage), tolerance: tolerance, latency: latency}, nil
 }
 
 // GetResourceReplicas calculates the desired replica count based on a target resource utilization percentage
 // of the given resource for pods matching the given selector in the given namespace, and the current replica count
-func (c *ReplicaCalculator) GetResourceReplicas(logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
+func (c *ReplicaCalculator) GetResourceReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
 
 	resourceName := metric.Resource.Name
 	selector := metric.Resource.MetricSelector
 	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
 	if err != nil {
-		return ReplicaCalculation{0, 0, time.Time{}}, err
+		return ReplicaCalculation{}, err
 	}
 
 	namespace := wpa.Namespace
-	metrics, timestamp, err := c.metricsClient.GetResourceMetric(resourceName, namespace, labelSelector)
+	start := time.Now()
+	metrics, timestamp, err := c.getResourceMetric(ctx, resourceName, namespace, labelSelector, metric.Resource)
+	latency := time.Since(start)
 	if err != nil {
 		// When we add official support for several metrics, move this Delete to only occur if no metric is available at all.
 		labelsWithReason := prometheus.Labels{
@@ -133,35 +247,41 @@ func (c *ReplicaCalculator) GetResourceReplicas(logger logr.Logger, target *auto
 		labelsWithReason[reasonPromLabel] = "within_bounds"
 		restrictedScaling.Delete(labelsWithReason)
 		value.Delete(prometheus.Labels{wpaNamePromLabel: wpa.Name, metricNamePromLabel: string(resourceName)})
-		return ReplicaCalculation{0, 0, time.Time{}}, fmt.Errorf("unable to get resource metric %s/%s/%+v: %s", wpa.Namespace, resourceName, selector, err)
+		return ReplicaCalculation{}, fmt.Errorf("unable to get resource metric %s/%s/%+v: %w", wpa.Namespace, resourceName, selector, err)
 	}
 	logger.V(4).Info("Metrics from the Resource Client", "metrics", metrics)
 
 	lbl, err := labels.Parse(target.Status.Selector)
 	if err != nil {
-		return ReplicaCalculation{0, 0, time.Time{}}, fmt.Errorf("could not parse the labels of the target: %v", err)
+		return ReplicaCalculation{}, fmt.Errorf("could not parse the labels of the target: %v", err)
 	}
 
 	podList, err := c.podLister.Pods(namespace).List(lbl)
 	if err != nil {
-		return ReplicaCalculation{0, 0, time.Time{}}, fmt.Errorf("unable to get pods while calculating replica count: %v", err)
+		return ReplicaCalculation{}, fmt.Errorf("unable to get pods while calculating replica count: %v", err)
 	}
 
 	if len(podList) == 0 {
-		return ReplicaCalculation{0, 0, time.Time{}}, fmt.Errorf("no pods returned by selector while calculating replica count")
+		return ReplicaCalculation{}, fmt.Errorf("no pods returned by selector while calculating replica count")
 	}
 
-	readyPods, ignoredPods := groupPods(logger, podList, metrics, resourceName, time.Duration(wpa.Spec.ReadinessDelaySeconds)*time.Second)
+	readyPods, ignoredPods, missingPods := groupPods(logger, podList, metrics, resourceName, time.Duration(wpa.Spec.ReadinessDelaySeconds)*time.Second, c.minReadyDuration(ctx, logger, wpa), c.excludedPods(ctx, logger, wpa))
 	readyPodCount := len(readyPods)
+	pods := &v1alpha1.PodsStatus{Total: int32(len(podList)), Ready: int32(readyPodCount), Ignored: int32(ignoredPods.Len()), MissingMetrics: int32(missingPods.Len())}
 
 	removeMetricsForPods(metrics, ignoredPods)
 	if len(metrics) == 0 {
-		return ReplicaCalculation{0, 0, time.Time{}}, fmt.Errorf("did not receive metrics for any ready pods")
+		return ReplicaCalculation{pods: pods}, fmt.Errorf("did not receive metrics for any ready pods")
 	}
 
+	currentReplicas := target.Status.Replicas
 	averaged := 1.0
-	if wpa.Spec.Algorithm == "average" {
-		averaged = float64(readyPodCount)
+	switch {
+	case wpa.Spec.Algorithm == averageAlgorithm:
+		averaged = sumPodWeights(podList, readyPods)
+	case wpa.Spec.Algorithm == averageSpecReplicasAlgorithm && target.Spec.Replicas > 0:
+		currentReplicas = target.Spec.Replicas
+		averaged = float64(currentReplicas)
 	}
 
 	var sum int64
@@ -169,33 +289,76 @@ func (c *ReplicaCalculator) GetResourceReplicas(logger logr.Logger, target *auto
 		sum += podMetric.Value
 	}
 	adjustedUsage := float64(sum) / averaged
+	if q := metric.Resource.PodQuantile; q != nil {
+		// The quantile is a value per pod, the watermarks resolved from the requests are then per pod as well.
+		adjustedUsage = podQuantile(metrics, *q)
+		averaged = float64(len(metrics))
+	}
 
-	replicaCount, utilizationQuantity := getReplicaCount(logger, target.Status.Replicas, wpa, string(resourceName), adjustedUsage, metric.Resource.LowWatermark, metric.Resource.HighWatermark)
-	return ReplicaCalculation{replicaCount, utilizationQuantity, timestamp}, nil
+	lowMark, highMark := metric.Resource.LowWatermark, metric.Resource.HighWatermark
+	if metric.Resource.LowWatermarkUtilization != nil && metric.Resource.HighWatermarkUtilization != nil {
+		requests, err := calculatePodRequests(podList, resourceName, containerWeights(metric.Resource))
+		if err != nil {
+			return ReplicaCalculation{pods: pods}, err
+		}
+		var requestsSum int64
+		for podName := range metrics {
+			requestsSum += requests[podName]
+		}
+		if requestsSum == 0 {
+			return ReplicaCalculation{pods: pods}, fmt.Errorf("no %s requests set on the pods, unable to compute the watermarks from the utilization", resourceName)
+		}
+		// The watermarks are resolved from the requests of the pods that reported a metric, and adjusted
+		// the same way as the usage so that the comparison is done on the same basis.
+		lowMark = resource.NewMilliQuantity(int64(float64(*metric.Resource.LowWatermarkUtilization)*float64(requestsSum)/100/averaged), resource.DecimalSI)
+		highMark = resource.NewMilliQuantity(int64(float64(*metric.Resource.HighWatermarkUtilization)*float64(requestsSum)/100/averaged), resource.DecimalSI)
+		logger.V(2).Info("Watermarks resolved from the requests", "requests", requestsSum, "lwm", lowMark.String(), "hwm", highMark.String())
+	}
+
+	lowMark, highMark = watermarksForReplicas(currentReplicas, lowMark, highMark, metric.Resource.WatermarkSteps)
+	tolerance := c.tolerance(logger, wpa, fmt.Sprintf("%s/%s/%s{%v}", wpa.Namespace, wpa.Name, resourceName, selector), adjustedUsage)
+	replicaCount, utilizationQuantity := getReplicaCount(logger, currentReplicas, wpa, string(resourceName), adjustedUsage, averaged, tolerance, lowMark, highMark, nil)
+	return ReplicaCalculation{pods: pods, replicaCount: replicaCount, utilization: utilizationQuantity, timestamp: timestamp, lowWatermark: lowMark, highWatermark: highMark, podMetrics: metrics, emergency: aboveEmergencyWatermark(metric.Resource.EmergencyHighWatermark, adjustedUsage), rawValue: sum, tolerance: tolerance, latency: latency}, nil
+}
+
+// tolerance returns the tolerance applied to the given metric, adapted to its recent values if enabled.
+func (c *ReplicaCalculator) tolerance(logger logr.Logger, wpa *v1alpha1.WatermarkPodAutoscaler, key string, adjustedUsage float64) float64 {
+	if wpa.Spec.AdaptiveTolerance == nil {
+		return wpa.Spec.Tolerance
+	}
+	values := c.history.record(key, adjustedUsage, int(wpa.Spec.AdaptiveTolerance.Samples))
+	tolerance := adaptiveTolerance(wpa.Spec.AdaptiveTolerance, wpa.Spec.Tolerance, values)
+	logger.V(2).Info("Adaptive tolerance", "samples", len(values), "tolerance", tolerance)
+	return tolerance
 }
 
-func getReplicaCount(logger logr.Logger, currentReplicas int32, wpa *v1alpha1.WatermarkPodAutoscaler, name string, adjustedUsage float64, lowMark, highMark *resource.Quantity) (replicaCount int32, utilization int64) {
+func getReplicaCount(logger logr.Logger, currentReplicas int32, wpa *v1alpha1.WatermarkPodAutoscaler, name string, adjustedUsage, averaged, tolerance float64, lowMark, highMark *resource.Quantity, strategy *v1alpha1.ScaleUpStrategySpec) (replicaCount int32, utilization int64) {
 	utilizationQuantity := resource.NewMilliQuantity(int64(adjustedUsage), resource.DecimalSI)
 
-	adjustedHM := float64(highMark.MilliValue()) + wpa.Spec.Tolerance*float64(highMark.MilliValue())
-	adjustedLM := float64(lowMark.MilliValue()) - wpa.Spec.Tolerance*float64(lowMark.MilliValue())
+	adjustedLM, adjustedHM := toleranceAdjustedWatermarks(lowMark, highMark, tolerance)
 
 	labelsWithReason := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind, reasonPromLabel: "within_bounds"}
 	labelsWithMetricName := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind, metricNamePromLabel: name}
 
+	lowwm.With(labelsWithMetricName).Set(float64(lowMark.MilliValue()))
+	lowwmV2.With(labelsWithMetricName).Set(float64(lowMark.MilliValue()))
+	highwm.With(labelsWithMetricName).Set(float64(highMark.MilliValue()))
+	highwmV2.With(labelsWithMetricName).Set(float64(highMark.MilliValue()))
+
+	rounding := roundingOf(wpa)
 	switch {
 	case adjustedUsage > adjustedHM:
-		replicaCount = int32(math.Ceil(float64(currentReplicas) * adjustedUsage / (float64(highMark.MilliValue()))))
+		replicaCount = scaleUpReplicas(strategy, rounding.Upscale, currentReplicas, adjustedUsage, averaged, highMark) + rounding.BiasReplicas
 		logger.Info("Value is above highMark", "usage", utilizationQuantity.String(), "replicaCount", replicaCount)
 	case adjustedUsage < adjustedLM:
-		replicaCount = int32(math.Floor(float64(currentReplicas) * adjustedUsage / (float64(lowMark.MilliValue()))))
+		replicaCount = roundReplicas(rounding.Downscale, v1alpha1.RoundingModeFloor, float64(currentReplicas)*adjustedUsage/float64(lowMark.MilliValue()))
 		// Keep a minimum of 1 replica
 		replicaCount = int32(math.Max(float64(replicaCount), 1))
 		logger.Info("Value is below lowMark", "usage", utilizationQuantity.String(), "replicaCount", replicaCount)
 	default:
 		restrictedScaling.With(labelsWithReason).Set(1)
 		value.With(labelsWithMetricName).Set(adjustedUsage)
-		logger.Info("Within bounds of the watermarks", "value", utilizationQuantity.String(), "lwm", lowMark.String(), "hwm", highMark.String(), "tolerance", wpa.Spec.Tolerance)
+		logger.Info("Within bounds of the watermarks", "value", utilizationQuantity.String(), "lwm", lowMark.String(), "hwm", highMark.String(), "tolerance", tolerance)
 		// returning the currentReplicas instead of the count of healthy ones to be consistent with the upstream behavior.
 		return currentReplicas, utilizationQuantity.MilliValue()
 	}
@@ -206,43 +369,239 @@ func getReplicaCount(logger logr.Logger, currentReplicas int32, wpa *v1alpha1.Wa
 	return replicaCount, utilizationQuantity.MilliValue()
 }
 
-func (c *ReplicaCalculator) getReadyPodsCount(namespace string, selector labels.Selector, readinessDelay time.Duration) (int32, error) {
+// toleranceAdjustedWatermarks returns the milli values the usage is compared to: the low watermark lowered, and the
+// high watermark raised, by the tolerance.
+func toleranceAdjustedWatermarks(lowMark, highMark *resource.Quantity, tolerance float64) (float64, float64) {
+	return float64(lowMark.MilliValue()) - tolerance*float64(lowMark.MilliValue()), float64(highMark.MilliValue()) + tolerance*float64(highMark.MilliValue())
+}
+
+// getExternalMetric queries the external metrics API, unless its circuit breaker is open, and exports its latency.
+func (c *ReplicaCalculator) getExternalMetric(ctx context.Context, metricName, namespace string, selector labels.Selector) ([]int64, time.Time, error) {
+	if err := c.externalBreaker.allow(time.Now()); err != nil {
+		return nil, time.Time{}, err
+	}
+	var metrics []int64
+	var timestamp time.Time
+	start := time.Now()
+	err := callWithContext(ctx, func() (err error) {
+		metrics, timestamp, err = c.metricsClient.GetExternalMetric(metricName, namespace, selector)
+		return err
+	})
+	observeMetricsProviderLatency(externalMetricsAPI, start)
+	c.externalBreaker.record(err, time.Now())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return metrics, timestamp, nil
+}
+
+// getShardedExternalMetric appends the series of the shards to the ones of the calculator, the timestamp being the
+// oldest one. The metric is unavailable when one of the shards fails to report it.
+func (c *ReplicaCalculator) getShardedExternalMetric(ctx context.Context, metricName, namespace string, selector labels.Selector, shards []*ReplicaCalculator) ([]int64, time.Time, error) {
+	metrics, timestamp, err := c.getExternalMetric(ctx, metricName, namespace, selector)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	for _, shard := range shards {
+		shardMetrics, shardTimestamp, err := shard.getExternalMetric(ctx, metricName, namespace, selector)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("unable to get the metric of a shard: %w", err)
+		}
+		metrics = append(metrics, shardMetrics...)
+		if shardTimestamp.Before(timestamp) {
+			timestamp = shardTimestamp
+		}
+	}
+	return metrics, timestamp, nil
+}
+
+// getResourceMetric queries the resource metrics API, unless its circuit breaker is open, and exports its latency.
+func (c *ReplicaCalculator) getResourceMetric(ctx context.Context, resourceName corev1.ResourceName, namespace string, selector labels.Selector, source *v1alpha1.ResourceMetricSource) (metricsclient.PodMetricsInfo, time.Time, error) {
+	if err := c.resourceBreaker.allow(time.Now()); err != nil {
+		return nil, time.Time{}, err
+	}
+	var metrics metricsclient.PodMetricsInfo
+	var timestamp time.Time
+	start := time.Now()
+	err := callWithContext(ctx, func() (err error) {
+		switch {
+		case len(source.ContainerWeights) > 0:
+			metrics, timestamp, err = c.metricsClient.GetWeightedResourceMetric(resourceName, namespace, selector, containerWeights(source))
+		case source.Container != "":
+			metrics, timestamp, err = c.metricsClient.GetContainerResourceMetric(resourceName, namespace, selector, source.Container)
+		default:
+			metrics, timestamp, err = c.metricsClient.GetResourceMetric(resourceName, namespace, selector)
+		}
+		return err
+	})
+	observeMetricsProviderLatency(resourceMetricsAPI, start)
+	c.resourceBreaker.record(err, time.Now())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return metrics, timestamp, nil
+}
+
+// aggregateSeries combines the values of the series returned for an external metric.
+func aggregateSeries(values []int64, aggregation v1alpha1.SeriesAggregation) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	switch aggregation {
+	case v1alpha1.SeriesAggregationAvg:
+		return sumSeries(values) / float64(len(values))
+	case v1alpha1.SeriesAggregationMax:
+		return float64(sorted[len(sorted)-1])
+	case v1alpha1.SeriesAggregationMin:
+		return float64(sorted[0])
+	case v1alpha1.SeriesAggregationP95:
+		// nearest-rank method
+		return float64(sorted[int(math.Ceil(0.95*float64(len(sorted))))-1])
+	default:
+		return sumSeries(values)
+	}
+}
+
+// podQuantile returns the given percentile of the values of the pods, with the nearest-rank method.
+func podQuantile(metrics metricsclient.PodMetricsInfo, quantile int32) float64 {
+	values := make([]int64, 0, len(metrics))
+	for _, podMetric := range metrics {
+		values = append(values, podMetric.Value)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return float64(values[int(math.Ceil(float64(quantile)/100*float64(len(values))))-1])
+}
+
+func sumSeries(values []int64) float64 {
+	var sum int64
+	for _, val := range values {
+		sum += val
+	}
+	return float64(sum)
+}
+
+// watermarksForReplicas returns the watermarks of the step with the highest minReplicas not above the current replicas,
+// or the given watermarks if there is none.
+func watermarksForReplicas(currentReplicas int32, lowMark, highMark *resource.Quantity, steps []v1alpha1.WatermarkStep) (*resource.Quantity, *resource.Quantity) {
+	var current *v1alpha1.WatermarkStep
+	for i, step := range steps {
+		if step.MinReplicas <= currentReplicas && (current == nil || step.MinReplicas > current.MinReplicas) {
+			current = &steps[i]
+		}
+	}
+	if current == nil {
+		return lowMark, highMark
+	}
+	return &current.LowWatermark, &current.HighWatermark
+}
+
+// containerWeights returns the weights in percent of the containers whose usage is considered, nil when the usage of
+// all the containers is.
+func containerWeights(source *v1alpha1.ResourceMetricSource) map[string]int32 {
+	if source.Container != "" {
+		return map[string]int32{source.Container: 100}
+	}
+	if len(source.ContainerWeights) == 0 {
+		return nil
+	}
+	weights := make(map[string]int32, len(source.ContainerWeights))
+	for _, w := range source.ContainerWeights {
+		weights[w.Name] = w.Weight
+	}
+	return weights
+}
+
+// calculatePodRequests returns the requests of the given resource for each pod, only considering
+// the weighted containers, if any, in proportion to their weight.
+func calculatePodRequests(pods []*corev1.Pod, resource corev1.ResourceName, weights map[string]int32) (map[string]int64, error) {
+	requests := make(map[string]int64, len(pods))
+	for _, pod := range pods {
+		podSum := int64(0)
+		found := false
+		for _, c := range pod.Spec.Containers {
+			weight, weighted := weights[c.Name]
+			if weights == nil {
+				weight = 100
+			} else if !weighted {
+				continue
+			}
+			containerRequest, ok := c.Resources.Requests[resource]
+			if !ok {
+				return nil, fmt.Errorf("missing request for %s in container %s of pod %s/%s", resource, c.Name, pod.Namespace, pod.Name)
+			}
+			podSum += containerRequest.MilliValue() * int64(weight) / 100
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("container %s not found in pod %s/%s", strings.Join(sortedContainers(weights), ","), pod.Namespace, pod.Name)
+		}
+		requests[pod.Name] = podSum
+	}
+	return requests, nil
+}
+
+func sortedContainers(weights map[string]int32) []string {
+	containers := make([]string, 0, len(weights))
+	for container := range weights {
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+	return containers
+}
+
+// getReadyPodsCount counts the pods tolerated as ready, and the ones ignored, among the pods matching the selector.
+// It also returns the sum of the weights of the pods tolerated as ready.
+func (c *ReplicaCalculator) getReadyPodsCount(namespace string, selector labels.Selector, readinessDelay, minReady time.Duration, excluded podExclusion) (*v1alpha1.PodsStatus, float64, error) {
 	podList, err := c.podLister.Pods(namespace).List(selector)
 	if err != nil {
-		return 0, fmt.Errorf("unable to get pods while calculating replica count: %v", err)
+		return nil, 0, fmt.Errorf("unable to get pods while calculating replica count: %v", err)
 	}
 
 	if len(podList) == 0 {
-		return 0, fmt.Errorf("no pods returned by selector while calculating replica count")
+		return &v1alpha1.PodsStatus{}, 0, fmt.Errorf("no pods returned by selector while calculating replica count")
 	}
 
 	toleratedAsReadyPodCount := 0
+	var toleratedAsReadyWeight float64
+	now := time.Now()
 
 	for _, pod := range podList {
+		if excluded(pod) {
+			continue
+		}
 		_, condition := getPodCondition(&pod.Status, corev1.PodReady)
 		if condition == nil || pod.Status.StartTime == nil {
 			log.V(4).Info("Pod unready", "namespace", pod.Namespace, "name", pod.Name)
 			continue
 		}
-		if pod.Status.Phase == corev1.PodRunning && condition.Status == corev1.ConditionTrue ||
+		if pod.Status.Phase == corev1.PodRunning && condition.Status == corev1.ConditionTrue && !isWarmingUp(pod) && isAvailable(pod, minReady, now) ||
 			// We only care about the time after start as a warm up. If a pod becomes unresponsive it will not elect for a readinessDelay tolerance.
 			// We do not use v1.ConditionFalse, because we only tolerate the Pending state stuck in the same condition for more than readinessDelay.
 			// Pending includes the time spent pulling images onto the host.
 			pod.Status.Phase == corev1.PodPending && condition.LastTransitionTime.Sub(pod.Status.StartTime.Time) < readinessDelay {
 			toleratedAsReadyPodCount++
+			toleratedAsReadyWeight += podWeight(pod)
 		}
 	}
+	pods := &v1alpha1.PodsStatus{Total: int32(len(podList)), Ready: int32(toleratedAsReadyPodCount), Ignored: int32(len(podList) - toleratedAsReadyPodCount)}
 	if toleratedAsReadyPodCount == 0 {
-		return 0, fmt.Errorf("among the %d pods, none is ready. Skipping recommendation", len(podList))
+		return pods, 0, fmt.Errorf("among the %d pods, none is ready. Skipping recommendation", len(podList))
 	}
-	return int32(toleratedAsReadyPodCount), nil
+	return pods, toleratedAsReadyWeight, nil
 }
 
-func groupPods(logger logr.Logger, podList []*corev1.Pod, metrics metricsclient.PodMetricsInfo, resource corev1.ResourceName, delayOfInitialReadinessStatus time.Duration) (readyPods, ignoredPods sets.String) {
+func groupPods(logger logr.Logger, podList []*corev1.Pod, metrics metricsclient.PodMetricsInfo, resource corev1.ResourceName, delayOfInitialReadinessStatus, minReady time.Duration, excluded podExclusion) (readyPods, ignoredPods, missing sets.String) {
+	now := time.Now()
 	readyPods = sets.NewString()
 	ignoredPods = sets.NewString()
-	missing := sets.NewString()
+	missing = sets.NewString()
 	for _, pod := range podList {
+		if excluded(pod) {
+			ignoredPods.Insert(pod.Name)
+			continue
+		}
 		// Failed pods shouldn't produce metrics, but add to ignoredPods to be safe
 		if pod.Status.Phase == corev1.PodFailed {
 			ignoredPods.Insert(pod.Name)
@@ -276,10 +635,16 @@ func groupPods(logger logr.Logger, podList []*corev1.Pod, metrics metricsclient.
 				continue
 			}
 		}
+		// Pods still behind a startup probe or a readiness gate, or Ready for less than the minReadySeconds of
+		// the target, don't take their share of the load yet.
+		if isWarmingUp(pod) || !isAvailable(pod, minReady, now) {
+			ignoredPods.Insert(pod.Name)
+			continue
+		}
 		readyPods.Insert(pod.Name)
 	}
 	logger.V(2).Info("GroupPods", "Ready", len(readyPods), "Missing", len(missing), "Ignored", len(ignoredPods))
-	return readyPods, ignoredPods
+	return readyPods, ignoredPods, missing
 }
 
 func removeMetricsForPods(metrics metricsclient.PodMetricsInfo, pods sets.String) {
@@ -288,6 +653,27 @@ func removeMetricsForPods(metrics metricsclient.PodMetricsInfo, pods sets.String
 	}
 }
 
+// isWarmingUp returns whether a container of the pod has not passed its startup probe yet, or a readiness gate
+// of the pod is not satisfied yet.
+func isWarmingUp(pod *corev1.Pod) bool {
+	started := make(map[string]bool, len(pod.Status.ContainerStatuses))
+	for _, status := range pod.Status.ContainerStatuses {
+		started[status.Name] = status.Started != nil && *status.Started
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.StartupProbe != nil && !started[container.Name] {
+			return true
+		}
+	}
+	for _, gate := range pod.Spec.ReadinessGates {
+		_, condition := getPodCondition(&pod.Status, gate.ConditionType)
+		if condition == nil || condition.Status != corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 // getPodCondition extracts the provided condition from the given status and returns that, and the
 // index of the located condition. Returns nil and -1 if the condition is not present.
 func getPodCondition(status *corev1.PodStatus, conditionType corev1.PodConditionType) (int, *corev1.PodCondition) {
@@ -6,6 +6,7 @@
 package watermarkpodautoscaler
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -42,12 +43,14 @@ type resourceInfo struct {
 type metricInfo struct {
 	spec                v1alpha1.MetricSpec
 	levels              []int64
+	sidecarLevels       []int64
 	expectedUtilization int64
 }
 
 type replicaCalcTestCase struct {
 	expectedReplicas int32
 	expectedError    error
+	expectedPods     *v1alpha1.PodsStatus
 	timestamp        time.Time
 
 	namespace string
@@ -91,7 +94,7 @@ func (tc *replicaCalcTestCase) getFakeResourceClient() *metricsfake.Clientset {
 
 			for j := 0; j < numContainersPerPod; j++ {
 				cm := metricsapi.ContainerMetrics{
-					Name: fmt.Sprintf("%s-%d-container-%d", podNamePrefix, i, j),
+					Name: fmt.Sprintf("container-%d", j),
 					Usage: corev1.ResourceList{
 						corev1.ResourceCPU: *resource.NewMilliQuantity(
 							cpu,
@@ -103,6 +106,14 @@ func (tc *replicaCalcTestCase) getFakeResourceClient() *metricsfake.Clientset {
 				}
 				metric.Containers = append(metric.Containers, cm)
 			}
+			if i < len(tc.metric.sidecarLevels) {
+				metric.Containers = append(metric.Containers, metricsapi.ContainerMetrics{
+					Name: "sidecar",
+					Usage: corev1.ResourceList{
+						corev1.ResourceCPU: *resource.NewMilliQuantity(tc.metric.sidecarLevels[i], resource.DecimalSI),
+					},
+				})
+			}
 			podMetrics.Items = append(podMetrics.Items, metric)
 		}
 		return true, podMetrics, nil
@@ -238,9 +249,9 @@ func (tc *replicaCalcTestCase) runTest(t *testing.T) {
 
 	rClient := tc.getFakeResourceClient()
 
-	mClient := metrics.NewRESTMetricsClient(rClient.MetricsV1beta1(), nil, emClient)
+	mClient := NewRESTMetricsClient(rClient.MetricsV1beta1(), nil, emClient)
 
-	replicaCalculator := NewReplicaCalculator(mClient, informer.Lister())
+	replicaCalculator := NewReplicaCalculator(mClient, informer.Lister(), nil)
 
 	stop := make(chan struct{})
 	defer close(stop)
@@ -254,7 +265,10 @@ func (tc *replicaCalcTestCase) runTest(t *testing.T) {
 	if tc.metric.spec.Resource != nil {
 		// Resource metric tests
 		// Update with the correct labels.
-		replicaCalculation, err = replicaCalculator.GetResourceReplicas(logf.Log, tc.scale, tc.metric.spec, tc.wpa)
+		replicaCalculation, err = replicaCalculator.GetResourceReplicas(context.TODO(), logf.Log, tc.scale, tc.metric.spec, tc.wpa)
+		if tc.expectedPods != nil {
+			assert.Equal(t, tc.expectedPods, replicaCalculation.pods, "the pods should have been accounted for as expected")
+		}
 
 		if tc.expectedError != nil {
 			require.Error(t, err, "there should be an error calculating the replica count")
@@ -263,7 +277,10 @@ func (tc *replicaCalcTestCase) runTest(t *testing.T) {
 		}
 	} else if tc.metric.spec.External != nil {
 		// External metric tests
-		replicaCalculation, err = replicaCalculator.GetExternalMetricReplicas(logf.Log, tc.scale, tc.metric.spec, tc.wpa)
+		replicaCalculation, err = replicaCalculator.GetExternalMetricReplicas(context.TODO(), logf.Log, tc.scale, tc.metric.spec, tc.wpa)
+		if tc.expectedPods != nil {
+			assert.Equal(t, tc.expectedPods, replicaCalculation.pods, "the pods should have been accounted for as expected")
+		}
 		if tc.expectedError != nil {
 			require.Error(t, err, "there should be an error calculating the replica count")
 			assert.Contains(t, err.Error(), tc.expectedError.Error(), "the error message should have contained the expected error message")
@@ -308,6 +325,40 @@ func TestReplicaCalcDisjointResourcesMetrics(t *testing.T) {
 	tc.runTest(t)
 }
 
+func TestReplicaCalcNoReadyPodsReportsPods(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:           corev1.ResourceCPU,
+			MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermark:  resource.NewMilliQuantity(40000, resource.DecimalSI),
+			LowWatermark:   resource.NewMilliQuantity(20000, resource.DecimalSI),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		// All the pods are Pending, so their metrics are ignored: the counts are still reported to explain the error.
+		expectedError: fmt.Errorf("did not receive metrics for any ready pods"),
+		expectedPods:  &v1alpha1.PodsStatus{Total: 3, Ignored: 3},
+		scale:         makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.2,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		podPhase: []corev1.PodPhase{corev1.PodPending, corev1.PodPending, corev1.PodPending},
+		metric: &metricInfo{
+			spec:                metric1,
+			levels:              []int64{30000, 30000, 30000},
+			expectedUtilization: 90000,
+		},
+	}
+	tc.runTest(t)
+}
+
 func makeScale(currentReplicas int32, labelsMap map[string]string) *autoscalingv1.Scale {
 	return &autoscalingv1.Scale{
 		Status: autoscalingv1.ScaleStatus{
@@ -348,6 +399,253 @@ func TestReplicaCalcAbsoluteScaleUp(t *testing.T) {
 	tc.runTest(t)
 }
 
+func TestReplicaCalcContainerScaleUp(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:           corev1.ResourceCPU,
+			Container:      "container-0",
+			MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermark:  resource.NewMilliQuantity(40000, resource.DecimalSI),
+			LowWatermark:   resource.NewMilliQuantity(20000, resource.DecimalSI),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		expectedReplicas: 21,
+		scale:            makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.2,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		metric: &metricInfo{
+			spec:                metric1,
+			levels:              []int64{90000, 90000, 90000},
+			sidecarLevels:       []int64{500000, 500000, 500000}, // Usage of the sidecar is not considered
+			expectedUtilization: 270000,
+		},
+	}
+	tc.runTest(t)
+}
+
+func TestReplicaCalcContainerWeightsScaleUp(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:             corev1.ResourceCPU,
+			ContainerWeights: []v1alpha1.ContainerWeight{{Name: "container-0", Weight: 100}, {Name: "sidecar", Weight: 10}},
+			MetricSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermark:    resource.NewMilliQuantity(40000, resource.DecimalSI),
+			LowWatermark:     resource.NewMilliQuantity(20000, resource.DecimalSI),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		expectedReplicas: 23,
+		scale:            makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.2,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		metric: &metricInfo{
+			spec:                metric1,
+			levels:              []int64{90000, 90000, 90000},
+			sidecarLevels:       []int64{100000, 100000, 100000}, // 10% of the usage of the sidecar is considered
+			expectedUtilization: 300000,
+		},
+	}
+	tc.runTest(t)
+}
+
+func TestReplicaCalcContainerWeightsNotFound(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:             corev1.ResourceCPU,
+			ContainerWeights: []v1alpha1.ContainerWeight{{Name: "istio-proxy", Weight: 50}},
+			MetricSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermark:    resource.NewMilliQuantity(40000, resource.DecimalSI),
+			LowWatermark:     resource.NewMilliQuantity(20000, resource.DecimalSI),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		expectedError: fmt.Errorf("no metrics returned from resource metrics API for the weighted containers"),
+		scale:         makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.2,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		metric: &metricInfo{
+			spec:   metric1,
+			levels: []int64{90000, 90000, 90000},
+		},
+	}
+	tc.runTest(t)
+}
+
+func TestCalculatePodRequestsWithContainerWeights(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: testNamespace},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}}},
+			{Name: "sidecar", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}}},
+			{Name: "debug"},
+		}},
+	}
+
+	requests, err := calculatePodRequests([]*corev1.Pod{pod}, corev1.ResourceCPU, map[string]int32{"app": 100, "sidecar": 20})
+	require.NoError(t, err, "the containers without a weight are left out")
+	assert.Equal(t, int64(1100), requests["pod"])
+
+	_, err = calculatePodRequests([]*corev1.Pod{pod}, corev1.ResourceCPU, nil)
+	require.Error(t, err, "all the containers are considered without weights")
+
+	_, err = calculatePodRequests([]*corev1.Pod{pod}, corev1.ResourceCPU, map[string]int32{"istio-proxy": 50})
+	require.EqualError(t, err, "container istio-proxy not found in pod test-namespace/pod")
+}
+
+func TestReplicaCalcContainerNotFound(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:           corev1.ResourceCPU,
+			Container:      "istio-proxy",
+			MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermark:  resource.NewMilliQuantity(40000, resource.DecimalSI),
+			LowWatermark:   resource.NewMilliQuantity(20000, resource.DecimalSI),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		expectedError: fmt.Errorf("no metrics returned from resource metrics API for container istio-proxy"),
+		scale:         makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.2,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		metric: &metricInfo{
+			spec:   metric1,
+			levels: []int64{90000, 90000, 90000},
+		},
+	}
+	tc.runTest(t)
+}
+
+func TestReplicaCalcUtilizationScaleUp(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:                     corev1.ResourceCPU,
+			MetricSelector:           &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermarkUtilization: v1alpha1.NewInt32(80),
+			LowWatermarkUtilization:  v1alpha1.NewInt32(50),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		expectedReplicas: 4,
+		scale:            makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.1,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		// Each pod has two containers requesting 1 CPU, the high watermark is resolved to 4800m.
+		resource: &resourceInfo{
+			name:     corev1.ResourceCPU,
+			requests: []resource.Quantity{resource.MustParse("1"), resource.MustParse("1"), resource.MustParse("1")},
+		},
+		metric: &metricInfo{
+			spec:                metric1,
+			levels:              []int64{1800, 1800, 1800},
+			expectedUtilization: 5400,
+		},
+	}
+	tc.runTest(t)
+}
+
+func TestReplicaCalcPodQuantileScaleUp(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:           corev1.ResourceCPU,
+			MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermark:  resource.NewMilliQuantity(40000, resource.DecimalSI),
+			LowWatermark:   resource.NewMilliQuantity(20000, resource.DecimalSI),
+			PodQuantile:    v1alpha1.NewInt32(90),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		expectedReplicas: 7,
+		scale:            makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "average",
+				Tolerance: 0.2,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		metric: &metricInfo{
+			spec:                metric1,
+			levels:              []int64{10000, 10000, 90000}, // The average is within the watermarks, the hot pod is not
+			expectedUtilization: 90000,
+		},
+	}
+	tc.runTest(t)
+}
+
+func TestReplicaCalcUtilizationMissingRequests(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ResourceMetricSourceType,
+		Resource: &v1alpha1.ResourceMetricSource{
+			Name:                     corev1.ResourceCPU,
+			MetricSelector:           &metav1.LabelSelector{MatchLabels: map[string]string{"name": "test-pod"}},
+			HighWatermarkUtilization: v1alpha1.NewInt32(80),
+			LowWatermarkUtilization:  v1alpha1.NewInt32(50),
+		},
+	}
+
+	tc := replicaCalcTestCase{
+		expectedError: fmt.Errorf("missing request for cpu"),
+		scale:         makeScale(3, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.1,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		metric: &metricInfo{
+			spec:   metric1,
+			levels: []int64{1800, 1800, 1800},
+		},
+	}
+	tc.runTest(t)
+}
+
 func TestReplicaCalcAbsoluteScaleDown(t *testing.T) {
 	logf.SetLogger(logf.ZapLogger(true))
 	metric1 := v1alpha1.MetricSpec{
@@ -424,6 +722,7 @@ func TestReplicaCalcAbsoluteScaleUpPendingLessScale(t *testing.T) {
 
 	tc := replicaCalcTestCase{
 		expectedReplicas: 9,
+		expectedPods:     &v1alpha1.PodsStatus{Total: 3, Ready: 2, Ignored: 1},
 		scale:            makeScale(3, map[string]string{"name": "test-pod"}),
 		wpa: &v1alpha1.WatermarkPodAutoscaler{
 			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
@@ -1383,6 +1682,41 @@ func TestReplicaCalcBelowAverageExternal_Downscale4(t *testing.T) {
 	tc.runTest(t)
 }
 
+// Half of the replicas are starting: averaging over the ready ones would double the value.
+func TestReplicaCalcAverageSpecReplicasExternal(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ExternalMetricSourceType,
+		External: &v1alpha1.ExternalMetricSource{
+			MetricName:     "loadbalancer.request.per.seconds",
+			MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			HighWatermark:  resource.NewMilliQuantity(85000, resource.DecimalSI),
+			LowWatermark:   resource.NewMilliQuantity(75000, resource.DecimalSI),
+		},
+	}
+	scale := makeScale(10, map[string]string{"name": "test-pod"})
+	scale.Spec.Replicas = 10
+	tc := replicaCalcTestCase{
+		expectedReplicas: 10,
+		scale:            scale,
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "averageSpecReplicas",
+				Tolerance: 0.01,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		podPhase: []corev1.PodPhase{corev1.PodPending, corev1.PodPending, corev1.PodPending, corev1.PodPending, corev1.PodPending, corev1.PodRunning, corev1.PodRunning, corev1.PodRunning, corev1.PodRunning, corev1.PodRunning},
+		metric: &metricInfo{
+			spec:                metric1,
+			levels:              []int64{800000}, // We are within the watermarks.
+			expectedUtilization: 80000,           // utilization was 800/10 = 80
+		},
+	}
+	tc.runTest(t)
+}
+
 func TestGroupPods(t *testing.T) {
 	logf.SetLogger(logf.ZapLogger(true))
 
@@ -1681,7 +2015,7 @@ func TestGroupPods(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			readyPods, ignoredPods := groupPods(logf.Log, tc.pods, tc.metrics, tc.resource, time.Duration(readinessDelay)*time.Second)
+			readyPods, ignoredPods, _ := groupPods(logf.Log, tc.pods, tc.metrics, tc.resource, time.Duration(readinessDelay)*time.Second, 0, noPodExcluded)
 			readyPodCount := len(readyPods)
 			assert.Equal(t, readyPodCount, tc.expectReadyPodCount, "%s got readyPodCount %d, expected %d", tc.name, readyPodCount, tc.expectReadyPodCount)
 			assert.EqualValues(t, ignoredPods, tc.expectIgnoredPods, "%s got unreadyPods %v, expected %v", tc.name, ignoredPods, tc.expectIgnoredPods)
@@ -1886,7 +2220,7 @@ func TestGetReadyPodsCount(t *testing.T) {
 			informerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
 			informer := informerFactory.Core().V1().Pods()
 
-			replicaCalculator := NewReplicaCalculator(nil, informer.Lister())
+			replicaCalculator := NewReplicaCalculator(nil, informer.Lister(), nil)
 
 			stop := make(chan struct{})
 			defer close(stop)
@@ -1894,8 +2228,10 @@ func TestGetReadyPodsCount(t *testing.T) {
 			if !cache.WaitForNamedCacheSync("HPA", stop, informer.Informer().HasSynced) {
 				return
 			}
-			val, err := replicaCalculator.getReadyPodsCount(tc.namespace, labels.SelectorFromSet(f.selector), readinessDelay*time.Second)
-			assert.Equal(t, f.expected, val)
+			pods, _, err := replicaCalculator.getReadyPodsCount(tc.namespace, labels.SelectorFromSet(f.selector), readinessDelay*time.Second, 0, noPodExcluded)
+			if pods != nil {
+				assert.Equal(t, f.expected, pods.Ready)
+			}
 			if f.errorExpected != nil {
 				assert.EqualError(t, f.errorExpected, err.Error())
 			}
@@ -1903,6 +2239,72 @@ func TestGetReadyPodsCount(t *testing.T) {
 	}
 }
 
+func TestIsWarmingUp(t *testing.T) {
+	started, notStarted := true, false
+	probe := &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/started"}}}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{
+			name: "no startup probe nor readiness gate",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			},
+		},
+		{
+			name: "startup probe passed",
+			pod: &corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app", StartupProbe: probe}, {Name: "sidecar"}}},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Started: &started}, {Name: "sidecar"}}},
+			},
+		},
+		{
+			name: "startup probe not passed yet",
+			pod: &corev1.Pod{
+				Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app", StartupProbe: probe}}},
+				Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Started: &notStarted}}},
+			},
+			want: true,
+		},
+		{
+			name: "container with a startup probe without status",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", StartupProbe: probe}}},
+			},
+			want: true,
+		},
+		{
+			name: "readiness gate satisfied",
+			pod: &corev1.Pod{
+				Spec:   corev1.PodSpec{ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example.com/warm"}}},
+				Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: "example.com/warm", Status: corev1.ConditionTrue}}},
+			},
+		},
+		{
+			name: "readiness gate not satisfied yet",
+			pod: &corev1.Pod{
+				Spec:   corev1.PodSpec{ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example.com/warm"}}},
+				Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: "example.com/warm", Status: corev1.ConditionFalse}}},
+			},
+			want: true,
+		},
+		{
+			name: "readiness gate without condition",
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{ReadinessGates: []corev1.PodReadinessGate{{ConditionType: "example.com/warm"}}},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isWarmingUp(tt.pod))
+		})
+	}
+}
+
 func TestGetPodCondition(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -1978,3 +2380,98 @@ func TestGetPodCondition(t *testing.T) {
 	}
 
 }
+
+func TestReplicaCalcAboveAbsoluteExternalWatermarkSteps(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+
+	metric1 := v1alpha1.MetricSpec{
+		Type: v1alpha1.ExternalMetricSourceType,
+		External: &v1alpha1.ExternalMetricSource{
+			MetricName:     "deadbeef",
+			MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			HighWatermark:  resource.NewMilliQuantity(10000, resource.DecimalSI),
+			LowWatermark:   resource.NewMilliQuantity(5000, resource.DecimalSI),
+			WatermarkSteps: []v1alpha1.WatermarkStep{
+				{MinReplicas: 10, HighWatermark: *resource.NewMilliQuantity(1000, resource.DecimalSI), LowWatermark: *resource.NewMilliQuantity(500, resource.DecimalSI)},
+				{MinReplicas: 3, HighWatermark: *resource.NewMilliQuantity(4000, resource.DecimalSI), LowWatermark: *resource.NewMilliQuantity(2000, resource.DecimalSI)},
+			},
+		},
+	}
+	tc := replicaCalcTestCase{
+		expectedReplicas: 9,
+		scale:            makeScale(4, map[string]string{"name": "test-pod"}),
+		wpa: &v1alpha1.WatermarkPodAutoscaler{
+			Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+				Algorithm: "absolute",
+				Tolerance: 0.2,
+				Metrics:   []v1alpha1.MetricSpec{metric1},
+			},
+		},
+		metric: &metricInfo{
+			spec:                metric1,
+			levels:              []int64{8600}, // Within the base watermarks, but above the ones of the step starting at 3 replicas
+			expectedUtilization: 8600,
+		},
+	}
+	tc.runTest(t)
+}
+
+func TestWatermarksForReplicas(t *testing.T) {
+	low, high := resource.MustParse("1"), resource.MustParse("2")
+	steps := []v1alpha1.WatermarkStep{
+		{MinReplicas: 50, LowWatermark: resource.MustParse("5"), HighWatermark: resource.MustParse("6")},
+		{MinReplicas: 10, LowWatermark: resource.MustParse("3"), HighWatermark: resource.MustParse("4")},
+	}
+	for replicas, want := range map[int32][2]string{
+		1:   {"1", "2"},
+		10:  {"3", "4"},
+		49:  {"3", "4"},
+		500: {"5", "6"},
+	} {
+		gotLow, gotHigh := watermarksForReplicas(replicas, &low, &high, steps)
+		require.Equal(t, want[0], gotLow.String())
+		require.Equal(t, want[1], gotHigh.String())
+	}
+}
+
+func TestAggregateSeries(t *testing.T) {
+	values := make([]int64, 0, 20)
+	for i := int64(20); i > 0; i-- {
+		values = append(values, i)
+	}
+	for aggregation, want := range map[v1alpha1.SeriesAggregation]float64{
+		"":                            210,
+		v1alpha1.SeriesAggregationSum: 210,
+		v1alpha1.SeriesAggregationAvg: 10.5,
+		v1alpha1.SeriesAggregationMax: 20,
+		v1alpha1.SeriesAggregationMin: 1,
+		v1alpha1.SeriesAggregationP95: 19,
+	} {
+		require.Equal(t, want, aggregateSeries(values, aggregation), "aggregation %q", aggregation)
+	}
+	require.Equal(t, float64(0), aggregateSeries(nil, v1alpha1.SeriesAggregationMax))
+	require.Equal(t, []int64{20, 19}, values[:2], "the values should not be reordered")
+}
+
+func TestCallWithContext(t *testing.T) {
+	err := callWithContext(context.TODO(), func() error {
+		return fmt.Errorf("unable to fetch metrics from external metrics API")
+	})
+	require.EqualError(t, err, "unable to fetch metrics from external metrics API")
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	err = callWithContext(ctx, func() error {
+		<-ctx.Done()
+		return fmt.Errorf("the request timed out")
+	})
+	require.EqualError(t, err, "the request timed out", "the query should not be abandoned when the context expires")
+
+	called := false
+	err = callWithContext(ctx, func() error {
+		called = true
+		return nil
+	})
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.False(t, called, "no query should be made once the context is done")
+}
@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getResourceQuotaMaxReplicas returns the maximum number of replicas of the target that can be admitted
+// given the ResourceQuotas of the namespace. The boolean is false if no quota restricts the target.
+func (r *ReconcileWatermarkPodAutoscaler) getResourceQuotaMaxReplicas(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale) (int32, bool, error) {
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.client.List(context.TODO(), quotas, client.InNamespace(wpa.Namespace)); err != nil {
+		return 0, false, fmt.Errorf("unable to list the resource quotas: %v", err)
+	}
+	if len(quotas.Items) == 0 {
+		return 0, false, nil
+	}
+
+	selector, err := labels.Parse(scale.Status.Selector)
+	if err != nil {
+		return 0, false, fmt.Errorf("could not parse the labels of the target: %v", err)
+	}
+	pods, err := r.podLister.Pods(wpa.Namespace).List(selector)
+	if err != nil {
+		return 0, false, fmt.Errorf("unable to list the pods of the target: %v", err)
+	}
+	if len(pods) == 0 {
+		return 0, false, nil
+	}
+
+	additional, limited := additionalReplicasAllowedByQuotas(quotas.Items, pods)
+	if !limited {
+		return 0, false, nil
+	}
+	maxReplicas := int64(scale.Status.Replicas) + int64(additional)
+	if maxReplicas > math.MaxInt32 {
+		maxReplicas = math.MaxInt32
+	}
+	return int32(maxReplicas), true, nil
+}
+
+// additionalReplicasAllowedByQuotas returns how many pods similar to the given ones can still be admitted given the
+// remaining capacity of the quotas, each new pod being assumed to consume as much as the largest of the pods matched
+// by the scopes of the quota. The boolean is false if no quota restricts these pods.
+func additionalReplicasAllowedByQuotas(quotas []corev1.ResourceQuota, pods []*corev1.Pod) (int32, bool) {
+	allowed := int64(math.MaxInt32)
+	limited := false
+	for i := range quotas {
+		quota := &quotas[i]
+		var matching []*corev1.Pod
+		for _, pod := range pods {
+			if quotaMatchesPod(quota, pod) {
+				matching = append(matching, pod)
+			}
+		}
+		for name, hard := range quota.Status.Hard {
+			var perPod int64
+			for _, pod := range matching {
+				if usage := podQuotaUsage(pod, name); usage > perPod {
+					perPod = usage
+				}
+			}
+			if perPod <= 0 {
+				continue
+			}
+			remaining := hard.MilliValue()
+			if used, found := quota.Status.Used[name]; found {
+				remaining -= used.MilliValue()
+			}
+			if remaining < 0 {
+				remaining = 0
+			}
+			if remaining/perPod < allowed {
+				allowed = remaining / perPod
+			}
+			limited = true
+		}
+	}
+	return int32(allowed), limited
+}
+
+// quotaMatchesPod returns whether the pod is tracked by the quota given its scopes and scopeSelector, which are
+// all required to match. The quotas with a scope unknown to the controller are considered to match, so that the
+// upscale is not let through a quota that may apply.
+func quotaMatchesPod(quota *corev1.ResourceQuota, pod *corev1.Pod) bool {
+	for _, scope := range quota.Spec.Scopes {
+		if !scopeMatchesPod(corev1.ScopedResourceSelectorRequirement{ScopeName: scope, Operator: corev1.ScopeSelectorOpExists}, pod) {
+			return false
+		}
+	}
+	if quota.Spec.ScopeSelector != nil {
+		for _, requirement := range quota.Spec.ScopeSelector.MatchExpressions {
+			if !scopeMatchesPod(requirement, pod) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func scopeMatchesPod(requirement corev1.ScopedResourceSelectorRequirement, pod *corev1.Pod) bool {
+	switch requirement.ScopeName {
+	case corev1.ResourceQuotaScopeTerminating:
+		return pod.Spec.ActiveDeadlineSeconds != nil && *pod.Spec.ActiveDeadlineSeconds >= 0
+	case corev1.ResourceQuotaScopeNotTerminating:
+		return pod.Spec.ActiveDeadlineSeconds == nil || *pod.Spec.ActiveDeadlineSeconds < 0
+	case corev1.ResourceQuotaScopeBestEffort:
+		return isBestEffortPod(pod)
+	case corev1.ResourceQuotaScopeNotBestEffort:
+		return !isBestEffortPod(pod)
+	case corev1.ResourceQuotaScopePriorityClass:
+		switch requirement.Operator {
+		case corev1.ScopeSelectorOpIn, corev1.ScopeSelectorOpNotIn:
+			in := false
+			for _, value := range requirement.Values {
+				if value == pod.Spec.PriorityClassName {
+					in = true
+				}
+			}
+			return in == (requirement.Operator == corev1.ScopeSelectorOpIn)
+		case corev1.ScopeSelectorOpExists:
+			return pod.Spec.PriorityClassName != ""
+		case corev1.ScopeSelectorOpDoesNotExist:
+			return pod.Spec.PriorityClassName == ""
+		}
+	}
+	return true
+}
+
+// isBestEffortPod returns whether none of the containers of the pod have cpu or memory requests or limits.
+func isBestEffortPod(pod *corev1.Pod) bool {
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range containers {
+			for _, list := range []corev1.ResourceList{c.Resources.Requests, c.Resources.Limits} {
+				for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+					if q, found := list[name]; found && !q.IsZero() {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+// podQuotaUsage returns the amount (as a milli-value) of the quota resource consumed by the pod.
+func podQuotaUsage(pod *corev1.Pod, name corev1.ResourceName) int64 {
+	switch {
+	case name == corev1.ResourcePods || name == "count/pods":
+		return 1000
+	case strings.HasPrefix(string(name), "limits."):
+		return sumContainersResource(pod, corev1.ResourceName(strings.TrimPrefix(string(name), "limits.")), true)
+	case strings.HasPrefix(string(name), "requests."):
+		return sumContainersResource(pod, corev1.ResourceName(strings.TrimPrefix(string(name), "requests.")), false)
+	case name == corev1.ResourceCPU || name == corev1.ResourceMemory || name == corev1.ResourceEphemeralStorage:
+		return sumContainersResource(pod, name, false)
+	}
+	return 0
+}
+
+func sumContainersResource(pod *corev1.Pod, name corev1.ResourceName, limits bool) int64 {
+	var sum int64
+	for _, c := range pod.Spec.Containers {
+		list := c.Resources.Requests
+		if limits {
+			list = c.Resources.Limits
+		}
+		if q, found := list[name]; found {
+			sum += q.MilliValue()
+		}
+	}
+	return sum
+}
+
+// capDesiredReplicasWithResourceQuota makes sure the upscale does not go over what the ResourceQuotas can admit.
+func (r *ReconcileWatermarkPodAutoscaler) capDesiredReplicasWithResourceQuota(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+	maxReplicas, limited, err := r.getResourceQuotaMaxReplicas(wpa, scale)
+	if err != nil {
+		logger.Info("Could not compute the maximum number of replicas allowed by the resource quotas", "error", err)
+		return desiredReplicas
+	}
+	if !limited || desiredReplicas <= maxReplicas {
+		return desiredReplicas
+	}
+	// Never downscale because of a quota.
+	capped := int32(math.Max(float64(maxReplicas), float64(currentReplicas)))
+	setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, "LimitedByResourceQuota", "the desired replica count is above what the resource quotas of the namespace can admit (%d)", maxReplicas)
+	logger.Info("Capping the upscale to the number of replicas admitted by the resource quotas", "desiredReplicas", desiredReplicas, "quotaMaxReplicas", maxReplicas)
+	return capped
+}
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdditionalReplicasAllowedByQuotas(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+						Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+					},
+				},
+			},
+		},
+	}
+	largerPod := pod.DeepCopy()
+	largerPod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("1")
+	largerPod.Spec.PriorityClassName = "high"
+	tests := []struct {
+		name        string
+		quotas      []corev1.ResourceQuota
+		pods        []*corev1.Pod
+		want        int32
+		wantLimited bool
+	}{
+		{
+			name:        "no quota",
+			wantLimited: false,
+		},
+		{
+			name: "quota on a resource not used by the pods",
+			quotas: []corev1.ResourceQuota{
+				newResourceQuota(corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("4")}, nil),
+			},
+			wantLimited: false,
+		},
+		{
+			name: "quota on the cpu requests",
+			quotas: []corev1.ResourceQuota{
+				newResourceQuota(corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8")}),
+			},
+			want:        4,
+			wantLimited: true,
+		},
+		{
+			name: "most restrictive quota is used",
+			quotas: []corev1.ResourceQuota{
+				newResourceQuota(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}),
+				newResourceQuota(corev1.ResourceList{corev1.ResourceLimitsMemory: resource.MustParse("10Gi")}, corev1.ResourceList{corev1.ResourceLimitsMemory: resource.MustParse("8Gi")}),
+				newResourceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("20")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("17")}),
+			},
+			want:        2,
+			wantLimited: true,
+		},
+		{
+			name: "quota already exceeded",
+			quotas: []corev1.ResourceQuota{
+				newResourceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("12")}),
+			},
+			want:        0,
+			wantLimited: true,
+		},
+		{
+			name: "largest request of the pods is used",
+			quotas: []corev1.ResourceQuota{
+				newResourceQuota(corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8")}),
+			},
+			pods:        []*corev1.Pod{pod, largerPod},
+			want:        2,
+			wantLimited: true,
+		},
+		{
+			name: "scoped quota not matching the pods",
+			quotas: []corev1.ResourceQuota{
+				withQuotaScopes(newResourceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}), corev1.ResourceQuotaScopeBestEffort),
+				withQuotaScopes(newResourceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}), corev1.ResourceQuotaScopeTerminating),
+			},
+			wantLimited: false,
+		},
+		{
+			name: "scoped quota matching the pods",
+			quotas: []corev1.ResourceQuota{
+				withQuotaScopes(newResourceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("7")}), corev1.ResourceQuotaScopeNotBestEffort, corev1.ResourceQuotaScopeNotTerminating),
+			},
+			want:        3,
+			wantLimited: true,
+		},
+		{
+			name: "quota selecting a priority class",
+			quotas: []corev1.ResourceQuota{
+				withPriorityClassQuota(newResourceQuota(corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8")}), "high"),
+			},
+			pods:        []*corev1.Pod{pod, largerPod},
+			want:        2,
+			wantLimited: true,
+		},
+		{
+			name: "quota selecting another priority class",
+			quotas: []corev1.ResourceQuota{
+				withPriorityClassQuota(newResourceQuota(corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8")}), "low"),
+			},
+			pods:        []*corev1.Pod{pod, largerPod},
+			wantLimited: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := tt.pods
+			if pods == nil {
+				pods = []*corev1.Pod{pod}
+			}
+			got, limited := additionalReplicasAllowedByQuotas(tt.quotas, pods)
+			assert.Equal(t, tt.wantLimited, limited)
+			if tt.wantLimited {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func newResourceQuota(hard, used corev1.ResourceList) corev1.ResourceQuota {
+	return corev1.ResourceQuota{
+		Status: corev1.ResourceQuotaStatus{
+			Hard: hard,
+			Used: used,
+		},
+	}
+}
+
+func withQuotaScopes(quota corev1.ResourceQuota, scopes ...corev1.ResourceQuotaScope) corev1.ResourceQuota {
+	quota.Spec.Scopes = scopes
+	return quota
+}
+
+func withPriorityClassQuota(quota corev1.ResourceQuota, priorityClasses ...string) corev1.ResourceQuota {
+	quota.Spec.ScopeSelector = &corev1.ScopeSelector{
+		MatchExpressions: []corev1.ScopedResourceSelectorRequirement{
+			{ScopeName: corev1.ResourceQuotaScopePriorityClass, Operator: corev1.ScopeSelectorOpIn, Values: priorityClasses},
+		},
+	}
+	return quota
+}
+
+func TestGetResourceQuotaMaxReplicas(t *testing.T) {
+	quota := newResourceQuota(corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1T")}, nil)
+	quota.ObjectMeta = metav1.ObjectMeta{Namespace: testingNamespace, Name: "quota"}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "pod", Labels: map[string]string{"app": "test"}}}))
+	r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClient(&quota), podLister: corelisters.NewPodLister(indexer)}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+
+	maxReplicas, limited, err := r.getResourceQuotaMaxReplicas(wpa, &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Replicas: 5, Selector: "app=test"}})
+	assert.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, int32(math.MaxInt32), maxReplicas, "the replicas are clamped instead of overflowing")
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// restMapperResetInterval is the minimum interval between two resets of the RESTMapper, so that the WPAs whose
+// target doesn't exist don't trigger a discovery of all the APIs at every reconcile.
+const restMapperResetInterval = 30 * time.Second
+
+// resettableRESTMapper is implemented by the deferred discovery RESTMapper, whose cached discovery can be invalidated.
+type resettableRESTMapper interface {
+	Reset()
+}
+
+// restMapperResetter invalidates the discovery of the RESTMapper when a mapping is likely stale, typically when
+// the CRD of a target was installed after the controller started.
+type restMapperResetter struct {
+	mu        sync.Mutex
+	mapper    resettableRESTMapper
+	interval  time.Duration
+	lastReset time.Time
+}
+
+func newRESTMapperResetter(mapper resettableRESTMapper, interval time.Duration) *restMapperResetter {
+	return &restMapperResetter{mapper: mapper, interval: interval}
+}
+
+// reset returns whether the RESTMapper was reset, it is not when it was already reset during the interval.
+func (m *restMapperResetter) reset(now time.Time) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.lastReset.IsZero() && now.Sub(m.lastReset) < m.interval {
+		return false
+	}
+	m.lastReset = now
+	m.mapper.Reset()
+	return true
+}
+
+// getScaleForGroupKind resolves the mappings of the kind of the target before fetching its scale. When the kind
+// is unknown, or the scale of the target is not found, the RESTMapper is reset and the resolution retried once.
+func (r *ReconcileWatermarkPodAutoscaler) getScaleForGroupKind(namespace, name string, targetGK schema.GroupKind) (*autoscalingv1.Scale, schema.GroupResource, error) {
+	scale, targetGR, stale, err := r.tryGetScaleForGroupKind(namespace, name, targetGK)
+	if scale != nil || !stale || !r.mapperResetter.reset(time.Now()) {
+		return scale, targetGR, err
+	}
+	log.Info("Reset the RESTMapper, the mapping of the target may be stale", "kind", targetGK.String(), "namespace", namespace, "name", name, "error", err)
+	scale, targetGR, _, err = r.tryGetScaleForGroupKind(namespace, name, targetGK)
+	return scale, targetGR, err
+}
+
+// tryGetScaleForGroupKind also returns whether the failure may come from a stale mapping.
+func (r *ReconcileWatermarkPodAutoscaler) tryGetScaleForGroupKind(namespace, name string, targetGK schema.GroupKind) (*autoscalingv1.Scale, schema.GroupResource, bool, error) {
+	mappings, err := r.restMapper.RESTMappings(targetGK)
+	if err != nil {
+		return nil, schema.GroupResource{}, apimeta.IsNoMatchError(err), fmt.Errorf("unable to determine resource for scale target reference: %v", err)
+	}
+	scale, targetGR, notFound, err := r.getScaleForResourceMappings(namespace, name, mappings)
+	return scale, targetGR, scale == nil && notFound, err
+}
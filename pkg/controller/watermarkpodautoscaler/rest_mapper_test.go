@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakescale "k8s.io/client-go/scale/fake"
+	core "k8s.io/client-go/testing"
+)
+
+// staleRESTMapper doesn't know any kind until it is reset.
+type staleRESTMapper struct {
+	apimeta.RESTMapper
+	resets int
+}
+
+func (m *staleRESTMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*apimeta.RESTMapping, error) {
+	if m.resets == 0 {
+		return nil, &apimeta.NoKindMatchError{GroupKind: gk}
+	}
+	return m.RESTMapper.RESTMappings(gk, versions...)
+}
+
+func (m *staleRESTMapper) Reset() {
+	m.resets++
+}
+
+func TestGetScaleForGroupKind(t *testing.T) {
+	scaleClient := &fakescale.FakeScaleClient{}
+	scaleClient.AddReactor("get", "deployments", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &autoscalingv1.Scale{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: testingNamespace}, Status: autoscalingv1.ScaleStatus{Replicas: 3}}, nil
+	})
+	mapper := &staleRESTMapper{RESTMapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)}
+	r := &ReconcileWatermarkPodAutoscaler{scaleClient: scaleClient, restMapper: mapper}
+	deployments := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	_, _, err := r.getScaleForGroupKind(testingNamespace, "foo", deployments)
+	require.Error(t, err, "the RESTMapper is not reset without a resetter")
+
+	r.mapperResetter = newRESTMapperResetter(mapper, time.Minute)
+	scale, targetGR, err := r.getScaleForGroupKind(testingNamespace, "foo", deployments)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), scale.Status.Replicas)
+	require.Equal(t, schema.GroupResource{Group: "apps", Resource: "deployments"}, targetGR)
+	require.Equal(t, 1, mapper.resets)
+
+	_, _, err = r.getScaleForGroupKind(testingNamespace, "foo", schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"})
+	require.Error(t, err)
+	require.Equal(t, 1, mapper.resets, "the RESTMapper is reset at most once per interval")
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// isRolloutInProgress returns whether the target of the WPA is a Deployment or a DeploymentConfig with a rollout in progress.
+// If it is, the AbleToScale condition of the WPA is updated to reflect that scaling is frozen.
+func (r *ReconcileWatermarkPodAutoscaler) isRolloutInProgress(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) bool {
+	deploy := &appsv1.Deployment{}
+	switch wpa.Spec.ScaleTargetRef.Kind {
+	case "Deployment":
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Spec.ScaleTargetRef.Name}, deploy); err != nil {
+			logger.Error(err, "Could not get the target Deployment")
+			return false
+		}
+	case deploymentConfigKind:
+		dc, err := r.getDeploymentConfig(wpa.Namespace, wpa.Spec.ScaleTargetRef.Name)
+		if err != nil {
+			logger.Error(err, "Could not get the target DeploymentConfig")
+			return false
+		}
+		deploy = deploymentConfigAsDeployment(dc)
+	default:
+		return false
+	}
+	if !isDeploymentRollingOut(deploy) {
+		return false
+	}
+	setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "RolloutInProgress", "the target is rolling out, scaling is frozen until it stabilizes")
+	logger.Info("Freezing scaling while the target is rolling out", "replicas", deploy.Status.Replicas, "updatedReplicas", deploy.Status.UpdatedReplicas)
+	return true
+}
+
+// isDeploymentRollingOut returns whether the latest spec of the Deployment is not observed yet, or some of its
+// replicas are not updated yet. The availability of the replicas is not considered: the new replicas of an upscale,
+// or a crashlooping pod, are not a rollout. A rollout that exceeded its progress deadline is over, so that a failed
+// rollout doesn't freeze the scaling until it is fixed.
+func isDeploymentRollingOut(deploy *appsv1.Deployment) bool {
+	if deploy.Generation > deploy.Status.ObservedGeneration {
+		return true
+	}
+	for _, condition := range deploy.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse {
+			return false
+		}
+	}
+	if deploy.Spec.Replicas != nil && deploy.Status.UpdatedReplicas < *deploy.Spec.Replicas {
+		return true
+	}
+	// old replicas are pending termination
+	return deploy.Status.Replicas > deploy.Status.UpdatedReplicas
+}
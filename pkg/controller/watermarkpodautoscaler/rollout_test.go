@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestIsDeploymentRollingOut(t *testing.T) {
+	progressing := func(status corev1.ConditionStatus, reason string) []appsv1.DeploymentCondition {
+		return []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Status: status, Reason: reason}}
+	}
+	tests := []struct {
+		name   string
+		status appsv1.DeploymentStatus
+		want   bool
+	}{
+		{
+			name:   "stable",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			want:   false,
+		},
+		{
+			name:   "spec not observed yet",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+			want:   true,
+		},
+		{
+			name:   "replicas not updated yet",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 3, Conditions: progressing(corev1.ConditionTrue, "ReplicaSetUpdated")},
+			want:   true,
+		},
+		{
+			name:   "old replicas pending termination",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 4},
+			want:   true,
+		},
+		{
+			name:   "new replicas of an upscale not available yet",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 1, UnavailableReplicas: 2},
+			want:   false,
+		},
+		{
+			name:   "crashlooping replica",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2, UnavailableReplicas: 1, Conditions: progressing(corev1.ConditionTrue, "NewReplicaSetAvailable")},
+			want:   false,
+		},
+		{
+			name:   "progress deadline exceeded",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3, Conditions: progressing(corev1.ConditionFalse, "ProgressDeadlineExceeded")},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deploy := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: getReplicas(3)},
+				Status:     tt.status,
+			}
+			require.Equal(t, tt.want, isDeploymentRollingOut(deploy))
+		})
+	}
+}
+
+func TestIsRolloutInProgress(t *testing.T) {
+	deploy := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "app", Generation: 2},
+		Spec:       appsv1.DeploymentSpec{Replicas: getReplicas(3)},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 2},
+	}
+	r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClientWithScheme(scheme.Scheme, deploy)}
+	newWPA := func(kind, name string) *v1alpha1.WatermarkPodAutoscaler {
+		return test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+				ScaleTargetRef:      v1alpha1.CrossVersionObjectReference{Kind: kind, Name: name, APIVersion: "apps/v1"},
+				FreezeDuringRollout: true,
+			},
+		})
+	}
+	logger := logf.Log.WithName(t.Name())
+
+	wpa := newWPA("Deployment", "app")
+	require.True(t, r.isRolloutInProgress(logger, wpa))
+	require.False(t, isConditionTrue(wpa, autoscalingv2.AbleToScale))
+	require.Equal(t, "RolloutInProgress", wpa.Status.Conditions[0].Reason)
+
+	require.False(t, r.isRolloutInProgress(logger, newWPA("Deployment", "missing")), "a missing target is not rolling out")
+	require.False(t, r.isRolloutInProgress(logger, newWPA("StatefulSet", "app")), "only the Deployments roll out")
+}
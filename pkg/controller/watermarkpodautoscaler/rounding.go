@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"math"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+)
+
+// roundReplicas rounds a fractional number of replicas with the mode, or with the default one of the direction of
+// the scale if it is not set.
+func roundReplicas(mode, defaultMode v1alpha1.RoundingMode, replicas float64) int32 {
+	if mode == "" {
+		mode = defaultMode
+	}
+	switch mode {
+	case v1alpha1.RoundingModeFloor:
+		return int32(math.Floor(replicas))
+	case v1alpha1.RoundingModeRound:
+		return int32(math.Round(replicas))
+	default:
+		return int32(math.Ceil(replicas))
+	}
+}
+
+// roundingOf returns the rounding spec of the WPA, the zero value applying the default modes without bias.
+func roundingOf(wpa *v1alpha1.WatermarkPodAutoscaler) v1alpha1.RoundingSpec {
+	if wpa.Spec.Rounding == nil {
+		return v1alpha1.RoundingSpec{}
+	}
+	return *wpa.Spec.Rounding
+}
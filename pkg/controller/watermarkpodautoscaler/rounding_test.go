@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestRoundReplicas(t *testing.T) {
+	require.Equal(t, int32(11), roundReplicas("", v1alpha1.RoundingModeCeil, 10.2))
+	require.Equal(t, int32(10), roundReplicas("", v1alpha1.RoundingModeFloor, 10.8))
+	require.Equal(t, int32(10), roundReplicas(v1alpha1.RoundingModeFloor, v1alpha1.RoundingModeCeil, 10.8))
+	require.Equal(t, int32(11), roundReplicas(v1alpha1.RoundingModeCeil, v1alpha1.RoundingModeFloor, 10.2))
+	require.Equal(t, int32(10), roundReplicas(v1alpha1.RoundingModeRound, v1alpha1.RoundingModeCeil, 10.4))
+	require.Equal(t, int32(11), roundReplicas(v1alpha1.RoundingModeRound, v1alpha1.RoundingModeFloor, 10.5))
+}
+
+func TestGetReplicaCountRounding(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	lowMark := resource.NewQuantity(40, resource.DecimalSI)
+	highMark := resource.NewQuantity(60, resource.DecimalSI)
+	tests := []struct {
+		name     string
+		rounding *v1alpha1.RoundingSpec
+		usage    float64
+		want     int32
+	}{
+		{
+			name:  "upscale rounded up by default",
+			usage: 64000,
+			want:  11,
+		},
+		{
+			name:  "downscale rounded down by default",
+			usage: 37000,
+			want:  9,
+		},
+		{
+			name:     "symmetric rounding of an upscale",
+			rounding: &v1alpha1.RoundingSpec{Upscale: v1alpha1.RoundingModeRound, Downscale: v1alpha1.RoundingModeRound},
+			usage:    64000,
+			want:     11,
+		},
+		{
+			name:     "symmetric rounding of a downscale",
+			rounding: &v1alpha1.RoundingSpec{Upscale: v1alpha1.RoundingModeRound, Downscale: v1alpha1.RoundingModeRound},
+			usage:    37000,
+			want:     9,
+		},
+		{
+			name:     "downscale rounded up",
+			rounding: &v1alpha1.RoundingSpec{Downscale: v1alpha1.RoundingModeCeil},
+			usage:    37000,
+			want:     10,
+		},
+		{
+			name:     "bias added to the upscale",
+			rounding: &v1alpha1.RoundingSpec{BiasReplicas: 2},
+			usage:    64000,
+			want:     13,
+		},
+		{
+			name:     "bias not added to the downscale",
+			rounding: &v1alpha1.RoundingSpec{BiasReplicas: 2},
+			usage:    37000,
+			want:     9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{Rounding: tt.rounding},
+			})
+			replicas, _ := getReplicaCount(logger, 10, wpa, "queue_length", tt.usage, 10, 0, lowMark, highMark, nil)
+			require.Equal(t, tt.want, replicas)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// scaleUpReplicas computes the replicas recommended for a usage above the high watermark, rounded with the mode,
+// up by default. The usage is in milli units, averaged over the given number of replicas.
+func scaleUpReplicas(strategy *v1alpha1.ScaleUpStrategySpec, mode v1alpha1.RoundingMode, currentReplicas int32, adjustedUsage, averaged float64, highMark *resource.Quantity) int32 {
+	if strategy == nil {
+		strategy = &v1alpha1.ScaleUpStrategySpec{Type: v1alpha1.ScaleUpStrategyProportional}
+	}
+	switch strategy.Type {
+	case v1alpha1.ScaleUpStrategyLinear:
+		excess := adjustedUsage - float64(highMark.MilliValue())
+		return currentReplicas + roundReplicas(mode, v1alpha1.RoundingModeCeil, excess/float64(strategy.UsagePerReplica.MilliValue()))
+	case v1alpha1.ScaleUpStrategyMultiplier:
+		return roundReplicas(mode, v1alpha1.RoundingModeCeil, float64(currentReplicas)*strategy.Multiplier)
+	case v1alpha1.ScaleUpStrategyBacklog:
+		// The backlog is the usage before it is averaged, each replica drains processingRate per second of it.
+		replicas := roundReplicas(mode, v1alpha1.RoundingModeCeil, adjustedUsage*averaged/(float64(strategy.ProcessingRate.MilliValue())*float64(strategy.DrainSeconds)))
+		if replicas < currentReplicas {
+			// The usage is above the high watermark, the target is not scaled down.
+			return currentReplicas
+		}
+		return replicas
+	default:
+		return roundReplicas(mode, v1alpha1.RoundingModeCeil, float64(currentReplicas)*adjustedUsage/float64(highMark.MilliValue()))
+	}
+}
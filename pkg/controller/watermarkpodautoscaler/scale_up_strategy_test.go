@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestScaleUpReplicas(t *testing.T) {
+	highMark := resource.NewQuantity(100, resource.DecimalSI)
+	tests := []struct {
+		name     string
+		strategy *v1alpha1.ScaleUpStrategySpec
+		usage    float64
+		averaged float64
+		want     int32
+	}{
+		{
+			name:     "proportional by default",
+			usage:    150000,
+			averaged: 1,
+			want:     15,
+		},
+		{
+			name:     "proportional",
+			strategy: &v1alpha1.ScaleUpStrategySpec{Type: v1alpha1.ScaleUpStrategyProportional},
+			usage:    250000,
+			averaged: 1,
+			want:     25,
+		},
+		{
+			name:     "linear in the excess over the high watermark",
+			strategy: &v1alpha1.ScaleUpStrategySpec{Type: v1alpha1.ScaleUpStrategyLinear, UsagePerReplica: resource.NewQuantity(20, resource.DecimalSI)},
+			usage:    150000,
+			averaged: 1,
+			want:     13,
+		},
+		{
+			name:     "fixed multiplier",
+			strategy: &v1alpha1.ScaleUpStrategySpec{Type: v1alpha1.ScaleUpStrategyMultiplier, Multiplier: 1.5},
+			usage:    101000,
+			averaged: 1,
+			want:     15,
+		},
+		{
+			name:     "backlog drained within the duration",
+			strategy: &v1alpha1.ScaleUpStrategySpec{Type: v1alpha1.ScaleUpStrategyBacklog, ProcessingRate: resource.NewQuantity(5, resource.DecimalSI), DrainSeconds: 60},
+			usage:    600000,
+			averaged: 10,
+			want:     20,
+		},
+		{
+			name:     "backlog not scaling down",
+			strategy: &v1alpha1.ScaleUpStrategySpec{Type: v1alpha1.ScaleUpStrategyBacklog, ProcessingRate: resource.NewQuantity(50, resource.DecimalSI), DrainSeconds: 60},
+			usage:    150000,
+			averaged: 10,
+			want:     10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, scaleUpReplicas(tt.strategy, "", 10, tt.usage, tt.averaged, highMark))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// scalingMode returns the scaling mode of the WPA, the deprecated scaleDownDisabled being converted to UpOnly when
+// the WPA is defaulted.
+func scalingMode(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec) datadoghqv1alpha1.ScalingMode {
+	if spec.ScalingMode == "" {
+		return datadoghqv1alpha1.ScalingModeBoth
+	}
+	return spec.ScalingMode
+}
+
+// capDesiredReplicasWithScalingMode keeps the current replicas instead of scaling the target in a direction
+// suppressed by the scaling mode, and reports the suppressed recommendation in the status and the metrics.
+func capDesiredReplicasWithScalingMode(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas int32) int32 {
+	promLabels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+	mode := scalingMode(&wpa.Spec)
+	var reason, direction string
+	switch {
+	case mode == datadoghqv1alpha1.ScalingModeBoth:
+		suppressedReplicas.Delete(promLabels)
+		return desiredReplicas
+	case mode == datadoghqv1alpha1.ScalingModeUpOnly && desiredReplicas < currentReplicas:
+		reason, direction = "ScaleDownDisabled", "downscales"
+	case mode == datadoghqv1alpha1.ScalingModeDownOnly && desiredReplicas > currentReplicas:
+		reason, direction = "ScaleUpDisabled", "upscales"
+	default:
+		suppressedReplicas.With(promLabels).Set(0)
+		return desiredReplicas
+	}
+	wpa.Status.SuppressedReplicas = desiredReplicas
+	suppressedReplicas.With(promLabels).Set(float64(desiredReplicas))
+	setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, reason, "the desired replica count is %d, but the %s are disabled", desiredReplicas, direction)
+	logger.Info("Skipping the rescale, its direction is disabled", "scalingMode", mode, "currentReplicas", currentReplicas, "desiredReplicas", desiredReplicas)
+	return currentReplicas
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestCapDesiredReplicasWithScalingMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           v1alpha1.WatermarkPodAutoscalerSpec
+		desired        int32
+		want           int32
+		wantReason     string
+		wantSuppressed int32
+	}{
+		{
+			name:    "both directions by default",
+			desired: 2,
+			want:    2,
+		},
+		{
+			name:    "upscale with UpOnly",
+			spec:    v1alpha1.WatermarkPodAutoscalerSpec{ScalingMode: v1alpha1.ScalingModeUpOnly},
+			desired: 8,
+			want:    8,
+		},
+		{
+			name:           "downscale with UpOnly",
+			spec:           v1alpha1.WatermarkPodAutoscalerSpec{ScalingMode: v1alpha1.ScalingModeUpOnly},
+			desired:        2,
+			want:           5,
+			wantReason:     "ScaleDownDisabled",
+			wantSuppressed: 2,
+		},
+		{
+			name:           "upscale with DownOnly",
+			spec:           v1alpha1.WatermarkPodAutoscalerSpec{ScalingMode: v1alpha1.ScalingModeDownOnly},
+			desired:        8,
+			want:           5,
+			wantReason:     "ScaleUpDisabled",
+			wantSuppressed: 8,
+		},
+		{
+			name:    "downscale with DownOnly",
+			spec:    v1alpha1.WatermarkPodAutoscalerSpec{ScalingMode: v1alpha1.ScalingModeDownOnly},
+			desired: 2,
+			want:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{Spec: &tt.spec})
+
+			require.Equal(t, tt.want, capDesiredReplicasWithScalingMode(logf.Log.WithName(tt.name), wpa, 5, tt.desired))
+			require.Equal(t, tt.wantSuppressed, wpa.Status.SuppressedReplicas)
+			if tt.wantReason == "" {
+				require.Empty(t, wpa.Status.Conditions)
+				return
+			}
+			require.Len(t, wpa.Status.Conditions, 1)
+			require.Equal(t, autoscalingv2.ScalingLimited, wpa.Status.Conditions[0].Type)
+			require.Equal(t, corev1.ConditionTrue, wpa.Status.Conditions[0].Status)
+			require.Equal(t, tt.wantReason, wpa.Status.Conditions[0].Reason)
+		})
+	}
+}
+
+func TestScaleDownDisabledConversion(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{ScaleTargetRef: testCrossVersionObjectRef, MaxReplicas: 5, ScaleDownDisabled: true},
+	})
+	require.Contains(t, v1alpha1.MissingDefaults(wpa), "scalingMode")
+	defaultWPA := v1alpha1.DefaultWatermarkPodAutoscaler(wpa)
+	require.Equal(t, v1alpha1.ScalingModeUpOnly, scalingMode(&defaultWPA.Spec))
+	require.NotContains(t, v1alpha1.MissingDefaults(defaultWPA), "scalingMode")
+
+	wpa.Spec.ScalingMode = v1alpha1.ScalingModeDownOnly
+	require.NotContains(t, v1alpha1.MissingDefaults(wpa), "scalingMode")
+	require.NoError(t, v1alpha1.CheckWPAValidity(v1alpha1.DefaultWatermarkPodAutoscaler(wpa)), "the deprecated field should not conflict with the scaling mode")
+	require.Equal(t, v1alpha1.ScalingModeDownOnly, scalingMode(&v1alpha1.DefaultWatermarkPodAutoscaler(wpa).Spec))
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	scalingPlanCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "ScalingPlan"
+)
+
+// applyScalingPlans overrides the replicas of the WPA with the active step of the ScalingPlans referencing it.
+// The spec of the WPA is only updated in memory.
+func (r *ReconcileWatermarkPodAutoscaler) applyScalingPlans(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, now time.Time) {
+	plans := &datadoghqv1alpha1.ScalingPlanList{}
+	if err := r.client.List(context.TODO(), plans, client.InNamespace(wpa.Namespace)); err != nil {
+		logger.Error(err, "Could not list the ScalingPlans")
+		return
+	}
+	plan, index := activeScalingPlanStep(wpa, plans.Items, now)
+	if plan == nil {
+		if isConditionTrue(wpa, scalingPlanCondition) {
+			setCondition(wpa, scalingPlanCondition, corev1.ConditionFalse, "NoActiveStep", "no step of a scaling plan applies to the WPA")
+		}
+		return
+	}
+	step := plan.Spec.Steps[index]
+	if step.Replicas == nil && step.MinReplicas == nil {
+		setCondition(wpa, scalingPlanCondition, corev1.ConditionFalse, "NoActiveStep", "the step %d of the plan %s hands the WPA back to its own spec", index, plan.Name)
+		return
+	}
+	applyScalingPlanStep(&wpa.Spec, step)
+	logger.Info("Applying the step of a scaling plan", "scalingPlan", plan.Name, "step", index, "replicas", step.Replicas, "minReplicas", step.MinReplicas)
+	setCondition(wpa, scalingPlanCondition, corev1.ConditionTrue, "StepApplied", "the step %d of the plan %s, started at %s, applies to the WPA", index, plan.Name, step.At.Format(time.RFC3339))
+}
+
+// activeScalingPlanStep returns the valid plan referencing the WPA whose active step started last, along with the
+// index of this step.
+func activeScalingPlanStep(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, plans []datadoghqv1alpha1.ScalingPlan, now time.Time) (*datadoghqv1alpha1.ScalingPlan, int) {
+	var active *datadoghqv1alpha1.ScalingPlan
+	activeIndex := -1
+	for i := range plans {
+		plan := &plans[i]
+		if plan.Spec.WPA != wpa.Name || datadoghqv1alpha1.CheckScalingPlanValidity(plan) != nil {
+			continue
+		}
+		index := plan.ActiveStep(now)
+		if index < 0 {
+			continue
+		}
+		if active == nil || active.Spec.Steps[activeIndex].At.Before(&plan.Spec.Steps[index].At) {
+			active, activeIndex = plan, index
+		}
+	}
+	return active, activeIndex
+}
+
+// applyScalingPlanStep overrides the replicas and the minReplicas of the spec with the ones of the step. A minReplicas
+// above the maxReplicas raises the maxReplicas as well.
+func applyScalingPlanStep(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, step datadoghqv1alpha1.ScalingPlanStep) {
+	if step.Replicas != nil {
+		spec.Replicas = datadoghqv1alpha1.NewInt32(*step.Replicas)
+	}
+	if step.MinReplicas != nil {
+		spec.MinReplicas = datadoghqv1alpha1.NewInt32(*step.MinReplicas)
+		if spec.MaxReplicas < *step.MinReplicas {
+			spec.MaxReplicas = *step.MinReplicas
+		}
+	}
+}
+
+// requestsForScalingPlan returns a mapping function enqueueing the WPA of the ScalingPlan that changed.
+func requestsForScalingPlan() handler.ToRequestsFunc {
+	return func(a handler.MapObject) []reconcile.Request {
+		plan, ok := a.Object.(*datadoghqv1alpha1.ScalingPlan)
+		if !ok || plan.Spec.WPA == "" {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: plan.Namespace, Name: plan.Spec.WPA}}}
+	}
+}
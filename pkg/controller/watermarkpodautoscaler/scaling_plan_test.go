@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestApplyScalingPlans(t *testing.T) {
+	now := time.Date(2020, 11, 27, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) metav1.Time { return metav1.NewTime(now.Add(d)) }
+	newPlan := func(name, wpa string, steps ...v1alpha1.ScalingPlanStep) *v1alpha1.ScalingPlan {
+		return &v1alpha1.ScalingPlan{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: name},
+			Spec:       v1alpha1.ScalingPlanSpec{WPA: wpa, Steps: steps},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		plans           []runtime.Object
+		wantReplicas    *int32
+		wantMinReplicas int32
+		wantMaxReplicas int32
+		wantCondition   corev1.ConditionStatus
+		wantMessage     string
+	}{
+		{
+			name:            "no plan",
+			wantMinReplicas: 2,
+			wantMaxReplicas: 10,
+		},
+		{
+			name: "minReplicas above the maxReplicas",
+			plans: []runtime.Object{
+				newPlan("launch", "foo", v1alpha1.ScalingPlanStep{At: at(-time.Hour), MinReplicas: v1alpha1.NewInt32(20)}),
+			},
+			wantMinReplicas: 20,
+			wantMaxReplicas: 20,
+			wantCondition:   corev1.ConditionTrue,
+			wantMessage:     "the step 0 of the plan launch, started at 2020-11-27T11:00:00Z, applies to the WPA",
+		},
+		{
+			name: "the step started last applies",
+			plans: []runtime.Object{
+				newPlan("launch", "foo", v1alpha1.ScalingPlanStep{At: at(-2 * time.Hour), MinReplicas: v1alpha1.NewInt32(5)}),
+				newPlan("sale", "foo",
+					v1alpha1.ScalingPlanStep{At: at(-3 * time.Hour), MinReplicas: v1alpha1.NewInt32(4)},
+					v1alpha1.ScalingPlanStep{At: at(-time.Hour), Replicas: v1alpha1.NewInt32(8)},
+					v1alpha1.ScalingPlanStep{At: at(time.Hour)},
+				),
+				newPlan("other", "bar", v1alpha1.ScalingPlanStep{At: at(-time.Minute), Replicas: v1alpha1.NewInt32(1)}),
+			},
+			wantReplicas:    v1alpha1.NewInt32(8),
+			wantMinReplicas: 2,
+			wantMaxReplicas: 10,
+			wantCondition:   corev1.ConditionTrue,
+			wantMessage:     "the step 1 of the plan sale, started at 2020-11-27T11:00:00Z, applies to the WPA",
+		},
+		{
+			name: "empty step",
+			plans: []runtime.Object{
+				newPlan("sale", "foo",
+					v1alpha1.ScalingPlanStep{At: at(-3 * time.Hour), Replicas: v1alpha1.NewInt32(8)},
+					v1alpha1.ScalingPlanStep{At: at(-time.Hour)},
+				),
+			},
+			wantMinReplicas: 2,
+			wantMaxReplicas: 10,
+			wantCondition:   corev1.ConditionFalse,
+			wantMessage:     "the step 1 of the plan sale hands the WPA back to its own spec",
+		},
+		{
+			name: "invalid and future plans are ignored",
+			plans: []runtime.Object{
+				newPlan("unordered", "foo",
+					v1alpha1.ScalingPlanStep{At: at(-time.Hour), Replicas: v1alpha1.NewInt32(8)},
+					v1alpha1.ScalingPlanStep{At: at(-3 * time.Hour), Replicas: v1alpha1.NewInt32(4)},
+				),
+				newPlan("future", "foo", v1alpha1.ScalingPlanStep{At: at(time.Hour), Replicas: v1alpha1.NewInt32(8)}),
+			},
+			wantMinReplicas: 2,
+			wantMaxReplicas: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := runtime.NewScheme()
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.ScalingPlan{}, &v1alpha1.ScalingPlanList{})
+			r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClientWithScheme(s, tt.plans...)}
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, "foo", &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{MinReplicas: v1alpha1.NewInt32(2), MaxReplicas: 10},
+			})
+			r.applyScalingPlans(logf.Log, wpa, now)
+
+			require.Equal(t, tt.wantReplicas, wpa.Spec.Replicas)
+			require.Equal(t, tt.wantMinReplicas, *wpa.Spec.MinReplicas)
+			require.Equal(t, tt.wantMaxReplicas, wpa.Spec.MaxReplicas)
+			if tt.wantCondition == "" {
+				require.Empty(t, wpa.Status.Conditions)
+				return
+			}
+			require.Len(t, wpa.Status.Conditions, 1)
+			require.Equal(t, scalingPlanCondition, wpa.Status.Conditions[0].Type)
+			require.Equal(t, tt.wantCondition, wpa.Status.Conditions[0].Status)
+			require.Equal(t, tt.wantMessage, wpa.Status.Conditions[0].Message)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// shard is the target of a sharded workload in one of the other clusters it runs in.
+type shard struct {
+	secret      string
+	reconciler  *ReconcileWatermarkPodAutoscaler
+	replicaCalc *ReplicaCalculator
+	scale       *autoscalingv1.Scale
+	targetGR    schema.GroupResource
+	weight      int32
+}
+
+// getShards fetches the scales of the targets of the other clusters the workload of the WPA is sharded across.
+func (r *ReconcileWatermarkPodAutoscaler) getShards(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, targetGK schema.GroupKind) ([]*shard, error) {
+	if wpa.Spec.Shards == nil {
+		return nil, nil
+	}
+	if r.remoteClusters == nil {
+		return nil, fmt.Errorf("the shards are not enabled on this controller, see --enable-remote-clusters")
+	}
+	shards := make([]*shard, 0, len(wpa.Spec.Shards.Clusters))
+	for _, spec := range wpa.Spec.Shards.Clusters {
+		secret := fmt.Sprintf("%s/%s", wpa.Namespace, spec.KubeconfigSecretRef.Name)
+		cluster, err := r.remoteClusters.get(r.secretReader(), types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}, spec.KubeconfigSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		remote := r.withCluster(cluster)
+		scale, targetGR, err := remote.getScaleForGroupKind(wpa.Namespace, wpa.Spec.ScaleTargetRef.Name, targetGK)
+		if scale == nil {
+			return nil, fmt.Errorf("unable to get the scale of the shard of the cluster of the Secret %s: %v", secret, err)
+		}
+		shards = append(shards, &shard{
+			secret:      secret,
+			reconciler:  remote,
+			replicaCalc: cluster.replicaCalc,
+			scale:       scale,
+			targetGR:    targetGR,
+			weight:      shardWeight(spec.Weight),
+		})
+	}
+	return shards, nil
+}
+
+// shardedScale returns the scale of the whole workload, whose replicas are the sum of the replicas of the targets.
+func shardedScale(scale *autoscalingv1.Scale, shards []*shard) *autoscalingv1.Scale {
+	if len(shards) == 0 {
+		return scale
+	}
+	total := scale.DeepCopy()
+	for _, s := range shards {
+		total.Spec.Replicas += s.scale.Spec.Replicas
+		total.Status.Replicas += s.scale.Status.Replicas
+	}
+	return total
+}
+
+// shardedReplicaCalculator aggregates the ready pods and the external metrics of the shards with the ones of the
+// cluster of the scaleTargetRef.
+type shardedReplicaCalculator struct {
+	*ReplicaCalculator
+	shards []*ReplicaCalculator
+}
+
+// withShards returns a copy of the reconciler computing the replicas of the whole workload, the reconciler is
+// returned as is when there are no shards.
+func (r *ReconcileWatermarkPodAutoscaler) withShards(shards []*shard) (*ReconcileWatermarkPodAutoscaler, error) {
+	if len(shards) == 0 {
+		return r, nil
+	}
+	local, ok := r.replicaCalc.(*ReplicaCalculator)
+	if !ok {
+		return nil, fmt.Errorf("the shards are not supported by the replica calculator %T", r.replicaCalc)
+	}
+	calc := &shardedReplicaCalculator{ReplicaCalculator: local}
+	for _, s := range shards {
+		calc.shards = append(calc.shards, s.replicaCalc)
+	}
+	sharded := *r
+	sharded.replicaCalc = calc
+	return &sharded, nil
+}
+
+// GetExternalMetricReplicas computes the replicas of the whole workload.
+func (c *shardedReplicaCalculator) GetExternalMetricReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric datadoghqv1alpha1.MetricSpec, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) (ReplicaCalculation, error) {
+	return c.getExternalMetricReplicas(ctx, logger, target, metric, wpa, c.shards)
+}
+
+// scaleShards scales the targets of the shards to their share of the replicas. Failing to scale one of them does not
+// prevent the others from being scaled.
+func (r *ReconcileWatermarkPodAutoscaler) scaleShards(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, shards []*shard, replicas []int32) {
+	for i, s := range shards {
+		if s.scale.Spec.Replicas == replicas[i] {
+			continue
+		}
+		if err := s.reconciler.checkTargetOwnership(wpa, wpa.Spec.ScaleTargetRef); err != nil {
+			r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "TargetNotOwned", "Shard of the cluster of the Secret %s: %v", s.secret, err)
+			logger.Info("Not allowed to scale a shard", "secret", s.secret, "error", err)
+			continue
+		}
+		s.scale.Spec.Replicas = replicas[i]
+		if _, err := s.reconciler.scaleClient.Scales(wpa.Namespace).Update(s.targetGR, s.scale); err != nil {
+			r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedRescale", "New size of the shard of the cluster of the Secret %s: %d; error: %v", s.secret, replicas[i], err)
+			logger.Info("Could not scale a shard", "secret", s.secret, "error", err)
+			continue
+		}
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "SuccessfulRescale", "New size of the shard of the cluster of the Secret %s: %d", s.secret, replicas[i])
+		logger.Info("Successful rescale of a shard", "secret", s.secret, "desiredReplicas", replicas[i])
+	}
+}
+
+// shardWeights returns the weights of the scaleTargetRef and of the shards, in this order.
+func shardWeights(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, shards []*shard) []int32 {
+	weights := []int32{shardWeight(wpa.Spec.Shards.Weight)}
+	for _, s := range shards {
+		weights = append(weights, s.weight)
+	}
+	return weights
+}
+
+func shardWeight(weight int32) int32 {
+	if weight < 1 {
+		return 1
+	}
+	return weight
+}
+
+// splitReplicas splits the replicas across the targets proportionally to their weight. Each target keeps at least
+// one replica when there are enough of them, and the remainder goes to the targets with the largest fractional
+// shares so that the sum of the split is the number of replicas.
+func splitReplicas(replicas int32, weights []int32) []int32 {
+	split := make([]int32, len(weights))
+	if len(weights) == 0 || replicas <= 0 {
+		return split
+	}
+	remaining := int64(replicas)
+	if remaining >= int64(len(weights)) {
+		for i := range split {
+			split[i] = 1
+		}
+		remaining -= int64(len(weights))
+	}
+	var total int64
+	for _, w := range weights {
+		total += int64(w)
+	}
+	remainders := make([]int64, len(weights))
+	assigned := int64(0)
+	for i, w := range weights {
+		share := remaining * int64(w)
+		split[i] += int32(share / total)
+		remainders[i] = share % total
+		assigned += share / total
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; assigned < remaining; i++ {
+		split[order[i]]++
+		assigned++
+	}
+	return split
+}
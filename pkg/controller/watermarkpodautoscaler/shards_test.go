@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestSplitReplicas(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		weights  []int32
+		want     []int32
+	}{
+		{
+			name:     "even split",
+			replicas: 9,
+			weights:  []int32{1, 1, 1},
+			want:     []int32{3, 3, 3},
+		},
+		{
+			name:     "remainder to the largest fractional shares",
+			replicas: 10,
+			weights:  []int32{1, 1, 1},
+			want:     []int32{4, 3, 3},
+		},
+		{
+			name:     "weighted split",
+			replicas: 12,
+			weights:  []int32{2, 1},
+			want:     []int32{8, 4},
+		},
+		{
+			name:     "every target keeps a replica",
+			replicas: 3,
+			weights:  []int32{10, 1, 1},
+			want:     []int32{1, 1, 1},
+		},
+		{
+			name:     "fewer replicas than targets",
+			replicas: 2,
+			weights:  []int32{1, 3, 1},
+			want:     []int32{1, 1, 0},
+		},
+		{
+			name:     "no replicas",
+			replicas: 0,
+			weights:  []int32{1, 1},
+			want:     []int32{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitReplicas(tt.replicas, tt.weights)
+			require.Equal(t, tt.want, got)
+			sum := int32(0)
+			for _, replicas := range got {
+				sum += replicas
+			}
+			require.Equal(t, tt.replicas, sum)
+		})
+	}
+}
+
+func TestShardedScale(t *testing.T) {
+	local := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 3}, Status: autoscalingv1.ScaleStatus{Replicas: 2, Selector: "name=test-pod"}}
+	require.Equal(t, local, shardedScale(local, nil))
+
+	shards := []*shard{
+		{scale: &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 4}, Status: autoscalingv1.ScaleStatus{Replicas: 4}}},
+		{scale: &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 1}, Status: autoscalingv1.ScaleStatus{Replicas: 1}}},
+	}
+	total := shardedScale(local, shards)
+	require.Equal(t, int32(8), total.Spec.Replicas)
+	require.Equal(t, int32(7), total.Status.Replicas)
+	require.Equal(t, "name=test-pod", total.Status.Selector)
+	require.Equal(t, int32(3), local.Spec.Replicas, "the scale of the scaleTargetRef should not be modified")
+}
+
+func TestShardedExternalMetricReplicas(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	metric := v1alpha1.MetricSpec{
+		Type: v1alpha1.ExternalMetricSourceType,
+		External: &v1alpha1.ExternalMetricSource{
+			MetricName:     "loadbalancer.request.per.seconds",
+			MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			HighWatermark:  resource.NewMilliQuantity(85000, resource.DecimalSI),
+			LowWatermark:   resource.NewMilliQuantity(75000, resource.DecimalSI),
+		},
+	}
+	wpa := &v1alpha1.WatermarkPodAutoscaler{
+		Spec: v1alpha1.WatermarkPodAutoscalerSpec{
+			Algorithm: "average",
+			Tolerance: 0.01,
+			Metrics:   []v1alpha1.MetricSpec{metric},
+		},
+	}
+	local := &replicaCalcTestCase{
+		scale:  makeScale(3, map[string]string{"name": "test-pod"}),
+		metric: &metricInfo{spec: metric, levels: []int64{150000}},
+	}
+	remote := &replicaCalcTestCase{
+		scale:  makeScale(2, map[string]string{"name": "test-pod"}),
+		metric: &metricInfo{spec: metric, levels: []int64{220000}},
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	calc := &shardedReplicaCalculator{
+		ReplicaCalculator: local.newSyncedReplicaCalculator(t, stop),
+		shards:            []*ReplicaCalculator{remote.newSyncedReplicaCalculator(t, stop)},
+	}
+	total := shardedScale(local.scale, []*shard{{scale: remote.scale}})
+	replicaCalculation, err := calc.GetExternalMetricReplicas(context.TODO(), logf.Log, total, metric, wpa)
+	require.NoError(t, err)
+	// (150 + 220) / 5 = 74 is below the low watermark, the 5 replicas of the workload are scaled down to 4.
+	require.Equal(t, int32(4), replicaCalculation.replicaCount)
+	require.Equal(t, int64(74000), replicaCalculation.utilization)
+}
+
+func (tc *replicaCalcTestCase) newSyncedReplicaCalculator(t *testing.T, stop chan struct{}) *ReplicaCalculator {
+	tc.timestamp = time.Now()
+	informerFactory := informers.NewSharedInformerFactory(tc.prepareTestClientSet(), 0)
+	informer := informerFactory.Core().V1().Pods()
+	calc := NewReplicaCalculator(NewRESTMetricsClient(tc.getFakeResourceClient().MetricsV1beta1(), nil, tc.getFakeEMClient(t)), informer.Lister(), nil)
+	informerFactory.Start(stop)
+	require.True(t, cache.WaitForNamedCacheSync("HPA", stop, informer.Informer().HasSynced))
+	return calc
+}
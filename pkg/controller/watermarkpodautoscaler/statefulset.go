@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// capDesiredReplicasForStatefulSet applies the ordering constraints of the StatefulSets that the scale subresource ignores.
+func (r *ReconcileWatermarkPodAutoscaler) capDesiredReplicasForStatefulSet(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas int32) int32 {
+	if wpa.Spec.ScaleTargetRef.Kind != "StatefulSet" || desiredReplicas == currentReplicas {
+		return desiredReplicas
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Spec.ScaleTargetRef.Name}, sts); err != nil {
+		logger.Error(err, "Could not get the target StatefulSet")
+		return desiredReplicas
+	}
+	lastOrdinalReady := true
+	if desiredReplicas > currentReplicas && wpa.Spec.StatefulSet.WaitForReadyOrdinal && currentReplicas > 0 {
+		lastOrdinalReady = r.isOrdinalReady(logger, sts, currentReplicas-1)
+	}
+	return capStatefulSetReplicas(logger, wpa, sts, lastOrdinalReady, currentReplicas, desiredReplicas)
+}
+
+// isOrdinalReady returns whether the pod of the ordinal is ready.
+func (r *ReconcileWatermarkPodAutoscaler) isOrdinalReady(logger logr.Logger, sts *appsv1.StatefulSet, ordinal int32) bool {
+	pod, err := r.podLister.Pods(sts.Namespace).Get(fmt.Sprintf("%s-%d", sts.Name, ordinal))
+	if err != nil {
+		logger.Info("Could not get the pod of the StatefulSet", "ordinal", ordinal, "error", err)
+		return false
+	}
+	_, condition := getPodCondition(&pod.Status, corev1.PodReady)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+func capStatefulSetReplicas(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, sts *appsv1.StatefulSet, lastOrdinalReady bool, currentReplicas, desiredReplicas int32) int32 {
+	spec := wpa.Spec.StatefulSet
+	if spec.AlignWithPodManagementPolicy && sts.Spec.PodManagementPolicy == appsv1.ParallelPodManagement {
+		return desiredReplicas
+	}
+
+	if desiredReplicas > currentReplicas && !lastOrdinalReady {
+		setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, "WaitingForReadyOrdinal", "the pod %s-%d is not Ready yet, upscale is paused", sts.Name, currentReplicas-1)
+		logger.Info("Pausing upscale until the last ordinal is Ready", "ordinal", currentReplicas-1, "desiredReplicas", desiredReplicas)
+		return currentReplicas
+	}
+	if desiredReplicas < currentReplicas && spec.OrderedDownscaleStep != nil && currentReplicas-desiredReplicas > *spec.OrderedDownscaleStep {
+		capped := currentReplicas - *spec.OrderedDownscaleStep
+		setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, "LimitedByOrderedDownscaleStep", "the downscale of the StatefulSet is limited to %d replicas at once", *spec.OrderedDownscaleStep)
+		logger.Info("Limiting the downscale of the StatefulSet", "desiredReplicas", desiredReplicas, "cappedReplicas", capped)
+		return capped
+	}
+	return desiredReplicas
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestCapStatefulSetReplicas(t *testing.T) {
+	tests := []struct {
+		name             string
+		spec             v1alpha1.StatefulSetScalingSpec
+		policy           appsv1.PodManagementPolicyType
+		lastOrdinalReady bool
+		currentReplicas  int32
+		desiredReplicas  int32
+		want             int32
+	}{
+		{
+			name:             "upscale with the last ordinal ready",
+			spec:             v1alpha1.StatefulSetScalingSpec{WaitForReadyOrdinal: true},
+			policy:           appsv1.OrderedReadyPodManagement,
+			lastOrdinalReady: true,
+			currentReplicas:  3,
+			desiredReplicas:  5,
+			want:             5,
+		},
+		{
+			name:            "upscale paused until the last ordinal is ready",
+			spec:            v1alpha1.StatefulSetScalingSpec{WaitForReadyOrdinal: true},
+			policy:          appsv1.OrderedReadyPodManagement,
+			currentReplicas: 3,
+			desiredReplicas: 5,
+			want:            3,
+		},
+		{
+			name:            "downscale limited to the step",
+			spec:            v1alpha1.StatefulSetScalingSpec{OrderedDownscaleStep: getReplicas(2)},
+			policy:          appsv1.OrderedReadyPodManagement,
+			currentReplicas: 8,
+			desiredReplicas: 3,
+			want:            6,
+		},
+		{
+			name:            "downscale within the step",
+			spec:            v1alpha1.StatefulSetScalingSpec{OrderedDownscaleStep: getReplicas(2)},
+			policy:          appsv1.OrderedReadyPodManagement,
+			currentReplicas: 8,
+			desiredReplicas: 7,
+			want:            7,
+		},
+		{
+			name:            "parallel pod management ignores the constraints when aligned",
+			spec:            v1alpha1.StatefulSetScalingSpec{OrderedDownscaleStep: getReplicas(2), AlignWithPodManagementPolicy: true},
+			policy:          appsv1.ParallelPodManagement,
+			currentReplicas: 8,
+			desiredReplicas: 3,
+			want:            3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+					StatefulSet: &spec,
+				},
+			})
+			sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{PodManagementPolicy: tt.policy}}
+			sts.Name = "foo"
+			got := capStatefulSetReplicas(logf.Log.WithName(tt.name), wpa, sts, tt.lastOrdinalReady, tt.currentReplicas, tt.desiredReplicas)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
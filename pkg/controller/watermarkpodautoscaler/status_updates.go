@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"flag"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+var (
+	statusUpdateQPS   float64
+	statusUpdateBurst int
+)
+
+func init() {
+	flag.Float64Var(&statusUpdateQPS, "status-update-qps", 10, "Number of status updates per second carrying only new metric values, the others being coalesced into the next ones, 0 for no limit")
+	flag.IntVar(&statusUpdateBurst, "status-update-burst", 20, "Burst of the status updates carrying only new metric values")
+}
+
+type statusChange int
+
+const (
+	statusUnchanged statusChange = iota
+	// statusReconcileTimeChanged is a change of the time of the last successful reconcile only.
+	statusReconcileTimeChanged
+	// statusMetricsChanged is a change of the values of the metrics, of the replica-hours, or of the messages of the
+	// conditions.
+	statusMetricsChanged
+	statusChanged
+)
+
+func classifyStatusChange(old, updated *datadoghqv1alpha1.WatermarkPodAutoscalerStatus) statusChange {
+	if apiequality.Semantic.DeepEqual(old, updated) {
+		return statusUnchanged
+	}
+	old, updated = old.DeepCopy(), updated.DeepCopy()
+	old.LastSuccessfulReconcile, updated.LastSuccessfulReconcile = nil, nil
+	if apiequality.Semantic.DeepEqual(old, updated) {
+		return statusReconcileTimeChanged
+	}
+	old.CurrentMetrics, updated.CurrentMetrics = nil, nil
+	old.ActiveMetric, updated.ActiveMetric = nil, nil
+	old.MetricDetails, updated.MetricDetails = nil, nil
+	old.Efficiency, updated.Efficiency = nil, nil
+	old.Pods, updated.Pods = nil, nil
+	for _, status := range []*datadoghqv1alpha1.WatermarkPodAutoscalerStatus{old, updated} {
+		for i := range status.Conditions {
+			status.Conditions[i].Message = ""
+		}
+	}
+	if apiequality.Semantic.DeepEqual(old, updated) {
+		return statusMetricsChanged
+	}
+	return statusChanged
+}
+
+// statusCoalescer skips the status updates carrying only the time of the last reconcile, and spreads the ones
+// carrying only new metric values under a budget, to reduce the writes to the API server.
+type statusCoalescer struct {
+	// budget is nil when the updates of the metric values are not limited.
+	budget flowcontrol.RateLimiter
+	// refreshPeriod is the period the time of the last reconcile is written at least at, 0 if it isn't forced.
+	refreshPeriod time.Duration
+}
+
+func newStatusCoalescer(qps float32, burst int, refreshPeriod time.Duration) *statusCoalescer {
+	c := &statusCoalescer{refreshPeriod: refreshPeriod}
+	if qps > 0 {
+		c.budget = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	}
+	return c
+}
+
+// shouldWrite returns whether the status of a WPA should be written, all changes being written without a coalescer.
+func (c *statusCoalescer) shouldWrite(old, updated *datadoghqv1alpha1.WatermarkPodAutoscalerStatus, now time.Time) bool {
+	change := classifyStatusChange(old, updated)
+	switch {
+	case change == statusUnchanged:
+		return false
+	case change == statusChanged || c == nil:
+		return true
+	}
+	// The time of the last reconcile is written often enough for the watchdog not to consider the WPA stale.
+	if c.refreshPeriod > 0 && (old.LastSuccessfulReconcile == nil || now.Sub(old.LastSuccessfulReconcile.Time) >= c.refreshPeriod) {
+		return true
+	}
+	if change == statusReconcileTimeChanged {
+		return false
+	}
+	if c.budget == nil || c.budget.TryAccept() {
+		return true
+	}
+	log.V(4).Info("Coalescing the status update, the budget of status updates is exhausted")
+	return false
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatusCoalescer(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	newStatus := func(reconciled time.Time, value int64, replicas int32) *v1alpha1.WatermarkPodAutoscalerStatus {
+		lastReconcile := metav1.NewTime(reconciled)
+		return &v1alpha1.WatermarkPodAutoscalerStatus{
+			CurrentReplicas:         replicas,
+			LastSuccessfulReconcile: &lastReconcile,
+			CurrentMetrics: []autoscalingv2.MetricStatus{
+				{
+					Type:     autoscalingv2.ExternalMetricSourceType,
+					External: &autoscalingv2.ExternalMetricStatus{MetricName: "foo", CurrentValue: *resource.NewMilliQuantity(value, resource.DecimalSI)},
+				},
+			},
+			Conditions: []autoscalingv2.HorizontalPodAutoscalerCondition{
+				{Type: autoscalingv2.ScalingActive, Status: corev1.ConditionTrue, Message: "foo"},
+			},
+		}
+	}
+	persisted := newStatus(now.Add(-15*time.Second), 100, 3)
+	c := newStatusCoalescer(1, 1, time.Minute)
+
+	require.False(t, c.shouldWrite(persisted, persisted.DeepCopy(), now))
+	require.False(t, c.shouldWrite(persisted, newStatus(now, 100, 3), now), "the time of the last reconcile alone is not written")
+	require.True(t, c.shouldWrite(newStatus(now.Add(-time.Minute), 100, 3), newStatus(now, 100, 3), now), "the time of the last reconcile is refreshed")
+
+	require.True(t, c.shouldWrite(persisted, newStatus(now, 200, 3), now))
+	messageChanged := newStatus(now, 100, 3)
+	messageChanged.Conditions[0].Message = "bar"
+	require.False(t, c.shouldWrite(persisted, messageChanged, now), "the budget is exhausted")
+	efficiencyChanged := newStatus(now, 100, 3)
+	efficiencyChanged.Efficiency = &v1alpha1.EfficiencyStatus{ReplicaHours: 1}
+	require.False(t, c.shouldWrite(persisted, efficiencyChanged, now), "the replica-hours are coalesced like the metric values")
+
+	podsChanged := newStatus(now, 100, 3)
+	podsChanged.Pods = &v1alpha1.PodsStatus{Total: 3, Ready: 2, Ignored: 1}
+	require.False(t, c.shouldWrite(persisted, podsChanged, now), "the pod counts are coalesced like the metric values")
+
+	require.True(t, c.shouldWrite(persisted, newStatus(now, 200, 4), now), "the other changes are always written")
+
+	var unlimited *statusCoalescer
+	require.True(t, unlimited.shouldWrite(persisted, newStatus(now, 100, 3), now))
+}
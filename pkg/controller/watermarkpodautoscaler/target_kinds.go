@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var allowedTargetKinds string
+
+func init() {
+	flag.StringVar(&allowedTargetKinds, "allowed-target-kinds", "", "Comma-separated list of the kinds the WPAs are allowed to scale, as Kind.group, e.g. Deployment.apps,StatefulSet.apps, all of them when empty")
+}
+
+// parseTargetKinds returns the kinds of a comma-separated list of Kind.group, a kind without group being of the core
+// group.
+func parseTargetKinds(kinds string) map[schema.GroupKind]bool {
+	allowed := map[schema.GroupKind]bool{}
+	for _, kind := range strings.Split(kinds, ",") {
+		if kind = strings.TrimSpace(kind); kind != "" {
+			allowed[schema.ParseGroupKind(kind)] = true
+		}
+	}
+	return allowed
+}
+
+// checkTargetKinds returns an error when the kind of the scaleTargetRef or of one of the scaleTargetRefs of the WPA
+// is not among the allowed kinds of the controller.
+func checkTargetKinds(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) error {
+	if allowedTargetKinds == "" {
+		return nil
+	}
+	allowed := parseTargetKinds(allowedTargetKinds)
+	refs := []datadoghqv1alpha1.CrossVersionObjectReference{wpa.Spec.ScaleTargetRef}
+	for _, ref := range wpa.Spec.ScaleTargetRefs {
+		refs = append(refs, ref.CrossVersionObjectReference)
+	}
+	for _, ref := range refs {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			return fmt.Errorf("invalid API version in scale target reference: %v", err)
+		}
+		if gk := (schema.GroupKind{Group: gv.Group, Kind: ref.Kind}); !allowed[gk] {
+			return fmt.Errorf("the kind %s of the target %s is not among the kinds allowed by the controller: %s", gk, ref.Name, allowedTargetKinds)
+		}
+	}
+	return nil
+}
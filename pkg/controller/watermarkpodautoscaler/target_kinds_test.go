@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseTargetKinds(t *testing.T) {
+	require.Equal(t, map[schema.GroupKind]bool{
+		{Group: "apps", Kind: "Deployment"}:                    true,
+		{Group: "", Kind: "ReplicationController"}:             true,
+		{Group: "apps.openshift.io", Kind: "DeploymentConfig"}: true,
+		{Group: "argoproj.io", Kind: "Rollout"}:                true,
+	}, parseTargetKinds("Deployment.apps, ReplicationController,DeploymentConfig.apps.openshift.io,,Rollout.argoproj.io"))
+}
+
+func TestCheckTargetKinds(t *testing.T) {
+	defer func(kinds string) { allowedTargetKinds = kinds }(allowedTargetKinds)
+
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef: v1alpha1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "foo"},
+		},
+	})
+
+	allowedTargetKinds = ""
+	require.NoError(t, checkTargetKinds(wpa), "all the kinds are allowed by default")
+
+	allowedTargetKinds = "Deployment.apps,StatefulSet.apps"
+	require.NoError(t, checkTargetKinds(wpa))
+
+	wpa.Spec.ScaleTargetRefs = []v1alpha1.WeightedCrossVersionObjectReference{{CrossVersionObjectReference: v1alpha1.CrossVersionObjectReference{APIVersion: "v1", Kind: "ReplicationController", Name: "bar"}}}
+	require.EqualError(t, checkTargetKinds(wpa), "the kind ReplicationController of the target bar is not among the kinds allowed by the controller: Deployment.apps,StatefulSet.apps")
+
+	wpa.Spec.ScaleTargetRefs = nil
+	wpa.Spec.ScaleTargetRef.APIVersion = "extensions/v1beta1"
+	require.EqualError(t, checkTargetKinds(wpa), "the kind Deployment.extensions of the target foo is not among the kinds allowed by the controller: Deployment.apps,StatefulSet.apps", "the group is part of the kind")
+}
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"flag"
+	"fmt"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// managedByAnnotation names the WPA allowed to scale a workload, as <namespace>/<name>.
+const managedByAnnotation = "wpa.datadoghq.com/managed-by"
+
+var requireTargetOwnership bool
+
+func init() {
+	flag.BoolVar(&requireTargetOwnership, "require-target-ownership", false, "Only scale the targets annotated with "+managedByAnnotation+" set to the <namespace>/<name> of the WPA")
+}
+
+// checkTargetOwnership returns an error when the controller requires the targets to be owned by their WPA, and the
+// target isn't annotated with the WPA. The target is read from the API server rather than from the caches, as its
+// kind may be any scalable resource.
+func (r *ReconcileWatermarkPodAutoscaler) checkTargetOwnership(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, ref datadoghqv1alpha1.CrossVersionObjectReference) error {
+	if !requireTargetOwnership {
+		return nil
+	}
+	target, err := r.getUnstructured(wpa.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return fmt.Errorf("unable to get the %s %s to check its %s annotation: %v", ref.Kind, ref.Name, managedByAnnotation, err)
+	}
+	owner := types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}.String()
+	managedBy, found := target.GetAnnotations()[managedByAnnotation]
+	if !found {
+		return fmt.Errorf("the %s %s is not annotated with %s: %s", ref.Kind, ref.Name, managedByAnnotation, owner)
+	}
+	if managedBy != owner {
+		return fmt.Errorf("the %s %s is managed by %s according to its %s annotation, not by %s", ref.Kind, ref.Name, managedBy, managedByAnnotation, owner)
+	}
+	return nil
+}
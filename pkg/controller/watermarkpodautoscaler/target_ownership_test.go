@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckTargetOwnership(t *testing.T) {
+	defer func(require bool) { requireTargetOwnership = require }(requireTargetOwnership)
+
+	newDeployment := func(name string, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: name, Annotations: annotations}}
+	}
+	s := runtime.NewScheme()
+	s.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.Deployment{})
+	r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClientWithScheme(s,
+		newDeployment("owned", map[string]string{managedByAnnotation: testingNamespace + "/" + testingWPAName}),
+		newDeployment("other", map[string]string{managedByAnnotation: "other/wpa"}),
+		newDeployment("unannotated", nil),
+	)}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+	ref := func(name string) v1alpha1.CrossVersionObjectReference {
+		return v1alpha1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name}
+	}
+
+	requireTargetOwnership = false
+	require.NoError(t, r.checkTargetOwnership(wpa, ref("unannotated")), "the ownership is only checked when required")
+
+	requireTargetOwnership = true
+	require.NoError(t, r.checkTargetOwnership(wpa, ref("owned")))
+	require.EqualError(t, r.checkTargetOwnership(wpa, ref("other")), "the Deployment other is managed by other/wpa according to its wpa.datadoghq.com/managed-by annotation, not by "+testingNamespace+"/"+testingWPAName)
+	require.EqualError(t, r.checkTargetOwnership(wpa, ref("unannotated")), "the Deployment unannotated is not annotated with wpa.datadoghq.com/managed-by: "+testingNamespace+"/"+testingWPAName)
+	require.Error(t, r.checkTargetOwnership(wpa, ref("missing")), "the targets that can't be read are not scaled")
+}
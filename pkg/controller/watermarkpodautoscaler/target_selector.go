@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// selectedTargetWeight is the weight of the workloads matched by the selector of the scaleTargetRef,
+	// they all have the same number of replicas.
+	selectedTargetWeight int32 = 100
+)
+
+// resolveScaleTargets replaces the selectors of the scale targets of the WPA by the names of the workloads they match.
+// The first workload matched by the selector of the scaleTargetRef becomes the scaleTargetRef, the other ones
+// are scaled in lock-step with it. The spec of the WPA is only updated in memory.
+func (r *ReconcileWatermarkPodAutoscaler) resolveScaleTargets(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) error {
+	var refs []datadoghqv1alpha1.WeightedCrossVersionObjectReference
+	if wpa.Spec.ScaleTargetRef.Selector != nil {
+		targets, err := r.listTargets(wpa.Namespace, wpa.Spec.ScaleTargetRef)
+		if err != nil {
+			return err
+		}
+		if wpa.Spec.BlueGreen != nil && len(targets) > 0 {
+			if targets, err = r.liveColorFirst(logger, wpa, targets); err != nil {
+				return err
+			}
+		}
+		names := targetNames(targets)
+		if len(names) == 0 {
+			return fmt.Errorf("no %s matches the selector of the scale target reference", wpa.Spec.ScaleTargetRef.Kind)
+		}
+		refs = append(refs, weightedTargetRefs(wpa.Spec.ScaleTargetRef, names[1:], selectedTargetWeight)...)
+		wpa.Spec.ScaleTargetRef = targetRefWithName(wpa.Spec.ScaleTargetRef, names[0])
+		logger.Info("Resolved the selector of the scale target reference", "scaleTargetRef", names[0], "lockStepTargets", names[1:])
+	}
+
+	for _, ref := range wpa.Spec.ScaleTargetRefs {
+		if ref.Selector == nil {
+			refs = append(refs, ref)
+			continue
+		}
+		names, err := r.listTargetNames(wpa.Namespace, ref.CrossVersionObjectReference)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, weightedTargetRefs(ref.CrossVersionObjectReference, names, ref.Weight)...)
+	}
+	wpa.Spec.ScaleTargetRefs = refs
+	return nil
+}
+
+// listTargetNames returns the sorted names of the workloads of the kind of the reference matching its selector.
+func (r *ReconcileWatermarkPodAutoscaler) listTargetNames(namespace string, ref datadoghqv1alpha1.CrossVersionObjectReference) ([]string, error) {
+	targets, err := r.listTargets(namespace, ref)
+	if err != nil {
+		return nil, err
+	}
+	return targetNames(targets), nil
+}
+
+// listTargets returns the workloads of the kind of the reference matching its selector, sorted by name.
+func (r *ReconcileWatermarkPodAutoscaler) listTargets(namespace string, ref datadoghqv1alpha1.CrossVersionObjectReference) ([]unstructured.Unstructured, error) {
+	selector, err := metav1.LabelSelectorAsSelector(ref.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector in scale target reference: %v", err)
+	}
+	targets := &unstructured.UnstructuredList{}
+	targets.SetGroupVersionKind(schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind+"List"))
+	if err := r.client.List(context.TODO(), targets, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("unable to list the %s matching the selector of the scale target reference: %v", ref.Kind, err)
+	}
+	sort.Slice(targets.Items, func(i, j int) bool { return targets.Items[i].GetName() < targets.Items[j].GetName() })
+	return targets.Items, nil
+}
+
+func targetNames(targets []unstructured.Unstructured) []string {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.GetName())
+	}
+	return names
+}
+
+func targetRefWithName(ref datadoghqv1alpha1.CrossVersionObjectReference, name string) datadoghqv1alpha1.CrossVersionObjectReference {
+	ref.Name = name
+	ref.Selector = nil
+	return ref
+}
+
+func weightedTargetRefs(ref datadoghqv1alpha1.CrossVersionObjectReference, names []string, weight int32) []datadoghqv1alpha1.WeightedCrossVersionObjectReference {
+	refs := make([]datadoghqv1alpha1.WeightedCrossVersionObjectReference, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, datadoghqv1alpha1.WeightedCrossVersionObjectReference{
+			CrossVersionObjectReference: targetRefWithName(ref, name),
+			Weight:                      weight,
+		})
+	}
+	return refs
+}
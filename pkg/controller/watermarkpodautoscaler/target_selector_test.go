@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestResolveScaleTargets(t *testing.T) {
+	newDeployment := func(name, shard string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testingNamespace,
+				Name:      name,
+				Labels:    map[string]string{"app": "shard", "role": shard},
+			},
+		}
+	}
+	r := &ReconcileWatermarkPodAutoscaler{
+		client: fake.NewFakeClientWithScheme(scheme.Scheme,
+			newDeployment("shard-c", "customer"),
+			newDeployment("shard-a", "customer"),
+			newDeployment("shard-b", "customer"),
+			newDeployment("cache-a", "cache"),
+			newDeployment("cache-b", "cache"),
+		),
+	}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef: v1alpha1.CrossVersionObjectReference{
+				Kind:       "Deployment",
+				APIVersion: "apps/v1",
+				Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"role": "customer"}},
+			},
+			ScaleTargetRefs: []v1alpha1.WeightedCrossVersionObjectReference{
+				{
+					CrossVersionObjectReference: v1alpha1.CrossVersionObjectReference{
+						Kind:       "Deployment",
+						APIVersion: "apps/v1",
+						Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"role": "cache"}},
+					},
+					Weight: 50,
+				},
+			},
+		},
+	})
+
+	require.NoError(t, r.resolveScaleTargets(logf.Log.WithName(t.Name()), wpa))
+	require.Equal(t, "shard-a", wpa.Spec.ScaleTargetRef.Name)
+	require.Nil(t, wpa.Spec.ScaleTargetRef.Selector)
+	var resolved []string
+	for _, ref := range wpa.Spec.ScaleTargetRefs {
+		require.Nil(t, ref.Selector)
+		resolved = append(resolved, ref.Name)
+		if ref.Name == "shard-b" || ref.Name == "shard-c" {
+			require.Equal(t, selectedTargetWeight, ref.Weight)
+		} else {
+			require.Equal(t, int32(50), ref.Weight)
+		}
+	}
+	require.Equal(t, []string{"shard-b", "shard-c", "cache-a", "cache-b"}, resolved)
+
+	wpa.Spec.ScaleTargetRef = v1alpha1.CrossVersionObjectReference{
+		Kind:       "Deployment",
+		APIVersion: "apps/v1",
+		Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"role": "unknown"}},
+	}
+	require.Error(t, r.resolveScaleTargets(logf.Log.WithName(t.Name()), wpa))
+}
@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const timelinePath = "/recommendations"
+
+var (
+	timelineBindAddress string
+	timelineSize        int
+)
+
+func init() {
+	flag.StringVar(&timelineBindAddress, "recommendation-api-bind-address", "", "Address the read-only API of the recent recommendations of the WPAs binds to, e.g. :8082, disabled when empty")
+	flag.IntVar(&timelineSize, "recommendation-api-size", 240, "Number of recent recommendations kept per WPA for the recommendation API")
+}
+
+// timelineEntry is the outcome of a reconcile of a WPA.
+type timelineEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	// ProposedReplicas is nil when the algorithm didn't propose a number of replicas.
+	ProposedReplicas *int32   `json:"proposedReplicas,omitempty"`
+	AppliedReplicas  int32    `json:"appliedReplicas"`
+	Decision         string   `json:"decision"`
+	LimitingReasons  []string `json:"limitingReasons,omitempty"`
+}
+
+// recommendationTimeline keeps the outcomes of the last reconciles of the WPAs.
+type recommendationTimeline struct {
+	mu      sync.RWMutex
+	size    int
+	entries map[types.NamespacedName][]timelineEntry
+}
+
+func newRecommendationTimeline(size int) *recommendationTimeline {
+	return &recommendationTimeline{size: size, entries: map[types.NamespacedName][]timelineEntry{}}
+}
+
+func (t *recommendationTimeline) record(key types.NamespacedName, entry timelineEntry) {
+	if t == nil || t.size <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := append(t.entries[key], entry)
+	if len(entries) > t.size {
+		entries = entries[len(entries)-t.size:]
+	}
+	t.entries[key] = entries
+}
+
+func (t *recommendationTimeline) forget(key types.NamespacedName) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, key)
+}
+
+// get returns the entries of the WPAs in the namespace, of all the WPAs when it is empty, keyed by namespace/name.
+func (t *recommendationTimeline) get(namespace string) map[string][]timelineEntry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := map[string][]timelineEntry{}
+	for key, entries := range t.entries {
+		if namespace == "" || key.Namespace == namespace {
+			result[key.String()] = append([]timelineEntry(nil), entries...)
+		}
+	}
+	return result
+}
+
+// recordTimeline adds the outcome of the reconcile to the timeline of the WPA.
+func (r *ReconcileWatermarkPodAutoscaler) recordTimeline(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, decision string, proposed bool, proposedReplicas, appliedReplicas int32) {
+	entry := timelineEntry{
+		Timestamp:       time.Now(),
+		AppliedReplicas: appliedReplicas,
+		Decision:        decision,
+		LimitingReasons: limitingReasons(wpa),
+	}
+	if proposed {
+		entry.ProposedReplicas = &proposedReplicas
+	}
+	r.timeline.record(types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}, entry)
+}
+
+// healthyWhenTrue are the types of the conditions of a WPA holding back its scaling when they are false, the other
+// ones holding it back when they are true.
+var healthyWhenTrue = map[autoscalingv2.HorizontalPodAutoscalerConditionType]bool{
+	autoscalingv2.AbleToScale:   true,
+	autoscalingv2.ScalingActive: true,
+	policyCondition:             true,
+	metricsProviderCondition:    true,
+}
+
+// limitingReasons returns the reasons of the conditions of the WPA holding back its scaling.
+func limitingReasons(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) []string {
+	var reasons []string
+	for _, cond := range wpa.Status.Conditions {
+		limitingStatus := corev1.ConditionTrue
+		if healthyWhenTrue[cond.Type] {
+			limitingStatus = corev1.ConditionFalse
+		}
+		if cond.Status == limitingStatus && cond.Reason != "" {
+			reasons = append(reasons, cond.Reason)
+		}
+	}
+	return reasons
+}
+
+// timelineServer serves the timeline in JSON: /recommendations lists all the WPAs, /recommendations/<namespace>
+// the WPAs of a namespace, and /recommendations/<namespace>/<name> a single WPA.
+type timelineServer struct {
+	address  string
+	timeline *recommendationTimeline
+}
+
+func (s *timelineServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, timelinePath), "/")
+	var parts []string
+	if path != "" {
+		parts = strings.Split(path, "/")
+	}
+	var body interface{}
+	switch len(parts) {
+	case 0:
+		body = s.timeline.get("")
+	case 1:
+		body = s.timeline.get(parts[0])
+	case 2:
+		entries, found := s.timeline.get(parts[0])[types.NamespacedName{Namespace: parts[0], Name: parts[1]}.String()]
+		if !found {
+			http.Error(w, "no recommendation for this WPA", http.StatusNotFound)
+			return
+		}
+		body = entries
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error(err, "Unable to write the recommendations")
+	}
+}
+
+// Start implements manager.Runnable, it serves the timeline until the stop channel is closed.
+func (s *timelineServer) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.Handle(timelinePath, s)
+	mux.Handle(timelinePath+"/", s)
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: mux}
+	go func() {
+		<-stop
+		if err := server.Shutdown(context.Background()); err != nil {
+			log.Error(err, "Unable to shut down the recommendation API")
+		}
+	}()
+	log.Info("Serving the recommendation API", "address", s.address, "path", timelinePath)
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
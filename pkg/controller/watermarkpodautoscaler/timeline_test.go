@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRecommendationTimeline(t *testing.T) {
+	r := &ReconcileWatermarkPodAutoscaler{timeline: newRecommendationTimeline(2)}
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+	r.recordTimeline(wpa, decisionMetricError, false, 0, 3)
+	setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "BackoffUpscale", "")
+	setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionTrue, "ValidMetricFound", "")
+	setCondition(wpa, flappingCondition, corev1.ConditionFalse, "NotFlapping", "")
+	r.recordTimeline(wpa, decisionBackoffUp, true, 5, 3)
+	r.recordTimeline(wpa, decisionScaledUp, true, 5, 5)
+	other := test.NewWatermarkPodAutoscaler("other", testingWPAName, nil)
+	r.recordTimeline(other, decisionWithinBounds, true, 2, 2)
+
+	get := func(path string, code int, body interface{}) {
+		rec := httptest.NewRecorder()
+		(&timelineServer{timeline: r.timeline}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, code, rec.Code, path)
+		if body != nil {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), body))
+		}
+	}
+	var entries []timelineEntry
+	get("/recommendations/"+testingNamespace+"/"+testingWPAName, http.StatusOK, &entries)
+	require.Len(t, entries, 2, "only the last entries are kept")
+	require.Equal(t, decisionBackoffUp, entries[0].Decision)
+	require.Equal(t, int32(5), *entries[0].ProposedReplicas)
+	require.Equal(t, int32(3), entries[0].AppliedReplicas)
+	require.Equal(t, []string{"BackoffUpscale"}, entries[0].LimitingReasons)
+
+	var all map[string][]timelineEntry
+	get("/recommendations", http.StatusOK, &all)
+	require.Len(t, all, 2)
+	var namespaced map[string][]timelineEntry
+	get("/recommendations/other", http.StatusOK, &namespaced)
+	require.Len(t, namespaced["other/"+testingWPAName], 1)
+	require.Len(t, namespaced, 1)
+
+	get("/recommendations/"+testingNamespace+"/unknown", http.StatusNotFound, nil)
+	get("/recommendations/a/b/c", http.StatusNotFound, nil)
+
+	r.timeline.forget(types.NamespacedName{Namespace: "other", Name: testingWPAName})
+	get("/recommendations/other/"+testingWPAName, http.StatusNotFound, nil)
+}
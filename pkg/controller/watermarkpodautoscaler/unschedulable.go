@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// capDesiredReplicasWithUnschedulablePods prevents upscaling the target while some of its pods can't be scheduled,
+// as adding replicas would only create more pending pods.
+func (r *ReconcileWatermarkPodAutoscaler) capDesiredReplicasWithUnschedulablePods(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale, currentReplicas, desiredReplicas int32) int32 {
+	selector, err := labels.Parse(scale.Status.Selector)
+	if err != nil {
+		logger.Error(err, "Could not parse the labels of the target")
+		return desiredReplicas
+	}
+	pods, err := r.podLister.Pods(wpa.Namespace).List(selector)
+	if err != nil {
+		logger.Error(err, "Could not list the pods of the target")
+		return desiredReplicas
+	}
+
+	unschedulable := countUnschedulablePods(pods)
+	unschedulablePods.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Set(float64(unschedulable))
+	if unschedulable == 0 || desiredReplicas <= currentReplicas {
+		return desiredReplicas
+	}
+	setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, "UnschedulablePods", "%d pods of the target can't be scheduled, upscale is paused", unschedulable)
+	logger.Info("Pausing upscale while pods are unschedulable", "unschedulablePods", unschedulable, "desiredReplicas", desiredReplicas)
+	return currentReplicas
+}
+
+func countUnschedulablePods(pods []*corev1.Pod) int {
+	unschedulable := 0
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodPending || pod.DeletionTimestamp != nil {
+			continue
+		}
+		_, condition := getPodCondition(&pod.Status, corev1.PodScheduled)
+		if condition != nil && condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable {
+			unschedulable++
+		}
+	}
+	return unschedulable
+}
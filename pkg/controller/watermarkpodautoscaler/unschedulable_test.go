@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func newTestPodScheduled(name string, phase corev1.PodPhase, conditions ...corev1.PodCondition) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testingNamespace, Labels: map[string]string{"app": "test"}},
+		Status:     corev1.PodStatus{Phase: phase, Conditions: conditions},
+	}
+}
+
+func TestCountUnschedulablePods(t *testing.T) {
+	scheduled := corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}
+	unschedulable := corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable}
+	terminating := newTestPodScheduled("terminating", corev1.PodPending, unschedulable)
+	terminating.DeletionTimestamp = &metav1.Time{}
+
+	tests := []struct {
+		name string
+		pods []*corev1.Pod
+		want int
+	}{
+		{
+			name: "no pods",
+			pods: nil,
+			want: 0,
+		},
+		{
+			name: "running pods",
+			pods: []*corev1.Pod{newTestPodScheduled("running", corev1.PodRunning, scheduled)},
+			want: 0,
+		},
+		{
+			name: "pending pods with the Unschedulable condition",
+			pods: []*corev1.Pod{newTestPodScheduled("a", corev1.PodPending, unschedulable), newTestPodScheduled("b", corev1.PodPending, unschedulable)},
+			want: 2,
+		},
+		{
+			name: "pending pods already scheduled",
+			pods: []*corev1.Pod{newTestPodScheduled("pulling", corev1.PodPending, scheduled)},
+			want: 0,
+		},
+		{
+			name: "pending pods not considered by the scheduler yet",
+			pods: []*corev1.Pod{newTestPodScheduled("new", corev1.PodPending)},
+			want: 0,
+		},
+		{
+			name: "pending pods not scheduled for another reason",
+			pods: []*corev1.Pod{newTestPodScheduled("gated", corev1.PodPending, corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "SchedulingGated"})},
+			want: 0,
+		},
+		{
+			name: "terminating pods are not considered",
+			pods: []*corev1.Pod{terminating},
+			want: 0,
+		},
+		{
+			name: "mixed pods",
+			pods: []*corev1.Pod{newTestPodScheduled("running", corev1.PodRunning, scheduled), newTestPodScheduled("new", corev1.PodPending), newTestPodScheduled("a", corev1.PodPending, unschedulable)},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, countUnschedulablePods(tt.pods))
+		})
+	}
+}
+
+func TestCapDesiredReplicasWithUnschedulablePods(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(newTestPodScheduled("running", corev1.PodRunning, corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionTrue})))
+	assert.NoError(t, indexer.Add(newTestPodScheduled("pending", corev1.PodPending, corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable})))
+	r := &ReconcileWatermarkPodAutoscaler{podLister: corelisters.NewPodLister(indexer)}
+	scale := &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Selector: "app=test"}}
+	logger := logf.Log.WithName(t.Name())
+
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil)
+	assert.Equal(t, int32(1), r.capDesiredReplicasWithUnschedulablePods(logger, wpa, scale, 2, 1), "the downscales are not paused")
+	assert.Empty(t, wpa.Status.Conditions)
+
+	assert.Equal(t, int32(2), r.capDesiredReplicasWithUnschedulablePods(logger, wpa, scale, 2, 4))
+	assert.Len(t, wpa.Status.Conditions, 1)
+	assert.Equal(t, autoscalingv2.ScalingLimited, wpa.Status.Conditions[0].Type)
+	assert.Equal(t, "UnschedulablePods", wpa.Status.Conditions[0].Reason)
+
+	scale.Status.Selector = "app=other"
+	assert.Equal(t, int32(4), r.capDesiredReplicasWithUnschedulablePods(logger, test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, nil), scale, 2, 4), "only the pods of the target are considered")
+}
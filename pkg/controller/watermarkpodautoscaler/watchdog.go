@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
+
+	"github.com/prometheus/client_golang/prometheus"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const staleCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "Stale"
+
+var staleReconcileFactor int
+
+func init() {
+	flag.IntVar(&staleReconcileFactor, "stale-reconcile-factor", 4, "Number of sync periods without a successful reconcile after which a WPA is marked as Stale, 0 to disable the watchdog")
+}
+
+// staleWatchdog periodically marks the WPAs that were not reconciled successfully for factor sync periods as
+// Stale, so that the stuck ones can be alerted on.
+type staleWatchdog struct {
+	client     client.Client
+	syncPeriod time.Duration
+	factor     int
+}
+
+// Start implements manager.Runnable.
+func (w *staleWatchdog) Start(stop <-chan struct{}) error {
+	wait.Until(func() { w.check(time.Now()) }, w.syncPeriod, stop)
+	return nil
+}
+
+func (w *staleWatchdog) check(now time.Time) {
+	wpas := &datadoghqv1alpha1.WatermarkPodAutoscalerList{}
+	if err := w.client.List(context.TODO(), wpas); err != nil {
+		log.Error(err, "Could not list the WPAs to detect the stale ones")
+		return
+	}
+	threshold := time.Duration(w.factor) * w.syncPeriod
+	for i := range wpas.Items {
+		wpa := &wpas.Items[i]
+		if wpa.DeletionTimestamp != nil || !util.OwnsNamespace(wpa.Namespace) {
+			continue
+		}
+		last := wpa.CreationTimestamp
+		if wpa.Status.LastSuccessfulReconcile != nil {
+			last = *wpa.Status.LastSuccessfulReconcile
+		}
+		stale := now.Sub(last.Time) > threshold
+		value := 0.0
+		if stale {
+			value = 1
+		}
+		staleWPA.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Set(value)
+
+		wasStale := isConditionTrue(wpa, staleCondition)
+		switch {
+		case stale && !wasStale:
+			setCondition(wpa, staleCondition, corev1.ConditionTrue, "NotReconciled", "the WPA was not reconciled successfully since %s", last.Format(time.RFC3339))
+			log.Info("The WPA was not reconciled successfully for a while", "namespace", wpa.Namespace, "name", wpa.Name, "lastSuccessfulReconcile", last.Time)
+		case !stale && wasStale:
+			setCondition(wpa, staleCondition, corev1.ConditionFalse, "Reconciled", "the WPA was reconciled successfully at %s", last.Format(time.RFC3339))
+		default:
+			continue
+		}
+		if err := w.client.Status().Update(context.TODO(), wpa); err != nil {
+			log.Info("Could not update the Stale condition of the WPA", "namespace", wpa.Namespace, "name", wpa.Name, "error", err)
+		}
+	}
+}
+
+func isConditionTrue(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, conditionType autoscalingv2.HorizontalPodAutoscalerConditionType) bool {
+	for _, condition := range wpa.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
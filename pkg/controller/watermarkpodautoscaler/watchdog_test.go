@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func wpaPromLabels(wpa *v1alpha1.WatermarkPodAutoscaler) prometheus.Labels {
+	return prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+}
+
+func TestStaleWatchdog(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	newWPA := func(name string, lastReconcile time.Time) *v1alpha1.WatermarkPodAutoscaler {
+		reconciled := metav1.NewTime(lastReconcile)
+		return test.NewWatermarkPodAutoscaler(testingNamespace, name, &test.NewWatermarkPodAutoscalerOptions{
+			Status: &v1alpha1.WatermarkPodAutoscalerStatus{LastSuccessfulReconcile: &reconciled},
+		})
+	}
+	recovered := newWPA("recovered", now.Add(-10*time.Second))
+	setCondition(recovered, staleCondition, corev1.ConditionTrue, "NotReconciled", "")
+
+	s := runtime.NewScheme()
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{}, &v1alpha1.WatermarkPodAutoscalerList{})
+	w := &staleWatchdog{
+		client:     fake.NewFakeClientWithScheme(s, newWPA("fresh", now.Add(-30*time.Second)), newWPA("stuck", now.Add(-2*time.Minute)), recovered),
+		syncPeriod: 15 * time.Second,
+		factor:     4,
+	}
+	w.check(now)
+
+	get := func(name string) *v1alpha1.WatermarkPodAutoscaler {
+		wpa := &v1alpha1.WatermarkPodAutoscaler{}
+		require.NoError(t, w.client.Get(context.TODO(), types.NamespacedName{Namespace: testingNamespace, Name: name}, wpa))
+		return wpa
+	}
+	require.Empty(t, get("fresh").Status.Conditions)
+
+	stuck := get("stuck")
+	require.Len(t, stuck.Status.Conditions, 1)
+	require.Equal(t, staleCondition, stuck.Status.Conditions[0].Type)
+	require.Equal(t, corev1.ConditionTrue, stuck.Status.Conditions[0].Status)
+	require.Equal(t, "the WPA was not reconciled successfully since 2020-01-01T11:58:00Z", stuck.Status.Conditions[0].Message)
+	require.Equal(t, 1.0, gaugeValue(t, staleWPA.With(wpaPromLabels(stuck))))
+
+	require.False(t, isConditionTrue(get("recovered"), staleCondition))
+	require.Equal(t, 0.0, gaugeValue(t, staleWPA.With(wpaPromLabels(recovered))))
+}
@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const configMapSourceKind = "ConfigMap"
+
+// resolveWatermarkSources reads the watermarks referenced by the highWatermarkFrom and lowWatermarkFrom of the metrics.
+// The spec of the WPA is only updated in memory.
+func (r *ReconcileWatermarkPodAutoscaler) resolveWatermarkSources(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) error {
+	for _, metric := range wpa.Spec.Metrics {
+		var err error
+		switch {
+		case metric.External != nil:
+			if err = r.resolveWatermark(wpa.Namespace, metric.External.HighWatermarkFrom, &metric.External.HighWatermark); err == nil {
+				err = r.resolveWatermark(wpa.Namespace, metric.External.LowWatermarkFrom, &metric.External.LowWatermark)
+			}
+			if err != nil {
+				return fmt.Errorf("unable to resolve the watermarks of the External metric %s: %v", metric.External.MetricName, err)
+			}
+		case metric.Resource != nil:
+			if err = r.resolveWatermark(wpa.Namespace, metric.Resource.HighWatermarkFrom, &metric.Resource.HighWatermark); err == nil {
+				err = r.resolveWatermark(wpa.Namespace, metric.Resource.LowWatermarkFrom, &metric.Resource.LowWatermark)
+			}
+			if err != nil {
+				return fmt.Errorf("unable to resolve the watermarks of the Resource metric %s: %v", metric.Resource.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileWatermarkPodAutoscaler) resolveWatermark(namespace string, source *datadoghqv1alpha1.WatermarkSource, watermark **resource.Quantity) error {
+	if source == nil {
+		return nil
+	}
+	if *watermark != nil {
+		return fmt.Errorf("a watermark can't be set both as a value and as a reference")
+	}
+	value, err := r.readWatermarkSource(namespace, source)
+	if err != nil {
+		return err
+	}
+	quantity, err := resource.ParseQuantity(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("invalid watermark %q: %v", value, err)
+	}
+	*watermark = &quantity
+	return nil
+}
+
+func (r *ReconcileWatermarkPodAutoscaler) readWatermarkSource(namespace string, source *datadoghqv1alpha1.WatermarkSource) (string, error) {
+	switch {
+	case source.ConfigMapKeyRef != nil && source.SecretKeyRef != nil:
+		return "", fmt.Errorf("a watermark can't reference both a ConfigMap and a Secret")
+	case source.ConfigMapKeyRef != nil:
+		ref := source.ConfigMapKeyRef
+		configMap := &corev1.ConfigMap{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, configMap); err != nil {
+			return "", fmt.Errorf("unable to get the ConfigMap %s: %v", ref.Name, err)
+		}
+		value, found := configMap.Data[ref.Key]
+		if !found {
+			return "", fmt.Errorf("the key %s is not found in the ConfigMap %s", ref.Key, ref.Name)
+		}
+		return value, nil
+	case source.SecretKeyRef != nil:
+		ref := source.SecretKeyRef
+		secret := &corev1.Secret{}
+		if err := r.secretReader().Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+			return "", fmt.Errorf("unable to get the Secret %s: %v", ref.Name, err)
+		}
+		value, found := secret.Data[ref.Key]
+		if !found {
+			return "", fmt.Errorf("the key %s is not found in the Secret %s", ref.Key, ref.Name)
+		}
+		return string(value), nil
+	}
+	return "", fmt.Errorf("a watermark reference should have either a configMapKeyRef or a secretKeyRef")
+}
+
+// secretReader returns the reader of the Secrets referenced by the WPAs, which doesn't cache them when the reconciler
+// has a reader of the API server.
+func (r *ReconcileWatermarkPodAutoscaler) secretReader() client.Reader {
+	if r.apiReader != nil {
+		return r.apiReader
+	}
+	return r.client
+}
+
+// watermarkSources returns the references to ConfigMaps and Secrets of the watermarks of the WPA,
+// along with the one of its calendar.
+func watermarkSources(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) []*datadoghqv1alpha1.WatermarkSource {
+	var sources []*datadoghqv1alpha1.WatermarkSource
+	if wpa.Spec.Calendar != nil && wpa.Spec.Calendar.ConfigMapKeyRef != nil {
+		sources = append(sources, &datadoghqv1alpha1.WatermarkSource{ConfigMapKeyRef: wpa.Spec.Calendar.ConfigMapKeyRef})
+	}
+	for _, metric := range wpa.Spec.Metrics {
+		switch {
+		case metric.External != nil:
+			sources = append(sources, metric.External.HighWatermarkFrom, metric.External.LowWatermarkFrom)
+		case metric.Resource != nil:
+			sources = append(sources, metric.Resource.HighWatermarkFrom, metric.Resource.LowWatermarkFrom)
+		}
+	}
+	return sources
+}
+
+func referencesWatermarkSource(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, kind, name string) bool {
+	for _, source := range watermarkSources(wpa) {
+		if source == nil {
+			continue
+		}
+		if kind == configMapSourceKind && source.ConfigMapKeyRef != nil && source.ConfigMapKeyRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// requestsForWatermarkSource returns a mapping function enqueueing the WPAs reading their watermarks from
+// the ConfigMap that changed. The Secrets aren't watched, they are read again at every reconcile.
+func requestsForWatermarkSource(c client.Client, kind string) handler.ToRequestsFunc {
+	return func(a handler.MapObject) []reconcile.Request {
+		wpas := &datadoghqv1alpha1.WatermarkPodAutoscalerList{}
+		if err := c.List(context.TODO(), wpas, client.InNamespace(a.Meta.GetNamespace())); err != nil {
+			log.Error(err, "Could not list the WatermarkPodAutoscalers", "namespace", a.Meta.GetNamespace())
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range wpas.Items {
+			if referencesWatermarkSource(&wpas.Items[i], kind, a.Meta.GetName()) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: wpas.Items[i].Namespace, Name: wpas.Items[i].Name}})
+			}
+		}
+		return requests
+	}
+}
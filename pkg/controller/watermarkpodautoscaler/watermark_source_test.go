@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveWatermarkSources(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "watermarks"},
+		Data:       map[string]string{"high": " 150m\n", "low": "50m", "invalid": "foo"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testingNamespace, Name: "watermarks"},
+		Data:       map[string][]byte{"low": []byte("100m")},
+	}
+	// The Secrets are read from the API server rather than from the cache of the client.
+	r := &ReconcileWatermarkPodAutoscaler{client: fake.NewFakeClient(configMap), apiReader: fake.NewFakeClient(secret)}
+	fromConfigMap := func(key string) *v1alpha1.WatermarkSource {
+		return &v1alpha1.WatermarkSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "watermarks"}, Key: key}}
+	}
+	fromSecret := func(key string) *v1alpha1.WatermarkSource {
+		return &v1alpha1.WatermarkSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "watermarks"}, Key: key}}
+	}
+	newWPA := func(external *v1alpha1.ExternalMetricSource) *v1alpha1.WatermarkPodAutoscaler {
+		external.MetricName = "foo"
+		return test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+				Metrics: []v1alpha1.MetricSpec{{Type: v1alpha1.ExternalMetricSourceType, External: external}},
+			},
+		})
+	}
+
+	wpa := newWPA(&v1alpha1.ExternalMetricSource{HighWatermarkFrom: fromConfigMap("high"), LowWatermarkFrom: fromSecret("low")})
+	require.NoError(t, r.resolveWatermarkSources(wpa))
+	require.Equal(t, resource.MustParse("150m"), *wpa.Spec.Metrics[0].External.HighWatermark)
+	require.Equal(t, resource.MustParse("100m"), *wpa.Spec.Metrics[0].External.LowWatermark)
+	require.True(t, referencesWatermarkSource(wpa, configMapSourceKind, "watermarks"))
+	require.False(t, referencesWatermarkSource(wpa, configMapSourceKind, "other"))
+
+	high := resource.MustParse("200m")
+	for name, external := range map[string]*v1alpha1.ExternalMetricSource{
+		"missing key":          {HighWatermarkFrom: fromConfigMap("unknown")},
+		"invalid value":        {HighWatermarkFrom: fromConfigMap("invalid")},
+		"value and reference":  {HighWatermark: &high, HighWatermarkFrom: fromConfigMap("high")},
+		"empty reference":      {LowWatermarkFrom: &v1alpha1.WatermarkSource{}},
+		"missing secret value": {LowWatermarkFrom: fromSecret("high")},
+	} {
+		require.Error(t, r.resolveWatermarkSources(newWPA(external)), name)
+	}
+}
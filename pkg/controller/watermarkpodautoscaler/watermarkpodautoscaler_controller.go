@@ -9,10 +9,12 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"strings"
+	"net/http"
+	"os"
 	"time"
 
 	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
 
 	// TODO revisit error level logs as https://github.com/operator-framework/operator-sdk/pull/2319 is merged
 	"github.com/go-logr/logr"
@@ -27,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	discocache "k8s.io/client-go/discovery/cached"
 	"k8s.io/client-go/dynamic"
@@ -36,9 +39,9 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/scale"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	ctrl "k8s.io/kubernetes/pkg/controller"
-	"k8s.io/kubernetes/pkg/controller/podautoscaler/metrics"
 	resourceclient "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
 	"k8s.io/metrics/pkg/client/external_metrics"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -61,7 +64,7 @@ var (
 	dryRunCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "DryRun"
 )
 
-func initializePodInformer(clientConfig *rest.Config, stop chan struct{}) listerv1.PodLister {
+func initializePodInformer(clientConfig *rest.Config, stop chan struct{}) (listerv1.PodLister, cache.InformerSynced) {
 	a := ctrl.SimpleControllerClientBuilder{ClientConfig: clientConfig}
 	versionedClient := a.ClientOrDie("watermark-pod-autoscaler-shared-informer")
 	// Only resync every 5 minutes.
@@ -72,19 +75,24 @@ func initializePodInformer(clientConfig *rest.Config, stop chan struct{}) lister
 
 	go sharedInf.Core().V1().Pods().Informer().Run(stop)
 
-	return sharedInf.Core().V1().Pods().Lister()
+	return sharedInf.Core().V1().Pods().Lister(), sharedInf.Core().V1().Pods().Informer().HasSynced
 }
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	if err := checkControllerDefaults(); err != nil {
+		return nil, err
+	}
 	clientConfig := mgr.GetConfig()
-	metricsClient := metrics.NewRESTMetricsClient(
-		resourceclient.NewForConfigOrDie(clientConfig),
+	metricsClientConfig := rest.CopyConfig(clientConfig)
+	metricsClientConfig.Timeout = metricsClientTimeout
+	metricsClient := NewRESTMetricsClient(
+		resourceclient.NewForConfigOrDie(metricsClientConfig),
 		nil,
-		external_metrics.NewForConfigOrDie(clientConfig),
+		external_metrics.NewForConfigOrDie(metricsClientConfig),
 	)
 	var stop chan struct{}
-	podLister := initializePodInformer(clientConfig, stop)
+	podLister, podsSynced := initializePodInformer(clientConfig, stop)
 
 	clientSet, err := kubernetes.NewForConfig(clientConfig)
 	if err != nil {
@@ -103,19 +111,82 @@ func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
 		return nil, err
 	}
 
-	replicaCalc := NewReplicaCalculator(metricsClient, podLister)
+	replicaCalc := NewReplicaCalculator(metricsClient, podLister, mgr.GetClient())
+	replicaCalc.setMetricsProvider(metricsClientConfig.Host)
+	replicaCalc.apiReader = mgr.GetAPIReader()
+	podAnnotator := newPodAnnotator(clientSet.CoreV1(), defaultPodAnnotationQPS, defaultPodAnnotationBurst)
 	r := &ReconcileWatermarkPodAutoscaler{
-		client:        mgr.GetClient(),
-		scaleClient:   scaleClient,
-		restMapper:    restMapper,
-		scheme:        mgr.GetScheme(),
-		eventRecorder: mgr.GetEventRecorderFor("wpa_controller"),
-		replicaCalc:   replicaCalc,
-		syncPeriod:    defaultSyncPeriod,
+		client:            mgr.GetClient(),
+		apiReader:         mgr.GetAPIReader(),
+		scaleClient:       scaleClient,
+		restMapper:        restMapper,
+		mapperResetter:    newRESTMapperResetter(restMapper, restMapperResetInterval),
+		scheme:            mgr.GetScheme(),
+		eventRecorder:     mgr.GetEventRecorderFor("wpa_controller"),
+		replicaCalc:       replicaCalc,
+		podLister:         podLister,
+		podAnnotator:      podAnnotator,
+		calendars:         newCalendarCache(),
+		recommendations:   newRecommendationHistory(),
+		evaluationWindows: newEvaluationWindows(),
+		httpClient:        &http.Client{Timeout: capacityCeilingTimeout},
+		timeline:          newRecommendationTimeline(timelineSize),
+		dryRunReports:     newDryRunReports(),
+		decisionHistories: newDecisionHistories(),
+		metricRetries:     newMetricRetries(metricRetryBaseDelay, metricRetryMaxDelay),
+		replicaHours:      newReplicaHoursAccounting(),
+		health:            newReconcileHealth(),
+		statusUpdates:     newStatusCoalescer(float32(statusUpdateQPS), statusUpdateBurst, time.Duration(staleReconcileFactor)*defaultSyncPeriod/2),
+		syncPeriod:        defaultSyncPeriod,
+	}
+	if enableRemoteClusters {
+		r.remoteClusters = newRemoteClusters(mgr.GetScheme())
+	}
+	if err = addHealthChecks(mgr, r.health, clientSet.Discovery(), podsSynced); err != nil {
+		return nil, err
+	}
+	if metricProbeInterval > 0 {
+		replicaCalc.probes = newMetricProbes(metricProbeInterval)
+		if err = mgr.Add(&metricProber{client: mgr.GetClient(), metricsClient: metricsClient, probes: replicaCalc.probes}); err != nil {
+			return nil, err
+		}
+	}
+	if timelineBindAddress != "" {
+		if err = mgr.Add(&timelineServer{address: timelineBindAddress, timeline: r.timeline}); err != nil {
+			return nil, err
+		}
+	}
+	if dashboardBindAddress != "" {
+		if err = mgr.Add(&dashboardServer{address: dashboardBindAddress, reconciler: r}); err != nil {
+			return nil, err
+		}
 	}
 	return r, nil
 }
 
+// NewReconciler returns a ReconcileWatermarkPodAutoscaler relying on the given clients rather than on a manager, e.g.
+// to reconcile WPAs against the fakes of the wpatest package. The pods are not annotated with their deletion cost, the
+// remote clusters are disabled, and the status of the WPAs is written at every reconcile.
+func NewReconciler(client client.Client, scaleClient scale.ScalesGetter, podLister listerv1.PodLister, restMapper apimeta.RESTMapper, scheme *runtime.Scheme, eventRecorder record.EventRecorder, replicaCalc ReplicaCalculatorItf) *ReconcileWatermarkPodAutoscaler {
+	return &ReconcileWatermarkPodAutoscaler{
+		client:            client,
+		scaleClient:       scaleClient,
+		podLister:         podLister,
+		restMapper:        restMapper,
+		scheme:            scheme,
+		eventRecorder:     eventRecorder,
+		replicaCalc:       replicaCalc,
+		recommendations:   newRecommendationHistory(),
+		evaluationWindows: newEvaluationWindows(),
+		httpClient:        &http.Client{Timeout: capacityCeilingTimeout},
+		timeline:          newRecommendationTimeline(timelineSize),
+		metricRetries:     newMetricRetries(metricRetryBaseDelay, metricRetryMaxDelay),
+		replicaHours:      newReplicaHoursAccounting(),
+		health:            newReconcileHealth(),
+		syncPeriod:        defaultSyncPeriod,
+	}
+}
+
 // Add creates a new WatermarkPodAutoscaler Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
@@ -123,6 +194,41 @@ func Add(mgr manager.Manager) error {
 	if err != nil {
 		return err
 	}
+	if err = mgr.Add(&gaugeRestorer{client: mgr.GetClient()}); err != nil {
+		return err
+	}
+	if staleReconcileFactor > 0 {
+		if err = mgr.Add(&staleWatchdog{client: mgr.GetClient(), syncPeriod: defaultSyncPeriod, factor: staleReconcileFactor}); err != nil {
+			return err
+		}
+	}
+	if remoteWriteURL != "" {
+		writer, err := newRemoteWriter(remoteWriteURL, remoteWriteInterval, remoteWriteBearerTokenFile)
+		if err != nil {
+			return err
+		}
+		if err = mgr.Add(writer); err != nil {
+			return err
+		}
+	}
+	if dogstatsdAddress != "" {
+		sink, err := newDogstatsdSink(dogstatsdAddress, dogstatsdFlushInterval, dogstatsdTags)
+		if err != nil {
+			return err
+		}
+		if err = mgr.Add(sink); err != nil {
+			return err
+		}
+	}
+	exporter, err := newOTLPExporterFromEnv(os.Getenv)
+	if err != nil {
+		return err
+	}
+	if exporter != nil {
+		if err = mgr.Add(exporter); err != nil {
+			return err
+		}
+	}
 	return add(mgr, r)
 }
 
@@ -133,10 +239,28 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+	if err = setQueueRateLimiter(c, "watermarkpodautoscaler-controller", newQueueRateLimiter()); err != nil {
+		log.Error(err, "Falling back to the default rate limiter of the workqueue")
+	}
 
 	p := predicate.Funcs{UpdateFunc: updatePredicate}
 	// Watch for changes to primary resource WatermarkPodAutoscaler
-	return c.Watch(&source.Kind{Type: &datadoghqv1alpha1.WatermarkPodAutoscaler{}}, &handler.EnqueueRequestForObject{}, p)
+	if err = c.Watch(&source.Kind{Type: &datadoghqv1alpha1.WatermarkPodAutoscaler{}}, &handler.EnqueueRequestForObject{}, p); err != nil {
+		return err
+	}
+
+	// Watch for changes to the ConfigMaps the watermarks can be read from
+	if err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: requestsForWatermarkSource(mgr.GetClient(), configMapSourceKind)}); err != nil {
+		return err
+	}
+
+	// Watch for changes to the ScalingPlans, which apply to the WPA they reference
+	if err = c.Watch(&source.Kind{Type: &datadoghqv1alpha1.ScalingPlan{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: requestsForScalingPlan()}); err != nil {
+		return err
+	}
+
+	// Watch for changes to the policies, which apply to all the WPAs
+	return c.Watch(&source.Kind{Type: &datadoghqv1alpha1.WatermarkPodAutoscalerPolicy{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: requestsForPolicy(mgr.GetClient())})
 }
 
 // When the WPA is changed (status is changed, edited by the user, etc),
@@ -171,17 +295,61 @@ type ReconcileWatermarkPodAutoscaler struct {
 	syncPeriod    time.Duration
 	eventRecorder record.EventRecorder
 	replicaCalc   ReplicaCalculatorItf
+	podLister     listerv1.PodLister
+	podAnnotator  *podAnnotator
+	calendars     *calendarCache
+	// apiReader reads the Secrets from the API server, so that the Secrets of the cluster are neither watched nor
+	// cached. The client is used when nil.
+	apiReader client.Reader
+	// recommendations keeps the last proposals of replicas of the WPAs with a recommendation history.
+	recommendations *recommendationHistory
+	// evaluationWindows keeps since when the metrics of the WPAs with an evaluation window recommend a scale.
+	evaluationWindows *evaluationWindows
+	// health keeps track of the failed reconciles, reported in the liveness of the controller.
+	health *reconcileHealth
+	// statusUpdates coalesces the minor status updates, all of them are written when nil.
+	statusUpdates *statusCoalescer
+	// mapperResetter resets the RESTMapper on stale mappings, it is never reset when nil.
+	mapperResetter *restMapperResetter
+	// timeline keeps the outcomes of the last reconciles, served by the recommendation API.
+	timeline *recommendationTimeline
+	// metricRetries backs off the failed metrics of the WPAs, they are computed at every reconcile when nil.
+	metricRetries *metricRetries
+	// dryRunReports keeps the ongoing reports of the WPAs in dry-run mode, they are not written when nil.
+	dryRunReports *dryRunReports
+	// decisionHistories keeps the last decisions persisted for the WPAs with a decision history, they are not persisted when nil.
+	decisionHistories *decisionHistories
+	// replicaHours keeps the last accounting of the replica-hours of the WPAs in the counters, which are not
+	// incremented when nil.
+	replicaHours *replicaHoursAccounting
+	// remoteClusters caches the clients of the clusters the targets of some WPAs run in.
+	remoteClusters *remoteClusters
+	// httpClient polls the capacityCeiling endpoints of the WPAs.
+	httpClient *http.Client
 }
 
 // Reconcile reads that state of the cluster for a WatermarkPodAutoscaler object and makes changes based on the state read
 // and what is in the WatermarkPodAutoscaler.Spec
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;update;patch
-// +kubebuilder:rbac:groups=,resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets;replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.openshift.io,resources=deploymentconfigs,verbs=get;list
+// +kubebuilder:rbac:groups=apps.openshift.io,resources=deploymentconfigs/scale,verbs=get;update
+// +kubebuilder:rbac:groups=,resources=pods,verbs=get;list;patch
+// +kubebuilder:rbac:groups=,resources=resourcequotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=,resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=,resources=configmaps,verbs=create;update
 // +kubebuilder:rbac:groups=datadoghq.com,resources=watermarkpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=datadoghq.com,resources=wpagroups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=datadoghq.com,resources=watermarkpodautoscalerpolicies,verbs=get;list;watch
 func (r *ReconcileWatermarkPodAutoscaler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	logger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	if !util.OwnsNamespace(request.Namespace) {
+		// the namespace belongs to the shard of another replica of the controller
+		return reconcile.Result{}, nil
+	}
 	logger.Info("Reconciling WatermarkPodAutoscaler")
 
 	// resRepeat will be returned if we want to re-run reconcile process
@@ -197,26 +365,42 @@ func (r *ReconcileWatermarkPodAutoscaler) Reconcile(request reconcile.Request) (
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			r.health.forget(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
+		r.health.record(request.NamespacedName, err)
 		return reconcile.Result{}, err
 	}
+	logger = loggerForWPA(logger, instance)
 
 	var needToReturn bool
 	if needToReturn, err = r.handleFinalizer(logger, instance); err != nil || needToReturn {
 		return reconcile.Result{}, err
 	}
 
-	if !datadoghqv1alpha1.IsDefaultWatermarkPodAutoscaler(instance) {
-		logger.Info("Some configuration options are missing, falling back to the default ones")
-		defaultWPA := datadoghqv1alpha1.DefaultWatermarkPodAutoscaler(instance)
-		if err := r.client.Update(context.TODO(), defaultWPA); err != nil {
-			logger.Info("Failed to set the default values during reconciliation", "error", err)
+	// The defaults are applied in memory only, writing them to the spec would conflict with the tools
+	// managing the WPA from a source of truth, and trigger another reconcile.
+	missingDefaults := append(datadoghqv1alpha1.MissingDefaults(instance), missingControllerDefaults(instance)...)
+	if len(missingDefaults) > 0 {
+		logger.V(1).Info("Some configuration options are missing, falling back to the default ones", "options", missingDefaults)
+		policies, err := r.listPolicies()
+		if err != nil {
 			return reconcile.Result{}, err
 		}
-		// default values of the WatermarkPodAutoscaler are set. Return and requeue to show them in the spec.
-		return reconcile.Result{Requeue: true}, nil
+		instance = datadoghqv1alpha1.DefaultWatermarkPodAutoscaler(withControllerDefaults(withPolicyDefaults(instance, policies)))
+	}
+	instance.Status.AppliedDefaults = missingDefaults
+	if err := r.resolveWatermarkSources(instance); err != nil {
+		logger.Info("Error while resolving the watermarks", "error", err)
+		r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedResolveWatermarks", err.Error())
+		wpaStatusOriginal := instance.Status.DeepCopy()
+		setCondition(instance, autoscalingv2.AbleToScale, corev1.ConditionFalse, "FailedResolveWatermarks", "the WPA controller was unable to resolve the watermarks: %v", err)
+		if err := r.updateStatusIfNeeded(wpaStatusOriginal, instance); err != nil {
+			r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedUpdateStatus", err.Error())
+		}
+		// the ConfigMaps and Secrets are watched, creating the missing ones will requeue the resource.
+		return resRepeat, nil
 	}
 	if err := datadoghqv1alpha1.CheckWPAValidity(instance); err != nil {
 		logger.Info("Got an invalid WPA spec", "Instance", request.NamespacedName.String(), "error", err)
@@ -239,10 +423,49 @@ func (r *ReconcileWatermarkPodAutoscaler) Reconcile(request reconcile.Request) (
 	} else {
 		setCondition(instance, dryRunCondition, corev1.ConditionFalse, "DryRun mode disabled", "Scaling changes can be applied")
 	}
-	if err := r.reconcileWPA(logger, instance); err != nil {
+	if err := r.resolveScaleTargets(logger, instance); err != nil {
+		logger.Info("Error while resolving the scale targets", "error", err)
+		r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedResolveScaleTargets", err.Error())
+		wpaStatusOriginal := instance.Status.DeepCopy()
+		setCondition(instance, autoscalingv2.AbleToScale, corev1.ConditionFalse, "FailedResolveScaleTargets", "the WPA controller was unable to resolve the scale targets: %v", err)
+		if err := r.updateStatusIfNeeded(wpaStatusOriginal, instance); err != nil {
+			r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedUpdateStatus", err.Error())
+		}
+		return resRepeat, nil
+	}
+	if err := checkTargetKinds(instance); err != nil {
+		logger.Info("The kind of a target is not allowed", "error", err)
+		r.eventRecorder.Event(instance, corev1.EventTypeWarning, "TargetKindNotAllowed", err.Error())
+		wpaStatusOriginal := instance.Status.DeepCopy()
+		setCondition(instance, autoscalingv2.AbleToScale, corev1.ConditionFalse, "TargetKindNotAllowed", "the WPA controller is not allowed to scale the target: %v", err)
+		if err := r.updateStatusIfNeeded(wpaStatusOriginal, instance); err != nil {
+			r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedUpdateStatus", err.Error())
+		}
+		// the allowed kinds don't change while the controller runs, an update of the spec requeues the resource.
+		return reconcile.Result{}, nil
+	}
+	reconciler, err := r.forCluster(instance)
+	if err != nil {
+		logger.Info("Error while getting the remote cluster", "error", err)
+		r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedGetRemoteCluster", err.Error())
+		wpaStatusOriginal := instance.Status.DeepCopy()
+		setCondition(instance, autoscalingv2.AbleToScale, corev1.ConditionFalse, "FailedGetRemoteCluster", "the WPA controller was unable to get the clients of the remote cluster: %v", err)
+		if err := r.updateStatusIfNeeded(wpaStatusOriginal, instance); err != nil {
+			r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedUpdateStatus", err.Error())
+		}
+		r.health.record(request.NamespacedName, err)
+		return resRepeat, nil
+	}
+	err = reconciler.reconcileWPA(logger, instance)
+	r.health.record(request.NamespacedName, err)
+	if err != nil {
 		logger.Info("Error during reconcileWPA", "error", err)
 		r.eventRecorder.Event(instance, corev1.EventTypeWarning, "FailedProcessWPA", err.Error())
 		setCondition(instance, autoscalingv2.AbleToScale, corev1.ConditionFalse, "FailedProcessWPA", "Error happened while processing the WPA")
+		if classifyMetricError(err) == metricNotFound {
+			// retrying right away won't make a missing metric appear, the probes query it in the meantime.
+			return resRepeat, nil
+		}
 		// In case of `reconcileWPA` error, we need to requeue the Resource in order to retry to process it again
 		// we put a delay of 1 second in order to not retry directly and limit the number of retries if it only a transient issue.
 		return reconcile.Result{RequeueAfter: time.Second}, nil
@@ -268,19 +491,66 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 		Group: targetGV.Group,
 		Kind:  wpa.Spec.ScaleTargetRef.Kind,
 	}
-	mappings, err := r.restMapper.RESTMappings(targetGK)
+	currentScale, targetGR, err := r.getScaleForGroupKind(wpa.Namespace, wpa.Spec.ScaleTargetRef.Name, targetGK)
+	if currentScale == nil {
+		// it is possible that one of the GK in the mappings was not found, but if we have at least one that works, we can continue reconciling.
+		return err
+	}
+	if wpa.Spec.ScaleTargetRef.Kind == deploymentConfigKind && currentScale.Status.Selector == "" {
+		if err = r.setDeploymentConfigSelector(wpa.Namespace, wpa.Spec.ScaleTargetRef.Name, currentScale); err != nil {
+			return err
+		}
+	} else if currentScale.Status.Selector == "" {
+		if err = r.setOwnedPodsSelector(wpa, currentScale); err != nil {
+			return err
+		}
+	}
+	shards, err := r.getShards(wpa, targetGK)
 	if err != nil {
-		return fmt.Errorf("unable to determine resource for scale target reference: %v", err)
+		return err
 	}
-
-	currentScale, targetGR, err := r.getScaleForResourceMappings(wpa.Namespace, wpa.Spec.ScaleTargetRef.Name, mappings)
-	if currentScale == nil && strings.Contains(err.Error(), "not found") {
-		// it is possible that one of the GK in the mappings was not found, but if we have at least one that works, we can continue reconciling.
+	metricsReconciler, err := r.withShards(shards)
+	if err != nil {
 		return err
 	}
-	currentReplicas := currentScale.Status.Replicas
+	// totalScale is the scale of the whole workload when it is sharded across clusters.
+	totalScale := shardedScale(currentScale, shards)
+	currentReplicas := totalScale.Status.Replicas
 	logger.Info("Target deploy", "replicas", currentReplicas)
 	wpaStatusOriginal := wpa.Status.DeepCopy()
+	wpa.Status.Selector = currentScale.Status.Selector
+	wpa.Status.ActiveMetric = nil
+	wpa.Status.MetricDetails = nil
+	wpa.Status.Pods = nil
+	wpa.Status.SuppressedReplicas = 0
+	r.accountReplicaHours(wpa, currentReplicas, time.Now())
+	r.applyActiveProfile(logger, wpa, time.Now())
+	r.applyScalingPlans(logger, wpa, time.Now())
+	if wpa.Spec.Replicas != nil {
+		pinReplicas(&wpa.Spec, *wpa.Spec.Replicas)
+	}
+	if detectReplicaDrift(wpa, totalScale.Spec.Replicas) {
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "ReplicaDrift", "%s was scaled to %d replicas outside of the WPA, which last applied %d replicas", wpa.Spec.ScaleTargetRef.Name, totalScale.Spec.Replicas, wpa.Status.LastAppliedReplicas)
+	}
+	if applyFlappingDetection(wpa, time.Now()) {
+		r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "Flapping", "The scaling direction of %s changed %d times in the last %ds, the watermarks are likely too tight", wpa.Spec.ScaleTargetRef.Name, len(wpa.Status.ScaleReversals), wpa.Spec.FlappingDetection.WindowSeconds)
+	}
+	applyHysteresis(logger, wpa, time.Now())
+	r.pollCapacityCeiling(logger, wpa, time.Now())
+	r.reportFailedPods(logger, wpa, currentScale)
+	allowed, err := r.enforcePolicies(logger, wpa)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return r.updateStatusIfNeeded(wpaStatusOriginal, wpa)
+	}
+	if err = r.checkTargetOwnership(wpa, wpa.Spec.ScaleTargetRef); err != nil {
+		r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "TargetNotOwned", err.Error())
+		setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "TargetNotOwned", "the WPA controller is not allowed to scale the target: %v", err)
+		logger.Info("Not allowed to scale the target", "error", err)
+		return r.updateStatusIfNeeded(wpaStatusOriginal, wpa)
+	}
 
 	reference := fmt.Sprintf("%s/%s/%s", wpa.Spec.ScaleTargetRef.Kind, wpa.Namespace, wpa.Spec.ScaleTargetRef.Name)
 	setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionTrue, "SucceededGetScale", "the WPA controller was able to get the target's current scale")
@@ -289,6 +559,7 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 		metricStatuses = []autoscalingv2.MetricStatus{}
 	}
 	proposedReplicas := int32(0)
+	proposed := false
 	metricName := ""
 
 	desiredReplicas := int32(0)
@@ -297,7 +568,7 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 
 	rescale := true
 	switch {
-	case currentScale.Spec.Replicas == 0:
+	case totalScale.Spec.Replicas == 0:
 		// Autoscaling is disabled for this resource
 		desiredReplicas = 0
 		rescale = false
@@ -313,13 +584,19 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 		desiredReplicas = 1
 	default:
 		var metricTimestamp time.Time
+		var emergency bool
 
-		proposedReplicas, metricName, metricStatuses, metricTimestamp, err = r.computeReplicasForMetrics(logger, wpa, currentScale)
+		proposedReplicas, metricName, metricStatuses, metricTimestamp, emergency, err = metricsReconciler.computeReplicasForMetrics(logger, wpa, totalScale)
 		if err != nil {
+			countDecision(wpa, decisionMetricError)
+			r.recordTimeline(wpa, decisionMetricError, proposed, proposedReplicas, currentReplicas)
+			r.recordDryRunReport(logger, wpa, decisionMetricError, proposed, proposedReplicas, currentReplicas, currentReplicas, time.Now())
+			r.recordDecisionHistory(logger, wpa, decisionMetricError, proposed, proposedReplicas, currentReplicas, currentReplicas, time.Now())
+			setProposalDelta(wpa, proposed, proposedReplicas, currentReplicas)
 			r.setCurrentReplicasInStatus(wpa, currentReplicas)
 			if err2 := r.updateStatusIfNeeded(wpaStatusOriginal, wpa); err2 != nil {
 				r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "FailedUpdateReplicas", err2.Error())
-				setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "FailedUpdateReplicas", "the WPA controller was unable to update the number of replicas: %v", err)
+				setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "FailedUpdateReplicas", "the WPA controller was unable to update the number of replicas: %v", err2)
 				logger.Info("The WPA controller was unable to update the number of replicas", "error", err2)
 				return nil
 			}
@@ -328,6 +605,10 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 			return nil
 		}
 		logger.Info("Proposing replicas", "proposedReplicas", proposedReplicas, "metricName", metricName, "reference", reference)
+		if wpa.Spec.RecommendationHistory != nil {
+			proposedReplicas = r.aggregateProposals(logger, wpa, proposedReplicas)
+		}
+		proposed = true
 
 		rescaleMetric := ""
 		if proposedReplicas > desiredReplicas {
@@ -342,25 +623,96 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 			rescaleReason = "All metrics below target"
 		}
 
-		desiredReplicas = normalizeDesiredReplicas(logger, wpa, currentReplicas, desiredReplicas)
+		if wpa.Spec.SteppedConvergence != nil && desiredReplicas > currentReplicas && !emergency {
+			if stepped := stepDesiredReplicas(logger, wpa.Spec.SteppedConvergence, currentReplicas, desiredReplicas); stepped != desiredReplicas {
+				rescaleReason = fmt.Sprintf("%s, stepping towards %d replicas", rescaleReason, desiredReplicas)
+				desiredReplicas = stepped
+			}
+		}
+		desiredReplicas = scaleLimitsNormalizer.Normalize(logger, wpa, currentScale, currentReplicas, desiredReplicas)
+		if emergency {
+			desiredReplicas = emergencyReplicas(logger, wpa, currentReplicas, proposedReplicas, desiredReplicas)
+		} else if wpa.Spec.DownscaleEvaluationWindowSeconds > 0 || wpa.Spec.UpscaleEvaluationWindowSeconds > 0 {
+			desiredReplicas = r.sustainScale(logger, wpa, currentReplicas, desiredReplicas, time.Now())
+		}
+		if wpa.Spec.DriftPolicy == datadoghqv1alpha1.DriftPolicyCorrect && desiredReplicas == currentReplicas {
+			if corrected, drifting := correctReplicaDrift(logger, wpa, totalScale.Spec.Replicas); drifting {
+				rescaleReason = "Correcting the replicas changed outside of the WPA"
+				desiredReplicas = corrected
+			}
+		}
+		if wpa.Spec.Baseline != nil && someMetricsFailed(wpa) {
+			// the failed metrics are only trusted to scale up, the idle period starts again once they recover
+			wpa.Status.IdleSince = nil
+		} else if wpa.Spec.Baseline != nil {
+			updateIdleSince(wpa, metricStatuses, time.Now())
+			if baseline, idle := baselineReplicas(logger, wpa, time.Now()); idle && baseline < desiredReplicas {
+				rescaleReason = "All metrics idle, returning to the baseline"
+				desiredReplicas = baseline
+				setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionFalse, "ReturningToBaseline", "the metrics have been idle since %s, the desired replica count is the baseline", wpa.Status.IdleSince.Format(time.RFC3339))
+			}
+		}
+		desiredReplicas = r.capNormalizers(wpa).Normalize(logger, wpa, currentScale, currentReplicas, desiredReplicas)
 		logger.Info("Normalized replicas", "desiredReplicas", desiredReplicas)
 
 		rescale = shouldScale(logger, wpa, currentReplicas, desiredReplicas, now)
+		if emergency && desiredReplicas > currentReplicas {
+			rescale = true
+			rescaleReason = "Usage above the emergency high watermark"
+		}
+		if rescale && desiredReplicas < currentReplicas && wpa.Spec.DeletionCostPolicy == datadoghqv1alpha1.DeletionCostPolicyWait {
+			rescale = r.isDeletionCostCovered(logger, wpa, currentScale)
+		}
+		if rescale && wpa.Spec.FreezeDuringRollout {
+			rescale = !r.isRolloutInProgress(logger, wpa)
+		}
+		if rescale && wpa.Spec.MaxScaleEventsPerHour != nil {
+			rescale = !isRateLimited(logger, wpa, time.Now())
+		}
+		if emergency && desiredReplicas > currentReplicas {
+			// the emergency upscale is not split, and supersedes the canary in progress
+			if rescale {
+				abortCanary(logger, wpa)
+			}
+		} else if wpa.Spec.CanaryPercent > 0 || wpa.Status.Canary != nil {
+			var canaryReason string
+			desiredReplicas, rescale, canaryReason = applyCanary(logger, wpa, currentReplicas, desiredReplicas, rescale, time.Now())
+			if canaryReason != "" {
+				rescaleReason = canaryReason
+			}
+		}
+		if emergency && rescale && desiredReplicas > currentReplicas {
+			r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "EmergencyScale", "A metric is above its emergency high watermark, scaling from %d to %d replicas regardless of the upscale forbidden window and the scale-up limit factor", currentReplicas, desiredReplicas)
+		}
 	}
 
+	decision := scalingDecision(wpa, proposed, proposedReplicas, currentReplicas, desiredReplicas, rescale)
 	if rescale {
 		setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionTrue, "ReadyForScale", "the last scaling time was sufficiently old as to warrant a new scale")
 		if wpa.Spec.DryRun {
 			logger.Info("DryRun mode: scaling change was inhibited", "currentReplicas", currentReplicas, "desiredReplicas", desiredReplicas)
+			countDecision(wpa, decision)
+			r.recordTimeline(wpa, decision, proposed, proposedReplicas, currentReplicas)
+			r.recordDryRunReport(logger, wpa, decision, proposed, proposedReplicas, currentReplicas, desiredReplicas, time.Now())
+			r.recordDecisionHistory(logger, wpa, decision, proposed, proposedReplicas, currentReplicas, desiredReplicas, time.Now())
+			setProposalDelta(wpa, proposed, proposedReplicas, currentReplicas)
+			observeAppliedReplicas(wpa, currentReplicas)
 			setStatus(wpa, currentReplicas, desiredReplicas, metricStatuses, rescale)
 			return r.updateStatusIfNeeded(wpaStatusOriginal, wpa)
 		}
 
 		currentScale.Spec.Replicas = desiredReplicas
+		var split []int32
+		if len(shards) > 0 {
+			split = splitReplicas(desiredReplicas, shardWeights(wpa, shards))
+			currentScale.Spec.Replicas = split[0]
+		}
 		_, err = r.scaleClient.Scales(wpa.Namespace).Update(targetGR, currentScale)
 		if err != nil {
 			r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "FailedRescale", fmt.Sprintf("New size: %d; reason: %s; error: %v", desiredReplicas, rescaleReason, err.Error()))
 			setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionFalse, "FailedUpdateScale", "the HPA controller was unable to update the target scale: %v", err)
+			wpa.Status.Canary = wpaStatusOriginal.Canary
+			setProposalDelta(wpa, proposed, proposedReplicas, currentReplicas)
 			r.setCurrentReplicasInStatus(wpa, currentReplicas)
 			if err := r.updateStatusIfNeeded(wpaStatusOriginal, wpa); err != nil {
 				r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "FailedUpdateReplicas", err.Error())
@@ -372,13 +724,29 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 		setCondition(wpa, autoscalingv2.AbleToScale, corev1.ConditionTrue, datadoghqv1alpha1.ConditionReasonSucceededRescale, "the HPA controller was able to update the target scale to %d", desiredReplicas)
 		r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "SuccessfulRescale", fmt.Sprintf("New size: %d; reason: %s", desiredReplicas, rescaleReason))
 
+		if len(shards) > 0 {
+			r.scaleShards(logger, wpa, shards, split[1:])
+		}
+		recordScaleDirection(wpa, currentReplicas, desiredReplicas, time.Now())
+		recordAppliedReplicas(wpa, desiredReplicas)
+		recordScaleEvent(wpa, time.Now())
 		logger.Info("Successful rescale", "currentReplicas", currentReplicas, "desiredReplicas", desiredReplicas, "rescaleReason", rescaleReason)
 	} else {
 		r.eventRecorder.Eventf(wpa, corev1.EventTypeNormal, "NotScaling", fmt.Sprintf("Decided not to scale %s to %d (last scale time was %v )", reference, desiredReplicas, wpa.Status.LastScaleTime))
 		desiredReplicas = currentReplicas
 	}
 
+	if len(wpa.Spec.ScaleTargetRefs) > 0 && !wpa.Spec.DryRun && desiredReplicas > 0 {
+		r.scaleAdditionalTargets(logger, wpa, desiredReplicas)
+	}
+
 	replicaEffective.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Set(float64(desiredReplicas))
+	countDecision(wpa, decision)
+	r.recordTimeline(wpa, decision, proposed, proposedReplicas, desiredReplicas)
+	r.recordDryRunReport(logger, wpa, decision, proposed, proposedReplicas, currentReplicas, desiredReplicas, time.Now())
+	r.recordDecisionHistory(logger, wpa, decision, proposed, proposedReplicas, currentReplicas, desiredReplicas, time.Now())
+	setProposalDelta(wpa, proposed, proposedReplicas, desiredReplicas)
+	observeAppliedReplicas(wpa, desiredReplicas)
 	setStatus(wpa, currentReplicas, desiredReplicas, metricStatuses, rescale)
 	return r.updateStatusIfNeeded(wpaStatusOriginal, wpa)
 }
@@ -387,11 +755,12 @@ func (r *ReconcileWatermarkPodAutoscaler) reconcileWPA(logger logr.Logger, wpa *
 // resource with the given name and namespace, trying each RESTMapping
 // in turn until a working one is found.  If none work, the first error
 // is returned.  It returns both the scale, as well as the group-resource from
-// the working mapping.
-func (r *ReconcileWatermarkPodAutoscaler) getScaleForResourceMappings(namespace, name string, mappings []*apimeta.RESTMapping) (*autoscalingv1.Scale, schema.GroupResource, error) {
+// the working mapping, and whether one of the mappings was not found.
+func (r *ReconcileWatermarkPodAutoscaler) getScaleForResourceMappings(namespace, name string, mappings []*apimeta.RESTMapping) (*autoscalingv1.Scale, schema.GroupResource, bool, error) {
 	var errs []error
 	var scale *autoscalingv1.Scale
 	var targetGR schema.GroupResource
+	notFound := false
 	for _, mapping := range mappings {
 		var err error
 		targetGR = mapping.Resource.GroupResource()
@@ -399,13 +768,30 @@ func (r *ReconcileWatermarkPodAutoscaler) getScaleForResourceMappings(namespace,
 		if err == nil {
 			break
 		}
+		notFound = notFound || errors.IsNotFound(err)
 		errs = append(errs, fmt.Errorf("could not get scale for the GV %s, error: %v", mapping.GroupVersionKind.GroupVersion().String(), err.Error()))
 	}
 	if scale == nil {
 		errs = append(errs, fmt.Errorf("scale not found"))
 	}
 	// make sure we handle an empty set of mappings
-	return scale, targetGR, utilerrors.NewAggregate(errs)
+	return scale, targetGR, notFound, utilerrors.NewAggregate(errs)
+}
+
+// setProposalDelta exports the difference between the replicas proposed by the algorithm and the replicas applied to
+// the target, which the cooldowns, the caps and the dry-run mode account for. It is removed when there is no proposal.
+func setProposalDelta(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, proposed bool, proposedReplicas, appliedReplicas int32) {
+	promLabelsForWpa := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
+	if !proposed {
+		replicaProposalDelta.Delete(promLabelsForWpa)
+		return
+	}
+	replicaProposalDelta.With(promLabelsForWpa).Set(float64(proposedReplicas - appliedReplicas))
+}
+
+// observeAppliedReplicas records the replicas of the target in the histogram, one observation per reconcile.
+func observeAppliedReplicas(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, appliedReplicas int32) {
+	replicaHistogram.With(prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}).Observe(float64(appliedReplicas))
 }
 
 func shouldScale(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas int32, timestamp time.Time) bool {
@@ -466,7 +852,7 @@ func (r *ReconcileWatermarkPodAutoscaler) setCurrentReplicasInStatus(wpa *datado
 // updateStatusIfNeeded calls updateStatus only if the status of the new HPA is not the same as the old status
 func (r *ReconcileWatermarkPodAutoscaler) updateStatusIfNeeded(wpaStatus *datadoghqv1alpha1.WatermarkPodAutoscalerStatus, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler) error {
 	// skip a write if we wouldn't need to update
-	if apiequality.Semantic.DeepEqual(wpaStatus, &wpa.Status) {
+	if !r.statusUpdates.shouldWrite(wpaStatus, &wpa.Status, time.Now()) {
 		return nil
 	}
 	return r.updateWPA(wpa)
@@ -476,24 +862,27 @@ func (r *ReconcileWatermarkPodAutoscaler) updateWPA(wpa *datadoghqv1alpha1.Water
 	return r.client.Status().Update(context.TODO(), wpa)
 }
 
-// setStatus recreates the status of the given WPA, updating the current and
-// desired replicas, as well as the metric statuses
+// pinReplicas sets both bounds of the spec, in memory, to the pinned number of replicas.
+func pinReplicas(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, replicas int32) {
+	spec.MinReplicas = datadoghqv1alpha1.NewInt32(replicas)
+	spec.MaxReplicas = replicas
+}
+
+// setStatus updates the current and desired replicas, the metric statuses and the times in the status of the given
+// WPA, keeping the rest of the status as is
 func setStatus(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas int32, metricStatuses []autoscalingv2.MetricStatus, rescale bool) {
-	wpa.Status = datadoghqv1alpha1.WatermarkPodAutoscalerStatus{
-		CurrentReplicas: currentReplicas,
-		DesiredReplicas: desiredReplicas,
-		CurrentMetrics:  metricStatuses,
-		LastScaleTime:   wpa.Status.LastScaleTime,
-		Conditions:      wpa.Status.Conditions,
-	}
+	wpa.Status.CurrentReplicas = currentReplicas
+	wpa.Status.DesiredReplicas = desiredReplicas
+	wpa.Status.CurrentMetrics = metricStatuses
 
+	now := metav1.NewTime(time.Now())
+	wpa.Status.LastSuccessfulReconcile = &now
 	if rescale {
-		now := metav1.NewTime(time.Now())
 		wpa.Status.LastScaleTime = &now
 	}
 }
 
-func (r *ReconcileWatermarkPodAutoscaler) computeReplicasForMetrics(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale) (replicas int32, metric string, statuses []autoscalingv2.MetricStatus, timestamp time.Time, err error) {
+func (r *ReconcileWatermarkPodAutoscaler) computeReplicasForMetrics(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, scale *autoscalingv1.Scale) (replicas int32, metric string, statuses []autoscalingv2.MetricStatus, timestamp time.Time, emergency bool, err error) {
 	statuses = make([]autoscalingv2.MetricStatus, len(wpa.Spec.Metrics))
 
 	labels := prometheus.Labels{wpaNamePromLabel: wpa.Name, resourceNamespacePromLabel: wpa.Namespace, resourceNamePromLabel: wpa.Spec.ScaleTargetRef.Name, resourceKindPromLabel: wpa.Spec.ScaleTargetRef.Kind}
@@ -511,43 +900,80 @@ func (r *ReconcileWatermarkPodAutoscaler) computeReplicasForMetrics(logger logr.
 		resourceKindPromLabel:      wpa.Spec.ScaleTargetRef.Kind,
 	}
 
-	for i, metricSpec := range wpa.Spec.Metrics {
+	fallbacks := metricFallbacks(wpa)
+	fallenBack := map[string]bool{}
+	var slowestMetric string
+	var slowestLatency time.Duration
+	var proposals []metricProposal
+	var failed []failedMetric
+	key := types.NamespacedName{Namespace: wpa.Namespace, Name: wpa.Name}
+	for _, i := range metricsEvaluationOrder(wpa) {
+		metricSpec := wpa.Spec.Metrics[i]
 		if metricSpec.External == nil && metricSpec.Resource == nil {
 			continue
 		}
+		if metricSpec.FallbackFor != "" && !fallenBack[metricSpec.FallbackFor] {
+			continue
+		}
 
 		var replicaCountProposal int32
 		var utilizationProposal int64
 		var timestampProposal time.Time
 		var metricNameProposal string
+		var activeMetricProposal *datadoghqv1alpha1.ActiveMetricStatus
 		switch metricSpec.Type {
 		case datadoghqv1alpha1.ExternalMetricSourceType:
 			if metricSpec.External.HighWatermark != nil && metricSpec.External.LowWatermark != nil {
-				metricNameProposal = fmt.Sprintf("%s{%v}", metricSpec.External.MetricName, metricSpec.External.MetricSelector.MatchLabels)
-
-				promLabelsForWpaWithMetricName := prometheus.Labels{
-					wpaNamePromLabel:           wpa.Name,
-					resourceNamespacePromLabel: wpa.Namespace,
-					resourceNamePromLabel:      wpa.Spec.ScaleTargetRef.Name,
-					resourceKindPromLabel:      wpa.Spec.ScaleTargetRef.Kind,
-					metricNamePromLabel:        metricSpec.External.MetricName,
+				metricNameProposal = fmt.Sprintf("%s{%v}", metricSpec.External.MetricName, datadoghqv1alpha1.MetricSelectorLabels(metricSpec.External.MetricSelector))
+
+				var replicaCalculation ReplicaCalculation
+				errMetricsServer := r.metricRetries.allow(key, metricNameProposal, time.Now())
+				if errMetricsServer == nil {
+					ctx, cancel := context.WithTimeout(context.TODO(), metricsClientTimeout)
+					replicaCalculation, errMetricsServer = r.replicaCalc.GetExternalMetricReplicas(ctx, logger, scale, metricSpec, wpa)
+					cancel()
+					r.metricRetries.record(key, metricNameProposal, errMetricsServer, time.Now())
+				}
+				setMetricsProviderCondition(wpa, errMetricsServer)
+				availability := classifyMetricError(errMetricsServer)
+				setMetricAvailabilityCondition(wpa, metricSpec.External.MetricName, availability)
+				if replicaCalculation.pods != nil {
+					wpa.Status.Pods = replicaCalculation.pods
+				}
+				if errMetricsServer != nil && shouldFallBack(logger, wpa, fallbacks, metricSpec.External.MetricName) {
+					r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "MetricFallback", "the external metric %s failed for too many consecutive reconciles, using its fallback: %v", metricSpec.External.MetricName, errMetricsServer)
+					fallenBack[metricSpec.External.MetricName] = true
+					continue
 				}
-
-				replicaCalculation, errMetricsServer := r.replicaCalc.GetExternalMetricReplicas(logger, scale, metricSpec, wpa)
 				if errMetricsServer != nil {
-					replicaProposal.Delete(promLabelsForWpa)
-					r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "FailedGetExternalMetric", errMetricsServer.Error())
-					setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetExternalMetric", "the HPA was unable to compute the replica count: %v", errMetricsServer)
-					return 0, "", nil, time.Time{}, fmt.Errorf("failed to get external metric %s: %v", metricSpec.External.MetricName, errMetricsServer)
+					failure := failedMetric{
+						name:    metricSpec.External.MetricName,
+						reason:  "FailedGetExternalMetric",
+						message: fmt.Sprintf("the HPA was unable to compute the replica count: %v", errMetricsServer),
+						err:     fmt.Errorf("failed to get external metric %s: %w", metricSpec.External.MetricName, errMetricsServer),
+					}
+					if availability == metricNotFound {
+						failure.reason = "ExternalMetricNotFound"
+						failure.message = fmt.Sprintf("the external metric does not exist, the WPA scales once it appears: %v", errMetricsServer)
+					}
+					r.eventRecorder.Event(wpa, corev1.EventTypeWarning, failure.reason, errMetricsServer.Error())
+					failed = append(failed, failure)
+					continue
+				}
+				resetMetricFailures(wpa, metricSpec.External.MetricName)
+				if replicaCalculation.estimated {
+					r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "EstimatedExternalMetric", "no value was returned for the external metric %s, it was estimated from the previous ones", metricSpec.External.MetricName)
 				}
+				emergency = emergency || replicaCalculation.emergency
 				replicaCountProposal = replicaCalculation.replicaCount
 				utilizationProposal = replicaCalculation.utilization
 				timestampProposal = replicaCalculation.timestamp
+				activeMetricProposal = newActiveMetricStatus(metricSpec.External.MetricName, replicaCalculation)
+				wpa.Status.MetricDetails = append(wpa.Status.MetricDetails, newMetricDetailStatus(metricSpec.External.MetricName, replicaCalculation))
+				if replicaCalculation.latency > slowestLatency {
+					slowestMetric, slowestLatency = metricSpec.External.MetricName, replicaCalculation.latency
+				}
 
-				lowwm.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.External.LowWatermark.MilliValue()))
-				lowwmV2.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.External.LowWatermark.MilliValue()))
-				highwm.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.External.HighWatermark.MilliValue()))
-				highwmV2.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.External.HighWatermark.MilliValue()))
 				replicaProposal.With(promLabelsForWpa).Set(float64(replicaCountProposal))
 
 				statuses[i] = autoscalingv2.MetricStatus{
@@ -561,35 +987,54 @@ func (r *ReconcileWatermarkPodAutoscaler) computeReplicasForMetrics(logger logr.
 			} else {
 				errMsg := "invalid external metric source: the high watermark and the low watermark are required"
 				r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "FailedGetExternalMetric", errMsg)
-				setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetExternalMetric", "the WPA was unable to compute the replica count: %v", err)
-				return 0, "", nil, time.Time{}, fmt.Errorf(errMsg)
+				setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetExternalMetric", "the WPA was unable to compute the replica count: %s", errMsg)
+				return 0, "", nil, time.Time{}, false, fmt.Errorf(errMsg)
 			}
 		case datadoghqv1alpha1.ResourceMetricSourceType:
-			if metricSpec.Resource.HighWatermark != nil && metricSpec.Resource.LowWatermark != nil {
+			if datadoghqv1alpha1.HasResourceWatermarks(metricSpec.Resource) {
 				metricNameProposal = fmt.Sprintf("%s{%v}", metricSpec.Resource.Name, metricSpec.Resource.MetricSelector.MatchLabels)
-				promLabelsForWpaWithMetricName := prometheus.Labels{
-					wpaNamePromLabel:           wpa.Name,
-					resourceNamespacePromLabel: wpa.Namespace,
-					resourceNamePromLabel:      wpa.Spec.ScaleTargetRef.Name,
-					resourceKindPromLabel:      wpa.Spec.ScaleTargetRef.Kind,
-					metricNamePromLabel:        string(metricSpec.Resource.Name),
-				}
 
-				replicaCalculation, errMetricsServer := r.replicaCalc.GetResourceReplicas(logger, scale, metricSpec, wpa)
+				var replicaCalculation ReplicaCalculation
+				errMetricsServer := r.metricRetries.allow(key, metricNameProposal, time.Now())
+				if errMetricsServer == nil {
+					ctx, cancel := context.WithTimeout(context.TODO(), metricsClientTimeout)
+					replicaCalculation, errMetricsServer = r.replicaCalc.GetResourceReplicas(ctx, logger, scale, metricSpec, wpa)
+					cancel()
+					r.metricRetries.record(key, metricNameProposal, errMetricsServer, time.Now())
+				}
+				setMetricsProviderCondition(wpa, errMetricsServer)
+				if replicaCalculation.pods != nil {
+					wpa.Status.Pods = replicaCalculation.pods
+				}
+				if errMetricsServer != nil && shouldFallBack(logger, wpa, fallbacks, string(metricSpec.Resource.Name)) {
+					r.eventRecorder.Eventf(wpa, corev1.EventTypeWarning, "MetricFallback", "the resource metric %s failed for too many consecutive reconciles, using its fallback: %v", metricSpec.Resource.Name, errMetricsServer)
+					fallenBack[string(metricSpec.Resource.Name)] = true
+					continue
+				}
 				if errMetricsServer != nil {
-					replicaProposal.Delete(promLabelsForWpa)
 					r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "FailedGetResourceMetric", errMetricsServer.Error())
-					setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetResourceMetric", "the WPA was unable to compute the replica count: %v", errMetricsServer)
-					return 0, "", nil, time.Time{}, fmt.Errorf("failed to get resource metric %s: %v", metricSpec.Resource.Name, errMetricsServer)
+					failed = append(failed, failedMetric{
+						name:    string(metricSpec.Resource.Name),
+						reason:  "FailedGetResourceMetric",
+						message: fmt.Sprintf("the WPA was unable to compute the replica count: %v", errMetricsServer),
+						err:     fmt.Errorf("failed to get resource metric %s: %w", metricSpec.Resource.Name, errMetricsServer),
+					})
+					continue
 				}
+				resetMetricFailures(wpa, string(metricSpec.Resource.Name))
+				if metricSpec.Resource.Name == wpa.Spec.DeletionCostResource {
+					r.writeDeletionCosts(logger, wpa, replicaCalculation.podMetrics)
+				}
+				emergency = emergency || replicaCalculation.emergency
 				replicaCountProposal = replicaCalculation.replicaCount
 				utilizationProposal = replicaCalculation.utilization
 				timestampProposal = replicaCalculation.timestamp
+				activeMetricProposal = newActiveMetricStatus(string(metricSpec.Resource.Name), replicaCalculation)
+				wpa.Status.MetricDetails = append(wpa.Status.MetricDetails, newMetricDetailStatus(string(metricSpec.Resource.Name), replicaCalculation))
+				if replicaCalculation.latency > slowestLatency {
+					slowestMetric, slowestLatency = string(metricSpec.Resource.Name), replicaCalculation.latency
+				}
 
-				lowwm.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.Resource.LowWatermark.MilliValue()))
-				lowwmV2.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.Resource.LowWatermark.MilliValue()))
-				highwm.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.Resource.HighWatermark.MilliValue()))
-				highwmV2.With(promLabelsForWpaWithMetricName).Set(float64(metricSpec.Resource.HighWatermark.MilliValue()))
 				replicaProposal.With(promLabelsForWpa).Set(float64(replicaCountProposal))
 
 				statuses[i] = autoscalingv2.MetricStatus{
@@ -603,23 +1048,87 @@ func (r *ReconcileWatermarkPodAutoscaler) computeReplicasForMetrics(logger logr.
 			} else {
 				errMsg := "invalid resource metric source: the high watermark and the low watermark are required"
 				r.eventRecorder.Event(wpa, corev1.EventTypeWarning, "FailedGetResourceMetric", errMsg)
-				setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetResourceMetric", "the WPA was unable to compute the replica count: %v", err)
-				return 0, "", nil, time.Time{}, fmt.Errorf(errMsg)
+				setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionFalse, "FailedGetResourceMetric", "the WPA was unable to compute the replica count: %s", errMsg)
+				return 0, "", nil, time.Time{}, false, fmt.Errorf(errMsg)
 			}
 
 		default:
-			return 0, "", nil, time.Time{}, fmt.Errorf("metricSpec.Type:%s not supported", metricSpec.Type)
+			return 0, "", nil, time.Time{}, false, fmt.Errorf("metricSpec.Type:%s not supported", metricSpec.Type)
 		}
+		proposals = append(proposals, metricProposal{spec: metricSpec, replicas: replicaCountProposal})
 		// replicas will end up being the max of the replicaCountProposal if there are several metrics
 		if replicas == 0 || replicaCountProposal > replicas {
 			timestamp = timestampProposal
 			replicas = replicaCountProposal
 			metric = metricNameProposal
+			wpa.Status.ActiveMetric = activeMetricProposal
 		}
 	}
-	setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionTrue, "ValidMetricFound", "the HPA was able to successfully calculate a replica count from %s", metric)
+	if len(failed) > 0 && len(proposals) == 0 {
+		replicaProposal.Delete(promLabelsForWpa)
+		setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionFalse, failed[0].reason, "%s", failed[0].message)
+		return 0, "", nil, time.Time{}, false, failed[0].err
+	}
+	statuses = evaluatedMetricStatuses(statuses)
+	applyMetricLimitFactors(logger, &wpa.Spec, scale.Status.Replicas, proposals)
+	setFallbackCondition(wpa, fallbacks, fallenBack)
+	setMetricsProviderLatencyCondition(wpa, slowestMetric, slowestLatency)
+	if len(failed) == 0 {
+		setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionTrue, "ValidMetricFound", "the HPA was able to successfully calculate a replica count from %s", metric)
+		return replicas, metric, statuses, timestamp, emergency, nil
+	}
+	// a failed metric may have recommended more replicas than the healthy ones, they are only trusted to scale up.
+	if replicas < scale.Status.Replicas {
+		logger.Info("Some metrics failed, holding the current replicas instead of downscaling", "failedMetrics", failedMetricNames(failed), "desiredReplicas", replicas, "currentReplicas", scale.Status.Replicas)
+		replicas = scale.Status.Replicas
+	}
+	setCondition(wpa, autoscalingv2.ScalingActive, corev1.ConditionTrue, "SomeMetricsFailed", "the WPA computed the replica count from %s, the metrics %v failed and are retried with a backoff", metric, failedMetricNames(failed))
+
+	return replicas, metric, statuses, timestamp, emergency, nil
+}
 
-	return replicas, metric, statuses, timestamp, nil
+// evaluatedMetricStatuses drops the statuses of the metrics that were not evaluated, such as the fallbacks of healthy
+// metrics, keeping the others in the order of the spec.
+func evaluatedMetricStatuses(statuses []autoscalingv2.MetricStatus) []autoscalingv2.MetricStatus {
+	evaluated := make([]autoscalingv2.MetricStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status.Type != "" {
+			evaluated = append(evaluated, status)
+		}
+	}
+	return evaluated
+}
+
+// newActiveMetricStatus reports the value of the metric and the watermarks it was compared to.
+func newActiveMetricStatus(name string, replicaCalculation ReplicaCalculation) *datadoghqv1alpha1.ActiveMetricStatus {
+	status := &datadoghqv1alpha1.ActiveMetricStatus{
+		Name:         name,
+		CurrentValue: *resource.NewMilliQuantity(replicaCalculation.utilization, resource.DecimalSI),
+	}
+	if replicaCalculation.lowWatermark != nil {
+		status.LowWatermark = *replicaCalculation.lowWatermark
+	}
+	if replicaCalculation.highWatermark != nil {
+		status.HighWatermark = *replicaCalculation.highWatermark
+	}
+	return status
+}
+
+// newMetricDetailStatus reports how the metric was compared to its watermarks, and the replicas it proposed.
+func newMetricDetailStatus(name string, replicaCalculation ReplicaCalculation) datadoghqv1alpha1.MetricDetailStatus {
+	status := datadoghqv1alpha1.MetricDetailStatus{
+		Name:             name,
+		RawValue:         *resource.NewMilliQuantity(replicaCalculation.rawValue, resource.DecimalSI),
+		AdjustedValue:    *resource.NewMilliQuantity(replicaCalculation.utilization, resource.DecimalSI),
+		ProposedReplicas: replicaCalculation.replicaCount,
+	}
+	if replicaCalculation.lowWatermark != nil && replicaCalculation.highWatermark != nil {
+		low, high := toleranceAdjustedWatermarks(replicaCalculation.lowWatermark, replicaCalculation.highWatermark, replicaCalculation.tolerance)
+		status.EffectiveLowWatermark = *resource.NewMilliQuantity(int64(low), resource.DecimalSI)
+		status.EffectiveHighWatermark = *resource.NewMilliQuantity(int64(high), resource.DecimalSI)
+		status.WithinBounds = float64(replicaCalculation.utilization) >= low && float64(replicaCalculation.utilization) <= high
+	}
+	return status
 }
 
 // setCondition sets the specific condition type on the given WPA to the specified value with the given reason
@@ -663,27 +1172,6 @@ func setConditionInList(inputList []autoscalingv2.HorizontalPodAutoscalerConditi
 
 // Stolen from upstream
 
-// normalizeDesiredReplicas takes the metrics desired replicas value and normalizes it based on the appropriate conditions (i.e. < maxReplicas, >
-// minReplicas, etc...)
-func normalizeDesiredReplicas(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas int32, prenormalizedDesiredReplicas int32) int32 {
-	var minReplicas int32
-	if wpa.Spec.MinReplicas != nil {
-		minReplicas = *wpa.Spec.MinReplicas
-	} else {
-		minReplicas = 0
-	}
-
-	desiredReplicas, condition, reason := convertDesiredReplicasWithRules(logger, wpa, currentReplicas, prenormalizedDesiredReplicas, minReplicas, wpa.Spec.MaxReplicas)
-
-	if desiredReplicas == prenormalizedDesiredReplicas {
-		setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionFalse, condition, reason)
-	} else {
-		setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, condition, reason)
-	}
-
-	return desiredReplicas
-}
-
 // convertDesiredReplicas performs the actual normalization, without depending on the `WatermarkPodAutoscaler`
 func convertDesiredReplicasWithRules(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas, desiredReplicas, wpaMinReplicas, wpaMaxReplicas int32) (int32, string, string) {
 
@@ -709,7 +1197,7 @@ func convertDesiredReplicasWithRules(logger logr.Logger, wpa *datadoghqv1alpha1.
 		restrictedScaling.With(promLabelsForWpa).Set(1)
 		possibleLimitingCondition = "ScaleDownLimit"
 		possibleLimitingReason = "the desired replica count is decreasing faster than the maximum scale rate"
-		logger.Info("Downscaling rate higher than limit of `scaleDownLimitFactor`, capping the maximum downscale to 'minimumAllowedReplicas'", "scaleDownLimitFactor", fmt.Sprintf("%.1f%%", wpa.Spec.ScaleDownLimitFactor), "wpaMinReplicas", wpaMinReplicas, "minimumAllowedReplicas", minimumAllowedReplicas)
+		logger.Info("Downscaling rate higher than limit of `scaleDownLimitFactor`, capping the maximum downscale to 'minimumAllowedReplicas'", "scaleDownLimitFactor", fmt.Sprintf("%.1f%%", wpa.Spec.ScaleDownLimitFactor), "maxChangePerReconcile", wpa.Spec.MaxChangePerReconcile, "wpaMinReplicas", wpaMinReplicas, "minimumAllowedReplicas", minimumAllowedReplicas)
 	case desiredReplicas >= scaleDownLimit:
 		minimumAllowedReplicas = wpaMinReplicas
 		restrictedScaling.With(promLabelsForWpa).Set(0)
@@ -728,7 +1216,7 @@ func convertDesiredReplicasWithRules(logger logr.Logger, wpa *datadoghqv1alpha1.
 		promLabelsForWpa[reasonPromLabel] = upscaleCappingPromLabel
 
 		restrictedScaling.With(promLabelsForWpa).Set(1)
-		logger.Info("Upscaling rate higher than limit of 'ScaleUpLimitFactor' up to 'maximumAllowedReplicas' replicas. Capping the maximum upscale to %d replicas", "scaleUpLimitFactor", fmt.Sprintf("%.1f%%", wpa.Spec.ScaleUpLimitFactor), "wpaMaxReplicas", wpaMaxReplicas, "maximumAllowedReplicas", maximumAllowedReplicas)
+		logger.Info("Upscaling rate higher than limit of 'ScaleUpLimitFactor' up to 'maximumAllowedReplicas' replicas. Capping the maximum upscale to %d replicas", "scaleUpLimitFactor", fmt.Sprintf("%.1f%%", wpa.Spec.ScaleUpLimitFactor), "maxChangePerReconcile", wpa.Spec.MaxChangePerReconcile, "wpaMaxReplicas", wpaMaxReplicas, "maximumAllowedReplicas", maximumAllowedReplicas)
 		possibleLimitingCondition = "ScaleUpLimit"
 		possibleLimitingReason = "the desired replica count is increasing faster than the maximum scale rate"
 	} else {
@@ -753,10 +1241,18 @@ func convertDesiredReplicasWithRules(logger logr.Logger, wpa *datadoghqv1alpha1.
 // Scaleup limit is used to maximize the upscaling rate.
 func calculateScaleUpLimit(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas int32) int32 {
 	// returns TO how much we can upscale, not BY how much.
-	return int32(float64(currentReplicas) + math.Max(1, math.Floor(wpa.Spec.ScaleUpLimitFactor/100*float64(currentReplicas))))
+	return int32(float64(currentReplicas) + maxChange(wpa, math.Max(1, math.Floor(wpa.Spec.ScaleUpLimitFactor/100*float64(currentReplicas)))))
 }
 
 // Scaledown limit is used to maximize the downscaling rate.
 func calculateScaleDownLimit(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, currentReplicas int32) int32 {
-	return int32(float64(currentReplicas) - math.Max(1, math.Floor(wpa.Spec.ScaleDownLimitFactor/100*float64(currentReplicas))))
+	return int32(float64(currentReplicas) - maxChange(wpa, math.Max(1, math.Floor(wpa.Spec.ScaleDownLimitFactor/100*float64(currentReplicas)))))
+}
+
+// maxChange caps the number of replicas allowed by a limit factor with the maxChangePerReconcile of the WPA.
+func maxChange(wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, change float64) float64 {
+	if wpa.Spec.MaxChangePerReconcile == nil {
+		return change
+	}
+	return math.Min(change, float64(*wpa.Spec.MaxChangePerReconcile))
 }
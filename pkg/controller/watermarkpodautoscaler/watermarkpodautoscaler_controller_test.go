@@ -17,6 +17,8 @@ import (
 
 	logr "github.com/go-logr/logr"
 	"github.com/magiconair/properties/assert"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
@@ -33,6 +35,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/scale"
 	fakescale "k8s.io/client-go/scale/fake"
+	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/kubernetes/pkg/controller/podautoscaler/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -60,7 +63,7 @@ func TestReconcileWatermarkPodAutoscaler_Reconcile(t *testing.T) {
 	logf.SetLogger(logf.ZapLogger(true))
 	log = logf.Log.WithName("TestReconcileWatermarkPodAutoscaler_Reconcile")
 	s := scheme.Scheme
-	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{})
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{}, &v1alpha1.WatermarkPodAutoscalerPolicy{}, &v1alpha1.WatermarkPodAutoscalerPolicyList{})
 	type fields struct {
 		client        client.Client
 		scaleclient   scale.ScalesGetter
@@ -108,8 +111,20 @@ func TestReconcileWatermarkPodAutoscaler_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{Labels: map[string]string{"foo-key": "bar-value"}}))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{},
 			wantErr: false,
+			wantFunc: func(c client.Client) error {
+				rq := newRequest(testingNamespace, testingWPAName)
+				wpa := &v1alpha1.WatermarkPodAutoscaler{}
+				err := c.Get(context.TODO(), rq.NamespacedName, wpa)
+				if err != nil {
+					return err
+				}
+				if len(wpa.Status.AppliedDefaults) != 7 || wpa.Status.AppliedDefaults[0] != "minReplicas" {
+					return fmt.Errorf("Unexpected applied defaults %v for a WPA without options", wpa.Status.AppliedDefaults)
+				}
+				return nil
+			},
 		},
 		{
 			name: "WatermarkPodAutoscalerfound and defaulted but invalid metric spec",
@@ -258,7 +273,7 @@ func TestReconcileWatermarkPodAutoscaler_Reconcile(t *testing.T) {
 					return err
 				}
 				cond := &v2beta1.HorizontalPodAutoscalerCondition{
-					Message: "Invalid WPA specification: Low WaterMark of External metric deadbeef{map[label:value]} has to be strictly inferior to the High Watermark",
+					Message: "Invalid WPA specification: the Spec.Metrics[0].External.LowWatermark of the metric deadbeef{map[label:value]} has to be strictly inferior to the HighWatermark, currently 4 and 3",
 				}
 				if wpa.Status.Conditions[0].Message != cond.Message {
 					return fmt.Errorf("Unexpected Condition for incorrectly configured WPA")
@@ -306,7 +321,8 @@ func newRequest(ns, name string) reconcile.Request {
 }
 
 type fakeMetricsClient struct {
-	getExternalMetrics func(metricName string, namespace string, selector labels.Selector) ([]int64, time.Time, error)
+	getExternalMetrics       func(metricName string, namespace string, selector labels.Selector) ([]int64, time.Time, error)
+	getExternalMetricBuckets func(metricName string, namespace string, selector labels.Selector, bucketLabel string) (map[string]int64, time.Time, error)
 }
 
 //// GetResourceMetric gets the given resource metric (and an associated oldest timestamp)
@@ -338,6 +354,26 @@ func (f fakeMetricsClient) GetExternalMetric(metricName string, namespace string
 	return nil, time.Time{}, nil
 }
 
+// GetContainerResourceMetric gets the given resource metric of the named container
+// for all pods matching the specified selector in the given namespace
+func (f fakeMetricsClient) GetContainerResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, container string) (metrics.PodMetricsInfo, time.Time, error) {
+	return nil, time.Time{}, nil
+}
+
+// GetWeightedResourceMetric gets the given resource metric of the weighted containers
+// for all pods matching the specified selector in the given namespace
+func (f fakeMetricsClient) GetWeightedResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, weights map[string]int32) (metrics.PodMetricsInfo, time.Time, error) {
+	return nil, time.Time{}, nil
+}
+
+// GetExternalMetricBuckets gets the values of the given external metric summed by bucket
+func (f fakeMetricsClient) GetExternalMetricBuckets(metricName string, namespace string, selector labels.Selector, bucketLabel string) (map[string]int64, time.Time, error) {
+	if f.getExternalMetricBuckets != nil {
+		return f.getExternalMetricBuckets(metricName, namespace, selector, bucketLabel)
+	}
+	return nil, time.Time{}, nil
+}
+
 func TestReconcileWatermarkPodAutoscaler_reconcileWPA(t *testing.T) {
 	eventBroadcaster := record.NewBroadcaster()
 	eventRecorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "TestReconcileWatermarkPodAutoscaler"})
@@ -346,7 +382,7 @@ func TestReconcileWatermarkPodAutoscaler_reconcileWPA(t *testing.T) {
 	s := scheme.Scheme
 
 	s.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.Deployment{})
-	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{})
+	s.AddKnownTypes(v1alpha1.SchemeGroupVersion, &v1alpha1.WatermarkPodAutoscaler{}, &v1alpha1.WatermarkPodAutoscalerPolicy{}, &v1alpha1.WatermarkPodAutoscalerPolicyList{})
 	type fields struct {
 		client        client.Client
 		scaleclient   scale.ScalesGetter
@@ -599,7 +635,13 @@ func TestReconcileWatermarkPodAutoscaler_reconcileWPA(t *testing.T) {
 				},
 			}
 
-			r.replicaCalc = NewReplicaCalculator(mClient, nil)
+			r.replicaCalc = NewReplicaCalculator(mClient, nil, nil)
+			if fakeScales, ok := r.scaleClient.(*fakescale.FakeScaleClient); ok && tt.args.scale != nil {
+				// The fake scales are not stored, only their selector is reported so that the pods of the target are not discovered.
+				fakeScales.AddReactor("get", "*", func(action core.Action) (bool, runtime.Object, error) {
+					return true, &autoscalingv1.Scale{Status: autoscalingv1.ScaleStatus{Selector: tt.args.scale.Status.Selector}}, nil
+				})
+			}
 			if tt.args.loadFunc != nil {
 				tt.args.loadFunc(r.client, r.scaleClient, tt.args.wpa, tt.args.scale)
 			}
@@ -641,6 +683,7 @@ func TestReconcileWatermarkPodAutoscaler_computeReplicasForMetrics(t *testing.T)
 		replicas     int32
 		MetricName   string
 		validMetrics int
+		activeMetric *v1alpha1.ActiveMetricStatus
 	}
 
 	tests := []struct {
@@ -682,7 +725,7 @@ func TestReconcileWatermarkPodAutoscaler_computeReplicasForMetrics(t *testing.T)
 			},
 			wantFunc: func(metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error) {
 				// With 8 replicas, the avg algo and an external value returned of 100 we have 10 replicas and the utilization of 10
-				return ReplicaCalculation{10, 10, time.Time{}}, nil
+				return ReplicaCalculation{replicaCount: 10, utilization: 10, timestamp: time.Time{}}, nil
 			},
 			err: nil,
 		},
@@ -715,7 +758,7 @@ func TestReconcileWatermarkPodAutoscaler_computeReplicasForMetrics(t *testing.T)
 			},
 			wantFunc: func(metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error) {
 				// With 8 replicas, the avg algo and an external value returned of 100 we have 10 replicas and the utilization of 10
-				return ReplicaCalculation{0, 0, time.Time{}}, fmt.Errorf("unable to fetch metrics from external metrics API")
+				return ReplicaCalculation{}, fmt.Errorf("unable to fetch metrics from external metrics API")
 			},
 			err: fmt.Errorf("failed to get external metric deadbeef: unable to fetch metrics from external metrics API"),
 		},
@@ -728,6 +771,12 @@ func TestReconcileWatermarkPodAutoscaler_computeReplicasForMetrics(t *testing.T)
 				validMetrics: 2,
 				replicas:     10,
 				MetricName:   "deadbeef{map[label:value]}",
+				activeMetric: &v1alpha1.ActiveMetricStatus{
+					Name:          "deadbeef",
+					CurrentValue:  *resource.NewMilliQuantity(10, resource.DecimalSI),
+					HighWatermark: *resource.NewQuantity(8, resource.DecimalSI),
+					LowWatermark:  *resource.NewQuantity(3, resource.DecimalSI),
+				},
 				wpa: test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
 					Labels: map[string]string{"foo-key": "bar-value"},
 					Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
@@ -761,9 +810,9 @@ func TestReconcileWatermarkPodAutoscaler_computeReplicasForMetrics(t *testing.T)
 			wantFunc: func(metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error) {
 				// With 8 replicas, the avg algo and an external value returned of 100 we have 10 replicas and the utilization of 10
 				if metric.External.MetricName == "deadbeef" {
-					return ReplicaCalculation{10, 10, time.Time{}}, nil
+					return ReplicaCalculation{replicaCount: 10, utilization: 10, timestamp: time.Time{}, lowWatermark: metric.External.LowWatermark, highWatermark: metric.External.HighWatermark}, nil
 				}
-				return ReplicaCalculation{8, 5, time.Time{}}, nil
+				return ReplicaCalculation{replicaCount: 8, utilization: 5, timestamp: time.Time{}, lowWatermark: metric.External.LowWatermark, highWatermark: metric.External.HighWatermark}, nil
 			},
 			err: nil,
 		},
@@ -779,7 +828,7 @@ func TestReconcileWatermarkPodAutoscaler_computeReplicasForMetrics(t *testing.T)
 			}
 			// If we have 2 metrics, we can assert on the two statuses
 			// We can also use the returned replica, metric etc that is from the highest scaling event
-			replicas, metric, statuses, _, err := r.computeReplicasForMetrics(logf.Log.WithName(tt.name), tt.args.wpa, tt.args.scale)
+			replicas, metric, statuses, _, _, err := r.computeReplicasForMetrics(logf.Log.WithName(tt.name), tt.args.wpa, tt.args.scale)
 			if err != nil && err.Error() != tt.err.Error() {
 				t.Errorf("Unexpected error %v", err)
 			}
@@ -792,6 +841,11 @@ func TestReconcileWatermarkPodAutoscaler_computeReplicasForMetrics(t *testing.T)
 			if len(statuses) != tt.args.validMetrics {
 				t.Errorf("Incorrect number of valid metrics")
 			}
+			if want, got := tt.args.activeMetric, tt.args.wpa.Status.ActiveMetric; want != nil {
+				if got == nil || want.Name != got.Name || want.CurrentValue.Cmp(got.CurrentValue) != 0 || want.HighWatermark.Cmp(got.HighWatermark) != 0 || want.LowWatermark.Cmp(got.LowWatermark) != 0 {
+					t.Errorf("Active metric is incorrect: %+v", got)
+				}
+			}
 
 		})
 	}
@@ -801,18 +855,18 @@ type fakeReplicaCalculator struct {
 	replicasFunc func(metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error)
 }
 
-func (f *fakeReplicaCalculator) GetExternalMetricReplicas(logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error) {
+func (f *fakeReplicaCalculator) GetExternalMetricReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error) {
 	if f.replicasFunc != nil {
 		return f.replicasFunc(metric, wpa)
 	}
-	return ReplicaCalculation{0, 0, time.Time{}}, nil
+	return ReplicaCalculation{}, nil
 }
 
-func (f *fakeReplicaCalculator) GetResourceReplicas(logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error) {
+func (f *fakeReplicaCalculator) GetResourceReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (replicaCalculation ReplicaCalculation, err error) {
 	if f.replicasFunc != nil {
 		return f.replicasFunc(metric, wpa)
 	}
-	return ReplicaCalculation{0, 0, time.Time{}}, nil
+	return ReplicaCalculation{}, nil
 }
 
 func TestReconcileWatermarkPodAutoscaler_shouldScale(t *testing.T) {
@@ -1021,6 +1075,15 @@ func TestCalculateScaleDownLimit(t *testing.T) {
 	}
 }
 
+func TestCalculateScaleLimitsWithMaxChangePerReconcile(t *testing.T) {
+	wpa := makeWPAScaleFactor(50, 30)
+	wpa.Spec.MaxChangePerReconcile = v1alpha1.NewInt32(20)
+	assert.Equal(t, int32(443), calculateScaleUpLimit(wpa, 423), "the change is capped at 20 replicas instead of 211")
+	assert.Equal(t, int32(403), calculateScaleDownLimit(wpa, 423), "the change is capped at 20 replicas instead of 126")
+	assert.Equal(t, int32(15), calculateScaleUpLimit(wpa, 10), "the factor is the binding constraint")
+	assert.Equal(t, int32(7), calculateScaleDownLimit(wpa, 10), "the factor is the binding constraint")
+}
+
 func makeWPASpec(wpaMinReplicas, wpaMaxReplicas int32, scaleUpLimit, scaleDownLimit float64) *v1alpha1.WatermarkPodAutoscaler {
 	wpa := makeWPAScaleFactor(scaleUpLimit, scaleDownLimit)
 	wpa.Spec.MinReplicas = &wpaMinReplicas
@@ -1135,6 +1198,127 @@ func newScaleForDeployment(replicasSpec, replicasStatus int32) *autoscalingv1.Sc
 		},
 		Status: autoscalingv1.ScaleStatus{
 			Replicas: replicasStatus,
+			Selector: "app=foo",
 		},
 	}
 }
+
+func TestCapDesiredReplicasWithBounds(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	groups := []v1alpha1.WPAGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "group"},
+			Status: v1alpha1.WPAGroupStatus{
+				Bounds: []v1alpha1.WPAGroupBound{
+					{WPA: "other", MinReplicas: 10, MaxReplicas: 10},
+					{WPA: testingWPAName, MinReplicas: 4, MaxReplicas: 6},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name            string
+		minReplicas     int32
+		desiredReplicas int32
+		want            int32
+	}{
+		{name: "within bounds", minReplicas: 1, desiredReplicas: 5, want: 5},
+		{name: "above bounds", minReplicas: 1, desiredReplicas: 9, want: 6},
+		{name: "below bounds", minReplicas: 1, desiredReplicas: 2, want: 4},
+		{name: "minReplicas takes precedence", minReplicas: 8, desiredReplicas: 9, want: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+				Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+					MinReplicas: getReplicas(tt.minReplicas),
+					MaxReplicas: 20,
+				},
+			})
+			assert.Equal(t, capDesiredReplicasWithBounds(logf.Log.WithName(tt.name), wpa, groups, tt.desiredReplicas), tt.want)
+		})
+	}
+}
+
+func TestPinReplicas(t *testing.T) {
+	spec := &v1alpha1.WatermarkPodAutoscalerSpec{
+		MinReplicas: getReplicas(1),
+		MaxReplicas: 12,
+	}
+	pinReplicas(spec, 4)
+	if *spec.MinReplicas != 4 || spec.MaxReplicas != 4 {
+		t.Errorf("pinReplicas() bounds = [%d, %d], want [4, 4]", *spec.MinReplicas, spec.MaxReplicas)
+	}
+}
+
+func TestSetProposalDelta(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{ScaleTargetRef: testCrossVersionObjectRef},
+	})
+	setProposalDelta(wpa, true, 8, 5)
+	require.Equal(t, 3.0, gaugeValue(t, replicaProposalDelta.With(wpaPromLabels(wpa))))
+	setProposalDelta(wpa, true, 2, 5)
+	require.Equal(t, -3.0, gaugeValue(t, replicaProposalDelta.With(wpaPromLabels(wpa))))
+	setProposalDelta(wpa, false, 0, 5)
+	require.False(t, replicaProposalDelta.Delete(wpaPromLabels(wpa)), "the delta is removed without a proposal")
+}
+
+func TestObserveAppliedReplicas(t *testing.T) {
+	wpa := test.NewWatermarkPodAutoscaler(testingNamespace, "histogram", &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{ScaleTargetRef: testCrossVersionObjectRef},
+	})
+	defer replicaHistogram.Delete(wpaPromLabels(wpa))
+	for _, replicas := range []int32{3, 3, 6, 20} {
+		observeAppliedReplicas(wpa, replicas)
+	}
+	m := &dto.Metric{}
+	require.NoError(t, replicaHistogram.With(wpaPromLabels(wpa)).(prometheus.Metric).Write(m))
+	require.Equal(t, uint64(4), m.GetHistogram().GetSampleCount())
+	require.Equal(t, 32.0, m.GetHistogram().GetSampleSum())
+	cumulativeCounts := map[float64]uint64{}
+	for _, bucket := range m.GetHistogram().GetBucket() {
+		cumulativeCounts[bucket.GetUpperBound()] = bucket.GetCumulativeCount()
+	}
+	require.Equal(t, uint64(0), cumulativeCounts[2])
+	require.Equal(t, uint64(2), cumulativeCounts[4])
+	require.Equal(t, uint64(3), cumulativeCounts[8])
+	require.Equal(t, uint64(4), cumulativeCounts[32])
+}
+
+func TestNewMetricDetailStatus(t *testing.T) {
+	calculation := ReplicaCalculation{
+		replicaCount:  6,
+		utilization:   85000,
+		rawValue:      255000,
+		lowWatermark:  resource.NewQuantity(60, resource.DecimalSI),
+		highWatermark: resource.NewQuantity(80, resource.DecimalSI),
+		tolerance:     0.1,
+	}
+	status := newMetricDetailStatus("requests", calculation)
+	require.Equal(t, "requests", status.Name)
+	require.Equal(t, "255", status.RawValue.String())
+	require.Equal(t, "85", status.AdjustedValue.String())
+	require.Equal(t, "54", status.EffectiveLowWatermark.String())
+	require.Equal(t, "88", status.EffectiveHighWatermark.String())
+	require.True(t, status.WithinBounds, "the value is above the high watermark, but within the tolerance")
+	require.Equal(t, int32(6), status.ProposedReplicas)
+
+	calculation.tolerance = 0
+	require.False(t, newMetricDetailStatus("requests", calculation).WithinBounds)
+}
+
+func TestComputeReplicasForMetricsWithoutWatermarks(t *testing.T) {
+	for _, metric := range []v1alpha1.MetricSpec{
+		{Type: v1alpha1.ExternalMetricSourceType, External: &v1alpha1.ExternalMetricSource{MetricName: "queue.lag", MetricSelector: &metav1.LabelSelector{}}},
+		{Type: v1alpha1.ResourceMetricSourceType, Resource: &v1alpha1.ResourceMetricSource{Name: corev1.ResourceCPU, MetricSelector: &metav1.LabelSelector{}}},
+	} {
+		wpa := test.NewWatermarkPodAutoscaler(testingNamespace, testingWPAName, &test.NewWatermarkPodAutoscalerOptions{
+			Spec: &v1alpha1.WatermarkPodAutoscalerSpec{MaxReplicas: 5, Metrics: []v1alpha1.MetricSpec{metric}},
+		})
+		r := &ReconcileWatermarkPodAutoscaler{eventRecorder: record.NewFakeRecorder(10), replicaCalc: &fakeReplicaCalculator{}}
+		_, _, _, _, _, err := r.computeReplicasForMetrics(logf.Log.WithName(t.Name()), wpa, newScaleForDeployment(3, 3))
+		require.Error(t, err)
+		require.Len(t, wpa.Status.Conditions, 1)
+		require.Equal(t, "the WPA was unable to compute the replica count: "+err.Error(), wpa.Status.Conditions[0].Message)
+	}
+}
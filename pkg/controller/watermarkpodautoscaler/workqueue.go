@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	workqueueBaseDelay time.Duration
+	workqueueMaxDelay  time.Duration
+	workqueueQPS       float64
+	workqueueBurst     int
+)
+
+func init() {
+	flag.DurationVar(&workqueueBaseDelay, "workqueue-base-delay", 5*time.Millisecond, "Delay before retrying the first failed reconcile of a WPA, doubled at each consecutive failure")
+	flag.DurationVar(&workqueueMaxDelay, "workqueue-max-delay", 1000*time.Second, "Maximum delay before retrying the reconcile of a WPA")
+	flag.Float64Var(&workqueueQPS, "workqueue-qps", 10, "Number of retries per second across all the WPAs")
+	flag.IntVar(&workqueueBurst, "workqueue-burst", 100, "Bucket size of the retries across all the WPAs")
+}
+
+// newQueueRateLimiter returns the rate limiter of the retries, the defaults being those of controller-runtime.
+func newQueueRateLimiter() workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(workqueueBaseDelay, workqueueMaxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(workqueueQPS), workqueueBurst)},
+	)
+}
+
+// setQueueRateLimiter makes the controller build its workqueue with the rate limiter. The version of
+// controller-runtime in use doesn't expose it in the controller.Options, the queue is built when the controller
+// starts by its MakeQueue field.
+func setQueueRateLimiter(c interface{}, name string, rateLimiter workqueue.RateLimiter) error {
+	v := reflect.ValueOf(c)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("unable to set the rate limiter of the controller %s: unexpected type %T", name, c)
+	}
+	makeQueue := func() workqueue.RateLimitingInterface {
+		return workqueue.NewNamedRateLimitingQueue(rateLimiter, name)
+	}
+	field := v.FieldByName("MakeQueue")
+	if !field.IsValid() || !field.CanSet() || field.Type() != reflect.TypeOf(makeQueue) {
+		return fmt.Errorf("unable to set the rate limiter of the controller %s: no MakeQueue field in %T", name, c)
+	}
+	field.Set(reflect.ValueOf(makeQueue))
+	return nil
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestQueueRateLimiter(t *testing.T) {
+	defer func(base, max time.Duration) { workqueueBaseDelay, workqueueMaxDelay = base, max }(workqueueBaseDelay, workqueueMaxDelay)
+	workqueueBaseDelay, workqueueMaxDelay = time.Second, 3*time.Second
+
+	rateLimiter := newQueueRateLimiter()
+	require.Equal(t, time.Second, rateLimiter.When("foo"))
+	require.Equal(t, 2*time.Second, rateLimiter.When("foo"))
+	require.Equal(t, 3*time.Second, rateLimiter.When("foo"), "the delay is capped")
+	rateLimiter.Forget("foo")
+	require.Equal(t, time.Second, rateLimiter.When("foo"))
+
+	c := &struct {
+		MakeQueue func() workqueue.RateLimitingInterface
+	}{}
+	require.NoError(t, setQueueRateLimiter(c, "foo", rateLimiter))
+	queue := c.MakeQueue()
+	defer queue.ShutDown()
+	require.Equal(t, 0, queue.NumRequeues("bar"))
+
+	require.Error(t, setQueueRateLimiter(&struct{}{}, "foo", rateLimiter))
+	require.Error(t, setQueueRateLimiter("foo", "foo", rateLimiter))
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package watermarkpodautoscaler
+
+import (
+	"context"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// capDesiredReplicasWithGroups keeps the desired replicas within the bounds computed by the WPAGroups of the namespace.
+// The minReplicas and maxReplicas of the WPA still take precedence over the bounds.
+func (r *ReconcileWatermarkPodAutoscaler) capDesiredReplicasWithGroups(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, desiredReplicas int32) int32 {
+	groups := &datadoghqv1alpha1.WPAGroupList{}
+	if err := r.client.List(context.TODO(), groups, client.InNamespace(wpa.Namespace)); err != nil {
+		logger.Error(err, "Could not list the WPAGroups")
+		return desiredReplicas
+	}
+	return capDesiredReplicasWithBounds(logger, wpa, groups.Items, desiredReplicas)
+}
+
+func capDesiredReplicasWithBounds(logger logr.Logger, wpa *datadoghqv1alpha1.WatermarkPodAutoscaler, groups []datadoghqv1alpha1.WPAGroup, desiredReplicas int32) int32 {
+	for _, group := range groups {
+		for _, bound := range group.Status.Bounds {
+			if bound.WPA != wpa.Name {
+				continue
+			}
+			capped := desiredReplicas
+			if capped > bound.MaxReplicas {
+				capped = bound.MaxReplicas
+			}
+			if capped < bound.MinReplicas {
+				capped = bound.MinReplicas
+			}
+			if capped > wpa.Spec.MaxReplicas {
+				capped = wpa.Spec.MaxReplicas
+			}
+			if wpa.Spec.MinReplicas != nil && capped < *wpa.Spec.MinReplicas {
+				capped = *wpa.Spec.MinReplicas
+			}
+			if capped != desiredReplicas {
+				setCondition(wpa, autoscalingv2.ScalingLimited, corev1.ConditionTrue, "LimitedByGroup", "the desired replica count is out of the bounds [%d, %d] of the WPAGroup %s", bound.MinReplicas, bound.MaxReplicas, group.Name)
+				logger.Info("Keeping the desired replicas within the bounds of the group", "group", group.Name, "desiredReplicas", desiredReplicas, "cappedReplicas", capped)
+				desiredReplicas = capped
+			}
+		}
+	}
+	return desiredReplicas
+}
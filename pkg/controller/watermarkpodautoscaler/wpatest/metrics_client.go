@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package wpatest provides fakes of the clients of the WatermarkPodAutoscaler controller, to unit test the code
+// embedding it or built on top of it against the behavior of the WPAs, without a cluster or a metrics provider.
+package wpatest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/controller/watermarkpodautoscaler"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	metricsclient "k8s.io/kubernetes/pkg/controller/podautoscaler/metrics"
+)
+
+// blank assignment to verify that MetricsClient implements watermarkpodautoscaler.MetricsClient
+var _ watermarkpodautoscaler.MetricsClient = &MetricsClient{}
+
+// MetricsClient is an in-memory watermarkpodautoscaler.MetricsClient serving the external metrics and the usage of the
+// pods it is given, timestamped at the time they are read. It fails as the clients of the metrics APIs do, so that
+// the controller tells the missing metrics from a failing provider.
+type MetricsClient struct {
+	mu       sync.Mutex
+	external map[string][]externalSeries
+	errors   map[string]error
+	pods     map[string]map[string]podUsage
+}
+
+// externalSeries is a series of an external metric, matched against the selector of the WPAs with its labels.
+type externalSeries struct {
+	labels labels.Set
+	value  int64
+}
+
+// podUsage is the usage of the containers of a pod, in milli-units, by resource.
+type podUsage struct {
+	labels     labels.Set
+	containers map[corev1.ResourceName]map[string]int64
+}
+
+// NewMetricsClient returns a MetricsClient without any metric.
+func NewMetricsClient() *MetricsClient {
+	return &MetricsClient{
+		external: map[string][]externalSeries{},
+		errors:   map[string]error{},
+		pods:     map[string]map[string]podUsage{},
+	}
+}
+
+// SetExternalMetric replaces the series of the external metric, one per value with the given labels, and clears its
+// error. Without values, the metric is missing.
+func (c *MetricsClient) SetExternalMetric(name string, seriesLabels map[string]string, milliValues ...int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.errors, name)
+	series := make([]externalSeries, 0, len(milliValues))
+	for _, v := range milliValues {
+		series = append(series, externalSeries{labels: labels.Set(seriesLabels), value: v})
+	}
+	c.external[name] = series
+}
+
+// SetExternalMetricBuckets replaces the series of the external metric with the buckets of a histogram, one series per
+// bound with the given labels and the bound as the value of bucketLabel, and clears its error.
+func (c *MetricsClient) SetExternalMetricBuckets(name string, seriesLabels map[string]string, bucketLabel string, milliValues map[string]int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.errors, name)
+	series := make([]externalSeries, 0, len(milliValues))
+	for bound, v := range milliValues {
+		bucketLabels := labels.Merge(labels.Set(seriesLabels), labels.Set{bucketLabel: bound})
+		series = append(series, externalSeries{labels: bucketLabels, value: v})
+	}
+	c.external[name] = series
+}
+
+// SetExternalMetricError makes the reads of the external metric fail with the error, wrapped as by the client of the
+// external metrics API. For instance, a NotFound error of the API machinery reports the metric as missing, and any
+// other error the provider as failing.
+func (c *MetricsClient) SetExternalMetricError(name string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[name] = err
+}
+
+// SetPodUsage sets the usage of the containers of a pod for a resource, in milli-units, keyed by container name.
+func (c *MetricsClient) SetPodUsage(namespace, pod string, podLabels map[string]string, resource corev1.ResourceName, milliValues map[string]int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pods[namespace] == nil {
+		c.pods[namespace] = map[string]podUsage{}
+	}
+	usage, found := c.pods[namespace][pod]
+	if !found {
+		usage = podUsage{containers: map[corev1.ResourceName]map[string]int64{}}
+	}
+	usage.labels = labels.Set(podLabels)
+	usage.containers[resource] = milliValues
+	c.pods[namespace][pod] = usage
+}
+
+// DeletePod removes the usage of a pod.
+func (c *MetricsClient) DeletePod(namespace, pod string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pods[namespace], pod)
+}
+
+// GetExternalMetric returns the values of the series of the external metric matching the selector.
+func (c *MetricsClient) GetExternalMetric(metricName string, namespace string, selector labels.Selector) ([]int64, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err, found := c.errors[metricName]; found {
+		return []int64{}, time.Time{}, fmt.Errorf("unable to fetch metrics from external metrics API: %v", err)
+	}
+	var values []int64
+	for _, series := range c.external[metricName] {
+		if selector == nil || selector.Matches(series.labels) {
+			values = append(values, series.value)
+		}
+	}
+	if len(values) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from external metrics API")
+	}
+	return values, time.Now(), nil
+}
+
+// GetExternalMetricBuckets returns the values of the series of the external metric matching the selector, summed by
+// the value of their bucketLabel.
+func (c *MetricsClient) GetExternalMetricBuckets(metricName string, namespace string, selector labels.Selector, bucketLabel string) (map[string]int64, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err, found := c.errors[metricName]; found {
+		return nil, time.Time{}, fmt.Errorf("unable to fetch metrics from external metrics API: %v", err)
+	}
+	buckets := map[string]int64{}
+	for _, series := range c.external[metricName] {
+		if selector != nil && !selector.Matches(series.labels) || !series.labels.Has(bucketLabel) {
+			continue
+		}
+		buckets[series.labels.Get(bucketLabel)] += series.value
+	}
+	if len(buckets) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from external metrics API with the %s label", bucketLabel)
+	}
+	return buckets, time.Now(), nil
+}
+
+// GetResourceMetric returns the usage of the pods matching the selector, summed over their containers.
+func (c *MetricsClient) GetResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector) (metricsclient.PodMetricsInfo, time.Time, error) {
+	return c.podMetrics(resource, namespace, selector, func(container string) (int64, bool) { return 100, true })
+}
+
+// GetContainerResourceMetric returns the usage of the named container of the pods matching the selector.
+func (c *MetricsClient) GetContainerResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, container string) (metricsclient.PodMetricsInfo, time.Time, error) {
+	return c.podMetrics(resource, namespace, selector, func(name string) (int64, bool) { return 100, name == container })
+}
+
+// GetWeightedResourceMetric returns the usage of the pods matching the selector, summed over their weighted
+// containers, each weighted in percent.
+func (c *MetricsClient) GetWeightedResourceMetric(resource corev1.ResourceName, namespace string, selector labels.Selector, weights map[string]int32) (metricsclient.PodMetricsInfo, time.Time, error) {
+	return c.podMetrics(resource, namespace, selector, func(container string) (int64, bool) {
+		weight, weighted := weights[container]
+		return int64(weight), weighted
+	})
+}
+
+// podMetrics sums the usage of the containers of the pods matching the selector, weighted in percent by weight, the
+// containers it doesn't return true for being left out.
+func (c *MetricsClient) podMetrics(resource corev1.ResourceName, namespace string, selector labels.Selector, weight func(container string) (int64, bool)) (metricsclient.PodMetricsInfo, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	res := metricsclient.PodMetricsInfo{}
+	for name, pod := range c.pods[namespace] {
+		if selector != nil && !selector.Matches(pod.labels) {
+			continue
+		}
+		sum := int64(0)
+		found := false
+		for container, value := range pod.containers[resource] {
+			w, ok := weight(container)
+			if !ok {
+				continue
+			}
+			sum += value * w / 100
+			found = true
+		}
+		if found {
+			res[name] = metricsclient.PodMetric{Timestamp: now, Window: time.Minute, Value: sum}
+		}
+	}
+	if len(res) == 0 {
+		return nil, time.Time{}, fmt.Errorf("no metrics returned from resource metrics API")
+	}
+	return res, now, nil
+}
+
+// GetRawMetric is not supported, the WPAs don't rely on the custom metrics API.
+func (c *MetricsClient) GetRawMetric(metricName string, namespace string, selector labels.Selector, metricSelector labels.Selector) (metricsclient.PodMetricsInfo, time.Time, error) {
+	return nil, time.Time{}, fmt.Errorf("the custom metrics API is not supported by the fake metrics client")
+}
+
+// GetObjectMetric is not supported, the WPAs don't rely on the custom metrics API.
+func (c *MetricsClient) GetObjectMetric(metricName string, namespace string, objectRef *autoscalingv2.CrossVersionObjectReference, metricSelector labels.Selector) (int64, time.Time, error) {
+	return 0, time.Time{}, fmt.Errorf("the custom metrics API is not supported by the fake metrics client")
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package wpatest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestMetricsClient(t *testing.T) {
+	c := NewMetricsClient()
+	c.SetExternalMetric("queue.lag", map[string]string{"shard": "a"}, 10, 20)
+	c.SetExternalMetric("queue.lag", map[string]string{"shard": "b"}, 30)
+
+	values, _, err := c.GetExternalMetric("queue.lag", "default", labels.SelectorFromSet(labels.Set{"shard": "b"}))
+	require.NoError(t, err)
+	require.Equal(t, []int64{30}, values, "the series are matched against the selector")
+	_, _, err = c.GetExternalMetric("unknown", "default", labels.Everything())
+	require.EqualError(t, err, "no metrics returned from external metrics API")
+
+	c.SetExternalMetricError("queue.lag", apierrors.NewNotFound(schema.GroupResource{Resource: "queue.lag"}, ""))
+	_, _, err = c.GetExternalMetric("queue.lag", "default", labels.Everything())
+	require.Contains(t, err.Error(), "unable to fetch metrics from external metrics API")
+	c.SetExternalMetric("queue.lag", nil, 10)
+	_, _, err = c.GetExternalMetric("queue.lag", "default", labels.Everything())
+	require.NoError(t, err, "setting the metric clears its error")
+
+	c.SetPodUsage("default", "app-1", map[string]string{"app": "app"}, corev1.ResourceCPU, map[string]int64{"app": 400, "sidecar": 100})
+	c.SetPodUsage("default", "other", map[string]string{"app": "other"}, corev1.ResourceCPU, map[string]int64{"app": 1000})
+	selector := labels.SelectorFromSet(labels.Set{"app": "app"})
+
+	metrics, _, err := c.GetResourceMetric(corev1.ResourceCPU, "default", selector)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	require.Equal(t, int64(500), metrics["app-1"].Value)
+	metrics, _, err = c.GetContainerResourceMetric(corev1.ResourceCPU, "default", selector, "sidecar")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), metrics["app-1"].Value)
+	metrics, _, err = c.GetWeightedResourceMetric(corev1.ResourceCPU, "default", selector, map[string]int32{"app": 100, "sidecar": 50})
+	require.NoError(t, err)
+	require.Equal(t, int64(450), metrics["app-1"].Value)
+
+	c.DeletePod("default", "app-1")
+	_, _, err = c.GetResourceMetric(corev1.ResourceCPU, "default", selector)
+	require.Error(t, err)
+}
+
+func TestMetricsClientBuckets(t *testing.T) {
+	c := NewMetricsClient()
+	c.SetExternalMetricBuckets("request.latency", map[string]string{"service": "checkout"}, "le", map[string]int64{"0.1": 40000, "+Inf": 50000})
+
+	buckets, _, err := c.GetExternalMetricBuckets("request.latency", "default", labels.SelectorFromSet(labels.Set{"service": "checkout"}), "le")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"0.1": 40000, "+Inf": 50000}, buckets)
+	_, _, err = c.GetExternalMetricBuckets("request.latency", "default", labels.Everything(), "bucket")
+	require.EqualError(t, err, "no metrics returned from external metrics API with the bucket label", "the series without the bucket label are left out")
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package wpatest
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewPodLister returns a PodLister over the pods, to give to watermarkpodautoscaler.NewReplicaCalculator in place of
+// the informer of the controller. Pods can be added to or removed from the returned indexer later on.
+func NewPodLister(pods ...*corev1.Pod) (corelisters.PodLister, cache.Indexer) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pod := range pods {
+		// the key of a pod can't fail to be computed from its namespace and name
+		_ = indexer.Add(pod)
+	}
+	return corelisters.NewPodLister(indexer), indexer
+}
+
+// NewReadyPod returns a running pod, ready since it started an hour ago, so that it is accounted for by the WPAs
+// regardless of their readinessDelay and minReadySeconds.
+func NewReadyPod(namespace, name string, podLabels map[string]string) *corev1.Pod {
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: podLabels, CreationTimestamp: started},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodRunning,
+			StartTime: &started,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: started},
+			},
+		},
+	}
+}
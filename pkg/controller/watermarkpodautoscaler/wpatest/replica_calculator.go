@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package wpatest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/controller/watermarkpodautoscaler"
+
+	"github.com/go-logr/logr"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+)
+
+// blank assignment to verify that ScriptedReplicaCalculator implements watermarkpodautoscaler.ReplicaCalculatorItf
+var _ watermarkpodautoscaler.ReplicaCalculatorItf = &ScriptedReplicaCalculator{}
+
+// Step is what the ScriptedReplicaCalculator returns for a metric at a reconcile.
+type Step struct {
+	// Replicas is the number of replicas proposed for the metric.
+	Replicas int32
+	// Utilization is the value of the metric, in milli-units.
+	Utilization int64
+	// Err fails the computation of the replicas of the metric.
+	Err error
+}
+
+// ScriptedReplicaCalculator is a watermarkpodautoscaler.ReplicaCalculatorItf returning the scripted steps of each
+// metric in turn, to test the decisions of the controller, such as the forbidden windows and the limits, independently
+// of the algorithm of the WPAs. Once the steps of a metric are exhausted, its last step is repeated.
+type ScriptedReplicaCalculator struct {
+	mu    sync.Mutex
+	steps map[string][]Step
+	calls map[string]int
+}
+
+// NewScriptedReplicaCalculator returns a ScriptedReplicaCalculator without any script.
+func NewScriptedReplicaCalculator() *ScriptedReplicaCalculator {
+	return &ScriptedReplicaCalculator{steps: map[string][]Step{}, calls: map[string]int{}}
+}
+
+// Script sets the steps of a metric, named after the metricName of an external metric or the name of a resource, and
+// starts them over.
+func (c *ScriptedReplicaCalculator) Script(metricName string, steps ...Step) *ScriptedReplicaCalculator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps[metricName] = steps
+	c.calls[metricName] = 0
+	return c
+}
+
+// Calls returns the number of times the replicas of a metric were computed since it was scripted.
+func (c *ScriptedReplicaCalculator) Calls(metricName string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[metricName]
+}
+
+// GetExternalMetricReplicas returns the next step of the external metric.
+func (c *ScriptedReplicaCalculator) GetExternalMetricReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (watermarkpodautoscaler.ReplicaCalculation, error) {
+	return c.next(metric.External.MetricName)
+}
+
+// GetResourceReplicas returns the next step of the resource metric.
+func (c *ScriptedReplicaCalculator) GetResourceReplicas(ctx context.Context, logger logr.Logger, target *autoscalingv1.Scale, metric v1alpha1.MetricSpec, wpa *v1alpha1.WatermarkPodAutoscaler) (watermarkpodautoscaler.ReplicaCalculation, error) {
+	return c.next(string(metric.Resource.Name))
+}
+
+func (c *ScriptedReplicaCalculator) next(metricName string) (watermarkpodautoscaler.ReplicaCalculation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	steps := c.steps[metricName]
+	if len(steps) == 0 {
+		return watermarkpodautoscaler.ReplicaCalculation{}, fmt.Errorf("no step scripted for the metric %s", metricName)
+	}
+	step := steps[len(steps)-1]
+	if c.calls[metricName] < len(steps) {
+		step = steps[c.calls[metricName]]
+	}
+	c.calls[metricName]++
+	if step.Err != nil {
+		return watermarkpodautoscaler.ReplicaCalculation{}, step.Err
+	}
+	return watermarkpodautoscaler.NewReplicaCalculation(step.Replicas, step.Utilization, time.Now()), nil
+}
@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package wpatest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1/test"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/controller/watermarkpodautoscaler"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var deployments = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func TestScriptedReplicaCalculator(t *testing.T) {
+	calc := NewScriptedReplicaCalculator().Script("queue.lag", Step{Replicas: 5, Utilization: 150}, Step{Err: fmt.Errorf("boom")}, Step{Replicas: 3})
+	metric := v1alpha1.MetricSpec{Type: v1alpha1.ExternalMetricSourceType, External: &v1alpha1.ExternalMetricSource{MetricName: "queue.lag"}}
+
+	_, err := calc.GetExternalMetricReplicas(context.TODO(), nil, nil, metric, nil)
+	require.NoError(t, err)
+	_, err = calc.GetExternalMetricReplicas(context.TODO(), nil, nil, metric, nil)
+	require.EqualError(t, err, "boom")
+	for i := 0; i < 2; i++ {
+		_, err = calc.GetExternalMetricReplicas(context.TODO(), nil, nil, metric, nil)
+		require.NoError(t, err, "the last step is repeated")
+	}
+	require.Equal(t, 4, calc.Calls("queue.lag"))
+
+	_, err = calc.GetResourceReplicas(context.TODO(), nil, nil, v1alpha1.MetricSpec{Type: v1alpha1.ResourceMetricSourceType, Resource: &v1alpha1.ResourceMetricSource{Name: "cpu"}}, nil)
+	require.Error(t, err, "the metrics without script fail")
+}
+
+func TestReconcileWithFakes(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, v1alpha1.AddToScheme(s))
+
+	wpa := test.NewWatermarkPodAutoscaler("default", "app", &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef: v1alpha1.CrossVersionObjectReference{Kind: "Deployment", Name: "app", APIVersion: "apps/v1"},
+			MinReplicas:    v1alpha1.NewInt32(1),
+			MaxReplicas:    10,
+			Metrics: []v1alpha1.MetricSpec{
+				{
+					Type: v1alpha1.ExternalMetricSourceType,
+					External: &v1alpha1.ExternalMetricSource{
+						MetricName:     "queue.lag",
+						MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"service": "app"}},
+						HighWatermark:  resource.NewQuantity(100, resource.DecimalSI),
+						LowWatermark:   resource.NewQuantity(50, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	})
+	client := fake.NewFakeClientWithScheme(s, v1alpha1.DefaultWatermarkPodAutoscaler(wpa))
+	scales := NewScaleClient()
+	scales.SetScale(deployments, "default", "app", 2, "app=app")
+	metrics := NewMetricsClient()
+	metrics.SetExternalMetric("queue.lag", map[string]string{"service": "app"}, 300000)
+	metrics.SetExternalMetric("other", map[string]string{"service": "app"}, 1000)
+
+	podLister, _ := NewPodLister(NewReadyPod("default", "app-1", map[string]string{"app": "app"}), NewReadyPod("default", "app-2", map[string]string{"app": "app"}))
+	calc := watermarkpodautoscaler.NewReplicaCalculator(metrics, podLister, client)
+
+	r := watermarkpodautoscaler.NewReconciler(client, scales, podLister, testrestmapper.TestOnlyStaticRESTMapper(s), s, record.NewFakeRecorder(100), calc)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "app"}}
+	for i := 0; i < 2; i++ {
+		_, err := r.Reconcile(request)
+		require.NoError(t, err)
+	}
+
+	replicas, found := scales.Replicas(deployments, "default", "app")
+	require.True(t, found)
+	require.Equal(t, int32(3), replicas, "the upscale is limited by the default scaleUpLimitFactor")
+	require.Equal(t, []int32{3}, scales.Updates(deployments, "default", "app"), "the upscale forbidden window holds the second reconcile")
+
+	updated := &v1alpha1.WatermarkPodAutoscaler{}
+	require.NoError(t, client.Get(context.TODO(), request.NamespacedName, updated))
+	require.Equal(t, int32(3), updated.Status.DesiredReplicas)
+}
+
+func TestReconcileWithPodFeatures(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, v1alpha1.AddToScheme(s))
+
+	wpa := test.NewWatermarkPodAutoscaler("default", "app", &test.NewWatermarkPodAutoscalerOptions{
+		Spec: &v1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef:                  v1alpha1.CrossVersionObjectReference{Kind: "Deployment", Name: "app", APIVersion: "apps/v1"},
+			MinReplicas:                     v1alpha1.NewInt32(1),
+			MaxReplicas:                     10,
+			ResourceQuotaAware:              true,
+			PauseUpscaleOnUnschedulablePods: true,
+			NodePressureAware:               true,
+			DeletionCostPolicy:              v1alpha1.DeletionCostPolicyWait,
+			Metrics: []v1alpha1.MetricSpec{
+				{
+					Type: v1alpha1.ExternalMetricSourceType,
+					External: &v1alpha1.ExternalMetricSource{
+						MetricName:     "queue.lag",
+						MetricSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"service": "app"}},
+						HighWatermark:  resource.NewQuantity(100, resource.DecimalSI),
+						LowWatermark:   resource.NewQuantity(50, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	})
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "quota"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("100")},
+			Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(s, v1alpha1.DefaultWatermarkPodAutoscaler(wpa), quota)
+	scales := NewScaleClient()
+	scales.SetScale(deployments, "default", "app", 2, "app=app")
+	metrics := NewMetricsClient()
+	metrics.SetExternalMetric("queue.lag", map[string]string{"service": "app"}, 300000)
+
+	podLister, _ := NewPodLister(NewReadyPod("default", "app-1", map[string]string{"app": "app"}), NewReadyPod("default", "app-2", map[string]string{"app": "app"}))
+	calc := watermarkpodautoscaler.NewReplicaCalculator(metrics, podLister, client)
+
+	r := watermarkpodautoscaler.NewReconciler(client, scales, podLister, testrestmapper.TestOnlyStaticRESTMapper(s), s, record.NewFakeRecorder(100), calc)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "app"}}
+	_, err := r.Reconcile(request)
+	require.NoError(t, err)
+
+	replicas, _ := scales.Replicas(deployments, "default", "app")
+	require.Equal(t, int32(3), replicas)
+}
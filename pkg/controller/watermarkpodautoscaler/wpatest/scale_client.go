@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package wpatest
+
+import (
+	"fmt"
+	"sync"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/scale"
+)
+
+// blank assignment to verify that ScaleClient implements scale.ScalesGetter
+var _ scale.ScalesGetter = &ScaleClient{}
+
+// ScaleClient is an in-memory scale.ScalesGetter over the scale subresource of the targets it is given. The targets
+// converge right away: the replicas of their status are the ones of their spec once updated.
+type ScaleClient struct {
+	mu      sync.Mutex
+	scales  map[scaleKey]*autoscalingv1.Scale
+	updates map[scaleKey][]int32
+}
+
+type scaleKey struct {
+	resource  schema.GroupResource
+	namespace string
+	name      string
+}
+
+// NewScaleClient returns a ScaleClient without any target.
+func NewScaleClient() *ScaleClient {
+	return &ScaleClient{scales: map[scaleKey]*autoscalingv1.Scale{}, updates: map[scaleKey][]int32{}}
+}
+
+// SetScale sets the replicas and the pod selector of a target, for instance the resource
+// schema.GroupResource{Group: "apps", Resource: "deployments"} of a Deployment.
+func (c *ScaleClient) SetScale(resource schema.GroupResource, namespace, name string, replicas int32, selector string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scales[scaleKey{resource, namespace, name}] = &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+		Status:     autoscalingv1.ScaleStatus{Replicas: replicas, Selector: selector},
+	}
+}
+
+// Replicas returns the replicas of the spec of a target, and whether the target exists.
+func (c *ScaleClient) Replicas(resource schema.GroupResource, namespace, name string) (int32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, found := c.scales[scaleKey{resource, namespace, name}]
+	if !found {
+		return 0, false
+	}
+	return s.Spec.Replicas, true
+}
+
+// Updates returns the replicas of the successive updates of the scale of a target.
+func (c *ScaleClient) Updates(resource schema.GroupResource, namespace, name string) []int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int32(nil), c.updates[scaleKey{resource, namespace, name}]...)
+}
+
+// Scales implements scale.ScalesGetter.
+func (c *ScaleClient) Scales(namespace string) scale.ScaleInterface {
+	return &namespacedScaleClient{client: c, namespace: namespace}
+}
+
+type namespacedScaleClient struct {
+	client    *ScaleClient
+	namespace string
+}
+
+func (n *namespacedScaleClient) Get(resource schema.GroupResource, name string) (*autoscalingv1.Scale, error) {
+	n.client.mu.Lock()
+	defer n.client.mu.Unlock()
+	s, found := n.client.scales[scaleKey{resource, n.namespace, name}]
+	if !found {
+		return nil, apierrors.NewNotFound(resource, name)
+	}
+	return s.DeepCopy(), nil
+}
+
+func (n *namespacedScaleClient) Update(resource schema.GroupResource, update *autoscalingv1.Scale) (*autoscalingv1.Scale, error) {
+	n.client.mu.Lock()
+	defer n.client.mu.Unlock()
+	key := scaleKey{resource, n.namespace, update.Name}
+	s, found := n.client.scales[key]
+	if !found {
+		return nil, apierrors.NewNotFound(resource, update.Name)
+	}
+	s.Spec.Replicas = update.Spec.Replicas
+	s.Status.Replicas = update.Spec.Replicas
+	n.client.updates[key] = append(n.client.updates[key], update.Spec.Replicas)
+	return s.DeepCopy(), nil
+}
+
+func (n *namespacedScaleClient) Patch(gvr schema.GroupVersionResource, name string, pt types.PatchType, data []byte) (*autoscalingv1.Scale, error) {
+	return nil, fmt.Errorf("patching the scale of %s %s is not supported by the fake scale client", gvr.Resource, name)
+}
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package wpagroup
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/watermarkpodautoscaler/pkg/util"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	defaultSyncPeriod = 15 * time.Second
+)
+
+var (
+	log                                                               = logf.Log.WithName("wpagroup_controller")
+	validCondition autoscalingv2.HorizontalPodAutoscalerConditionType = "Valid"
+)
+
+// Add creates a new WPAGroup Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileWPAGroup{
+		client:        mgr.GetClient(),
+		eventRecorder: mgr.GetEventRecorderFor("wpagroup_controller"),
+		syncPeriod:    defaultSyncPeriod,
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("wpagroup-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource WPAGroup
+	if err = c.Watch(&source.Kind{Type: &datadoghqv1alpha1.WPAGroup{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// The bounds follow the current replicas of the WPAs, so the groups of their namespace are requeued when they change
+	mapFn := handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+		return groupsInNamespace(mgr.GetClient(), a.Meta.GetNamespace())
+	})
+	return c.Watch(&source.Kind{Type: &datadoghqv1alpha1.WatermarkPodAutoscaler{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapFn})
+}
+
+func groupsInNamespace(c client.Client, namespace string) []reconcile.Request {
+	groups := &datadoghqv1alpha1.WPAGroupList{}
+	if err := c.List(context.TODO(), groups, client.InNamespace(namespace)); err != nil {
+		log.Error(err, "Could not list the WPAGroups", "namespace", namespace)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(groups.Items))
+	for _, group := range groups.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: group.Namespace, Name: group.Name}})
+	}
+	return requests
+}
+
+// blank assignment to verify that ReconcileWPAGroup implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileWPAGroup{}
+
+// ReconcileWPAGroup reconciles a WPAGroup object
+type ReconcileWPAGroup struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client        client.Client
+	eventRecorder record.EventRecorder
+	syncPeriod    time.Duration
+}
+
+// Reconcile computes the replicas allowed for the WPAs of a WPAGroup, given the current replicas of their references.
+// The WatermarkPodAutoscaler controller enforces these bounds when scaling the targets of the WPAs.
+// +kubebuilder:rbac:groups=datadoghq.com,resources=wpagroups;wpagroups/status,verbs=get;list;watch;update;patch
+func (r *ReconcileWPAGroup) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	if !util.OwnsNamespace(request.Namespace) {
+		// the namespace belongs to the shard of another replica of the controller
+		return reconcile.Result{}, nil
+	}
+	logger.Info("Reconciling WPAGroup")
+
+	group := &datadoghqv1alpha1.WPAGroup{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, group)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	statusOriginal := group.Status.DeepCopy()
+
+	if err = datadoghqv1alpha1.CheckWPAGroupValidity(group); err != nil {
+		logger.Info("Got an invalid WPAGroup spec", "error", err)
+		r.eventRecorder.Event(group, corev1.EventTypeWarning, "FailedSpecCheck", err.Error())
+		group.Status.Bounds = nil
+		setCondition(group, corev1.ConditionFalse, "FailedSpecCheck", "Invalid WPAGroup specification: %s", err)
+		// we don't requeue here, the update of the spec will requeue the resource.
+		return reconcile.Result{}, r.updateStatusIfNeeded(statusOriginal, group)
+	}
+
+	wpas := &datadoghqv1alpha1.WatermarkPodAutoscalerList{}
+	if err = r.client.List(context.TODO(), wpas, client.InNamespace(group.Namespace)); err != nil {
+		return reconcile.Result{}, err
+	}
+	bounds, missing := computeBounds(group, wpas.Items)
+	group.Status.Bounds = bounds
+	if len(missing) > 0 {
+		setCondition(group, corev1.ConditionFalse, "MissingWPA", "the WPAs %s of the group are missing, their constraints are ignored", strings.Join(missing, ", "))
+	} else {
+		setCondition(group, corev1.ConditionTrue, "BoundsComputed", "the bounds of the WPAs of the group are up to date")
+	}
+	if err = r.updateStatusIfNeeded(statusOriginal, group); err != nil {
+		r.eventRecorder.Event(group, corev1.EventTypeWarning, "FailedUpdateStatus", err.Error())
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{RequeueAfter: r.syncPeriod}, nil
+}
+
+func (r *ReconcileWPAGroup) updateStatusIfNeeded(statusOriginal *datadoghqv1alpha1.WPAGroupStatus, group *datadoghqv1alpha1.WPAGroup) error {
+	if apiequality.Semantic.DeepEqual(statusOriginal, &group.Status) {
+		return nil
+	}
+	return r.client.Status().Update(context.TODO(), group)
+}
+
+// computeBounds intersects the ranges of replicas allowed by the constraints of each WPA of the group.
+// It also returns the names of the WPAs referenced by the group that do not exist.
+func computeBounds(group *datadoghqv1alpha1.WPAGroup, wpas []datadoghqv1alpha1.WatermarkPodAutoscaler) ([]datadoghqv1alpha1.WPAGroupBound, []string) {
+	replicas := make(map[string]int32, len(wpas))
+	for _, wpa := range wpas {
+		replicas[wpa.Name] = wpa.Status.CurrentReplicas
+	}
+
+	boundsByWPA := map[string]*datadoghqv1alpha1.WPAGroupBound{}
+	missingSet := map[string]bool{}
+	for _, constraint := range group.Spec.Constraints {
+		referenceReplicas, found := replicas[constraint.Reference]
+		if !found {
+			missingSet[constraint.Reference] = true
+		}
+		if _, exists := replicas[constraint.WPA]; !exists {
+			missingSet[constraint.WPA] = true
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		bound, exists := boundsByWPA[constraint.WPA]
+		if !exists {
+			bound = &datadoghqv1alpha1.WPAGroupBound{WPA: constraint.WPA, MinReplicas: 0, MaxReplicas: math.MaxInt32}
+			boundsByWPA[constraint.WPA] = bound
+		}
+		if constraint.MinRatio != nil {
+			min := int32(math.Ceil(float64(referenceReplicas) * float64(*constraint.MinRatio) / 100))
+			if min > bound.MinReplicas {
+				bound.MinReplicas = min
+			}
+		}
+		if constraint.MaxRatio != nil {
+			max := int32(math.Floor(float64(referenceReplicas) * float64(*constraint.MaxRatio) / 100))
+			if max < bound.MaxReplicas {
+				bound.MaxReplicas = max
+			}
+		}
+	}
+
+	bounds := make([]datadoghqv1alpha1.WPAGroupBound, 0, len(boundsByWPA))
+	for _, bound := range boundsByWPA {
+		bounds = append(bounds, *bound)
+	}
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i].WPA < bounds[j].WPA })
+	missing := make([]string, 0, len(missingSet))
+	for name := range missingSet {
+		missing = append(missing, name)
+	}
+	sort.Strings(missing)
+	return bounds, missing
+}
+
+// setCondition sets the Valid condition of the group to the specified value with the given reason and message.
+// The message and args are treated like a format string.
+func setCondition(group *datadoghqv1alpha1.WPAGroup, status corev1.ConditionStatus, reason, message string, args ...interface{}) {
+	var existingCond *autoscalingv2.HorizontalPodAutoscalerCondition
+	for i, condition := range group.Status.Conditions {
+		if condition.Type == validCondition {
+			// can't take a pointer to an iteration variable
+			existingCond = &group.Status.Conditions[i]
+			break
+		}
+	}
+	if existingCond == nil {
+		group.Status.Conditions = append(group.Status.Conditions, autoscalingv2.HorizontalPodAutoscalerCondition{Type: validCondition})
+		existingCond = &group.Status.Conditions[len(group.Status.Conditions)-1]
+	}
+	if existingCond.Status != status {
+		existingCond.LastTransitionTime = metav1.Now()
+	}
+	existingCond.Status = status
+	existingCond.Reason = reason
+	existingCond.Message = fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package wpagroup
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newWPA(name string, currentReplicas int32) v1alpha1.WatermarkPodAutoscaler {
+	wpa := v1alpha1.WatermarkPodAutoscaler{}
+	wpa.Name = name
+	wpa.Status.CurrentReplicas = currentReplicas
+	return wpa
+}
+
+func TestComputeBounds(t *testing.T) {
+	wpas := []v1alpha1.WatermarkPodAutoscaler{
+		newWPA("dispatchers", 3),
+		newWPA("workers", 5),
+		newWPA("cache", 2),
+	}
+	tests := []struct {
+		name        string
+		constraints []v1alpha1.WPAGroupConstraint
+		want        []v1alpha1.WPAGroupBound
+		wantMissing []string
+	}{
+		{
+			name: "max ratio",
+			constraints: []v1alpha1.WPAGroupConstraint{
+				{WPA: "workers", Reference: "dispatchers", MaxRatio: v1alpha1.NewInt32(200)},
+			},
+			want:        []v1alpha1.WPAGroupBound{{WPA: "workers", MinReplicas: 0, MaxReplicas: 6}},
+			wantMissing: []string{},
+		},
+		{
+			name: "min and max ratios are intersected",
+			constraints: []v1alpha1.WPAGroupConstraint{
+				{WPA: "workers", Reference: "dispatchers", MinRatio: v1alpha1.NewInt32(100), MaxRatio: v1alpha1.NewInt32(300)},
+				{WPA: "workers", Reference: "cache", MaxRatio: v1alpha1.NewInt32(350)},
+				{WPA: "cache", Reference: "dispatchers", MinRatio: v1alpha1.NewInt32(50)},
+			},
+			want: []v1alpha1.WPAGroupBound{
+				{WPA: "cache", MinReplicas: 2, MaxReplicas: math.MaxInt32},
+				{WPA: "workers", MinReplicas: 3, MaxReplicas: 7},
+			},
+			wantMissing: []string{},
+		},
+		{
+			name: "missing WPAs",
+			constraints: []v1alpha1.WPAGroupConstraint{
+				{WPA: "workers", Reference: "unknown", MaxRatio: v1alpha1.NewInt32(200)},
+				{WPA: "gone", Reference: "dispatchers", MaxRatio: v1alpha1.NewInt32(200)},
+			},
+			want:        []v1alpha1.WPAGroupBound{},
+			wantMissing: []string{"gone", "unknown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &v1alpha1.WPAGroup{Spec: v1alpha1.WPAGroupSpec{Constraints: tt.constraints}}
+			bounds, missing := computeBounds(group, wpas)
+			require.Equal(t, tt.want, bounds)
+			require.Equal(t, tt.wantMissing, missing)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package hpaconversion
+
+import (
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+)
+
+const percentScalingPolicy = "Percent"
+
+// Behavior is the subset of the behavior of the HPAs, introduced after the version of the vendored API,
+// that has an equivalent in the WPA.
+type Behavior struct {
+	ScaleUp   *ScalingRules `json:"scaleUp,omitempty"`
+	ScaleDown *ScalingRules `json:"scaleDown,omitempty"`
+}
+
+// ScalingRules configures the scaling in one direction.
+type ScalingRules struct {
+	StabilizationWindowSeconds *int32          `json:"stabilizationWindowSeconds,omitempty"`
+	Policies                   []ScalingPolicy `json:"policies,omitempty"`
+}
+
+// ScalingPolicy limits the change of replicas during a period.
+type ScalingPolicy struct {
+	Type          string `json:"type"`
+	Value         int32  `json:"value"`
+	PeriodSeconds int32  `json:"periodSeconds"`
+}
+
+// ApplyBehavior maps the behavior of an HPA to the spec of a WPA: the stabilization windows become the forbidden
+// windows, and the largest Percent policy of each direction becomes its limit factor. The Pods policies are ignored.
+func ApplyBehavior(spec *datadoghqv1alpha1.WatermarkPodAutoscalerSpec, behavior *Behavior) {
+	if behavior == nil {
+		return
+	}
+	if rules := behavior.ScaleUp; rules != nil {
+		if rules.StabilizationWindowSeconds != nil && *rules.StabilizationWindowSeconds > 0 {
+			spec.UpscaleForbiddenWindowSeconds = *rules.StabilizationWindowSeconds
+		}
+		if factor := limitFactor(rules.Policies); factor > 0 {
+			spec.ScaleUpLimitFactor = factor
+		}
+	}
+	if rules := behavior.ScaleDown; rules != nil {
+		if rules.StabilizationWindowSeconds != nil && *rules.StabilizationWindowSeconds > 0 {
+			spec.DownscaleForbiddenWindowSeconds = *rules.StabilizationWindowSeconds
+		}
+		if factor := limitFactor(rules.Policies); factor > 0 {
+			spec.ScaleDownLimitFactor = factor
+		}
+	}
+}
+
+// limitFactor returns the largest Percent policy, within the range of the limit factors of the WPA, 0 if there is none.
+func limitFactor(policies []ScalingPolicy) float64 {
+	var factor float64
+	for _, policy := range policies {
+		if policy.Type == percentScalingPolicy && float64(policy.Value) > factor {
+			factor = float64(policy.Value)
+		}
+	}
+	if factor > 100 {
+		factor = 100
+	}
+	return factor
+}
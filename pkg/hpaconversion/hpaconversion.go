@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+// Package hpaconversion converts HorizontalPodAutoscalers into equivalent WatermarkPodAutoscalers.
+package hpaconversion
+
+import (
+	"fmt"
+	"math"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultBand is the default distance, in percent of the target of the HPA, between the target and each watermark.
+	DefaultBand = 10.0
+
+	averageAlgorithm  = "average"
+	absoluteAlgorithm = "absolute"
+)
+
+// Convert returns a WatermarkPodAutoscaler equivalent to the HorizontalPodAutoscaler, with the same name and namespace.
+// The target of each metric becomes a pair of watermarks, band percent below and above it.
+// The Object and Pods metrics aren't supported by the WPA.
+func Convert(hpa *autoscalingv2.HorizontalPodAutoscaler, band float64) (*datadoghqv1alpha1.WatermarkPodAutoscaler, error) {
+	if band <= 0 || band >= 100 {
+		return nil, fmt.Errorf("the band should be strictly between 0 and 100, currently %v", band)
+	}
+	wpa := &datadoghqv1alpha1.WatermarkPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: datadoghqv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "WatermarkPodAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      hpa.Name,
+			Namespace: hpa.Namespace,
+			Labels:    hpa.Labels,
+		},
+		Spec: datadoghqv1alpha1.WatermarkPodAutoscalerSpec{
+			ScaleTargetRef: datadoghqv1alpha1.CrossVersionObjectReference{
+				Kind:       hpa.Spec.ScaleTargetRef.Kind,
+				Name:       hpa.Spec.ScaleTargetRef.Name,
+				APIVersion: hpa.Spec.ScaleTargetRef.APIVersion,
+			},
+			MinReplicas: hpa.Spec.MinReplicas,
+			MaxReplicas: hpa.Spec.MaxReplicas,
+		},
+	}
+	for _, metric := range hpa.Spec.Metrics {
+		converted, algorithm, err := convertMetric(metric, band)
+		if err != nil {
+			return nil, err
+		}
+		if wpa.Spec.Algorithm != "" && wpa.Spec.Algorithm != algorithm {
+			return nil, fmt.Errorf("the metrics of the HPA require both the %s and the %s algorithms, a WPA only has one", wpa.Spec.Algorithm, algorithm)
+		}
+		wpa.Spec.Algorithm = algorithm
+		wpa.Spec.Metrics = append(wpa.Spec.Metrics, converted)
+	}
+	if len(wpa.Spec.Metrics) == 0 {
+		return nil, fmt.Errorf("the HPA has no metric")
+	}
+	return wpa, nil
+}
+
+// convertMetric returns the WPA metric equivalent to the HPA one, and the algorithm matching the semantics of its target.
+func convertMetric(metric autoscalingv2.MetricSpec, band float64) (datadoghqv1alpha1.MetricSpec, string, error) {
+	switch {
+	case metric.Type == autoscalingv2.ResourceMetricSourceType && metric.Resource != nil:
+		source := &datadoghqv1alpha1.ResourceMetricSource{
+			Name:           metric.Resource.Name,
+			MetricSelector: &metav1.LabelSelector{},
+		}
+		switch {
+		case metric.Resource.TargetAverageUtilization != nil:
+			source.LowWatermarkUtilization, source.HighWatermarkUtilization = utilizationBand(*metric.Resource.TargetAverageUtilization, band)
+		case metric.Resource.TargetAverageValue != nil:
+			source.LowWatermark, source.HighWatermark = quantityBand(*metric.Resource.TargetAverageValue, band)
+		default:
+			return datadoghqv1alpha1.MetricSpec{}, "", fmt.Errorf("the Resource metric %s has no target", metric.Resource.Name)
+		}
+		// the targets of the resource metrics are per pod
+		return datadoghqv1alpha1.MetricSpec{Type: datadoghqv1alpha1.ResourceMetricSourceType, Resource: source}, averageAlgorithm, nil
+	case metric.Type == autoscalingv2.ExternalMetricSourceType && metric.External != nil:
+		source := &datadoghqv1alpha1.ExternalMetricSource{
+			MetricName:     metric.External.MetricName,
+			MetricSelector: metric.External.MetricSelector,
+		}
+		if source.MetricSelector == nil {
+			source.MetricSelector = &metav1.LabelSelector{}
+		}
+		var algorithm string
+		switch {
+		case metric.External.TargetAverageValue != nil:
+			source.LowWatermark, source.HighWatermark = quantityBand(*metric.External.TargetAverageValue, band)
+			algorithm = averageAlgorithm
+		case metric.External.TargetValue != nil:
+			source.LowWatermark, source.HighWatermark = quantityBand(*metric.External.TargetValue, band)
+			algorithm = absoluteAlgorithm
+		default:
+			return datadoghqv1alpha1.MetricSpec{}, "", fmt.Errorf("the External metric %s has no target", metric.External.MetricName)
+		}
+		return datadoghqv1alpha1.MetricSpec{Type: datadoghqv1alpha1.ExternalMetricSourceType, External: source}, algorithm, nil
+	}
+	return datadoghqv1alpha1.MetricSpec{}, "", fmt.Errorf("the metrics of type %s are not supported by the WPA", metric.Type)
+}
+
+// utilizationBand returns the watermarks around a target utilization, the low one being at least 1%.
+func utilizationBand(target int32, band float64) (low, high *int32) {
+	lowValue := int32(math.Floor(float64(target) * (100 - band) / 100))
+	if lowValue < 1 {
+		lowValue = 1
+	}
+	highValue := int32(math.Ceil(float64(target) * (100 + band) / 100))
+	if highValue <= lowValue {
+		highValue = lowValue + 1
+	}
+	return datadoghqv1alpha1.NewInt32(lowValue), datadoghqv1alpha1.NewInt32(highValue)
+}
+
+// quantityBand returns the watermarks around a target quantity.
+func quantityBand(target resource.Quantity, band float64) (low, high *resource.Quantity) {
+	milli := float64(target.MilliValue())
+	low = resource.NewMilliQuantity(int64(math.Floor(milli*(100-band)/100)), target.Format)
+	high = resource.NewMilliQuantity(int64(math.Ceil(milli*(100+band)/100)), target.Format)
+	return low, high
+}
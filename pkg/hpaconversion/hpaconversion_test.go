@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package hpaconversion
+
+import (
+	"testing"
+
+	datadoghqv1alpha1 "github.com/DataDog/watermarkpodautoscaler/pkg/apis/datadoghq/v1alpha1"
+
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestHPA(metrics ...autoscalingv2.MetricSpec) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "foo"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "app", APIVersion: "apps/v1"},
+			MinReplicas:    datadoghqv1alpha1.NewInt32(2),
+			MaxReplicas:    10,
+			Metrics:        metrics,
+		},
+	}
+}
+
+func TestConvert(t *testing.T) {
+	target := resource.MustParse("100")
+	tests := []struct {
+		name          string
+		hpa           *autoscalingv2.HorizontalPodAutoscaler
+		band          float64
+		wantAlgorithm string
+		wantMetric    datadoghqv1alpha1.MetricSpec
+		wantErr       bool
+	}{
+		{
+			name: "resource utilization",
+			hpa: newTestHPA(autoscalingv2.MetricSpec{
+				Type:     autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceCPU, TargetAverageUtilization: datadoghqv1alpha1.NewInt32(70)},
+			}),
+			band:          10,
+			wantAlgorithm: "average",
+			wantMetric: datadoghqv1alpha1.MetricSpec{
+				Type: datadoghqv1alpha1.ResourceMetricSourceType,
+				Resource: &datadoghqv1alpha1.ResourceMetricSource{
+					Name:                     corev1.ResourceCPU,
+					MetricSelector:           &metav1.LabelSelector{},
+					LowWatermarkUtilization:  datadoghqv1alpha1.NewInt32(63),
+					HighWatermarkUtilization: datadoghqv1alpha1.NewInt32(77),
+				},
+			},
+		},
+		{
+			name: "external value",
+			hpa: newTestHPA(autoscalingv2.MetricSpec{
+				Type:     autoscalingv2.ExternalMetricSourceType,
+				External: &autoscalingv2.ExternalMetricSource{MetricName: "requests", TargetValue: &target},
+			}),
+			band:          20,
+			wantAlgorithm: "absolute",
+			wantMetric: datadoghqv1alpha1.MetricSpec{
+				Type: datadoghqv1alpha1.ExternalMetricSourceType,
+				External: &datadoghqv1alpha1.ExternalMetricSource{
+					MetricName:     "requests",
+					MetricSelector: &metav1.LabelSelector{},
+					LowWatermark:   resource.NewMilliQuantity(80000, resource.DecimalSI),
+					HighWatermark:  resource.NewMilliQuantity(120000, resource.DecimalSI),
+				},
+			},
+		},
+		{
+			name: "mixed algorithms",
+			hpa: newTestHPA(
+				autoscalingv2.MetricSpec{
+					Type:     autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceCPU, TargetAverageUtilization: datadoghqv1alpha1.NewInt32(70)},
+				},
+				autoscalingv2.MetricSpec{
+					Type:     autoscalingv2.ExternalMetricSourceType,
+					External: &autoscalingv2.ExternalMetricSource{MetricName: "requests", TargetValue: &target},
+				},
+			),
+			band:    10,
+			wantErr: true,
+		},
+		{
+			name: "pods metric",
+			hpa: newTestHPA(autoscalingv2.MetricSpec{
+				Type: autoscalingv2.PodsMetricSourceType,
+				Pods: &autoscalingv2.PodsMetricSource{MetricName: "requests", TargetAverageValue: target},
+			}),
+			band:    10,
+			wantErr: true,
+		},
+		{
+			name:    "invalid band",
+			hpa:     newTestHPA(),
+			band:    100,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wpa, err := Convert(tt.hpa, tt.band)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "foo", wpa.Name)
+			require.Equal(t, "bar", wpa.Namespace)
+			require.Equal(t, "app", wpa.Spec.ScaleTargetRef.Name)
+			require.Equal(t, int32(2), *wpa.Spec.MinReplicas)
+			require.Equal(t, int32(10), wpa.Spec.MaxReplicas)
+			require.Equal(t, tt.wantAlgorithm, wpa.Spec.Algorithm)
+			require.Len(t, wpa.Spec.Metrics, 1)
+			require.Equal(t, tt.wantMetric, wpa.Spec.Metrics[0])
+		})
+	}
+}
+
+const testingManifests = `apiVersion: v1
+kind: List
+items:
+- apiVersion: autoscaling/v1
+  kind: HorizontalPodAutoscaler
+  metadata:
+    name: web
+  spec:
+    scaleTargetRef:
+      kind: Deployment
+      name: web
+    maxReplicas: 20
+    targetCPUUtilizationPercentage: 60
+- apiVersion: autoscaling/v2beta2
+  kind: HorizontalPodAutoscaler
+  metadata:
+    name: worker
+  spec:
+    scaleTargetRef:
+      kind: Deployment
+      name: worker
+    maxReplicas: 50
+    metrics:
+    - type: External
+      external:
+        metric:
+          name: queue.length
+        target:
+          type: Value
+          value: "30"
+    behavior:
+      scaleDown:
+        stabilizationWindowSeconds: 600
+        policies:
+        - type: Pods
+          value: 4
+          periodSeconds: 60
+        - type: Percent
+          value: 10
+          periodSeconds: 60
+`
+
+func TestDecode(t *testing.T) {
+	hpas, behaviors, err := Decode([]byte(testingManifests))
+	require.NoError(t, err)
+	require.Len(t, hpas, 2)
+	require.Len(t, behaviors, 2)
+
+	require.Equal(t, "web", hpas[0].Name)
+	require.Equal(t, []autoscalingv2.MetricSpec{{
+		Type:     autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceCPU, TargetAverageUtilization: datadoghqv1alpha1.NewInt32(60)},
+	}}, hpas[0].Spec.Metrics)
+	require.Nil(t, behaviors[0])
+
+	require.Equal(t, "worker", hpas[1].Name)
+	require.Len(t, hpas[1].Spec.Metrics, 1)
+	require.Equal(t, "queue.length", hpas[1].Spec.Metrics[0].External.MetricName)
+	require.Equal(t, "30", hpas[1].Spec.Metrics[0].External.TargetValue.String())
+	require.NotNil(t, behaviors[1])
+	require.Equal(t, int32(600), *behaviors[1].ScaleDown.StabilizationWindowSeconds)
+
+	_, _, err = Decode([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n"))
+	require.Error(t, err)
+}
+
+func TestApplyBehavior(t *testing.T) {
+	spec := datadoghqv1alpha1.WatermarkPodAutoscalerSpec{}
+	ApplyBehavior(&spec, &Behavior{
+		ScaleUp: &ScalingRules{
+			Policies: []ScalingPolicy{{Type: "Percent", Value: 50}, {Type: "Percent", Value: 300}},
+		},
+		ScaleDown: &ScalingRules{
+			StabilizationWindowSeconds: datadoghqv1alpha1.NewInt32(600),
+			Policies:                   []ScalingPolicy{{Type: "Pods", Value: 4}, {Type: "Percent", Value: 10}},
+		},
+	})
+	require.Equal(t, int32(0), spec.UpscaleForbiddenWindowSeconds)
+	require.Equal(t, float64(100), spec.ScaleUpLimitFactor)
+	require.Equal(t, int32(600), spec.DownscaleForbiddenWindowSeconds)
+	require.Equal(t, float64(10), spec.ScaleDownLimitFactor)
+}
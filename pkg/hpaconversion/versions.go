@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package hpaconversion
+
+import (
+	"encoding/json"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Decode reads a manifest of a HorizontalPodAutoscaler in any of the autoscaling/v1, v2beta1 and v2beta2 versions,
+// or of a List of them, and returns them as v2beta1 HPAs along with their behaviors.
+func Decode(manifest []byte) ([]*autoscalingv2.HorizontalPodAutoscaler, []*Behavior, error) {
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(manifest, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if list, ok := obj.(*corev1.List); ok {
+		var hpas []*autoscalingv2.HorizontalPodAutoscaler
+		var behaviors []*Behavior
+		for _, item := range list.Items {
+			itemHPAs, itemBehaviors, err := Decode(item.Raw)
+			if err != nil {
+				return nil, nil, err
+			}
+			hpas = append(hpas, itemHPAs...)
+			behaviors = append(behaviors, itemBehaviors...)
+		}
+		return hpas, behaviors, nil
+	}
+	hpa, err := ToV2beta1(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	behavior, err := decodeBehavior(manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*autoscalingv2.HorizontalPodAutoscaler{hpa}, []*Behavior{behavior}, nil
+}
+
+// ToV2beta1 returns the autoscaling/v2beta1 version of a HorizontalPodAutoscaler.
+func ToV2beta1(obj runtime.Object) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	switch hpa := obj.(type) {
+	case *autoscalingv2.HorizontalPodAutoscaler:
+		return hpa, nil
+	case *autoscalingv1.HorizontalPodAutoscaler:
+		return fromV1(hpa), nil
+	case *autoscalingv2beta2.HorizontalPodAutoscaler:
+		return fromV2beta2(hpa), nil
+	}
+	return nil, fmt.Errorf("unsupported object %s", obj.GetObjectKind().GroupVersionKind().String())
+}
+
+func fromV1(hpa *autoscalingv1.HorizontalPodAutoscaler) *autoscalingv2.HorizontalPodAutoscaler {
+	converted := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: hpa.ObjectMeta,
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference(hpa.Spec.ScaleTargetRef),
+			MinReplicas:    hpa.Spec.MinReplicas,
+			MaxReplicas:    hpa.Spec.MaxReplicas,
+		},
+	}
+	if hpa.Spec.TargetCPUUtilizationPercentage != nil {
+		converted.Spec.Metrics = []autoscalingv2.MetricSpec{{
+			Type:     autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{Name: corev1.ResourceCPU, TargetAverageUtilization: hpa.Spec.TargetCPUUtilizationPercentage},
+		}}
+	}
+	return converted
+}
+
+// fromV2beta2 converts the Resource and External metrics, the other ones only keep their type.
+func fromV2beta2(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) *autoscalingv2.HorizontalPodAutoscaler {
+	converted := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: hpa.ObjectMeta,
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference(hpa.Spec.ScaleTargetRef),
+			MinReplicas:    hpa.Spec.MinReplicas,
+			MaxReplicas:    hpa.Spec.MaxReplicas,
+		},
+	}
+	for _, metric := range hpa.Spec.Metrics {
+		convertedMetric := autoscalingv2.MetricSpec{Type: autoscalingv2.MetricSourceType(metric.Type)}
+		switch {
+		case metric.Resource != nil:
+			convertedMetric.Resource = &autoscalingv2.ResourceMetricSource{
+				Name:                     metric.Resource.Name,
+				TargetAverageUtilization: metric.Resource.Target.AverageUtilization,
+				TargetAverageValue:       metric.Resource.Target.AverageValue,
+			}
+		case metric.External != nil:
+			convertedMetric.External = &autoscalingv2.ExternalMetricSource{
+				MetricName:         metric.External.Metric.Name,
+				MetricSelector:     metric.External.Metric.Selector,
+				TargetValue:        metric.External.Target.Value,
+				TargetAverageValue: metric.External.Target.AverageValue,
+			}
+		}
+		converted.Spec.Metrics = append(converted.Spec.Metrics, convertedMetric)
+	}
+	return converted
+}
+
+func decodeBehavior(manifest []byte) (*Behavior, error) {
+	hpa := struct {
+		Spec struct {
+			Behavior *Behavior `json:"behavior,omitempty"`
+		} `json:"spec"`
+	}{}
+	data, err := utilyaml.ToJSON(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &hpa); err != nil {
+		return nil, err
+	}
+	return hpa.Spec.Behavior, nil
+}
@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package util
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+var (
+	shardCount int
+	shardIndex int
+	// currentShard is the shard of the controller, set by SetupSharding. The controller owns all the namespaces
+	// until then.
+	currentShard = Shard{Index: 0, Count: 1}
+)
+
+func init() {
+	flag.IntVar(&shardCount, "shards", 1, "Number of shards the namespaces are split into, each replica of the controller processing the namespaces of its shard, 1 to disable the sharding")
+	flag.IntVar(&shardIndex, "shard-index", -1, "Index of the shard of the replica, from 0 to shards-1, derived from the ordinal of the name of the pod, e.g. watermarkpodautoscaler-2, when negative")
+}
+
+// Shard is a subset of the namespaces, the ones whose hash modulo Count is Index.
+type Shard struct {
+	Index int
+	Count int
+}
+
+// Owns returns whether the namespace belongs to the shard.
+func (s Shard) Owns(namespace string) bool {
+	if s.Count <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace))
+	return int(h.Sum32()%uint32(s.Count)) == s.Index
+}
+
+// LockName returns the name of the leader election lock of the shard, so that each shard has its own leader.
+func (s Shard) LockName(base string) string {
+	if s.Count <= 1 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d-of-%d", base, s.Index, s.Count)
+}
+
+// NewShard returns the shard configured by the flags, its index being derived from the name of the pod, as in a
+// StatefulSet, when the --shard-index flag is not set.
+func NewShard(count, index int, podName string) (Shard, error) {
+	if count < 1 {
+		return Shard{}, fmt.Errorf("the number of shards should be at least 1, currently %d", count)
+	}
+	if count == 1 {
+		return Shard{Index: 0, Count: 1}, nil
+	}
+	if index < 0 {
+		i := strings.LastIndex(podName, "-")
+		ordinal, err := strconv.Atoi(podName[i+1:])
+		if i < 0 || err != nil {
+			return Shard{}, fmt.Errorf("unable to derive the shard index from the pod name %q, set --shard-index", podName)
+		}
+		index = ordinal
+	}
+	if index >= count {
+		return Shard{}, fmt.Errorf("the shard index should be lower than the number of shards %d, currently %d", count, index)
+	}
+	return Shard{Index: index, Count: count}, nil
+}
+
+// SetupSharding sets the shard of the controller from the flags and returns it.
+func SetupSharding(podName string) (Shard, error) {
+	shard, err := NewShard(shardCount, shardIndex, podName)
+	if err != nil {
+		return Shard{}, err
+	}
+	currentShard = shard
+	return shard, nil
+}
+
+// OwnsNamespace returns whether the namespace belongs to the shard of the controller.
+func OwnsNamespace(namespace string) bool {
+	return currentShard.Owns(namespace)
+}
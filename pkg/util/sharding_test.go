@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2019 Datadog, Inc.
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShard(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		index   int
+		podName string
+		want    Shard
+		wantErr string
+	}{
+		{
+			name:  "disabled",
+			count: 1,
+			index: -1,
+			want:  Shard{Index: 0, Count: 1},
+		},
+		{
+			name:  "index flag",
+			count: 3,
+			index: 1,
+			want:  Shard{Index: 1, Count: 3},
+		},
+		{
+			name:    "ordinal of the pod",
+			count:   3,
+			index:   -1,
+			podName: "watermarkpodautoscaler-2",
+			want:    Shard{Index: 2, Count: 3},
+		},
+		{
+			name:    "pod without ordinal",
+			count:   3,
+			index:   -1,
+			podName: "watermarkpodautoscaler-7d9f8b6c4-xk2lp",
+			wantErr: `unable to derive the shard index from the pod name "watermarkpodautoscaler-7d9f8b6c4-xk2lp", set --shard-index`,
+		},
+		{
+			name:    "index out of range",
+			count:   3,
+			index:   -1,
+			podName: "watermarkpodautoscaler-3",
+			wantErr: "the shard index should be lower than the number of shards 3, currently 3",
+		},
+		{
+			name:    "no shard",
+			count:   0,
+			wantErr: "the number of shards should be at least 1, currently 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shard, err := NewShard(tt.count, tt.index, tt.podName)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, shard)
+		})
+	}
+}
+
+func TestShardOwns(t *testing.T) {
+	shards := []Shard{{Index: 0, Count: 3}, {Index: 1, Count: 3}, {Index: 2, Count: 3}}
+	owned := make([]int, len(shards))
+	for i := 0; i < 300; i++ {
+		namespace := fmt.Sprintf("team-%d", i)
+		owners := 0
+		for j, shard := range shards {
+			if shard.Owns(namespace) {
+				owners++
+				owned[j]++
+			}
+		}
+		require.Equal(t, 1, owners, "namespace %s", namespace)
+	}
+	for _, count := range owned {
+		require.InDelta(t, 100, count, 30)
+	}
+	require.True(t, Shard{Index: 0, Count: 1}.Owns("team-1"))
+
+	require.Equal(t, "watermarkpodautoscaler-lock", Shard{Index: 0, Count: 1}.LockName("watermarkpodautoscaler-lock"))
+	require.Equal(t, "watermarkpodautoscaler-lock-1-of-3", shards[1].LockName("watermarkpodautoscaler-lock"))
+}